@@ -28,6 +28,9 @@ type Config struct {
 	Environment                   string
 	ConfigServiceHostName         string
 	UserRateLimit                 int
+	TrashRetentionDays            int
+	MediaPath                     string
+	MaxMediaSizeMB                int
 }
 
 type ConfigInterface interface {
@@ -47,6 +50,9 @@ type ConfigInterface interface {
 	GetMailGunAPIKey() string
 	GetEnv() string
 	GetUserRateLimit() int
+	GetTrashRetentionDays() int
+	GetMediaPath() string
+	GetMaxMediaSizeMB() int
 }
 
 func loadEnvWithoutDefault(key string) string {
@@ -90,6 +96,16 @@ func NewConfig() (ConfigInterface, error) {
 		panic(err)
 	}
 
+	trashRetentionDays, err := strconv.Atoi(loadEnv("TRASH_RETENTION_DAYS", "30"))
+	if err != nil {
+		panic(err)
+	}
+
+	maxMediaSizeMB, err := strconv.Atoi(loadEnv("MAX_MEDIA_SIZE_MB", "10"))
+	if err != nil {
+		panic(err)
+	}
+
 	return &Config{
 		Port: loadEnv("PORT", "8080"),
 		MongoDBConnection: loadEnv(
@@ -110,9 +126,12 @@ func NewConfig() (ConfigInterface, error) {
 			"CARD_GENERATION_SERVICE_HOST_NAME",
 			"card-generation-service",
 		),
-		MailGunAPIKey: loadEnv("MAIL_GUN_API_KEY", ""),
-		Environment:   loadEnv("ENVIRONMENT", "dev"),
-		UserRateLimit: userRateLimit,
+		MailGunAPIKey:      loadEnv("MAIL_GUN_API_KEY", ""),
+		Environment:        loadEnv("ENVIRONMENT", "dev"),
+		UserRateLimit:      userRateLimit,
+		TrashRetentionDays: trashRetentionDays,
+		MediaPath:          loadEnv("MEDIA_PATH", "media"),
+		MaxMediaSizeMB:     maxMediaSizeMB,
 	}, nil
 }
 
@@ -179,3 +198,15 @@ func (c *Config) GetUserRateLimit() int {
 func (c *Config) GetMailDomain() string {
 	return c.MailDomain
 }
+
+func (c *Config) GetTrashRetentionDays() int {
+	return c.TrashRetentionDays
+}
+
+func (c *Config) GetMediaPath() string {
+	return c.MediaPath
+}
+
+func (c *Config) GetMaxMediaSizeMB() int {
+	return c.MaxMediaSizeMB
+}
@@ -1,6 +1,8 @@
 package auth
 
 import (
+	"crypto/rand"
+	"encoding/hex"
 	"fmt"
 	"time"
 
@@ -83,13 +85,33 @@ func (j *JWT) ValidateJWT(tokenString string, expClaims []string) (jwt.MapClaims
 	return claims, nil
 }
 
+// newTokenID returns the id a token is revoked by.
+func newTokenID() (string, error) {
+	id := make([]byte, 16)
+	if _, err := rand.Read(id); err != nil {
+		return "", err
+	}
+
+	return hex.EncodeToString(id), nil
+}
+
 func (j *JWT) CreateJWTWithClaims(
 	userID string,
 	additionalClaims ...map[string]interface{},
 ) (string, error) {
+	tokenID, err := newTokenID()
+	if err != nil {
+		return "", err
+	}
+
+	// the issue time has milliseconds, so logging in right after all tokens
+	// were revoked gives a token issued after the revocation
+	now := time.Now()
 	claims := jwt.MapClaims{
 		"Id":  userID,
-		"exp": time.Now().Add(j.expireOffset).Unix(),
+		"jti": tokenID,
+		"iat": float64(now.UnixMilli()) / 1000,
+		"exp": now.Add(j.expireOffset).Unix(),
 	}
 
 	for _, claim := range additionalClaims {
@@ -29,6 +29,18 @@ func TestValidateValidJWT(t *testing.T) {
 	assert.NotNil(t, claims)
 }
 
+func TestCreateJWTTokenIDs(t *testing.T) {
+	jwtObj := setup(60)
+	first, _ := jwtObj.CreateJWTWithClaims("1")
+	second, _ := jwtObj.CreateJWTWithClaims("1")
+
+	firstClaims, _ := jwtObj.ValidateJWT(first, []string{"jti", "iat"})
+	secondClaims, _ := jwtObj.ValidateJWT(second, []string{"jti", "iat"})
+
+	assert.NotEmpty(t, firstClaims["jti"])
+	assert.NotEqual(t, firstClaims["jti"], secondClaims["jti"])
+}
+
 func TestValidateInvalidJWT(t *testing.T) {
 	// set expire date to 0 so token is expired
 	jwtObj := setup(0)
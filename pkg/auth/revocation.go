@@ -0,0 +1,192 @@
+package auth
+
+import (
+	"context"
+	"math"
+	"sync"
+	"time"
+
+	"github.com/golang-jwt/jwt"
+	"github.com/moshrank/spacey-backend/pkg/db"
+	"github.com/moshrank/spacey-backend/pkg/logger"
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+const REVOCATION_COLLECTION = "tokenRevocation"
+
+// RevocationRefreshInterval is how often the gateway reloads the revoked
+// tokens, and with that the longest time a revoked token stays usable.
+const RevocationRefreshInterval = 10 * time.Second
+
+// RevocationMaxAge is how old the loaded revocations may get while reloading
+// them fails. Older revocations miss the tokens revoked since, so all tokens
+// are rejected until the revocations are loaded again.
+const RevocationMaxAge = 6 * RevocationRefreshInterval
+
+// Revocation invalidates the token with the id, or all tokens of the user
+// issued until RevokedAt if the token id is empty. RevokedAt is stored with
+// the millisecond precision tokens are issued with. The revocation is
+// removed at ExpiresAt, when the tokens it covers have expired anyway.
+type Revocation struct {
+	TokenID   string    `bson:"token_id,omitempty"`
+	UserID    string    `bson:"user_id"`
+	RevokedAt time.Time `bson:"revoked_at"`
+	ExpiresAt time.Time `bson:"expires_at"`
+}
+
+type RevocationStoreInterface interface {
+	Save(revocation *Revocation) error
+	FindAll() ([]Revocation, error)
+}
+
+type RevocationStore struct {
+	db db.DatabaseInterface
+}
+
+func NewRevocationStore(db db.DatabaseInterface) RevocationStoreInterface {
+	return &RevocationStore{db: db}
+}
+
+func (s *RevocationStore) Save(revocation *Revocation) error {
+	_, err := s.db.CreateDocument(REVOCATION_COLLECTION, revocation)
+
+	return err
+}
+
+// FindAll returns the revocations that have not expired yet. Expired ones
+// can still be found until mongo removes them.
+func (s *RevocationStore) FindAll() ([]Revocation, error) {
+	res, err := s.db.QueryDocuments(
+		REVOCATION_COLLECTION,
+		bson.M{"expires_at": bson.M{"$gt": time.Now()}},
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	revocations := []Revocation{}
+	err = res.All(context.TODO(), &revocations)
+
+	return revocations, err
+}
+
+type RevocationCheckerInterface interface {
+	IsRevoked(claims jwt.MapClaims) bool
+}
+
+// RevocationCache keeps the revocations in memory, so checking a token does
+// not need a database query.
+type RevocationCache struct {
+	store       RevocationStoreInterface
+	mutex       sync.RWMutex
+	tokens      map[string]bool
+	users       map[string]time.Time
+	refreshedAt time.Time
+}
+
+func NewRevocationCache(store RevocationStoreInterface) *RevocationCache {
+	return &RevocationCache{
+		store:  store,
+		tokens: map[string]bool{},
+		users:  map[string]time.Time{},
+	}
+}
+
+// Refresh loads the revocations from the store. The revocations loaded
+// before are kept if the store fails.
+func (c *RevocationCache) Refresh() error {
+	revocations, err := c.store.FindAll()
+	if err != nil {
+		return err
+	}
+
+	tokens := map[string]bool{}
+	users := map[string]time.Time{}
+	for _, revocation := range revocations {
+		if revocation.TokenID != "" {
+			tokens[revocation.TokenID] = true
+		} else if revocation.RevokedAt.After(users[revocation.UserID]) {
+			users[revocation.UserID] = revocation.RevokedAt
+		}
+	}
+
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	c.tokens = tokens
+	c.users = users
+	c.refreshedAt = time.Now()
+
+	return nil
+}
+
+// isStale reports whether the revocations are older than RevocationMaxAge.
+func (c *RevocationCache) isStale() bool {
+	c.mutex.RLock()
+	defer c.mutex.RUnlock()
+
+	return time.Since(c.refreshedAt) > RevocationMaxAge
+}
+
+// Run refreshes the revocations at the interval until the context is done.
+// Once the revocations are older than RevocationMaxAge, it reports once that
+// all tokens are rejected until a refresh succeeds.
+func (c *RevocationCache) Run(
+	ctx context.Context,
+	interval time.Duration,
+	log logger.LoggerInterface,
+) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	stale := false
+	for {
+		select {
+		case <-ticker.C:
+			if err := c.Refresh(); err != nil {
+				log.Error("failed to load revoked tokens: ", err)
+			}
+
+			if c.isStale() != stale {
+				stale = !stale
+				if stale {
+					log.Error("revoked tokens are outdated, all tokens are rejected")
+				} else {
+					log.Info("revoked tokens are loaded again, tokens are accepted")
+				}
+			}
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// IsRevoked reports whether the token with the claims was revoked. A token
+// revoked since the last refresh is still accepted, which is up to
+// RevocationRefreshInterval after it was revoked. If the revocations are
+// older than RevocationMaxAge, every token counts as revoked. Tokens issued
+// before tokens had ids can only be revoked together with all tokens of
+// their user, and tokens issued before their issue time had milliseconds
+// are revoked if they were issued in the same second as the revocation.
+func (c *RevocationCache) IsRevoked(claims jwt.MapClaims) bool {
+	c.mutex.RLock()
+	defer c.mutex.RUnlock()
+
+	if time.Since(c.refreshedAt) > RevocationMaxAge {
+		return true
+	}
+
+	if tokenID, ok := claims["jti"].(string); ok && c.tokens[tokenID] {
+		return true
+	}
+
+	userID, _ := claims["Id"].(string)
+	revokedAt, ok := c.users[userID]
+	if !ok {
+		return false
+	}
+
+	issuedAt, _ := claims["iat"].(float64)
+
+	return int64(math.Round(issuedAt*1000)) <= revokedAt.UnixMilli()
+}
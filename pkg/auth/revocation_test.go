@@ -0,0 +1,102 @@
+package auth
+
+import (
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt"
+	"github.com/stretchr/testify/assert"
+)
+
+type revocationStoreStub struct {
+	revocations []Revocation
+}
+
+func (s *revocationStoreStub) Save(revocation *Revocation) error {
+	s.revocations = append(s.revocations, *revocation)
+	return nil
+}
+
+func (s *revocationStoreStub) FindAll() ([]Revocation, error) {
+	return s.revocations, nil
+}
+
+func TestRevocationCacheIsRevoked(t *testing.T) {
+	revokedAt := time.Now()
+
+	tests := []struct {
+		testName    string
+		claims      jwt.MapClaims
+		wantRevoked bool
+	}{
+		{
+			"Revoked Token",
+			jwt.MapClaims{"Id": "1", "jti": "revoked", "iat": float64(revokedAt.Unix())},
+			true,
+		},
+		{
+			"Other Token",
+			jwt.MapClaims{"Id": "1", "jti": "other", "iat": float64(revokedAt.Unix())},
+			false,
+		},
+		{
+			"Issued Before User Revocation",
+			jwt.MapClaims{"Id": "2", "jti": "old", "iat": float64(revokedAt.Unix() - 60)},
+			true,
+		},
+		{
+			"Issued With User Revocation",
+			jwt.MapClaims{"Id": "2", "jti": "same", "iat": float64(revokedAt.UnixMilli()) / 1000},
+			true,
+		},
+		{
+			"Issued Right After User Revocation",
+			jwt.MapClaims{
+				"Id":  "2",
+				"jti": "login",
+				"iat": float64(revokedAt.UnixMilli()+1) / 1000,
+			},
+			false,
+		},
+		{
+			"Issued In Second Of User Revocation Without Milliseconds",
+			jwt.MapClaims{"Id": "2", "jti": "legacy", "iat": float64(revokedAt.Unix())},
+			true,
+		},
+		{
+			"Issued After User Revocation",
+			jwt.MapClaims{"Id": "2", "jti": "new", "iat": float64(revokedAt.Unix() + 60)},
+			false,
+		},
+		{
+			"Token Without Id",
+			jwt.MapClaims{"Id": "2"},
+			true,
+		},
+	}
+
+	cache := NewRevocationCache(&revocationStoreStub{revocations: []Revocation{
+		{TokenID: "revoked", UserID: "1", RevokedAt: revokedAt},
+		{UserID: "2", RevokedAt: revokedAt},
+	}})
+	assert.NoError(t, cache.Refresh())
+
+	for _, test := range tests {
+		t.Run(test.testName, func(t *testing.T) {
+			assert.Equal(t, test.wantRevoked, cache.IsRevoked(test.claims))
+		})
+	}
+}
+
+func TestRevocationCacheStale(t *testing.T) {
+	cache := NewRevocationCache(&revocationStoreStub{})
+	claims := jwt.MapClaims{"Id": "1", "jti": "token", "iat": float64(time.Now().Unix())}
+
+	assert.True(t, cache.IsRevoked(claims))
+
+	assert.NoError(t, cache.Refresh())
+	assert.False(t, cache.IsRevoked(claims))
+
+	cache.refreshedAt = time.Now().Add(-RevocationMaxAge - time.Second)
+	assert.True(t, cache.IsRevoked(claims))
+}
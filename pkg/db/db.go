@@ -29,8 +29,13 @@ type DatabaseInterface interface {
 	QueryDocument(string, interface{}) *mongo.SingleResult
 	QueryDocuments(string, interface{}, ...*options.FindOptions) (*mongo.Cursor, error)
 	CreateDocument(string, interface{}) (*mongo.InsertOneResult, error)
+	CreateDocuments(string, []interface{}) (*mongo.InsertManyResult, error)
 	UpdateDocument(string, interface{}, interface{}) (*mongo.UpdateResult, error)
+	UpdateDocuments(string, interface{}, interface{}) (*mongo.UpdateResult, error)
 	DeleteDocument(string, interface{}) (*mongo.DeleteResult, error)
+	DeleteDocuments(string, interface{}) (*mongo.DeleteResult, error)
+	CountDocuments(string, interface{}, ...*options.CountOptions) (int64, error)
+	Aggregate(string, interface{}) (*mongo.Cursor, error)
 }
 
 //go:embed migrations/*
@@ -120,6 +125,13 @@ func (db *Database) CreateDocument(
 	return db.DB.Collection(collectionName).InsertOne(context.TODO(), document)
 }
 
+func (db *Database) CreateDocuments(
+	collectionName string,
+	documents []interface{},
+) (*mongo.InsertManyResult, error) {
+	return db.DB.Collection(collectionName).InsertMany(context.TODO(), documents)
+}
+
 func (db *Database) UpdateDocument(
 	collectionName string,
 	filter interface{},
@@ -129,9 +141,40 @@ func (db *Database) UpdateDocument(
 		UpdateOne(context.TODO(), filter, update)
 }
 
+func (db *Database) UpdateDocuments(
+	collectionName string,
+	filter interface{},
+	update interface{},
+) (*mongo.UpdateResult, error) {
+	return db.DB.Collection(collectionName).
+		UpdateMany(context.TODO(), filter, update)
+}
+
 func (db *Database) DeleteDocument(
 	collectionName string,
 	filter interface{},
 ) (*mongo.DeleteResult, error) {
 	return db.DB.Collection(collectionName).DeleteOne(context.TODO(), filter)
 }
+
+func (db *Database) DeleteDocuments(
+	collectionName string,
+	filter interface{},
+) (*mongo.DeleteResult, error) {
+	return db.DB.Collection(collectionName).DeleteMany(context.TODO(), filter)
+}
+
+func (db *Database) CountDocuments(
+	collectionName string,
+	filter interface{},
+	opts ...*options.CountOptions,
+) (int64, error) {
+	return db.DB.Collection(collectionName).CountDocuments(context.TODO(), filter, opts...)
+}
+
+func (db *Database) Aggregate(
+	collectionName string,
+	pipeline interface{},
+) (*mongo.Cursor, error) {
+	return db.DB.Collection(collectionName).Aggregate(context.TODO(), pipeline)
+}
@@ -12,6 +12,7 @@ var ErrorMapping = map[int]string{
 	http.StatusForbidden:           "Forbidden",
 	http.StatusNotFound:            "Not Found",
 	http.StatusMethodNotAllowed:    "Method Not Allowed",
+	http.StatusPreconditionFailed:  "Precondition Failed",
 	http.StatusInternalServerError: "Internal Server Error",
 }
 
@@ -22,6 +23,13 @@ func WriteUnauthorized(c *gin.Context, message string) {
 	})
 }
 
+func WriteForbidden(c *gin.Context, message string) {
+	c.JSON(http.StatusForbidden, gin.H{
+		"error":   ErrorMapping[http.StatusForbidden],
+		"message": message,
+	})
+}
+
 func WriteDatabaseError(c *gin.Context) {
 	c.JSON(http.StatusInternalServerError, gin.H{
 		"error": ErrorMapping[http.StatusInternalServerError],
@@ -56,6 +64,13 @@ func WriteNotFound(c *gin.Context, message string) {
 	})
 }
 
+func WritePreconditionFailed(c *gin.Context, message string) {
+	c.JSON(http.StatusPreconditionFailed, gin.H{
+		"error":   ErrorMapping[http.StatusPreconditionFailed],
+		"message": message,
+	})
+}
+
 func WriteLimitReached(c *gin.Context) {
 	c.JSON(429, gin.H{
 		"error": "Too many requests",
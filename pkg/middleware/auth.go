@@ -1,6 +1,7 @@
 package middleware
 
 import (
+	"errors"
 	"fmt"
 
 	"github.com/gin-gonic/gin"
@@ -9,7 +10,11 @@ import (
 	"github.com/moshrank/spacey-backend/pkg/httpconst"
 )
 
-func Auth(authObj auth.JWTInterface, config config.ConfigInterface) gin.HandlerFunc {
+func Auth(
+	authObj auth.JWTInterface,
+	revocations auth.RevocationCheckerInterface,
+	config config.ConfigInterface,
+) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		authCookie, err := c.Request.Cookie("Authorization")
 		if err != nil {
@@ -26,7 +31,12 @@ func Auth(authObj auth.JWTInterface, config config.ConfigInterface) gin.HandlerF
 			return
 		}
 
-		if claims, err := authObj.ValidateJWT(tokenString, []string{"IsBeta", "EmailValidated"}); err == nil {
+		claims, err := authObj.ValidateJWT(tokenString, []string{"IsBeta", "EmailValidated"})
+		if err == nil && revocations.IsRevoked(claims) {
+			err = errors.New("token revoked")
+		}
+
+		if err == nil {
 			userID := claims["Id"].(string)
 			isBeta := claims["IsBeta"].(bool)
 			emailValidated := claims["EmailValidated"].(bool)
@@ -38,6 +48,10 @@ func Auth(authObj auth.JWTInterface, config config.ConfigInterface) gin.HandlerF
 			q.Add("isBeta", fmt.Sprintf("%t", isBeta))
 			q.Del("emailValidated")
 			q.Add("emailValidated", fmt.Sprintf("%t", emailValidated))
+			q.Del("tokenID")
+			if tokenID, ok := claims["jti"].(string); ok {
+				q.Add("tokenID", tokenID)
+			}
 			c.Request.URL.RawQuery = q.Encode()
 
 			c.Next()
@@ -5,17 +5,26 @@ import (
 	"testing"
 
 	"github.com/gin-gonic/gin"
+	"github.com/golang-jwt/jwt"
 	"github.com/moshrank/spacey-backend/config"
 	"github.com/moshrank/spacey-backend/pkg/auth"
 	"github.com/stretchr/testify/assert"
 )
 
+type RevocationCheckerMock struct {
+	revoked bool
+}
+
+func (m *RevocationCheckerMock) IsRevoked(claims jwt.MapClaims) bool {
+	return m.revoked
+}
+
 func TestAuthMiddlewareMissingToken(t *testing.T) {
 	c, _ := gin.CreateTestContext(httptest.NewRecorder())
 	c.Request = httptest.NewRequest("GET", "/", nil)
 
 	cfg, _ := config.NewConfig()
-	Auth(auth.NewJWT(cfg), cfg)(c)
+	Auth(auth.NewJWT(cfg), &RevocationCheckerMock{}, cfg)(c)
 
 	assert.Equal(t, c.Writer.Status(), 401)
 }
@@ -29,6 +38,36 @@ func TestAuthMiddlewareInvalidToken(t *testing.T) {
 	)
 
 	cfg, _ := config.NewConfig()
-	Auth(auth.NewJWT(cfg), cfg)(c)
+	Auth(auth.NewJWT(cfg), &RevocationCheckerMock{}, cfg)(c)
 	assert.Equal(t, c.Writer.Status(), 401)
 }
+
+func TestAuthMiddlewareRevokedToken(t *testing.T) {
+	tests := []struct {
+		testName       string
+		revoked        bool
+		wantStatusCode int
+	}{
+		{"Valid Token", false, 200},
+		{"Revoked Token", true, 401},
+	}
+
+	for _, test := range tests {
+		t.Run(test.testName, func(t *testing.T) {
+			cfg, _ := config.NewConfig()
+			jwtObj := auth.NewJWT(cfg)
+			token, _ := jwtObj.CreateJWTWithClaims("1", map[string]interface{}{
+				"IsBeta":         false,
+				"EmailValidated": true,
+			})
+
+			c, _ := gin.CreateTestContext(httptest.NewRecorder())
+			c.Request = httptest.NewRequest("GET", "/", nil)
+			c.Request.Header.Add("Cookie", "Authorization="+token)
+
+			Auth(jwtObj, &RevocationCheckerMock{revoked: test.revoked}, cfg)(c)
+
+			assert.Equal(t, test.wantStatusCode, c.Writer.Status())
+		})
+	}
+}
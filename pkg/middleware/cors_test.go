@@ -68,6 +68,18 @@ func TestCORS(t *testing.T) {
 				c.Writer.Header().Get("Access-Control-Allow-Origin"),
 			)
 			assert.Equal(t, test.wantStatusCode, c.Writer.Status())
+			if test.wantOrigin != "" {
+				assert.Contains(
+					t,
+					c.Writer.Header().Get("Access-Control-Expose-Headers"),
+					"ETag",
+				)
+				assert.Contains(
+					t,
+					c.Writer.Header().Get("Access-Control-Allow-Headers"),
+					"If-Match",
+				)
+			}
 		})
 	}
 
@@ -1,8 +1,11 @@
 package main
 
 import (
+	"context"
+
 	"github.com/gin-gonic/gin"
 	"github.com/moshrank/spacey-backend/config"
+	"github.com/moshrank/spacey-backend/pkg/auth"
 	"github.com/moshrank/spacey-backend/pkg/db"
 	"github.com/moshrank/spacey-backend/pkg/logger"
 	"github.com/moshrank/spacey-backend/pkg/middleware"
@@ -31,7 +34,16 @@ func main() {
 
 	router.GET("/prometheus", prometheusHandler())
 
-	routes.CreateRoutes(router, config, db)
+	// revoked tokens are loaded before requests are served, the gateway does
+	// not start without them so that revoked tokens are never accepted
+	revocations := auth.NewRevocationCache(auth.NewRevocationStore(db))
+	if err := revocations.Refresh(); err != nil {
+		log.Error("failed to load revoked tokens: ", err)
+		panic(err)
+	}
+	go revocations.Run(context.Background(), auth.RevocationRefreshInterval, log)
+
+	routes.CreateRoutes(router, config, db, revocations)
 
 	if err != nil {
 		panic(err)
@@ -14,7 +14,12 @@ import (
 	limiter "github.com/ulule/limiter/v3"
 )
 
-func CreateRoutes(router *gin.Engine, cfg config.ConfigInterface, db db.DatabaseInterface) {
+func CreateRoutes(
+	router *gin.Engine,
+	cfg config.ConfigInterface,
+	db db.DatabaseInterface,
+	revocations auth.RevocationCheckerInterface,
+) {
 	router.GET("/ping", handler.Ping)
 
 	/*
@@ -27,7 +32,7 @@ func CreateRoutes(router *gin.Engine, cfg config.ConfigInterface, db db.Database
 	router.Use(cors)
 
 	jwt := auth.NewJWT(cfg)
-	auth := middleware.Auth(jwt, cfg)
+	auth := middleware.Auth(jwt, revocations, cfg)
 
 	userStore := store.NewStore(db)
 	emailVerified := middleware.NeedsEmailVerified(userStore, jwt, cfg)
@@ -84,6 +89,11 @@ func CreateRoutes(router *gin.Engine, cfg config.ConfigInterface, db db.Database
 			auth,
 			util.ProxyWithPath(util.GetUrl(userServiceHostName, "logout")),
 		)
+		userGroup.POST(
+			"/logout/all",
+			auth,
+			util.ProxyWithPath(util.GetUrl(userServiceHostName, "logout/all")),
+		)
 	}
 
 	deckServiceHostName := cfg.GetDeckServiceHostName()
@@ -91,17 +101,87 @@ func CreateRoutes(router *gin.Engine, cfg config.ConfigInterface, db db.Database
 	{
 		deckGroup.GET("", util.ProxyWithPath(util.GetUrl(deckServiceHostName, "decks")))
 		deckGroup.POST("", util.ProxyWithPath(util.GetUrl(deckServiceHostName, "decks")))
+		deckGroup.GET("/tree", util.Proxy(deckServiceHostName))
 		deckGroup.PUT("/:deckID", util.Proxy(deckServiceHostName))
 		deckGroup.DELETE("/:deckID", util.Proxy(deckServiceHostName))
+		deckGroup.POST("/:deckID/fork", util.Proxy(deckServiceHostName))
+		deckGroup.POST("/:deckID/pull", util.Proxy(deckServiceHostName))
+		deckGroup.POST("/:deckID/move", util.Proxy(deckServiceHostName))
+		deckGroup.GET("/:deckID/study", util.Proxy(deckServiceHostName))
+		deckGroup.POST("/:deckID/restore", util.Proxy(deckServiceHostName))
+		deckGroup.POST("/:deckID/undo", util.Proxy(deckServiceHostName))
+		deckGroup.GET("/:deckID/activity", util.Proxy(deckServiceHostName))
+		deckGroup.POST("/:deckID/lint", util.Proxy(deckServiceHostName))
+		deckGroup.GET("/:deckID/lint/settings", util.Proxy(deckServiceHostName))
+		deckGroup.PUT("/:deckID/lint/settings", util.Proxy(deckServiceHostName))
+		deckGroup.POST("/:deckID/bulk", util.Proxy(deckServiceHostName))
+		deckGroup.POST("/:deckID/replace", util.Proxy(deckServiceHostName))
 
 		deckGroup.POST("/:deckID/card", util.Proxy(deckServiceHostName))
+		deckGroup.GET("/:deckID/cards", util.Proxy(deckServiceHostName))
 		deckGroup.POST("/:deckID/cards", util.Proxy(deckServiceHostName))
 		deckGroup.PUT("/:deckID/cards/:id", util.Proxy(deckServiceHostName))
 		deckGroup.DELETE("/:deckID/cards/:id", util.Proxy(deckServiceHostName))
+		deckGroup.POST("/:deckID/cards/:id/restore", util.Proxy(deckServiceHostName))
+		deckGroup.GET("/:deckID/cards/:id/revisions", util.Proxy(deckServiceHostName))
+		deckGroup.POST(
+			"/:deckID/cards/:id/revisions/:revisionID/restore",
+			util.Proxy(deckServiceHostName),
+		)
 
 		deckGroup.GET("/public", util.ProxyWithPath(util.GetUrl(deckServiceHostName, "/public")))
+
+		deckGroup.GET("/:deckID/members", util.Proxy(deckServiceHostName))
+		deckGroup.POST("/:deckID/members", util.Proxy(deckServiceHostName))
+		deckGroup.POST("/:deckID/members/accept", util.Proxy(deckServiceHostName))
+		deckGroup.PUT("/:deckID/members/:memberID", util.Proxy(deckServiceHostName))
+		deckGroup.DELETE("/:deckID/members/:memberID", util.Proxy(deckServiceHostName))
 	}
 
+	jsonEndpoints.GET(
+		"/invitations",
+		auth,
+		emailVerified,
+		util.ProxyWithPath(util.GetUrl(deckServiceHostName, "invitations")),
+	)
+
+	noteTypeGroup := jsonEndpoints.Group("/note-types").Use(auth, emailVerified)
+	{
+		noteTypeGroup.GET("", util.ProxyWithPath(util.GetUrl(deckServiceHostName, "note-types")))
+		noteTypeGroup.POST("", util.ProxyWithPath(util.GetUrl(deckServiceHostName, "note-types")))
+		noteTypeGroup.GET("/:noteTypeID", util.Proxy(deckServiceHostName))
+		noteTypeGroup.PUT("/:noteTypeID", util.Proxy(deckServiceHostName))
+		noteTypeGroup.DELETE("/:noteTypeID", util.Proxy(deckServiceHostName))
+	}
+
+	jsonEndpoints.GET(
+		"/trash",
+		auth,
+		emailVerified,
+		util.ProxyWithPath(util.GetUrl(deckServiceHostName, "trash")),
+	)
+
+	jsonEndpoints.GET(
+		"/search",
+		auth,
+		emailVerified,
+		util.ProxyWithPath(util.GetUrl(deckServiceHostName, "search")),
+	)
+
+	jsonEndpoints.GET(
+		"/sync",
+		auth,
+		emailVerified,
+		util.ProxyWithPath(util.GetUrl(deckServiceHostName, "sync")),
+	)
+
+	jsonEndpoints.POST(
+		"/sync",
+		auth,
+		emailVerified,
+		util.ProxyWithPath(util.GetUrl(deckServiceHostName, "sync")),
+	)
+
 	learningServiceHostName := cfg.GetLearningServiceHostName()
 	learningGroup := jsonEndpoints.Group("/learning").Use(auth, emailVerified)
 	{
@@ -178,6 +258,19 @@ func CreateRoutes(router *gin.Engine, cfg config.ConfigInterface, db db.Database
 		)
 	}
 
+	// imports, exports and media transfer files instead of json
+	deckTransferGroup := router.Group("/decks").Use(auth, emailVerified)
+	{
+		deckTransferGroup.POST("/:deckID/media", util.Proxy(deckServiceHostName))
+		deckTransferGroup.GET("/:deckID/media/:mediaID", util.Proxy(deckServiceHostName))
+		deckTransferGroup.POST("/import/anki", util.Proxy(deckServiceHostName))
+		deckTransferGroup.GET("/:deckID/export.apkg", util.Proxy(deckServiceHostName))
+		deckTransferGroup.POST("/:deckID/import", util.Proxy(deckServiceHostName))
+		deckTransferGroup.GET("/:deckID/export.csv", util.Proxy(deckServiceHostName))
+		deckTransferGroup.GET("/:deckID/export.tsv", util.Proxy(deckServiceHostName))
+		deckTransferGroup.GET("/:deckID/export.md", util.Proxy(deckServiceHostName))
+	}
+
 	fileUploadGroup := router.Group("/").Use(auth, emailVerified, middleware.NeedsBeta())
 	{
 		fileUploadGroup.POST(
@@ -0,0 +1,95 @@
+package routes
+
+import (
+	"bytes"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang-jwt/jwt"
+	"github.com/moshrank/spacey-backend/config"
+	"github.com/moshrank/spacey-backend/pkg/auth"
+	"github.com/stretchr/testify/assert"
+)
+
+type noRevocations struct{}
+
+func (noRevocations) IsRevoked(claims jwt.MapClaims) bool {
+	return false
+}
+
+func TestMediaRoutes(t *testing.T) {
+	var upload bytes.Buffer
+	form := multipart.NewWriter(&upload)
+	file, _ := form.CreateFormFile("file", "map.png")
+	file.Write([]byte("\x89PNG\x0D\x0A\x1A\x0A"))
+	form.Close()
+
+	tests := []struct {
+		testName    string
+		method      string
+		path        string
+		contentType string
+		body        []byte
+	}{
+		{
+			"Upload",
+			"POST",
+			"/decks/test_deck_id/media",
+			form.FormDataContentType(),
+			upload.Bytes(),
+		},
+		{"Download", "GET", "/decks/test_deck_id/media/test_media_id", "", nil},
+	}
+
+	for _, test := range tests {
+		t.Run(test.testName, func(t *testing.T) {
+			var proxied *http.Request
+			deckService := httptest.NewServer(
+				http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+					proxied = r
+					w.WriteHeader(http.StatusOK)
+				}),
+			)
+			defer deckService.Close()
+
+			cfg := &config.Config{
+				AuthSecretKey:       "secret",
+				MaxAgeAuth:          60,
+				UserRateLimit:       100,
+				DeckServiceHostName: strings.TrimPrefix(deckService.URL, "http://"),
+			}
+			token, _ := auth.NewJWT(cfg).CreateJWTWithClaims("1", map[string]interface{}{
+				"IsBeta":         false,
+				"EmailValidated": true,
+			})
+
+			gin.SetMode(gin.TestMode)
+			router := gin.New()
+			CreateRoutes(router, cfg, nil, noRevocations{})
+
+			// the reverse proxy needs a real connection to the client
+			gateway := httptest.NewServer(router)
+			defer gateway.Close()
+
+			body := bytes.NewReader(test.body)
+			req, _ := http.NewRequest(test.method, gateway.URL+test.path, body)
+			if test.contentType != "" {
+				req.Header.Set("Content-Type", test.contentType)
+			}
+			req.AddCookie(&http.Cookie{Name: "Authorization", Value: token})
+
+			res, err := http.DefaultClient.Do(req)
+
+			assert.Nil(t, err)
+			assert.Equal(t, http.StatusOK, res.StatusCode)
+			if assert.NotNil(t, proxied) {
+				assert.Equal(t, test.path, proxied.URL.Path)
+				assert.Equal(t, test.contentType, proxied.Header.Get("Content-Type"))
+			}
+		})
+	}
+}
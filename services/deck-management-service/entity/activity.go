@@ -0,0 +1,71 @@
+package entity
+
+import "time"
+
+// Actions recorded in the activity of a deck.
+const (
+	ActivityDeckCreate   = "deck_create"
+	ActivityDeckUpdate   = "deck_update"
+	ActivityDeckMove     = "deck_move"
+	ActivityDeckDelete   = "deck_delete"
+	ActivityDeckRestore  = "deck_restore"
+	ActivityDeckFork     = "deck_fork"
+	ActivityDeckPull     = "deck_pull"
+	ActivityLintSettings = "lint_settings"
+	ActivityCardCreate   = "card_create"
+	ActivityCardUpdate   = "card_update"
+	ActivityCardDelete   = "card_delete"
+	ActivityCardRestore  = "card_restore"
+	ActivityCardRevert   = "card_revert"
+	ActivityCardsImport  = "cards_import"
+	ActivityCardsMove    = "cards_move"
+	ActivityCardsCopy    = "cards_copy"
+	ActivityCardsDelete  = "cards_delete"
+	ActivityCardsReplace = "cards_replace"
+	ActivityUndo         = "undo"
+)
+
+// Activity records a change of a deck or its cards together with the user
+// who made it. Cards is the number of cards changed, while CardIDs holds
+// the ids of the first of them. Cards moved or copied to another deck show
+// up in the activity of both decks.
+type Activity struct {
+	ID           string           `bson:"_id,omitempty"`
+	DeckID       string           `bson:"deck_id"`
+	TargetDeckID string           `bson:"target_deck_id,omitempty"`
+	ActorID      string           `bson:"actor_id"`
+	Action       string           `bson:"action"`
+	Cards        int              `bson:"cards,omitempty"`
+	CardIDs      []string         `bson:"card_ids,omitempty"`
+	Changes      []ActivityChange `bson:"changes,omitempty"`
+	CreatedAt    *time.Time       `bson:"created_at"`
+}
+
+// ActivityChange is a field of a deck or card that was changed, with its
+// values shortened for display.
+type ActivityChange struct {
+	Field  string `bson:"field"            json:"field"`
+	Before string `bson:"before,omitempty" json:"before,omitempty"`
+	After  string `bson:"after,omitempty"  json:"after,omitempty"`
+}
+
+type ActivityRes struct {
+	ID           string           `json:"id"`
+	DeckID       string           `json:"deckID"`
+	TargetDeckID string           `json:"targetDeckID,omitempty"`
+	ActorID      string           `json:"actorID"`
+	Action       string           `json:"action"`
+	Cards        int              `json:"cards,omitempty"`
+	CardIDs      []string         `json:"cardIDs,omitempty"`
+	Changes      []ActivityChange `json:"changes,omitempty"`
+	CreatedAt    *time.Time       `json:"createdAt"`
+}
+
+type ActivityUseCaseInterface interface {
+	GetActivity(userID, deckID string, page *PageReq) ([]ActivityRes, string, error)
+}
+
+type ActivityStoreInterface interface {
+	Save(activity *Activity) error
+	FindByDeckID(deckID string, page *Page) ([]Activity, string, error)
+}
@@ -0,0 +1,52 @@
+package entity
+
+import "errors"
+
+// Actions of a bulk card request.
+const (
+	BulkMove   = "move"
+	BulkCopy   = "copy"
+	BulkDelete = "delete"
+)
+
+// Card fields find and replace works on.
+const (
+	FieldQuestion = "question"
+	FieldAnswer   = "answer"
+)
+
+type BulkCardReq struct {
+	Action       string   `json:"action"       binding:"required,oneof=move copy delete"`
+	CardIDs      []string `json:"cardIDs"      binding:"required,min=1,max=500,dive,required"`
+	TargetDeckID string   `json:"targetDeckID" binding:"required_unless=Action delete"`
+}
+
+// BulkCardRes tells how many cards a bulk action changed. CardIDs are the
+// ids of the copies when cards were copied. If the learning history of
+// moved cards could not be moved, the cards are moved nevertheless and
+// HistoryError tells why.
+type BulkCardRes struct {
+	Action       string   `json:"action"`
+	Cards        int      `json:"cards"`
+	CardIDs      []string `json:"cardIDs,omitempty"`
+	HistoryError string   `json:"historyError,omitempty"`
+}
+
+type FindReplaceReq struct {
+	Find      string   `json:"find"      binding:"required,max=200"`
+	Replace   string   `json:"replace"   binding:"max=200"`
+	Fields    []string `json:"fields"    binding:"omitempty,dive,oneof=question answer"`
+	MatchCase bool     `json:"matchCase"`
+}
+
+type FindReplaceRes struct {
+	Cards        int `json:"cards"`
+	Replacements int `json:"replacements"`
+}
+
+type BulkUseCaseInterface interface {
+	BulkCards(userID, deckID string, req *BulkCardReq) (*BulkCardRes, error)
+	FindReplace(userID, deckID string, req *FindReplaceReq) (*FindReplaceRes, error)
+}
+
+var ErrSameDeck = errors.New("cards are already in the target deck")
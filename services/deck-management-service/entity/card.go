@@ -1,42 +1,137 @@
 package entity
 
-import "time"
+import (
+	"errors"
+	"time"
+)
 
 type Card struct {
-	ID        string     `bson:"_id,omitempty"`
-	Question  string     `bson:"question"`
-	Answer    string     `bson:"answer"`
-	UserID    string     `bson:"user_id"`
-	DeckID    string     `bson:"deck_id"`
-	CreatedAt *time.Time `bson:"created_at"`
-	UpdatedAt *time.Time `bson:"updated_at"`
-	DeletedAt *time.Time `bson:"deleted_at"`
+	ID        string         `bson:"_id,omitempty"`
+	Question  string         `bson:"question"`
+	Answer    string         `bson:"answer"`
+	UserID    string         `bson:"user_id"`
+	DeckID    string         `bson:"deck_id"`
+	Tags      []string       `bson:"tags,omitempty"`
+	Source    string         `bson:"source,omitempty"`
+	Type      string         `bson:"type,omitempty"`
+	NoteID    string         `bson:"note_id,omitempty"`
+	Ordinal   int            `bson:"ordinal,omitempty"`
+	Cloze     *ClozeNote     `bson:"cloze,omitempty"`
+	Choices   []string       `bson:"choices,omitempty"`
+	Accepted  []string       `bson:"accepted,omitempty"`
+	Occlusion *OcclusionNote `bson:"occlusion,omitempty"`
+	Custom    *CustomNote    `bson:"custom,omitempty"`
+	SourceRef *SourceRef     `bson:"source_ref,omitempty"`
+	Origin    *CardOrigin    `bson:"origin,omitempty"`
+	Version   int            `bson:"version"`
+	CreatedAt *time.Time     `bson:"created_at"`
+	UpdatedAt *time.Time     `bson:"updated_at"`
+	DeletedAt *time.Time     `bson:"deleted_at"`
 }
 
+// CardOrigin links a card of a forked deck to the card it was copied from.
+// SyncedAt is set whenever the card content was taken over from its origin,
+// so a later UpdatedAt means the user edited the copy.
+type CardOrigin struct {
+	CardID   string     `bson:"card_id"`
+	SyncedAt *time.Time `bson:"synced_at"`
+}
+
+// SourceRef links a generated card to the passage of the note it was
+// generated from, given by the indices of its first character and of the
+// character after its end in the note text.
+type SourceRef struct {
+	NoteID     string `bson:"note_id"     json:"noteID"     binding:"required"`
+	StartIndex int    `bson:"start_index" json:"startIndex" binding:"min=0"`
+	EndIndex   int    `bson:"end_index"   json:"endIndex"   binding:"gtfield=StartIndex"`
+}
+
+// CardReq is a card as the user writes it. For cloze cards the question
+// holds the cloze text and the answer optional extra information. For image
+// occlusion cards the question holds the image and the masks are given
+// separately, while the answer holds optional extra information again.
+// Custom cards have neither, they are rendered from the fields of the note
+// by the templates of its note type.
 type CardReq struct {
-	ID       string `json:"id,omitempty"`
-	Question string `json:"question"     binding:"required"`
-	Answer   string `json:"answer"       binding:"required"`
-	DeckID   string `json:"deckID"       binding:"required"`
+	ID         string            `json:"id,omitempty"`
+	Question   string            `json:"question"             binding:"required_unless=Type custom"`
+	Answer     string            `json:"answer"               binding:"required_unless=Type cloze Type image_occlusion Type custom"`
+	DeckID     string            `json:"deckID"               binding:"required"`
+	Tags       []string          `json:"tags,omitempty"       binding:"omitempty,max=20,dive,min=1,max=30"`
+	Source     string            `json:"source"               binding:"omitempty,oneof=manual import generated"`
+	Type       string            `json:"type"                 binding:"omitempty,oneof=basic reversed cloze multiple_choice typed image_occlusion custom"`
+	Choices    []string          `json:"choices,omitempty"    binding:"omitempty,max=10,dive,min=1"`
+	Accepted   []string          `json:"accepted,omitempty"   binding:"omitempty,max=10,dive,min=1"`
+	Masks      []OcclusionMask   `json:"masks,omitempty" binding:"omitempty,max=50,dive"`
+	NoteTypeID string            `json:"noteTypeID,omitempty" binding:"required_if=Type custom"`
+	Fields     map[string]string `json:"fields,omitempty"     binding:"omitempty,max=30,dive,max=10000"`
+	SourceRef  *SourceRef        `json:"sourceRef,omitempty"`
 }
 
+// CardRes is a card as it is returned. The question and answer are
+// markdown with math, QuestionHTML and AnswerHTML hold them rendered into
+// safe html.
 type CardRes struct {
-	ID       string `json:"id"`
-	Question string `json:"question" binding:"required"`
-	Answer   string `json:"answer"   binding:"required"`
-	DeckID   string `json:"deckID"   binding:"required"`
+	ID           string         `json:"id"`
+	Question     string         `json:"question"           binding:"required"`
+	Answer       string         `json:"answer"             binding:"required"`
+	QuestionHTML string         `json:"questionHTML,omitempty"`
+	AnswerHTML   string         `json:"answerHTML,omitempty"`
+	DeckID       string         `json:"deckID"             binding:"required"`
+	Tags         []string       `json:"tags,omitempty"`
+	Type         string         `json:"type,omitempty"`
+	NoteID       string         `json:"noteID,omitempty"`
+	Ordinal      int            `json:"ordinal,omitempty"`
+	Cloze        *ClozeNote     `json:"cloze,omitempty"`
+	Choices      []string       `json:"choices,omitempty"`
+	Accepted     []string       `json:"accepted,omitempty"`
+	Occlusion    *OcclusionNote `json:"occlusion,omitempty"`
+	Custom       *CustomNote    `json:"custom,omitempty"`
+	SourceRef    *SourceRef     `json:"sourceRef,omitempty"`
+	Version      int            `json:"version,omitempty"`
+	Duplicate    *DuplicateRes  `json:"duplicate,omitempty"`
+	Skipped      bool           `json:"skipped,omitempty"`
 }
 
 type CardUseCaseInterface interface {
-	CreateCard(deckID, userID string, card *CardReq) (*CardRes, error)
-	CreateCards(deckID, userID string, card []CardReq) ([]CardRes, error)
-	UpdateCard(cardID, userID, deckID string, card *CardReq) (*CardRes, error)
-	DeleteCard(userID, deckID, cardID string) error
+	CreateCard(deckID, userID string, card *CardReq, duplicates *DuplicateReq) (*CardRes, error)
+	CreateCards(deckID, userID string, card []CardReq, duplicates *DuplicateReq) ([]CardRes, error)
+	UpdateCard(cardID, userID, deckID string, card *CardReq, ifMatch IfMatch) (*CardRes, error)
+	DeleteCard(userID, deckID, cardID string, ifMatch IfMatch) error
+	GetCards(userID, deckID string, page *PageReq) ([]CardRes, string, error)
+	SanitizeStoredCards() (int, error)
 }
 
 type CardStoreInterface interface {
 	SaveCard(deckID, userID string, card *Card) (string, error)
 	SaveCards(deckID, userID string, card []Card) ([]string, error)
 	UpdateCard(cardID, userID, deckID string, card *Card) error
-	DeleteCard(userID, deckID, cardID string) error
+	SyncCard(cardID, userID, deckID string, card *Card) error
+	DeleteCard(userID, deckID, cardID string, deletedAt time.Time) error
+	FindDeleted(deckIDs []string) ([]Card, error)
+	RestoreCard(userID, deckID, cardID string, restoredAt time.Time) error
+	Purge(deletedBefore time.Time) (int64, error)
+	FindByDeckID(deckID string, page *Page) ([]Card, string, error)
+	CountByDeckIDs(deckIDs []string) (map[string]int, error)
+	FindByIDs(deckID string, cardIDs []string) ([]Card, error)
+	FindByNoteID(deckID, noteID string) ([]Card, error)
+	MoveCards(userID, deckID string, cardIDs []string, target *Deck, movedAt time.Time) error
+	Search(query *SearchQuery) ([]CardMatch, error)
+	DeleteCards(userID, deckID string, cardIDs []string, deletedAt time.Time) error
+	FindRevisions(deckID, cardID string) ([]CardRevision, error)
+	FindRevision(deckID, cardID, revisionID string) (*CardRevision, error)
+	FindChanged(deckIDs []string, since time.Time) ([]Card, error)
+	FindByNoteTypeID(noteTypeID string) ([]Card, error)
+	HasNoteType(noteTypeID string) (bool, error)
+	FindUnsanitized(limit int) ([]Card, error)
+	SaveSanitized(card *Card) error
 }
+
+// Limits of the tags of a card, matching the binding of CardReq.
+const (
+	MaxTags      = 20
+	MaxTagLength = 30
+)
+
+var ErrCardNotFound = errors.New("card not found")
+var ErrInvalidTags = errors.New("cards can have up to 20 tags of up to 30 characters each")
@@ -0,0 +1,63 @@
+package entity
+
+import "errors"
+
+// Types of a card. Cards without a type are basic cards.
+const (
+	CardTypeBasic          = "basic"
+	CardTypeReversed       = "reversed"
+	CardTypeCloze          = "cloze"
+	CardTypeMultipleChoice = "multiple_choice"
+	CardTypeTyped          = "typed"
+	CardTypeImageOcclusion = "image_occlusion"
+	CardTypeCustom         = "custom"
+)
+
+// Ordinals of the two cards of a reversed note.
+const (
+	OrdinalForward = 1
+	OrdinalReverse = 2
+)
+
+// ClozeNote is the text the cards of a cloze note are generated from, e.g.
+// "{{c1::Paris}} is the capital of {{c2::France}}". Every card hides the
+// deletions with its ordinal.
+type ClozeNote struct {
+	Text  string `bson:"text"            json:"text"`
+	Extra string `bson:"extra,omitempty" json:"extra,omitempty"`
+}
+
+// OcclusionNote is the image the cards of an image occlusion note are
+// generated from, together with the masks hiding its regions. Every card
+// hides the region of the mask with its ordinal.
+type OcclusionNote struct {
+	Image string          `bson:"image"           json:"image"`
+	Masks []OcclusionMask `bson:"masks"           json:"masks"`
+	Extra string          `bson:"extra,omitempty" json:"extra,omitempty"`
+}
+
+// OcclusionMask is a rectangle on the image. Its geometry is relative to
+// the size of the image, from 0,0 at the top left to 1,1 at the bottom
+// right corner, so it does not depend on the size the image is shown in.
+type OcclusionMask struct {
+	Ordinal int     `bson:"ordinal"         json:"ordinal,omitempty" binding:"omitempty,min=1"`
+	X       float64 `bson:"x"               json:"x"                 binding:"min=0,max=1"`
+	Y       float64 `bson:"y"               json:"y"                 binding:"min=0,max=1"`
+	Width   float64 `bson:"width"           json:"width"             binding:"gt=0,max=1"`
+	Height  float64 `bson:"height"          json:"height"            binding:"gt=0,max=1"`
+	Label   string  `bson:"label,omitempty" json:"label,omitempty"   binding:"max=200"`
+}
+
+var (
+	ErrInvalidCloze = errors.New(
+		"cloze text needs at least one deletion like {{c1::answer}}",
+	)
+	ErrInvalidChoices = errors.New(
+		"multiple choice cards need at least two different choices including the answer",
+	)
+	ErrInvalidTypedAnswer = errors.New("typed answers must be a single line")
+	ErrInvalidOcclusion   = errors.New(
+		"image occlusion cards need an image like media:<id> in the question " +
+			"and at least one mask within the image, with distinct ordinals",
+	)
+)
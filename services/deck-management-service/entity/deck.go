@@ -1,46 +1,125 @@
 package entity
 
-import "time"
+import (
+	"errors"
+	"time"
+)
 
 type Deck struct {
-	ID          string     `bson:"_id,omitempty"`
-	Name        string     `bson:"name"`
-	Description string     `bson:"description"`
-	Color       string     `bson:"color"`
-	UserID      string     `bson:"user_id"`
-	Cards       []Card     `bson:"cards"`
-	CreatedAt   *time.Time `bson:"created_at"`
-	UpdatedAt   *time.Time `bson:"updated_at"`
-	DeletedAt   *time.Time `bson:"deleted_at"`
+	ID                string        `bson:"_id,omitempty"`
+	Name              string        `bson:"name"`
+	Description       string        `bson:"description"`
+	Color             string        `bson:"color"`
+	UserID            string        `bson:"user_id"`
+	Public            bool          `bson:"public"`
+	ParentID          string        `bson:"parent_id,omitempty"`
+	Origin            *DeckOrigin   `bson:"origin,omitempty"`
+	Members           []DeckMember  `bson:"members"`
+	Removals          []DeckRemoval `bson:"removals,omitempty"`
+	Cards             []Card        `bson:"-"`
+	DisabledLintRules []string      `bson:"disabled_lint_rules,omitempty"`
+	Version           int           `bson:"version"`
+	CreatedAt         *time.Time    `bson:"created_at"`
+	UpdatedAt         *time.Time    `bson:"updated_at"`
+	DeletedAt         *time.Time    `bson:"deleted_at"`
 }
 
+// DeckOrigin points a forked deck to the deck it was copied from. SyncedAt
+// is the version of the origin the fork was last brought up to date with.
+type DeckOrigin struct {
+	DeckID   string     `bson:"deck_id"`
+	SyncedAt *time.Time `bson:"synced_at"`
+}
+
+// DeckReq creates or changes a deck. The parent is only taken when the deck
+// is created, since decks are moved through DeckMoveReq.
 type DeckReq struct {
 	Name        string `json:"name"        binding:"required,max=30"`
 	Description string `json:"description" binding:"max=200"`
 	Color       string `json:"color"       binding:"required"`
+	Public      bool   `json:"public"`
+	ParentID    string `json:"parentID"`
+}
+
+// DeckMoveReq moves a deck below another deck, or to the top level if
+// ParentID is empty. The sub-decks of the deck move with it.
+type DeckMoveReq struct {
+	ParentID string `json:"parentID"`
+}
+
+type DeckOriginRes struct {
+	DeckID   string     `json:"deckID"`
+	SyncedAt *time.Time `json:"syncedAt"`
 }
 
 type DeckRes struct {
-	ID          string     `json:"id"`
-	Name        string     `json:"name"`
-	Description string     `json:"description"`
-	Color       string     `json:"color"`
-	Cards       []CardRes  `json:"cards"`
-	CreatedAt   *time.Time `json:"created_at"`
+	ID          string         `json:"id"`
+	Name        string         `json:"name"`
+	Description string         `json:"description"`
+	Color       string         `json:"color"`
+	Public      bool           `json:"public"`
+	ParentID    string         `json:"parentID,omitempty"`
+	Origin      *DeckOriginRes `json:"origin,omitempty"`
+	Role        string         `json:"role"`
+	Cards       []CardRes      `json:"cards"`
+	Version     int            `json:"version,omitempty"`
+	CreatedAt   *time.Time     `json:"created_at"`
+}
+
+// DeckTreeRes is a deck in the tree of decks, e.g. "Drugs" with the path
+// "Medicine::Cardio::Drugs". CardCount counts the cards of the deck itself
+// and TotalCardCount those of its sub-decks as well.
+type DeckTreeRes struct {
+	ID             string        `json:"id"`
+	Name           string        `json:"name"`
+	Path           string        `json:"path"`
+	Color          string        `json:"color"`
+	Role           string        `json:"role"`
+	CardCount      int           `json:"cardCount"`
+	TotalCardCount int           `json:"totalCardCount"`
+	Children       []DeckTreeRes `json:"children"`
 }
 
+// DeckPathSeparator separates the names of the decks in the path of a deck.
+const DeckPathSeparator = "::"
+
 type DeckUseCaseInterface interface {
 	CreateDeck(userID string, deck *DeckReq) (*DeckRes, error)
-	GetDecks(userID string) ([]DeckRes, error)
+	GetDecks(userID string, req *DeckListReq) ([]DeckRes, string, error)
 	GetDeck(userID, DeckID string) (*DeckRes, error)
-	UpdateDeck(userID, DeckID string, deck *DeckReq) (*DeckRes, error)
-	DeleteDeck(userID, DeckID string) error
+	UpdateDeck(userID, DeckID string, deck *DeckReq, ifMatch IfMatch) (*DeckRes, error)
+	DeleteDeck(userID, DeckID string, ifMatch IfMatch) error
+	ForkDeck(userID, DeckID string) (*DeckRes, error)
+	PullDeck(userID, DeckID string) (*DeckRes, error)
+	GetDeckTree(userID string) ([]DeckTreeRes, error)
+	MoveDeck(userID, DeckID string, move *DeckMoveReq) (*DeckRes, error)
 }
 
 type DeckStoreInterface interface {
 	Save(deck *Deck) (string, error)
-	FindAll(userID string) ([]Deck, error)
+	FindAll(userID string, query *DeckQuery) ([]Deck, string, error)
 	FindByID(userID, deckID string) (*Deck, error)
+	FindAccessibleByID(userID, deckID string) (*Deck, error)
 	Update(deck *Deck) error
-	Delete(userID, deckID string) error
+	UpdateOrigin(userID, deckID string, origin *DeckOrigin) error
+	UpdateParent(userID, deckID, parentID string, updatedAt time.Time) error
+	Delete(userID, deckID string, deletedAt time.Time) error
+	FindDeleted(userID string) ([]Deck, error)
+	Restore(userID, deckID string, restoredAt time.Time) error
+	Purge(deletedBefore time.Time) (int64, error)
+	FindInvitations(userID string) ([]Deck, error)
+	AddMember(deckID string, member *DeckMember) error
+	UpdateMember(deckID, memberID, role string, updatedAt time.Time) error
+	AcceptMember(deckID, memberID string, acceptedAt time.Time) error
+	RemoveMember(deckID, memberID string, removedAt time.Time) error
+	Search(query *SearchQuery) ([]DeckMatch, error)
+	FindChanged(userID string, since time.Time) ([]Deck, error)
+	FindByColorNotIn(colors []string) ([]Deck, error)
+	UpdateLintRules(deckID string, disabledRules []string) error
 }
+
+var ErrDeckNotFound = errors.New("deck not found")
+var ErrDeckNotForked = errors.New("deck is not a fork")
+var ErrInvalidParent = errors.New(
+	"a deck can only be placed below another deck of its owner that is not one of its sub-decks",
+)
@@ -0,0 +1,28 @@
+package entity
+
+const (
+	DuplicateScopeDeck    = "deck"
+	DuplicateScopeAccount = "account"
+	DuplicateScopeNone    = "none"
+)
+
+// DuplicateReq configures the duplicate detection of created and imported
+// cards. Duplicates are searched in the deck the cards are added to or in
+// all decks the user has access to, and cards earlier in the same request
+// count as well. Detection is turned off if the scope is empty or none.
+type DuplicateReq struct {
+	Duplicates     string `form:"duplicates,default=deck" binding:"omitempty,oneof=deck account none"`
+	SkipDuplicates bool   `form:"skipDuplicates"`
+}
+
+// DuplicateRes is the card a new card duplicates. The card id is empty if
+// the duplicate is an earlier card of the same request. Similarity is 1 for
+// exact duplicates, which have the same question after normalizing case,
+// punctuation and whitespace.
+type DuplicateRes struct {
+	CardID     string  `json:"cardID,omitempty"`
+	DeckID     string  `json:"deckID"`
+	Question   string  `json:"question"`
+	Similarity float64 `json:"similarity"`
+	Exact      bool    `json:"exact"`
+}
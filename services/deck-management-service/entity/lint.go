@@ -0,0 +1,55 @@
+package entity
+
+// Rules the cards of a deck are linted with.
+const (
+	LintAnswerTooLong          = "answer-too-long"
+	LintQuestionContainsAnswer = "question-contains-answer"
+	LintMultipleFacts          = "multiple-facts"
+	LintEmptyField             = "empty-field"
+	LintNearDuplicate          = "near-duplicate"
+	LintUnbalancedCloze        = "unbalanced-cloze"
+)
+
+// LintRules are all lint rules in the order their warnings are listed.
+var LintRules = []string{
+	LintEmptyField,
+	LintAnswerTooLong,
+	LintQuestionContainsAnswer,
+	LintMultipleFacts,
+	LintUnbalancedCloze,
+	LintNearDuplicate,
+}
+
+// LintSettingsReq sets the lint rules that do not apply to a deck. All
+// other rules apply, including rules added later.
+type LintSettingsReq struct {
+	DisabledRules []string `json:"disabledRules" binding:"max=20,dive,oneof=answer-too-long question-contains-answer multiple-facts empty-field near-duplicate unbalanced-cloze"`
+}
+
+type LintSettingsRes struct {
+	Rules         []string `json:"rules"`
+	DisabledRules []string `json:"disabledRules"`
+}
+
+// LintWarningRes is a problem of a card. Near-duplicates point to the card
+// they duplicate.
+type LintWarningRes struct {
+	CardID        string `json:"cardID"`
+	Rule          string `json:"rule"`
+	Message       string `json:"message"`
+	RelatedCardID string `json:"relatedCardID,omitempty"`
+}
+
+// LintRes lists the warnings of the cards of a deck, in the order of the
+// cards. Rules are the rules the cards were checked with.
+type LintRes struct {
+	Cards    int              `json:"cards"`
+	Rules    []string         `json:"rules"`
+	Warnings []LintWarningRes `json:"warnings"`
+}
+
+type LintUseCaseInterface interface {
+	LintDeck(userID, deckID string) (*LintRes, error)
+	GetLintSettings(userID, deckID string) (*LintSettingsRes, error)
+	UpdateLintSettings(userID, deckID string, req *LintSettingsReq) (*LintSettingsRes, error)
+}
@@ -0,0 +1,111 @@
+package entity
+
+import (
+	"errors"
+	"io"
+	"regexp"
+	"slices"
+	"time"
+)
+
+// MediaScheme prefixes the id of a media file where it is referenced in a
+// card, e.g. "![map](media:61f0c1e5d3b7a0c3f8a1b2c4)".
+const MediaScheme = "media:"
+
+var mediaRef = regexp.MustCompile(MediaScheme + `([0-9a-f]{24})`)
+
+// Media is an image or audio file uploaded to a deck. The file itself is
+// kept in the blob store under Key.
+type Media struct {
+	ID          string     `bson:"_id,omitempty"`
+	DeckID      string     `bson:"deck_id"`
+	UserID      string     `bson:"user_id"`
+	Name        string     `bson:"name"`
+	ContentType string     `bson:"content_type"`
+	Size        int64      `bson:"size"`
+	Key         string     `bson:"key"`
+	CreatedAt   *time.Time `bson:"created_at"`
+}
+
+type MediaRes struct {
+	ID          string     `json:"id"`
+	Name        string     `json:"name"`
+	ContentType string     `json:"contentType"`
+	Size        int64      `json:"size"`
+	Ref         string     `json:"ref"`
+	CreatedAt   *time.Time `json:"createdAt"`
+}
+
+// MediaFile is the content of a media file together with its metadata.
+type MediaFile struct {
+	Media   Media
+	Content io.ReadCloser
+}
+
+// MediaRefs returns the ids of the media files the card references in any
+// of its texts, including the notes it is generated from.
+func (c *Card) MediaRefs() []string {
+	texts := append([]string{c.Question, c.Answer}, c.Choices...)
+	texts = append(texts, c.Accepted...)
+	if c.Cloze != nil {
+		texts = append(texts, c.Cloze.Text, c.Cloze.Extra)
+	}
+	if c.Occlusion != nil {
+		texts = append(texts, MediaScheme+c.Occlusion.Image, c.Occlusion.Extra)
+		for _, mask := range c.Occlusion.Masks {
+			texts = append(texts, mask.Label)
+		}
+	}
+	if c.Custom != nil {
+		names := []string{}
+		for name := range c.Custom.Fields {
+			names = append(names, name)
+		}
+		slices.Sort(names)
+		for _, name := range names {
+			texts = append(texts, c.Custom.Fields[name])
+		}
+	}
+
+	refs := []string{}
+	for _, text := range texts {
+		for _, match := range mediaRef.FindAllStringSubmatch(text, -1) {
+			if !slices.Contains(refs, match[1]) {
+				refs = append(refs, match[1])
+			}
+		}
+	}
+
+	return refs
+}
+
+type MediaUseCaseInterface interface {
+	Upload(userID, deckID, name string, content io.Reader) (*MediaRes, error)
+	GetMedia(userID, deckID, mediaID string) (*MediaFile, error)
+	CollectGarbage() (int, error)
+}
+
+type MediaStoreInterface interface {
+	Save(media *Media) (string, error)
+	FindByID(mediaID string) (*Media, error)
+	FindCreatedBefore(createdBefore time.Time) ([]Media, error)
+	CountReferences(mediaID string) (int64, error)
+	IsReferenced(deckID, mediaID string) (bool, error)
+	Delete(mediaID string) error
+}
+
+// BlobStoreInterface stores the content of media files by key.
+type BlobStoreInterface interface {
+	Put(key string, content io.Reader) error
+	Get(key string) (io.ReadCloser, error)
+	Delete(key string) error
+}
+
+var (
+	ErrMediaNotFound        = errors.New("media not found")
+	ErrUnsupportedMediaType = errors.New("only png, jpeg, gif and webp images " +
+		"and mp3, ogg and wav audio are supported")
+	ErrMediaTooLarge   = errors.New("media file is too large")
+	ErrInvalidMediaRef = errors.New("a card references a media file that does not exist " +
+		"or belongs to a deck you can not view")
+)
@@ -0,0 +1,96 @@
+package entity
+
+import (
+	"errors"
+	"time"
+)
+
+const (
+	RoleViewer = "viewer"
+	RoleEditor = "editor"
+	RoleOwner  = "owner"
+)
+
+var roleRanks = map[string]int{
+	RoleViewer: 1,
+	RoleEditor: 2,
+	RoleOwner:  3,
+}
+
+type DeckMember struct {
+	UserID     string     `bson:"user_id"`
+	Email      string     `bson:"email"`
+	Role       string     `bson:"role"`
+	Accepted   bool       `bson:"accepted"`
+	InvitedBy  string     `bson:"invited_by"`
+	InvitedAt  *time.Time `bson:"invited_at"`
+	AcceptedAt *time.Time `bson:"accepted_at,omitempty"`
+}
+
+// DeckRemoval records that a member was removed from a deck, so that their
+// clients learn about it when they sync.
+type DeckRemoval struct {
+	UserID    string     `bson:"user_id"`
+	RemovedAt *time.Time `bson:"removed_at"`
+}
+
+// MemberReq invites a member to a deck. A deck has one owner, its creator,
+// so members can only be viewers or editors.
+type MemberReq struct {
+	Email string `json:"email" binding:"required,email"`
+	Role  string `json:"role"  binding:"required,oneof=viewer editor"`
+}
+
+type MemberRoleReq struct {
+	Role string `json:"role" binding:"required,oneof=viewer editor"`
+}
+
+type MemberRes struct {
+	UserID    string     `json:"userID"`
+	Email     string     `json:"email"`
+	Role      string     `json:"role"`
+	Accepted  bool       `json:"accepted"`
+	InvitedAt *time.Time `json:"invitedAt"`
+}
+
+type InvitationRes struct {
+	DeckID    string     `json:"deckID"`
+	DeckName  string     `json:"deckName"`
+	Role      string     `json:"role"`
+	InvitedAt *time.Time `json:"invitedAt"`
+}
+
+type MemberUseCaseInterface interface {
+	GetMembers(userID, deckID string) ([]MemberRes, error)
+	InviteMember(userID, deckID string, member *MemberReq) (*MemberRes, error)
+	UpdateMember(userID, deckID, memberID, role string) (*MemberRes, error)
+	RemoveMember(userID, deckID, memberID string) error
+	GetInvitations(userID string) ([]InvitationRes, error)
+	AcceptInvitation(userID, deckID string) error
+}
+
+// HasRole reports whether role grants at least the permissions of required.
+func HasRole(role, required string) bool {
+	return roleRanks[role] > 0 && roleRanks[role] >= roleRanks[required]
+}
+
+// Role returns the role userID has on the deck. The creator of a deck is
+// always its owner, invitations only count once they were accepted.
+func (d *Deck) Role(userID string) string {
+	if d.UserID == userID {
+		return RoleOwner
+	}
+
+	for _, member := range d.Members {
+		if member.UserID == userID && member.Accepted {
+			return member.Role
+		}
+	}
+
+	return ""
+}
+
+var ErrForbidden = errors.New("insufficient permissions for this deck")
+var ErrMemberNotFound = errors.New("member not found")
+var ErrMemberExists = errors.New("user is already a member of this deck")
+var ErrUserNotFound = errors.New("user not found")
@@ -0,0 +1,94 @@
+package entity
+
+import (
+	"errors"
+	"time"
+)
+
+// FrontSideField stands for the rendered front of a card in the back
+// template, e.g. "{{FrontSide}}<hr>{{Meaning}}".
+const FrontSideField = "FrontSide"
+
+// NoteType defines the fields of custom notes and the templates their cards
+// are rendered from. Every template renders one card per note, unless the
+// fields its front uses are all empty. Forking a deck copies the note types
+// of its cards into the account of the user, OriginID links such a copy to
+// the note type it was copied from.
+type NoteType struct {
+	ID        string         `bson:"_id,omitempty"`
+	UserID    string         `bson:"user_id"`
+	OriginID  string         `bson:"origin_id,omitempty"`
+	Name      string         `bson:"name"`
+	Fields    []string       `bson:"fields"`
+	Templates []CardTemplate `bson:"templates"`
+	CreatedAt *time.Time     `bson:"created_at"`
+	UpdatedAt *time.Time     `bson:"updated_at"`
+}
+
+// CardTemplate renders the front and back of a card from the fields of a
+// note. "{{Field}}" is replaced by the value of the field, while the text
+// between "{{#Field}}" and "{{/Field}}" is only shown if the field is not
+// empty and the text between "{{^Field}}" and "{{/Field}}" only if it is.
+// Templates without an ordinal get the next free ones, so a client keeps the
+// learning history of the cards of a template by sending its ordinal back.
+type CardTemplate struct {
+	Ordinal int    `bson:"ordinal" json:"ordinal,omitempty" binding:"omitempty,min=1"`
+	Name    string `bson:"name"    json:"name"              binding:"required,max=50"`
+	Front   string `bson:"front"   json:"front"             binding:"required,max=5000"`
+	Back    string `bson:"back"    json:"back"              binding:"required,max=5000"`
+}
+
+// CustomNote holds the fields the cards of a custom note are rendered from.
+// Every card is rendered by the template with its ordinal.
+type CustomNote struct {
+	NoteTypeID string            `bson:"note_type_id" json:"noteTypeID"`
+	Fields     map[string]string `bson:"fields"       json:"fields"`
+}
+
+type NoteTypeReq struct {
+	Name      string         `json:"name"      binding:"required,max=50"`
+	Fields    []string       `json:"fields"    binding:"required,min=1,max=30,dive,required,max=50"`
+	Templates []CardTemplate `json:"templates" binding:"required,min=1,max=20,dive"`
+}
+
+type NoteTypeRes struct {
+	ID        string         `json:"id"`
+	Name      string         `json:"name"`
+	Fields    []string       `json:"fields"`
+	Templates []CardTemplate `json:"templates"`
+	CreatedAt *time.Time     `json:"createdAt"`
+	UpdatedAt *time.Time     `json:"updatedAt"`
+}
+
+var (
+	ErrNoteTypeNotFound = errors.New("note type not found")
+	ErrInvalidNoteType  = errors.New(
+		"note types need distinct field names without braces or leading #, ^ and /, " +
+			"and templates with distinct ordinals",
+	)
+	ErrInvalidTemplate = errors.New(
+		"templates may only use the fields of their note type, every section " +
+			"needs to be closed and every front needs at least one field",
+	)
+	ErrInvalidNote = errors.New(
+		"custom notes may only fill the fields of their note type and need a " +
+			"field used on the front of a template",
+	)
+	ErrNoteTypeInUse = errors.New("note type is used by cards")
+)
+
+type NoteTypeUseCaseInterface interface {
+	GetNoteTypes(userID string) ([]NoteTypeRes, error)
+	GetNoteType(userID, noteTypeID string) (*NoteTypeRes, error)
+	CreateNoteType(userID string, noteType *NoteTypeReq) (*NoteTypeRes, error)
+	UpdateNoteType(userID, noteTypeID string, noteType *NoteTypeReq) (*NoteTypeRes, error)
+	DeleteNoteType(userID, noteTypeID string) error
+}
+
+type NoteTypeStoreInterface interface {
+	Save(noteType *NoteType) (string, error)
+	FindAll(userID string) ([]NoteType, error)
+	FindByIDs(noteTypeIDs []string) ([]NoteType, error)
+	Update(noteType *NoteType) error
+	Delete(userID, noteTypeID string) error
+}
@@ -0,0 +1,69 @@
+package entity
+
+import (
+	"errors"
+	"strings"
+)
+
+const (
+	SortName      = "name"
+	SortCreatedAt = "createdAt"
+	SortUpdatedAt = "updatedAt"
+
+	OrderAsc  = "asc"
+	OrderDesc = "desc"
+)
+
+// DeckResFields are the fields of DeckRes that can be selected when listing
+// decks.
+var DeckResFields = []string{
+	"id", "name", "description", "color", "public", "parentID", "origin", "role", "cards",
+	"created_at",
+}
+
+// Page selects the items after Cursor. A Limit of 0 selects all of them.
+type Page struct {
+	Limit  int
+	Cursor string
+}
+
+type PageReq struct {
+	Limit  int    `form:"limit"  binding:"omitempty,min=1,max=100"`
+	Cursor string `form:"cursor"`
+}
+
+type DeckListReq struct {
+	PageReq
+	Sort         string `form:"sort"         binding:"omitempty,oneof=name createdAt updatedAt"`
+	Order        string `form:"order"        binding:"omitempty,oneof=asc desc"`
+	IncludeCards *bool  `form:"includeCards"`
+	Fields       string `form:"fields"`
+}
+
+// DeckQuery is a listing of decks sorted by one of the sort fields or by
+// creation if Sort is empty.
+type DeckQuery struct {
+	Page
+	Sort       string
+	Descending bool
+	WithCards  bool
+}
+
+// FieldList returns the selected fields or nil if all fields are selected.
+func (r *DeckListReq) FieldList() []string {
+	if strings.TrimSpace(r.Fields) == "" {
+		return nil
+	}
+
+	fields := []string{}
+	for _, field := range strings.Split(r.Fields, ",") {
+		if field = strings.TrimSpace(field); field != "" {
+			fields = append(fields, field)
+		}
+	}
+
+	return fields
+}
+
+var ErrInvalidCursor = errors.New("invalid cursor")
+var ErrInvalidField = errors.New("invalid field")
@@ -0,0 +1,18 @@
+package entity
+
+import "errors"
+
+// PaletteInterface holds the colors decks can have. The colors are loaded
+// from the config service before requests are served and refreshed
+// periodically.
+type PaletteInterface interface {
+	Refresh() (bool, error)
+	Colors() []string
+	Match(color string) (string, error)
+	FindInvalidDecks() ([]Deck, error)
+}
+
+var (
+	ErrInvalidColor     = errors.New("color is not in the palette")
+	ErrPaletteNotLoaded = errors.New("color palette is not loaded")
+)
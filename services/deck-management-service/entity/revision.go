@@ -0,0 +1,102 @@
+package entity
+
+import (
+	"errors"
+	"time"
+)
+
+// Sources of a card change.
+const (
+	SourceManual    = "manual"
+	SourceImport    = "import"
+	SourceGenerated = "generated"
+	SourceSync      = "sync"
+	SourceRestore   = "restore"
+	SourceUndo      = "undo"
+	SourceNoteType  = "note_type"
+)
+
+// Bulk operations on a deck that can be undone.
+const (
+	OperationCreate  = "create"
+	OperationImport  = "import"
+	OperationPull    = "pull"
+	OperationCopy    = "copy"
+	OperationDelete  = "delete"
+	OperationReplace = "replace"
+)
+
+// CardRevision is a version of a card. A revision is stored whenever a card
+// is created or changed.
+type CardRevision struct {
+	ID        string         `bson:"_id,omitempty"`
+	CardID    string         `bson:"card_id"`
+	DeckID    string         `bson:"deck_id"`
+	UserID    string         `bson:"user_id"`
+	Question  string         `bson:"question"`
+	Answer    string         `bson:"answer"`
+	Tags      []string       `bson:"tags,omitempty"`
+	Source    string         `bson:"source"`
+	Type      string         `bson:"type,omitempty"`
+	NoteID    string         `bson:"note_id,omitempty"`
+	Ordinal   int            `bson:"ordinal,omitempty"`
+	Cloze     *ClozeNote     `bson:"cloze,omitempty"`
+	Choices   []string       `bson:"choices,omitempty"`
+	Accepted  []string       `bson:"accepted,omitempty"`
+	Occlusion *OcclusionNote `bson:"occlusion,omitempty"`
+	Custom    *CustomNote    `bson:"custom,omitempty"`
+	CreatedAt *time.Time     `bson:"created_at"`
+}
+
+type RevisionRes struct {
+	ID        string         `json:"id"`
+	CardID    string         `json:"cardID"`
+	Question  string         `json:"question"`
+	Answer    string         `json:"answer"`
+	Tags      []string       `json:"tags,omitempty"`
+	Source    string         `json:"source"`
+	Type      string         `json:"type,omitempty"`
+	Ordinal   int            `json:"ordinal,omitempty"`
+	Cloze     *ClozeNote     `json:"cloze,omitempty"`
+	Choices   []string       `json:"choices,omitempty"`
+	Accepted  []string       `json:"accepted,omitempty"`
+	Occlusion *OcclusionNote `json:"occlusion,omitempty"`
+	Custom    *CustomNote    `json:"custom,omitempty"`
+	CreatedAt *time.Time     `json:"createdAt"`
+}
+
+// BulkOperation records what the last bulk operation on a deck changed, so
+// that it can be undone for a short time. ChangedCards holds the versions
+// of the cards from before the operation.
+type BulkOperation struct {
+	ID           string     `bson:"_id,omitempty"`
+	DeckID       string     `bson:"deck_id"`
+	UserID       string     `bson:"user_id"`
+	Operation    string     `bson:"operation"`
+	CreatedCards []string   `bson:"created_cards"`
+	ChangedCards []Card     `bson:"changed_cards"`
+	DeletedCards []string   `bson:"deleted_cards"`
+	Media        []string   `bson:"media"`
+	CreatedAt    *time.Time `bson:"created_at"`
+}
+
+type UndoRes struct {
+	Operation     string `json:"operation"`
+	RemovedCards  int    `json:"removedCards"`
+	RestoredCards int    `json:"restoredCards"`
+}
+
+type RevisionUseCaseInterface interface {
+	GetRevisions(userID, deckID, cardID string) ([]RevisionRes, error)
+	RestoreRevision(userID, deckID, cardID, revisionID string) (*CardRes, error)
+	Undo(userID, deckID string) (*UndoRes, error)
+}
+
+type UndoStoreInterface interface {
+	Save(operation *BulkOperation) error
+	FindByDeckID(deckID string) (*BulkOperation, error)
+	Delete(deckID string) error
+}
+
+var ErrRevisionNotFound = errors.New("revision not found")
+var ErrNothingToUndo = errors.New("nothing to undo")
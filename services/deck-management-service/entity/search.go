@@ -0,0 +1,68 @@
+package entity
+
+type SearchReq struct {
+	Query  string   `form:"q"      binding:"required,max=200"`
+	DeckID string   `form:"deckID"`
+	Tags   []string `form:"tag"`
+	Limit  int      `form:"limit"  binding:"omitempty,min=1,max=100"`
+}
+
+// SearchQuery is a text search restricted to the given decks. Cards must
+// carry all of the given tags.
+type SearchQuery struct {
+	Text    string
+	DeckIDs []string
+	Tags    []string
+	Limit   int
+}
+
+type DeckMatch struct {
+	Deck  `bson:",inline"`
+	Score float64 `bson:"score"`
+}
+
+type CardMatch struct {
+	Card  `bson:",inline"`
+	Score float64 `bson:"score"`
+}
+
+// MatchRes is the position of a match in a highlighted fragment, counted in
+// characters.
+type MatchRes struct {
+	Start int `json:"start"`
+	End   int `json:"end"`
+}
+
+type HighlightRes struct {
+	Field    string     `json:"field"`
+	Fragment string     `json:"fragment"`
+	Matches  []MatchRes `json:"matches"`
+}
+
+type DeckHitRes struct {
+	ID          string         `json:"id"`
+	Name        string         `json:"name"`
+	Description string         `json:"description"`
+	Color       string         `json:"color"`
+	Score       float64        `json:"score"`
+	Highlights  []HighlightRes `json:"highlights"`
+}
+
+type CardHitRes struct {
+	ID         string         `json:"id"`
+	DeckID     string         `json:"deckID"`
+	Question   string         `json:"question"`
+	Answer     string         `json:"answer"`
+	Tags       []string       `json:"tags,omitempty"`
+	Score      float64        `json:"score"`
+	Highlights []HighlightRes `json:"highlights"`
+}
+
+type SearchRes struct {
+	Decks []DeckHitRes `json:"decks"`
+	Cards []CardHitRes `json:"cards"`
+}
+
+type SearchUseCaseInterface interface {
+	Search(userID string, req *SearchReq) (*SearchRes, error)
+}
@@ -0,0 +1,11 @@
+package entity
+
+// StudyCardRes is a card in the learning queue of a deck.
+type StudyCardRes struct {
+	CardRes
+	RecallProbability float64 `json:"recallProbability"`
+}
+
+type StudyUseCaseInterface interface {
+	GetQueue(userID, deckID string) ([]StudyCardRes, error)
+}
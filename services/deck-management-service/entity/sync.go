@@ -0,0 +1,121 @@
+package entity
+
+import "time"
+
+// SyncReq asks for the changes since the cursor a previous sync returned.
+// Without a cursor all decks and cards of the user are returned.
+type SyncReq struct {
+	Since string `form:"since"`
+}
+
+// CardStateRes is the learning state of a card after its latest review.
+type CardStateRes struct {
+	CardID          string     `json:"cardID"`
+	MemoryHalfLife  float64    `json:"memoryHalfLife"`
+	NumberPracticed int        `json:"numberPracticed"`
+	NumberCorrect   int        `json:"numberCorrect"`
+	NumberIncorrect int        `json:"numberIncorrect"`
+	LastReviewedAt  *time.Time `json:"lastReviewedAt"`
+}
+
+// SyncDeletedRes lists the decks and cards deleted since the cursor. A deck
+// is also listed if the user lost access to it. Clients drop the cards of a
+// deleted deck together with the deck.
+type SyncDeletedRes struct {
+	Decks []string `json:"decks"`
+	Cards []string `json:"cards"`
+}
+
+// SyncRes holds the decks, cards and learning states changed since the
+// cursor. Tags are part of the cards, so changed tags come with the cards.
+// The cards of the decks are listed in Cards only. Reset tells the client to
+// replace its data with the response, because the changes since the cursor
+// are not known anymore once deleted decks and cards were purged.
+type SyncRes struct {
+	Cursor  string         `json:"cursor"`
+	Reset   bool           `json:"reset"`
+	Decks   []DeckRes      `json:"decks"`
+	Cards   []CardRes      `json:"cards"`
+	States  []CardStateRes `json:"states"`
+	Deleted SyncDeletedRes `json:"deleted"`
+}
+
+// SyncDeckChange is a change a client made to a deck while offline. Decks
+// created offline have no id yet and are referred to by their client id in
+// the rest of the push, e.g. by the cards created in them. Changes to
+// existing decks only apply to the version of the deck they are based on.
+type SyncDeckChange struct {
+	ID       string   `json:"id"`
+	ClientID string   `json:"clientID" binding:"required_without=ID"`
+	Version  int      `json:"version"  binding:"required_with=ID"`
+	Deleted  bool     `json:"deleted"`
+	Deck     *DeckReq `json:"deck"     binding:"required_without=Deleted"`
+}
+
+// SyncCardChange is a change a client made to a card while offline. DeckID
+// is either the id of the deck or the client id of a deck created in the
+// same push.
+type SyncCardChange struct {
+	ID       string   `json:"id"`
+	ClientID string   `json:"clientID" binding:"required_without=ID"`
+	DeckID   string   `json:"deckID"   binding:"required"`
+	Version  int      `json:"version"  binding:"required_with=ID"`
+	Deleted  bool     `json:"deleted"`
+	Card     *CardReq `json:"card"     binding:"required_without=Deleted"`
+}
+
+// SyncReviewReq is a review done offline. The ids may be client ids of decks
+// and cards created in the same push.
+type SyncReviewReq struct {
+	DeckID     string     `json:"deckID"     binding:"required"`
+	CardID     string     `json:"cardID"     binding:"required"`
+	ReviewedAt *time.Time `json:"reviewedAt" binding:"required"`
+	DurationMs int        `json:"durationMs"`
+	Correct    bool       `json:"correct"`
+}
+
+// SyncPushReq applies the changes a client made offline. Decks are changed
+// first, then cards, then the reviews are recorded.
+type SyncPushReq struct {
+	Decks   []SyncDeckChange `json:"decks"   binding:"dive"`
+	Cards   []SyncCardChange `json:"cards"   binding:"dive"`
+	Reviews []SyncReviewReq  `json:"reviews" binding:"dive"`
+}
+
+const (
+	SyncApplied  = "applied"
+	SyncConflict = "conflict"
+	SyncRejected = "rejected"
+)
+
+// SyncDeckResultRes tells whether a deck change was applied. On a conflict
+// Deck is the current deck, or nil if it was deleted in the meantime.
+// Rejected changes come with the reason in Error.
+type SyncDeckResultRes struct {
+	ID       string   `json:"id,omitempty"`
+	ClientID string   `json:"clientID,omitempty"`
+	Status   string   `json:"status"`
+	Error    string   `json:"error,omitempty"`
+	Deck     *DeckRes `json:"deck,omitempty"`
+}
+
+// SyncCardResultRes tells whether a card change was applied, like
+// SyncDeckResultRes does for decks.
+type SyncCardResultRes struct {
+	ID       string   `json:"id,omitempty"`
+	ClientID string   `json:"clientID,omitempty"`
+	Status   string   `json:"status"`
+	Error    string   `json:"error,omitempty"`
+	Card     *CardRes `json:"card,omitempty"`
+}
+
+type SyncPushRes struct {
+	Decks   []SyncDeckResultRes `json:"decks"`
+	Cards   []SyncCardResultRes `json:"cards"`
+	Reviews int                 `json:"reviews"`
+}
+
+type SyncUseCaseInterface interface {
+	GetChanges(userID string, req *SyncReq) (*SyncRes, error)
+	PushChanges(userID string, req *SyncPushReq) (*SyncPushRes, error)
+}
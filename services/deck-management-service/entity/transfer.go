@@ -0,0 +1,73 @@
+package entity
+
+import (
+	"errors"
+	"io"
+)
+
+// UnsupportedNoteRes is an anki note that could not be turned into cards, or
+// whose cards were imported without its images and audio.
+type UnsupportedNoteRes struct {
+	NoteID int64  `json:"noteID"`
+	Model  string `json:"model"`
+	Reason string `json:"reason"`
+}
+
+type AnkiImportRes struct {
+	Decks           []DeckRes            `json:"decks"`
+	Unsupported     []UnsupportedNoteRes `json:"unsupported"`
+	Skipped         []CardRes            `json:"skipped"`
+	ImportedReviews int                  `json:"importedReviews"`
+	HistoryError    string               `json:"historyError,omitempty"`
+}
+
+const (
+	FormatCSV      = "csv"
+	FormatTSV      = "tsv"
+	FormatMarkdown = "markdown"
+)
+
+// CardImportReq configures the import of cards from a text file. The
+// question and answer columns of csv and tsv files are selected by their
+// header name or by their position starting at 1.
+type CardImportReq struct {
+	DuplicateReq
+	Format   string `form:"format"   binding:"required,oneof=csv tsv markdown"`
+	DryRun   bool   `form:"dryRun"`
+	Header   bool   `form:"header"`
+	Question string `form:"question"`
+	Answer   string `form:"answer"`
+}
+
+type ImportErrorRes struct {
+	Line    int    `json:"line"`
+	Message string `json:"message"`
+}
+
+type CardImportRes struct {
+	DryRun bool             `json:"dryRun"`
+	Cards  []CardRes        `json:"cards"`
+	Errors []ImportErrorRes `json:"errors"`
+}
+
+// ExportFile is a deck converted into a file that can be downloaded.
+type ExportFile struct {
+	Name        string
+	ContentType string
+	Content     []byte
+}
+
+type TransferUseCaseInterface interface {
+	ImportAnki(
+		userID string,
+		file io.ReaderAt,
+		size int64,
+		history bool,
+		duplicates *DuplicateReq,
+	) (*AnkiImportRes, error)
+	ExportAnki(userID, deckID string, scheduling bool) (*ExportFile, error)
+	ImportCards(userID, deckID string, file io.Reader, req *CardImportReq) (*CardImportRes, error)
+	ExportCards(userID, deckID, fileFormat string) (*ExportFile, error)
+}
+
+var ErrUnknownFormat = errors.New("unknown file format")
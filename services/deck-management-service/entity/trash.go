@@ -0,0 +1,35 @@
+package entity
+
+import "time"
+
+// TrashDeckRes is a deleted deck. It is removed permanently at PurgeAt.
+type TrashDeckRes struct {
+	ID          string     `json:"id"`
+	Name        string     `json:"name"`
+	Description string     `json:"description"`
+	Color       string     `json:"color"`
+	DeletedAt   *time.Time `json:"deletedAt"`
+	PurgeAt     *time.Time `json:"purgeAt"`
+}
+
+// TrashCardRes is a deleted card. It is removed permanently at PurgeAt.
+type TrashCardRes struct {
+	ID        string     `json:"id"`
+	DeckID    string     `json:"deckID"`
+	Question  string     `json:"question"`
+	Answer    string     `json:"answer"`
+	DeletedAt *time.Time `json:"deletedAt"`
+	PurgeAt   *time.Time `json:"purgeAt"`
+}
+
+type TrashRes struct {
+	Decks []TrashDeckRes `json:"decks"`
+	Cards []TrashCardRes `json:"cards"`
+}
+
+type TrashUseCaseInterface interface {
+	GetTrash(userID string) (*TrashRes, error)
+	RestoreDeck(userID, deckID string) error
+	RestoreCard(userID, deckID, cardID string) error
+	Purge() (int64, error)
+}
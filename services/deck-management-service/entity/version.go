@@ -0,0 +1,27 @@
+package entity
+
+import (
+	"errors"
+	"slices"
+)
+
+// IfMatch holds the versions listed in the If-Match header of a request. A
+// nil IfMatch matches every version, like a missing header or "*" does.
+type IfMatch []int
+
+// Matches reports whether the change may be applied to the given version.
+func (m IfMatch) Matches(version int) bool {
+	return m == nil || slices.Contains(m, version)
+}
+
+// ErrVersionConflict is returned when a deck or card was changed after the
+// version a change is based on.
+var ErrVersionConflict = errors.New("resource was changed in the meantime")
+
+// ErrInvalidIfMatch is returned when the If-Match header is neither "*" nor
+// a list of entity tags.
+var ErrInvalidIfMatch = errors.New(`If-Match needs to be * or a list of entity tags like "3"`)
+
+// FirstVersion is the version of a newly created deck or card. Every change
+// increments it.
+const FirstVersion = 1
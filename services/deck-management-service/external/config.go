@@ -0,0 +1,54 @@
+package external
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/moshrank/spacey-backend/config"
+)
+
+// frontendConfig is the configuration document that holds the deck colors.
+const frontendConfig = "frontend"
+
+type ConfigServiceInterface interface {
+	GetColors() ([]string, error)
+}
+
+type ConfigService struct {
+	hostName string
+	client   *http.Client
+}
+
+func NewConfigService(cfg config.ConfigInterface) ConfigServiceInterface {
+	return &ConfigService{
+		hostName: cfg.GetConfigServiceHostName(),
+		client:   &http.Client{Timeout: 5 * time.Second},
+	}
+}
+
+// GetColors returns the colors decks can have.
+func (s *ConfigService) GetColors() ([]string, error) {
+	endpoint := fmt.Sprintf("http://%s/config/%s", s.hostName, frontendConfig)
+	res, err := s.client.Get(endpoint)
+	if err != nil {
+		return nil, err
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("config service responded with status %d", res.StatusCode)
+	}
+
+	var configRes struct {
+		Data struct {
+			Colors []string `json:"colors"`
+		} `json:"data"`
+	}
+	if err := json.NewDecoder(res.Body).Decode(&configRes); err != nil {
+		return nil, err
+	}
+
+	return configRes.Data.Colors, nil
+}
@@ -0,0 +1,209 @@
+package external
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/moshrank/spacey-backend/config"
+)
+
+type CardReview struct {
+	DeckID     string     `json:"deckID"`
+	CardID     string     `json:"cardID"`
+	ReviewedAt *time.Time `json:"reviewedAt"`
+	DurationMs int        `json:"durationMs"`
+	Correct    bool       `json:"correct"`
+}
+
+type CardState struct {
+	CardID          string     `json:"cardID"`
+	MemoryHalfLife  float64    `json:"memoryHalfLife"`
+	NumberPracticed int        `json:"numberPracticed"`
+	NumberCorrect   int        `json:"numberCorrect"`
+	NumberIncorrect int        `json:"numberIncorrect"`
+	LastReviewedAt  *time.Time `json:"lastReviewedAt"`
+}
+
+// LearningCard is a card in the learning queue of a user.
+type LearningCard struct {
+	CardID            string  `json:"cardID"`
+	RecallProbability float64 `json:"recallProbability"`
+}
+
+type LearningServiceInterface interface {
+	ImportReviews(userID string, reviews []CardReview) (int, error)
+	GetCardStates(userID string, cardIDs []string) ([]CardState, error)
+	GetChangedCardStates(userID string, since time.Time) ([]CardState, error)
+	MoveCards(cardIDs []string, deckID string) error
+	GetLearningQueue(userID string, cardIDs []string) ([]LearningCard, error)
+}
+
+type LearningService struct {
+	hostName string
+	client   *http.Client
+}
+
+func NewLearningService(cfg config.ConfigInterface) LearningServiceInterface {
+	return &LearningService{
+		hostName: cfg.GetLearningServiceHostName(),
+		client:   &http.Client{Timeout: 30 * time.Second},
+	}
+}
+
+func (s *LearningService) ImportReviews(userID string, reviews []CardReview) (int, error) {
+	body, err := json.Marshal(reviews)
+	if err != nil {
+		return 0, err
+	}
+
+	endpoint := fmt.Sprintf(
+		"http://%s/events/import?userID=%s",
+		s.hostName,
+		url.QueryEscape(userID),
+	)
+	res, err := s.client.Post(endpoint, "application/json", bytes.NewBuffer(body))
+	if err != nil {
+		return 0, err
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusCreated {
+		return 0, fmt.Errorf("learning service responded with status %d", res.StatusCode)
+	}
+
+	var importRes struct {
+		Data struct {
+			Imported int `json:"imported"`
+		} `json:"data"`
+	}
+	if err := json.NewDecoder(res.Body).Decode(&importRes); err != nil {
+		return 0, err
+	}
+
+	return importRes.Data.Imported, nil
+}
+
+func (s *LearningService) GetCardStates(userID string, cardIDs []string) ([]CardState, error) {
+	body, err := json.Marshal(map[string][]string{"cardIDs": cardIDs})
+	if err != nil {
+		return nil, err
+	}
+
+	endpoint := fmt.Sprintf(
+		"http://%s/events/states?userID=%s",
+		s.hostName,
+		url.QueryEscape(userID),
+	)
+	res, err := s.client.Post(endpoint, "application/json", bytes.NewBuffer(body))
+	if err != nil {
+		return nil, err
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("learning service responded with status %d", res.StatusCode)
+	}
+
+	var statesRes struct {
+		Data []CardState `json:"data"`
+	}
+	if err := json.NewDecoder(res.Body).Decode(&statesRes); err != nil {
+		return nil, err
+	}
+
+	return statesRes.Data, nil
+}
+
+// GetChangedCardStates returns the states of the cards the user reviewed
+// after the given time, or of all reviewed cards if the time is zero.
+func (s *LearningService) GetChangedCardStates(
+	userID string,
+	since time.Time,
+) ([]CardState, error) {
+	query := url.Values{"userID": {userID}}
+	if !since.IsZero() {
+		query.Set("since", since.Format(time.RFC3339Nano))
+	}
+
+	endpoint := fmt.Sprintf("http://%s/events/changes?%s", s.hostName, query.Encode())
+	res, err := s.client.Get(endpoint)
+	if err != nil {
+		return nil, err
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("learning service responded with status %d", res.StatusCode)
+	}
+
+	var statesRes struct {
+		Data []CardState `json:"data"`
+	}
+	if err := json.NewDecoder(res.Body).Decode(&statesRes); err != nil {
+		return nil, err
+	}
+
+	return statesRes.Data, nil
+}
+
+// MoveCards moves the learning history of the cards to the deck they were
+// moved to.
+func (s *LearningService) MoveCards(cardIDs []string, deckID string) error {
+	body, err := json.Marshal(map[string]interface{}{"cardIDs": cardIDs, "deckID": deckID})
+	if err != nil {
+		return err
+	}
+
+	endpoint := fmt.Sprintf("http://%s/events/move", s.hostName)
+	res, err := s.client.Post(endpoint, "application/json", bytes.NewBuffer(body))
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusOK {
+		return fmt.Errorf("learning service responded with status %d", res.StatusCode)
+	}
+
+	return nil
+}
+
+// GetLearningQueue returns the cards among the given ones the user should
+// learn, the ones most likely forgotten first.
+func (s *LearningService) GetLearningQueue(
+	userID string,
+	cardIDs []string,
+) ([]LearningCard, error) {
+	body, err := json.Marshal(map[string][]string{"cardIDs": cardIDs})
+	if err != nil {
+		return nil, err
+	}
+
+	endpoint := fmt.Sprintf(
+		"http://%s/events/queue?userID=%s",
+		s.hostName,
+		url.QueryEscape(userID),
+	)
+	res, err := s.client.Post(endpoint, "application/json", bytes.NewBuffer(body))
+	if err != nil {
+		return nil, err
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("learning service responded with status %d", res.StatusCode)
+	}
+
+	var queueRes struct {
+		Data []LearningCard `json:"data"`
+	}
+	if err := json.NewDecoder(res.Body).Decode(&queueRes); err != nil {
+		return nil, err
+	}
+
+	return queueRes.Data, nil
+}
@@ -0,0 +1,90 @@
+package external
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/moshrank/spacey-backend/config"
+	"github.com/moshrank/spacey-backend/services/deck-management-service/entity"
+)
+
+type User struct {
+	ID    string `json:"id"`
+	Email string `json:"email"`
+}
+
+type UserServiceInterface interface {
+	FindInvitee(email string) (*User, error)
+	InviteUser(inviterID, email, deckID, deckName string) (*User, error)
+}
+
+type UserService struct {
+	hostName string
+	client   *http.Client
+}
+
+func NewUserService(cfg config.ConfigInterface) UserServiceInterface {
+	return &UserService{
+		hostName: cfg.GetUserServiceHostName(),
+		client:   &http.Client{Timeout: 5 * time.Second},
+	}
+}
+
+// FindInvitee returns the user with the email without inviting them.
+func (s *UserService) FindInvitee(email string) (*User, error) {
+	body, err := json.Marshal(map[string]string{"email": email})
+	if err != nil {
+		return nil, err
+	}
+
+	return s.postInvitation(fmt.Sprintf("http://%s/invitation/invitee", s.hostName), body)
+}
+
+// InviteUser sends the user with the email an invitation to the deck.
+func (s *UserService) InviteUser(inviterID, email, deckID, deckName string) (*User, error) {
+	body, err := json.Marshal(map[string]string{
+		"email":    email,
+		"deckID":   deckID,
+		"deckName": deckName,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	endpoint := fmt.Sprintf(
+		"http://%s/invitation?userID=%s",
+		s.hostName,
+		url.QueryEscape(inviterID),
+	)
+
+	return s.postInvitation(endpoint, body)
+}
+
+func (s *UserService) postInvitation(endpoint string, body []byte) (*User, error) {
+	res, err := s.client.Post(endpoint, "application/json", bytes.NewBuffer(body))
+	if err != nil {
+		return nil, err
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode == http.StatusNotFound {
+		return nil, entity.ErrUserNotFound
+	}
+
+	if res.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("user service responded with status %d", res.StatusCode)
+	}
+
+	var userRes struct {
+		Data User `json:"data"`
+	}
+	if err := json.NewDecoder(res.Body).Decode(&userRes); err != nil {
+		return nil, err
+	}
+
+	return &userRes.Data, nil
+}
@@ -0,0 +1,488 @@
+package format
+
+import (
+	"archive/zip"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"html"
+	"io"
+	"os"
+	"regexp"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	_ "modernc.org/sqlite"
+)
+
+const (
+	AnkiModelStandard = 0
+	AnkiModelCloze    = 1
+
+	ankiFieldSeparator = "\x1f"
+)
+
+// maxAnkiCollectionSize is the size the collection of a package may have
+// once it is unpacked.
+var maxAnkiCollectionSize int64 = 1 << 30
+
+var ErrInvalidAnkiPackage = errors.New("invalid anki package")
+var ErrUnsupportedAnkiPackage = errors.New(
+	"unsupported anki package, please export it with \"support older anki versions\" enabled",
+)
+
+type AnkiField struct {
+	Name string `json:"name"`
+	Ord  int    `json:"ord"`
+}
+
+type AnkiTemplate struct {
+	Name string `json:"name"`
+	Ord  int    `json:"ord"`
+	QFmt string `json:"qfmt"`
+	AFmt string `json:"afmt"`
+}
+
+type AnkiModel struct {
+	ID        int64          `json:"id"`
+	Name      string         `json:"name"`
+	Type      int            `json:"type"`
+	Fields    []AnkiField    `json:"flds"`
+	Templates []AnkiTemplate `json:"tmpls"`
+}
+
+type AnkiDeck struct {
+	ID          int64  `json:"id"`
+	Name        string `json:"name"`
+	Description string `json:"desc"`
+}
+
+type AnkiNote struct {
+	ID      int64
+	GUID    string
+	ModelID int64
+	Fields  []string
+	Tags    []string
+}
+
+type AnkiCard struct {
+	ID       int64
+	NoteID   int64
+	DeckID   int64
+	Ord      int
+	Type     int
+	Queue    int
+	Due      int64
+	Interval int
+	Factor   int
+	Reps     int
+	Lapses   int
+}
+
+type AnkiReview struct {
+	// ID is the time of the review in milliseconds since epoch.
+	ID           int64
+	CardID       int64
+	Ease         int
+	Interval     int
+	LastInterval int
+	Factor       int
+	// Time is the time the review took in milliseconds.
+	Time int
+	Type int
+}
+
+type AnkiPackage struct {
+	// Created is the creation time of the collection which due dates of
+	// review cards are counted from.
+	Created time.Time
+	Models  map[int64]AnkiModel
+	Decks   map[int64]AnkiDeck
+	Notes   map[int64]AnkiNote
+	Cards   []AnkiCard
+	Reviews []AnkiReview
+	// Media maps the file names inside the archive to the original file names.
+	// It is only written, media files of imported packages are not read.
+	Media map[string]string
+}
+
+// FieldMap returns the fields of a note keyed by the field names of its model.
+func (m *AnkiModel) FieldMap(note *AnkiNote) map[string]string {
+	fields := map[string]string{}
+	for _, field := range m.Fields {
+		if field.Ord < len(note.Fields) {
+			fields[field.Name] = note.Fields[field.Ord]
+		}
+	}
+
+	return fields
+}
+
+func (m *AnkiModel) Template(ord int) (*AnkiTemplate, bool) {
+	for i := range m.Templates {
+		if m.Templates[i].Ord == ord {
+			return &m.Templates[i], true
+		}
+	}
+
+	return nil, false
+}
+
+func ReadAnkiPackage(r io.ReaderAt, size int64) (*AnkiPackage, error) {
+	archive, err := zip.NewReader(r, size)
+	if err != nil {
+		return nil, ErrInvalidAnkiPackage
+	}
+
+	files := map[string]*zip.File{}
+	for _, file := range archive.File {
+		files[file.Name] = file
+	}
+
+	collection, ok := files["collection.anki21"]
+	if !ok {
+		if _, ok := files["collection.anki21b"]; ok {
+			return nil, ErrUnsupportedAnkiPackage
+		}
+
+		collection, ok = files["collection.anki2"]
+		if !ok {
+			return nil, ErrInvalidAnkiPackage
+		}
+	}
+
+	pkg, err := readAnkiCollection(collection)
+	if err != nil {
+		return nil, err
+	}
+
+	return pkg, nil
+}
+
+func readAnkiCollection(collection *zip.File) (*AnkiPackage, error) {
+	// sqlite can only open databases from the file system
+	tmpFile, err := os.CreateTemp("", "collection-*.anki2")
+	if err != nil {
+		return nil, err
+	}
+	defer os.Remove(tmpFile.Name())
+
+	src, err := collection.Open()
+	if err != nil {
+		tmpFile.Close()
+		return nil, err
+	}
+
+	// the upload size only limits the compressed collection
+	written, err := io.Copy(tmpFile, io.LimitReader(src, maxAnkiCollectionSize+1))
+	src.Close()
+	tmpFile.Close()
+	if err != nil {
+		return nil, err
+	}
+
+	if written > maxAnkiCollectionSize {
+		return nil, fmt.Errorf("%w: the collection is too large", ErrInvalidAnkiPackage)
+	}
+
+	db, err := sql.Open("sqlite", "file:"+tmpFile.Name()+"?mode=ro")
+	if err != nil {
+		return nil, err
+	}
+	defer db.Close()
+
+	pkg := &AnkiPackage{}
+
+	if err := readAnkiCol(db, pkg); err != nil {
+		return nil, err
+	}
+
+	if err := readAnkiNotes(db, pkg); err != nil {
+		return nil, err
+	}
+
+	if err := readAnkiCards(db, pkg); err != nil {
+		return nil, err
+	}
+
+	if err := readAnkiReviews(db, pkg); err != nil {
+		return nil, err
+	}
+
+	return pkg, nil
+}
+
+func readAnkiCol(db *sql.DB, pkg *AnkiPackage) error {
+	var created int64
+	var models, decks string
+	err := db.QueryRow("SELECT crt, models, decks FROM col LIMIT 1").
+		Scan(&created, &models, &decks)
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidAnkiPackage, err)
+	}
+	pkg.Created = time.Unix(created, 0)
+
+	var modelsByID map[string]AnkiModel
+	if err := json.Unmarshal([]byte(models), &modelsByID); err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidAnkiPackage, err)
+	}
+
+	var decksByID map[string]AnkiDeck
+	if err := json.Unmarshal([]byte(decks), &decksByID); err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidAnkiPackage, err)
+	}
+
+	pkg.Models = map[int64]AnkiModel{}
+	for key, model := range modelsByID {
+		id, err := strconv.ParseInt(key, 10, 64)
+		if err != nil {
+			return fmt.Errorf("%w: invalid model id %s", ErrInvalidAnkiPackage, key)
+		}
+
+		sort.Slice(model.Fields, func(i, j int) bool {
+			return model.Fields[i].Ord < model.Fields[j].Ord
+		})
+		model.ID = id
+		pkg.Models[id] = model
+	}
+
+	pkg.Decks = map[int64]AnkiDeck{}
+	for key, deck := range decksByID {
+		id, err := strconv.ParseInt(key, 10, 64)
+		if err != nil {
+			return fmt.Errorf("%w: invalid deck id %s", ErrInvalidAnkiPackage, key)
+		}
+
+		deck.ID = id
+		pkg.Decks[id] = deck
+	}
+
+	return nil
+}
+
+func readAnkiNotes(db *sql.DB, pkg *AnkiPackage) error {
+	rows, err := db.Query("SELECT id, guid, mid, tags, flds FROM notes")
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidAnkiPackage, err)
+	}
+	defer rows.Close()
+
+	pkg.Notes = map[int64]AnkiNote{}
+	for rows.Next() {
+		var note AnkiNote
+		var tags, fields string
+
+		if err := rows.Scan(&note.ID, &note.GUID, &note.ModelID, &tags, &fields); err != nil {
+			return err
+		}
+
+		note.Tags = strings.Fields(tags)
+		note.Fields = strings.Split(fields, ankiFieldSeparator)
+		pkg.Notes[note.ID] = note
+	}
+
+	return rows.Err()
+}
+
+func readAnkiCards(db *sql.DB, pkg *AnkiPackage) error {
+	rows, err := db.Query(
+		`SELECT id, nid, did, ord, type, queue, due, ivl, factor, reps, lapses
+		FROM cards ORDER BY id`,
+	)
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidAnkiPackage, err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var card AnkiCard
+
+		err := rows.Scan(
+			&card.ID,
+			&card.NoteID,
+			&card.DeckID,
+			&card.Ord,
+			&card.Type,
+			&card.Queue,
+			&card.Due,
+			&card.Interval,
+			&card.Factor,
+			&card.Reps,
+			&card.Lapses,
+		)
+		if err != nil {
+			return err
+		}
+
+		pkg.Cards = append(pkg.Cards, card)
+	}
+
+	return rows.Err()
+}
+
+func readAnkiReviews(db *sql.DB, pkg *AnkiPackage) error {
+	rows, err := db.Query(
+		`SELECT id, cid, ease, ivl, lastIvl, factor, time, type
+		FROM revlog ORDER BY id`,
+	)
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidAnkiPackage, err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var review AnkiReview
+
+		err := rows.Scan(
+			&review.ID,
+			&review.CardID,
+			&review.Ease,
+			&review.Interval,
+			&review.LastInterval,
+			&review.Factor,
+			&review.Time,
+			&review.Type,
+		)
+		if err != nil {
+			return err
+		}
+
+		pkg.Reviews = append(pkg.Reviews, review)
+	}
+
+	return rows.Err()
+}
+
+var ankiTagPattern = regexp.MustCompile(`{{([^{}]+)}}`)
+
+// RenderAnkiTemplate renders a card template of a standard note type. Field
+// filters are ignored except for type answers which are not rendered at all.
+func RenderAnkiTemplate(template string, fields map[string]string) string {
+	template = renderAnkiSections(template, fields)
+
+	return ankiTagPattern.ReplaceAllStringFunc(template, func(tag string) string {
+		name := strings.TrimSpace(tag[2 : len(tag)-2])
+
+		filters := strings.Split(name, ":")
+		name = filters[len(filters)-1]
+		for _, filter := range filters[:len(filters)-1] {
+			if filter == "type" {
+				return ""
+			}
+		}
+
+		return fields[name]
+	})
+}
+
+var ankiClozePattern = regexp.MustCompile(`{{\s*cloze:([^{}]+?)\s*}}`)
+
+// AnkiClozeField returns the name of the field a template of a cloze note
+// type takes its deletions from, e.g. "Text" for "{{cloze:Text}}".
+func AnkiClozeField(template string) (string, bool) {
+	match := ankiClozePattern.FindStringSubmatch(template)
+	if match == nil {
+		return "", false
+	}
+
+	return match[1], true
+}
+
+func renderAnkiSections(template string, fields map[string]string) string {
+	for {
+		start := strings.Index(template, "{{#")
+		inverted := strings.Index(template, "{{^")
+		if start == -1 || (inverted != -1 && inverted < start) {
+			start = inverted
+		}
+
+		if start == -1 {
+			return template
+		}
+
+		end := strings.Index(template[start:], "}}")
+		if end == -1 {
+			return template
+		}
+		end += start
+
+		name := strings.TrimSpace(template[start+3 : end])
+		closeTag := "{{/" + name + "}}"
+
+		closeStart := strings.Index(template[end:], closeTag)
+		if closeStart == -1 {
+			// drop the broken tag instead of looping forever
+			template = template[:start] + template[end+2:]
+			continue
+		}
+		closeStart += end
+
+		content := template[end+2 : closeStart]
+		isSet := strings.TrimSpace(AnkiHTMLToText(fields[name])) != ""
+		if isSet == (template[start+2] == '^') {
+			content = ""
+		}
+
+		template = template[:start] + content + template[closeStart+len(closeTag):]
+	}
+}
+
+var (
+	ankiLineBreakPattern = regexp.MustCompile(`(?i)<br\s*/?>|</(div|p|li|tr)>`)
+	ankiAnswerPattern    = regexp.MustCompile(`(?i)<hr[^>]*id=["']?answer["']?[^>]*>`)
+	ankiStylePattern     = regexp.MustCompile(`(?is)<(style|script)[^>]*>.*?</(style|script)>`)
+	ankiHTMLTagPattern   = regexp.MustCompile(`<[^>]*>`)
+	ankiNewLinesPattern  = regexp.MustCompile(`\n{3,}`)
+	ankiMediaPattern     = regexp.MustCompile(
+		`(?i)<img[^>]*\ssrc=(?:"([^"]+)"|'([^']+)'|([^\s>]+))|\[sound:([^\]]+)\]`,
+	)
+)
+
+// AnkiAnswer renders the answer template without the front side which
+// anki usually repeats above the answer.
+func AnkiAnswer(template string, fields map[string]string) string {
+	answerFields := map[string]string{}
+	for name, value := range fields {
+		answerFields[name] = value
+	}
+	answerFields["FrontSide"] = ""
+
+	answer := RenderAnkiTemplate(template, answerFields)
+	if loc := ankiAnswerPattern.FindStringIndex(answer); loc != nil {
+		answer = answer[loc[1]:]
+	}
+
+	return answer
+}
+
+// AnkiHTMLToText converts the html of anki fields into plain text.
+func AnkiHTMLToText(value string) string {
+	value = ankiStylePattern.ReplaceAllString(value, "")
+	value = ankiLineBreakPattern.ReplaceAllString(value, "\n")
+	value = ankiHTMLTagPattern.ReplaceAllString(value, "")
+	value = html.UnescapeString(value)
+	value = strings.ReplaceAll(value, " ", " ")
+	value = ankiNewLinesPattern.ReplaceAllString(value, "\n\n")
+
+	return strings.TrimSpace(value)
+}
+
+// AnkiMediaFiles returns the names of the images and sounds the fields
+// reference, which are lost when the fields are converted into text.
+func AnkiMediaFiles(fields []string) []string {
+	files := []string{}
+	for _, field := range fields {
+		for _, match := range ankiMediaPattern.FindAllStringSubmatch(field, -1) {
+			name := html.UnescapeString(match[1] + match[2] + match[3] + match[4])
+			if !slices.Contains(files, name) {
+				files = append(files, name)
+			}
+		}
+	}
+
+	return files
+}
@@ -0,0 +1,131 @@
+package format
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAnkiPackageRoundTrip(t *testing.T) {
+	created := time.Unix(1600000000, 0)
+
+	pkg := &AnkiPackage{
+		Created: created,
+		Models:  map[int64]AnkiModel{AnkiBasicModelID: AnkiBasicModel},
+		Decks:   map[int64]AnkiDeck{10: {Name: "Spanish", Description: "Verbs"}},
+		Notes: map[int64]AnkiNote{
+			100: {
+				ID:      100,
+				GUID:    "card_1",
+				ModelID: AnkiBasicModelID,
+				Fields:  []string{"hablar", AnkiTextToHTML("to speak\n<loudly>")},
+				Tags:    []string{"verbs"},
+			},
+		},
+		Cards: []AnkiCard{
+			{
+				ID:       1000,
+				NoteID:   100,
+				DeckID:   10,
+				Type:     AnkiCardReview,
+				Queue:    AnkiCardReview,
+				Due:      4,
+				Interval: 2,
+				Factor:   AnkiDefaultFactor,
+				Reps:     3,
+			},
+		},
+		Reviews: []AnkiReview{{ID: 1600000000000, CardID: 1000, Ease: 3, Interval: 2}},
+	}
+
+	var buf bytes.Buffer
+	assert.Nil(t, WriteAnkiPackage(&buf, pkg))
+
+	read, err := ReadAnkiPackage(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+
+	assert.Nil(t, err)
+	assert.Equal(t, created, read.Created)
+	assert.Equal(t, "Spanish", read.Decks[10].Name)
+	assert.Equal(t, "Verbs", read.Decks[10].Description)
+	assert.Equal(t, "Default", read.Decks[ankiDefaultDeckID].Name)
+	assert.Equal(t, pkg.Notes[100], read.Notes[100])
+	assert.Equal(t, pkg.Cards, read.Cards)
+	assert.Equal(t, pkg.Reviews, read.Reviews)
+
+	model := read.Models[AnkiBasicModelID]
+	note := read.Notes[100]
+	fields := model.FieldMap(&note)
+	template, _ := model.Template(0)
+	assert.Equal(t, "hablar", AnkiHTMLToText(RenderAnkiTemplate(template.QFmt, fields)))
+	assert.Equal(t, "to speak\n<loudly>", AnkiHTMLToText(AnkiAnswer(template.AFmt, fields)))
+}
+
+func TestRenderAnkiTemplate(t *testing.T) {
+	fields := map[string]string{"Front": "front", "Back": "back", "Hint": ""}
+
+	tests := []struct {
+		testName string
+		template string
+		expected string
+	}{
+		{"Field", "{{Front}}", "front"},
+		{"Filter", "{{text:Front}}", "front"},
+		{"Type Answer", "{{Front}}{{type:Back}}", "front"},
+		{"Section", "{{#Back}}[{{Back}}]{{/Back}}", "[back]"},
+		{"Empty Section", "{{#Hint}}[{{Hint}}]{{/Hint}}", ""},
+		{"Inverted Section", "{{^Hint}}no hint{{/Hint}}", "no hint"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.testName, func(t *testing.T) {
+			assert.Equal(t, test.expected, RenderAnkiTemplate(test.template, fields))
+		})
+	}
+}
+
+func TestAnkiClozeField(t *testing.T) {
+	tests := []struct {
+		testName string
+		template string
+		field    string
+		ok       bool
+	}{
+		{"Cloze Field", "{{cloze:Text}}", "Text", true},
+		{"Spaces", "<div>{{ cloze:Back Extra }}</div>", "Back Extra", true},
+		{"No Cloze", "{{Front}}", "", false},
+	}
+
+	for _, test := range tests {
+		t.Run(test.testName, func(t *testing.T) {
+			field, ok := AnkiClozeField(test.template)
+
+			assert.Equal(t, test.field, field)
+			assert.Equal(t, test.ok, ok)
+		})
+	}
+}
+
+func TestReadAnkiPackageTooLarge(t *testing.T) {
+	var buf bytes.Buffer
+	assert.Nil(t, WriteAnkiPackage(&buf, &AnkiPackage{}))
+
+	maxSize := maxAnkiCollectionSize
+	maxAnkiCollectionSize = 1024
+	defer func() { maxAnkiCollectionSize = maxSize }()
+
+	_, err := ReadAnkiPackage(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+
+	assert.ErrorIs(t, err, ErrInvalidAnkiPackage)
+}
+
+func TestAnkiMediaFiles(t *testing.T) {
+	files := AnkiMediaFiles([]string{
+		`<img src="a b.jpg"> <IMG class=x src='c.png'>`,
+		`<img src=d.gif>[sound:e.mp3] <img src="a b.jpg">`,
+		"no media",
+	})
+
+	assert.Equal(t, []string{"a b.jpg", "c.png", "d.gif", "e.mp3"}, files)
+}
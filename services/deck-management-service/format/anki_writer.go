@@ -0,0 +1,393 @@
+package format
+
+import (
+	"archive/zip"
+	"crypto/sha1"
+	"database/sql"
+	"encoding/hex"
+	"encoding/json"
+	"html"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+const (
+	AnkiCardNew    = 0
+	AnkiCardReview = 2
+
+	AnkiDefaultFactor = 2500
+
+	// ankiDefaultDeckID is the deck every anki collection has to contain.
+	ankiDefaultDeckID = 1
+	ankiSchemaVersion = 11
+)
+
+// AnkiBasicModelID is fixed so that anki recognises the note type of
+// repeated exports instead of creating a new copy every time.
+const AnkiBasicModelID int64 = 1645300000000
+
+var AnkiBasicModel = AnkiModel{
+	ID:   AnkiBasicModelID,
+	Name: "Spacey Basic",
+	Type: AnkiModelStandard,
+	Fields: []AnkiField{
+		{Name: "Front", Ord: 0},
+		{Name: "Back", Ord: 1},
+	},
+	Templates: []AnkiTemplate{
+		{
+			Name: "Card 1",
+			Ord:  0,
+			QFmt: "{{Front}}",
+			AFmt: "{{FrontSide}}\n\n<hr id=answer>\n\n{{Back}}",
+		},
+	},
+}
+
+const ankiSchema = `
+CREATE TABLE col (
+	id integer primary key, crt integer not null, mod integer not null,
+	scm integer not null, ver integer not null, dty integer not null,
+	usn integer not null, ls integer not null, conf text not null,
+	models text not null, decks text not null, dconf text not null,
+	tags text not null
+);
+CREATE TABLE notes (
+	id integer primary key, guid text not null, mid integer not null,
+	mod integer not null, usn integer not null, tags text not null,
+	flds text not null, sfld integer not null, csum integer not null,
+	flags integer not null, data text not null
+);
+CREATE TABLE cards (
+	id integer primary key, nid integer not null, did integer not null,
+	ord integer not null, mod integer not null, usn integer not null,
+	type integer not null, queue integer not null, due integer not null,
+	ivl integer not null, factor integer not null, reps integer not null,
+	lapses integer not null, left integer not null, odue integer not null,
+	odid integer not null, flags integer not null, data text not null
+);
+CREATE TABLE revlog (
+	id integer primary key, cid integer not null, usn integer not null,
+	ease integer not null, ivl integer not null, lastIvl integer not null,
+	factor integer not null, time integer not null, type integer not null
+);
+CREATE TABLE graves (usn integer not null, oid integer not null, type integer not null);
+CREATE INDEX ix_notes_usn ON notes (usn);
+CREATE INDEX ix_cards_usn ON cards (usn);
+CREATE INDEX ix_revlog_usn ON revlog (usn);
+CREATE INDEX ix_cards_nid ON cards (nid);
+CREATE INDEX ix_cards_sched ON cards (did, queue, due);
+CREATE INDEX ix_revlog_cid ON revlog (cid);
+CREATE INDEX ix_notes_csum ON notes (csum);
+`
+
+const ankiCollectionConf = `{"nextPos": 1, "estTimes": true, "activeDecks": [1],
+"sortType": "noteFld", "timeLim": 0, "sortBackwards": false, "addToCur": true,
+"curDeck": 1, "newBury": true, "newSpread": 0, "dueCounts": true, "curModel": null,
+"collapseTime": 1200}`
+
+const ankiDeckConf = `{"1": {"id": 1, "name": "Default", "replayq": true, "timer": 0,
+"maxTaken": 60, "usn": 0, "mod": 0, "autoplay": true, "dyn": false,
+"lapse": {"leechFails": 8, "minInt": 1, "delays": [10], "leechAction": 0, "mult": 0},
+"rev": {"perDay": 200, "fuzz": 0.05, "ivlFct": 1, "maxIvl": 36500, "ease4": 1.3,
+"bury": false, "minSpace": 1},
+"new": {"perDay": 20, "delays": [1, 10], "separate": true, "ints": [1, 4, 7],
+"initialFactor": 2500, "bury": false, "order": 1}}}`
+
+const ankiCSS = `.card {
+ font-family: arial;
+ font-size: 20px;
+ text-align: center;
+ color: black;
+ background-color: white;
+}`
+
+// WriteAnkiPackage writes pkg as an .apkg archive which uses the legacy
+// collection format so that it can be imported by all anki versions.
+func WriteAnkiPackage(w io.Writer, pkg *AnkiPackage) error {
+	dir, err := os.MkdirTemp("", "anki-export-*")
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "collection.anki2")
+	if err := writeAnkiCollection(path, pkg); err != nil {
+		return err
+	}
+
+	archive := zip.NewWriter(w)
+
+	collection, err := archive.Create("collection.anki2")
+	if err != nil {
+		return err
+	}
+
+	collectionFile, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer collectionFile.Close()
+
+	if _, err := io.Copy(collection, collectionFile); err != nil {
+		return err
+	}
+
+	media, err := archive.Create("media")
+	if err != nil {
+		return err
+	}
+
+	mediaNames := pkg.Media
+	if mediaNames == nil {
+		mediaNames = map[string]string{}
+	}
+	if err := json.NewEncoder(media).Encode(mediaNames); err != nil {
+		return err
+	}
+
+	return archive.Close()
+}
+
+func writeAnkiCollection(path string, pkg *AnkiPackage) error {
+	db, err := sql.Open("sqlite", path)
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	tx, err := db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec(ankiSchema); err != nil {
+		return err
+	}
+
+	if err := writeAnkiCol(tx, pkg); err != nil {
+		return err
+	}
+
+	if err := writeAnkiNotes(tx, pkg); err != nil {
+		return err
+	}
+
+	if err := writeAnkiCards(tx, pkg); err != nil {
+		return err
+	}
+
+	if err := writeAnkiReviews(tx, pkg); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+func writeAnkiCol(tx *sql.Tx, pkg *AnkiPackage) error {
+	mod := pkg.Created.UnixMilli()
+
+	models := map[string]interface{}{}
+	for id, model := range pkg.Models {
+		models[strconv.FormatInt(id, 10)] = ankiModelJSON(id, &model, mod)
+	}
+
+	decks := map[string]interface{}{
+		strconv.Itoa(ankiDefaultDeckID): ankiDeckJSON(
+			AnkiDeck{ID: ankiDefaultDeckID, Name: "Default"},
+			mod,
+		),
+	}
+	for id, deck := range pkg.Decks {
+		deck.ID = id
+		decks[strconv.FormatInt(id, 10)] = ankiDeckJSON(deck, mod)
+	}
+
+	modelsJSON, err := json.Marshal(models)
+	if err != nil {
+		return err
+	}
+
+	decksJSON, err := json.Marshal(decks)
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.Exec(
+		"INSERT INTO col VALUES (1, ?, ?, ?, ?, 0, 0, 0, ?, ?, ?, ?, '{}')",
+		pkg.Created.Unix(),
+		mod,
+		mod,
+		ankiSchemaVersion,
+		ankiCollectionConf,
+		string(modelsJSON),
+		string(decksJSON),
+		ankiDeckConf,
+	)
+
+	return err
+}
+
+func ankiModelJSON(id int64, model *AnkiModel, mod int64) map[string]interface{} {
+	templates := []map[string]interface{}{}
+	for _, template := range model.Templates {
+		templates = append(templates, map[string]interface{}{
+			"name":  template.Name,
+			"ord":   template.Ord,
+			"qfmt":  template.QFmt,
+			"afmt":  template.AFmt,
+			"did":   nil,
+			"bqfmt": "",
+			"bafmt": "",
+		})
+	}
+
+	fields := []map[string]interface{}{}
+	for _, field := range model.Fields {
+		fields = append(fields, map[string]interface{}{
+			"name":   field.Name,
+			"ord":    field.Ord,
+			"sticky": false,
+			"rtl":    false,
+			"font":   "Arial",
+			"size":   20,
+			"media":  []string{},
+		})
+	}
+
+	return map[string]interface{}{
+		"id":        id,
+		"name":      model.Name,
+		"type":      model.Type,
+		"mod":       mod / 1000,
+		"usn":       0,
+		"sortf":     0,
+		"did":       ankiDefaultDeckID,
+		"tmpls":     templates,
+		"flds":      fields,
+		"css":       ankiCSS,
+		"latexPre":  "\\documentclass[12pt]{article}\n\\begin{document}\n",
+		"latexPost": "\\end{document}",
+		"tags":      []string{},
+		"vers":      []string{},
+		"req":       []interface{}{[]interface{}{0, "any", []int{0}}},
+	}
+}
+
+func ankiDeckJSON(deck AnkiDeck, mod int64) map[string]interface{} {
+	return map[string]interface{}{
+		"id":               deck.ID,
+		"name":             deck.Name,
+		"desc":             deck.Description,
+		"mod":              mod / 1000,
+		"usn":              0,
+		"lrnToday":         []int{0, 0},
+		"revToday":         []int{0, 0},
+		"newToday":         []int{0, 0},
+		"timeToday":        []int{0, 0},
+		"collapsed":        false,
+		"browserCollapsed": false,
+		"dyn":              0,
+		"conf":             1,
+		"extendNew":        10,
+		"extendRev":        50,
+	}
+}
+
+func writeAnkiNotes(tx *sql.Tx, pkg *AnkiPackage) error {
+	mod := pkg.Created.Unix()
+
+	for _, note := range pkg.Notes {
+		sortField := ""
+		if len(note.Fields) > 0 {
+			sortField = AnkiHTMLToText(note.Fields[0])
+		}
+
+		tags := ""
+		if len(note.Tags) > 0 {
+			tags = " " + strings.Join(note.Tags, " ") + " "
+		}
+
+		_, err := tx.Exec(
+			"INSERT INTO notes VALUES (?, ?, ?, ?, 0, ?, ?, ?, ?, 0, '')",
+			note.ID,
+			note.GUID,
+			note.ModelID,
+			mod,
+			tags,
+			strings.Join(note.Fields, ankiFieldSeparator),
+			sortField,
+			ankiChecksum(sortField),
+		)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func writeAnkiCards(tx *sql.Tx, pkg *AnkiPackage) error {
+	mod := pkg.Created.Unix()
+
+	for _, card := range pkg.Cards {
+		_, err := tx.Exec(
+			"INSERT INTO cards VALUES (?, ?, ?, ?, ?, 0, ?, ?, ?, ?, ?, ?, ?, 0, 0, 0, 0, '')",
+			card.ID,
+			card.NoteID,
+			card.DeckID,
+			card.Ord,
+			mod,
+			card.Type,
+			card.Queue,
+			card.Due,
+			card.Interval,
+			card.Factor,
+			card.Reps,
+			card.Lapses,
+		)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func writeAnkiReviews(tx *sql.Tx, pkg *AnkiPackage) error {
+	for _, review := range pkg.Reviews {
+		_, err := tx.Exec(
+			"INSERT INTO revlog VALUES (?, ?, 0, ?, ?, ?, ?, ?, ?)",
+			review.ID,
+			review.CardID,
+			review.Ease,
+			review.Interval,
+			review.LastInterval,
+			review.Factor,
+			review.Time,
+			review.Type,
+		)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// ankiChecksum is used by anki to find duplicate notes.
+func ankiChecksum(sortField string) int64 {
+	sum := sha1.Sum([]byte(sortField))
+	checksum, _ := strconv.ParseInt(hex.EncodeToString(sum[:4]), 16, 64)
+
+	return checksum
+}
+
+// AnkiTextToHTML converts plain card text into the html anki expects.
+func AnkiTextToHTML(value string) string {
+	return strings.ReplaceAll(html.EscapeString(value), "\n", "<br>")
+}
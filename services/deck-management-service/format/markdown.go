@@ -0,0 +1,874 @@
+package format
+
+import (
+	"html"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/microcosm-cc/bluemonday"
+)
+
+// MediaURL returns the url a media file referenced as media:<id> is served
+// at.
+type MediaURL func(mediaID string) string
+
+// cardElements lists the html elements card content may use. Every other
+// element is removed, while its content is kept.
+var cardElements = []string{
+	"a", "b", "blockquote", "br", "code", "del", "div", "em", "h1", "h2", "h3", "h4",
+	"h5", "h6", "hr", "i", "img", "ins", "kbd", "li", "mark", "ol", "p", "pre", "rp",
+	"rt", "ruby", "s", "small", "span", "strong", "sub", "sup", "table", "tbody",
+	"td", "th", "thead", "tr", "u", "ul",
+}
+
+var (
+	// markdownPolicy sanitizes the html within markdown card content.
+	// Images may only be loaded from the web or the media of the deck.
+	markdownPolicy = newHTMLPolicy(regexp.MustCompile(`(?i)^\s*(https?|media):`))
+	// htmlPolicy sanitizes rendered card content, in which media files may
+	// already be linked by the relative url they are served at.
+	htmlPolicy = newHTMLPolicy(regexp.MustCompile(`(?i)^\s*(https?:|media:|/)`))
+	mathClass  = regexp.MustCompile(`^math (inline|display)$`)
+	codeClass  = regexp.MustCompile(`^language-[\w+-]+$`)
+)
+
+// newHTMLPolicy returns the policy for the html card content may contain,
+// with images loaded from the urls imageURL matches.
+func newHTMLPolicy(imageURL *regexp.Regexp) *bluemonday.Policy {
+	policy := bluemonday.NewPolicy()
+	policy.AllowElements(cardElements...)
+	policy.AllowNoAttrs().OnElements("a")
+	policy.AllowAttrs("title").OnElements(cardElements...)
+	policy.AllowAttrs("href").OnElements("a")
+	policy.AllowAttrs("src").Matching(imageURL).OnElements("img")
+	policy.AllowAttrs("alt").OnElements("img")
+	policy.AllowAttrs("start").Matching(bluemonday.Integer).OnElements("ol")
+	policy.AllowAttrs("colspan", "rowspan").Matching(bluemonday.Integer).OnElements("td", "th")
+	policy.AllowAttrs("class").Matching(mathClass).OnElements("span", "div")
+	policy.AllowAttrs("class").Matching(codeClass).OnElements("code")
+	policy.AllowURLSchemes("http", "https", "mailto", "media")
+	policy.AllowRelativeURLs(true)
+
+	return policy
+}
+
+// droppedTags are removed together with their content.
+var droppedTags = map[string]bool{
+	"script": true, "style": true, "iframe": true, "object": true, "embed": true,
+	"noscript": true, "template": true, "textarea": true, "select": true,
+	"svg": true, "math": true, "title": true, "head": true, "frame": true,
+	"frameset": true, "noembed": true, "xmp": true,
+}
+
+var voidTags = map[string]bool{"br": true, "hr": true, "img": true}
+
+var (
+	htmlTag     = regexp.MustCompile(`^<(/?)([a-zA-Z][a-zA-Z0-9]*)((?:\s+[^<>]*?)?)\s*/?>`)
+	htmlComment = regexp.MustCompile(`^<!--[\s\S]*?-->`)
+	htmlEntity  = regexp.MustCompile(
+		`^&(?:[a-zA-Z][a-zA-Z0-9]{1,31}|#[0-9]{1,7}|#[xX][0-9a-fA-F]{1,6});`,
+	)
+	autolink       = regexp.MustCompile(`^<((?:https?://|mailto:)[^\s<>]+)>`)
+	urlScheme      = regexp.MustCompile(`^([a-zA-Z][a-zA-Z0-9+.-]*):`)
+	urlUnsafeChars = regexp.MustCompile(`[\x00-\x20\x7f]`)
+	codeFence      = regexp.MustCompile("^ {0,3}(`{3,}|~{3,})\\s*([\\w+-]*)")
+	titleEscaper   = strings.NewReplacer("<", "&lt;", ">", "&gt;", `"`, "&quot;")
+	mediaAttribute = regexp.MustCompile(`\b(href|src)="(?i:media:)([^"]*)"`)
+)
+
+// safeURL cleans the url and reports whether it may be linked to. Only web,
+// mail and media links and relative links are allowed, images may only be
+// loaded from the web or the media of the deck.
+func safeURL(url string, image bool) (string, bool) {
+	url = urlUnsafeChars.ReplaceAllString(html.UnescapeString(url), "")
+
+	match := urlScheme.FindStringSubmatch(url)
+	if match == nil {
+		return url, !image
+	}
+
+	switch strings.ToLower(match[1]) {
+	case "http", "https", "media":
+		return url, true
+	case "mailto":
+		return url, !image
+	}
+
+	return url, false
+}
+
+// resolveMedia replaces the media:<id> urls of sanitized html with the urls
+// the media files are served at.
+func resolveMedia(text string, mediaURL MediaURL) string {
+	if mediaURL == nil {
+		return text
+	}
+
+	return mediaAttribute.ReplaceAllStringFunc(text, func(match string) string {
+		parts := mediaAttribute.FindStringSubmatch(match)
+		return parts[1] + `="` + html.EscapeString(mediaURL(parts[2])) + `"`
+	})
+}
+
+// skipHTML returns how many bytes the html at the start of the text takes
+// and what it is replaced by, or 0 if the text does not start with html.
+// Comments and dropped tags are removed together with their content.
+func skipHTML(text string) (int, string) {
+	if match := htmlComment.FindString(text); match != "" {
+		return len(match), ""
+	}
+
+	match := htmlTag.FindStringSubmatch(text)
+	if match == nil {
+		return 0, ""
+	}
+
+	name := strings.ToLower(match[2])
+	if droppedTags[name] {
+		if match[1] != "" {
+			return len(match[0]), ""
+		}
+
+		end := strings.Index(strings.ToLower(text), "</"+name)
+		if end < 0 {
+			return len(text), ""
+		}
+
+		closing := strings.IndexByte(text[end:], '>')
+		if closing < 0 {
+			return len(text), ""
+		}
+
+		return end + closing + 1, ""
+	}
+
+	return len(match[0]), markdownPolicy.Sanitize(match[0])
+}
+
+// protectedLength returns how many bytes the code or math at the start of
+// the text takes, or 0 if it does not start with code or math. Neither html
+// nor markdown is interpreted within code and math.
+func protectedLength(text string) int {
+	switch {
+	case strings.HasPrefix(text, `\(`):
+		if end := strings.Index(text[2:], `\)`); end >= 0 {
+			return end + 4
+		}
+	case strings.HasPrefix(text, `\[`):
+		if end := strings.Index(text[2:], `\]`); end >= 0 {
+			return end + 4
+		}
+	case strings.HasPrefix(text, "$$"):
+		if end := strings.Index(text[2:], "$$"); end > 0 {
+			return end + 4
+		}
+	case strings.HasPrefix(text, "$"):
+		return inlineMathLength(text)
+	case strings.HasPrefix(text, "`"):
+		run := len(text) - len(strings.TrimLeft(text, "`"))
+		fence := text[:run]
+		for i := run; i < len(text); {
+			end := strings.Index(text[i:], fence)
+			if end < 0 {
+				break
+			}
+
+			end += i
+			after := end + run
+			if after == len(text) || text[after] != '`' {
+				return after
+			}
+			i = after + len(text[after:]) - len(strings.TrimLeft(text[after:], "`"))
+		}
+	}
+
+	return 0
+}
+
+// inlineMathLength returns the length of math like $x^2$ at the start of the
+// text. Like in pandoc, the math may neither start nor end with a space and
+// a digit may not follow it, so that prices like $5 and $10 stay text.
+func inlineMathLength(text string) int {
+	if len(text) < 3 || text[1] == ' ' || text[1] == '$' || text[1] == '\n' {
+		return 0
+	}
+
+	for i := 2; i < len(text); i++ {
+		switch text[i] {
+		case '\\':
+			i++
+		case '\n':
+			if i+1 < len(text) && text[i+1] == '\n' {
+				return 0
+			}
+		case '$':
+			if text[i-1] == ' ' || i+1 < len(text) && text[i+1] >= '0' && text[i+1] <= '9' {
+				return 0
+			}
+			return i + 1
+		}
+	}
+
+	return 0
+}
+
+// SanitizeMarkdown removes the html card content may not contain from the
+// markdown text and replaces unsafe link targets. Allowed tags are kept
+// with their allowed attributes, while code and math are left untouched.
+// Anything else that looks like a tag is escaped, so the text is safe for
+// every markdown renderer that allows html.
+func SanitizeMarkdown(text string) string {
+	var sanitized strings.Builder
+	lineStart := true
+	for i := 0; i < len(text); {
+		if lineStart {
+			if fence := codeFence.FindStringSubmatch(text[i:]); fence != nil {
+				end := fencedCodeEnd(text[i:], fence[1])
+				sanitized.WriteString(text[i : i+end])
+				i += end
+				continue
+			}
+		}
+
+		c := text[i]
+		lineStart = c == '\n'
+
+		if n := protectedLength(text[i:]); n > 0 {
+			sanitized.WriteString(text[i : i+n])
+			i += n
+			continue
+		}
+
+		switch {
+		case c == '\\' && i+1 < len(text):
+			sanitized.WriteString(text[i : i+2])
+			i += 2
+		case c == '<':
+			if n := len(autolink.FindString(text[i:])); n > 0 {
+				sanitized.WriteString(text[i : i+n])
+				i += n
+				continue
+			}
+
+			if n, tag := skipHTML(text[i:]); n > 0 {
+				sanitized.WriteString(tag)
+				i += n
+				continue
+			}
+
+			if i+1 < len(text) && strings.ContainsAny(text[i+1:i+2], "/!?") ||
+				i+1 < len(text) && isLetter(text[i+1]) {
+				sanitized.WriteString("&lt;")
+			} else {
+				sanitized.WriteByte(c)
+			}
+			i++
+		case c == '[' || c == '!' && strings.HasPrefix(text[i+1:], "["):
+			n, link := sanitizeLink(text[i:])
+			if n == 0 {
+				sanitized.WriteByte(c)
+				i++
+				continue
+			}
+
+			sanitized.WriteString(link)
+			i += n
+		default:
+			sanitized.WriteByte(c)
+			i++
+		}
+	}
+
+	return sanitized.String()
+}
+
+// markdownLink is a link or image like [text](url "title").
+type markdownLink struct {
+	image    bool
+	text     string
+	url      string
+	angled   bool
+	title    string
+	hasTitle bool
+}
+
+// sanitizeLink returns how many bytes the link or image at the start of the
+// text takes and what it is replaced by, or 0 if the text does not start
+// with a link. The link is replaced as a whole: its text is sanitized, its
+// title escaped and an unsafe url is dropped together with the link syntax,
+// which leaves the text only.
+func sanitizeLink(text string) (int, string) {
+	n, link := parseLink(text)
+	if n == 0 {
+		return 0, ""
+	}
+
+	linkText := SanitizeMarkdown(link.text)
+
+	url, ok := safeURL(link.url, link.image)
+	if !ok {
+		return n, linkText
+	}
+
+	if link.angled {
+		url = "<" + url + ">"
+	}
+
+	var sanitized strings.Builder
+	if link.image {
+		sanitized.WriteByte('!')
+	}
+	sanitized.WriteString("[" + linkText + "](" + url)
+	if link.hasTitle {
+		sanitized.WriteString(` "` + titleEscaper.Replace(link.title) + `"`)
+	}
+	sanitized.WriteByte(')')
+
+	return n, sanitized.String()
+}
+
+// parseLink parses the link or image at the start of the text and returns
+// how many bytes it takes, or 0 if the text does not start with a link. Like
+// in CommonMark, the url may be put in angle brackets or contain balanced
+// parentheses, and the title may be quoted with ", ' or parentheses.
+func parseLink(text string) (int, markdownLink) {
+	link := markdownLink{}
+	i := 0
+	if strings.HasPrefix(text, "!") {
+		link.image = true
+		i++
+	}
+
+	textEnd := closingBracket(text, i, '[', ']')
+	if textEnd < 0 || !strings.HasPrefix(text[textEnd+1:], "(") {
+		return 0, link
+	}
+	link.text = text[i+1 : textEnd]
+	i = skipSpaces(text, textEnd+2)
+
+	if strings.HasPrefix(text[i:], "<") {
+		end := strings.IndexAny(text[i+1:], "<>\n")
+		if end < 0 || text[i+1+end] != '>' {
+			return 0, link
+		}
+		link.url = text[i+1 : i+1+end]
+		link.angled = true
+		i += end + 2
+	} else {
+		start := i
+		depth := 0
+	url:
+		for ; i < len(text); i++ {
+			switch text[i] {
+			case '\\':
+				i++
+			case '(':
+				depth++
+			case ')':
+				if depth == 0 {
+					break url
+				}
+				depth--
+			case ' ', '\t', '\n':
+				break url
+			}
+		}
+		if i > len(text) || depth > 0 {
+			return 0, link
+		}
+		link.url = text[start:i]
+	}
+
+	titleStart := skipSpaces(text, i)
+	if titleStart > i && titleStart < len(text) {
+		closing := map[byte]byte{'"': '"', '\'': '\'', '(': ')'}[text[titleStart]]
+		if closing != 0 {
+			end := closingBracket(text, titleStart, text[titleStart], closing)
+			if end < 0 {
+				return 0, link
+			}
+			link.title = text[titleStart+1 : end]
+			link.hasTitle = true
+			i = end + 1
+		}
+	}
+
+	i = skipSpaces(text, i)
+	if i >= len(text) || text[i] != ')' {
+		return 0, link
+	}
+
+	return i + 1, link
+}
+
+// closingBracket returns the position of the bracket that closes the one at
+// start, skipping escaped brackets, or -1 if it is not closed.
+func closingBracket(text string, start int, open, close byte) int {
+	depth := 0
+	for i := start; i < len(text); i++ {
+		switch {
+		case text[i] == '\\':
+			i++
+		case text[i] == close && (i > start || open != close):
+			depth--
+			if depth == 0 {
+				return i
+			}
+		case text[i] == open:
+			depth++
+		}
+	}
+
+	return -1
+}
+
+func skipSpaces(text string, i int) int {
+	for i < len(text) && (text[i] == ' ' || text[i] == '\t' || text[i] == '\n') {
+		i++
+	}
+
+	return i
+}
+
+// closesFence reports whether the line closes the code block opened by the
+// fence.
+func closesFence(line, fence string) bool {
+	line = strings.TrimSpace(line)
+	return strings.HasPrefix(line, fence) && strings.Trim(line, fence[:1]) == ""
+}
+
+func isLetter(c byte) bool {
+	return c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z'
+}
+
+// fencedCodeEnd returns the end of the code block starting with the fence
+// at the start of the text, after its closing fence or at the end of the
+// text.
+func fencedCodeEnd(text, fence string) int {
+	pos := strings.IndexByte(text, '\n')
+	for pos >= 0 {
+		line := text[pos+1:]
+		end := strings.IndexByte(line, '\n')
+		if end < 0 {
+			end = len(line)
+		}
+
+		if closesFence(line[:end], fence) {
+			return pos + 1 + end
+		}
+
+		if end == len(line) {
+			break
+		}
+		pos += end + 1
+	}
+
+	return len(text)
+}
+
+var (
+	heading       = regexp.MustCompile(`^ {0,3}(#{1,6})(?:\s+(.*?))?(?:\s+#+)?\s*$`)
+	thematicBreak = regexp.MustCompile(`^ {0,3}(?:(?:\*\s*){3,}|(?:-\s*){3,}|(?:_\s*){3,})$`)
+	blockQuote    = regexp.MustCompile(`^ {0,3}> ?`)
+	listItem      = regexp.MustCompile(`^ {0,3}([-*+]|(\d{1,9})[.)])\s+`)
+	image         = regexp.MustCompile(`!\[([^\]]*)\]\(\s*([^\s()]+)(?:\s+&#34;(.*?)&#34;)?\s*\)`)
+	link          = regexp.MustCompile(`\[([^\]]+)\]\(\s*([^\s()]+)(?:\s+&#34;(.*?)&#34;)?\s*\)`)
+	placeholder   = regexp.MustCompile("\x00([0-9]+)\x00")
+	emphasis      = []struct {
+		pattern *regexp.Regexp
+		tag     string
+	}{
+		{regexp.MustCompile(`(?s)\*\*(\S(?:.*?\S)?)\*\*`), "strong"},
+		{regexp.MustCompile(`(?s)(^|[^\w])__(\S(?:.*?\S)?)__($|[^\w])`), "strong"},
+		{regexp.MustCompile(`(?s)\*(\S(?:.*?\S)?)\*`), "em"},
+		{regexp.MustCompile(`(?s)(^|[^\w])_(\S(?:.*?\S)?)_($|[^\w])`), "em"},
+		{regexp.MustCompile(`(?s)~~(\S(?:.*?\S)?)~~`), "del"},
+	}
+)
+
+// markdownRenderer renders markdown into html. Pieces of html that must not
+// be touched by later steps are replaced by placeholders until the end.
+type markdownRenderer struct {
+	pieces []string
+}
+
+// RenderMarkdown renders card content written in markdown into safe html.
+// Math between $...$, $$...$$, \(...\) or \[...\] is kept as LaTeX in spans
+// and divs of the class "math", for clients to typeset it with KaTeX or
+// MathJax. Allowed html is kept as in SanitizeMarkdown. Media files
+// referenced as media:<id> are linked to the url mediaURL returns. The html
+// is sanitized as a whole, so it is safe even for content that was stored
+// without being sanitized.
+func RenderMarkdown(text string, mediaURL MediaURL) string {
+	text = strings.ReplaceAll(text, "\r\n", "\n")
+	text = strings.ReplaceAll(text, "\x00", "")
+
+	renderer := markdownRenderer{}
+	rendered := renderer.blocks(strings.Split(text, "\n"))
+	rendered = htmlPolicy.Sanitize(balanceHTML(renderer.restore(rendered)))
+
+	return resolveMedia(rendered, mediaURL)
+}
+
+func (r *markdownRenderer) hold(piece string) string {
+	r.pieces = append(r.pieces, piece)
+	return "\x00" + strconv.Itoa(len(r.pieces)-1) + "\x00"
+}
+
+func (r *markdownRenderer) restore(text string) string {
+	for placeholder.MatchString(text) {
+		text = placeholder.ReplaceAllStringFunc(text, func(match string) string {
+			index, _ := strconv.Atoi(match[1 : len(match)-1])
+			return r.pieces[index]
+		})
+	}
+
+	return text
+}
+
+func (r *markdownRenderer) blocks(lines []string) string {
+	var rendered strings.Builder
+	paragraph := []string{}
+	flush := func() {
+		if len(paragraph) > 0 {
+			rendered.WriteString("<p>" + r.inline(strings.Join(paragraph, "\n")) + "</p>\n")
+			paragraph = []string{}
+		}
+	}
+
+	for i := 0; i < len(lines); {
+		line := lines[i]
+		trimmed := strings.TrimSpace(line)
+
+		if trimmed == "" {
+			flush()
+			i++
+			continue
+		}
+
+		if fence := codeFence.FindStringSubmatch(line); fence != nil {
+			flush()
+			code := []string{}
+			for i++; i < len(lines); i++ {
+				if closesFence(lines[i], fence[1]) {
+					i++
+					break
+				}
+				code = append(code, lines[i])
+			}
+
+			class := ""
+			if fence[2] != "" {
+				class = ` class="language-` + html.EscapeString(fence[2]) + `"`
+			}
+			rendered.WriteString("<pre><code" + class + ">" +
+				html.EscapeString(strings.Join(code, "\n")) + "</code></pre>\n")
+			continue
+		}
+
+		opensMath := strings.HasPrefix(trimmed, "$$") && !strings.HasSuffix(trimmed, "$$")
+		if trimmed == "$$" || opensMath {
+			math := []string{strings.TrimPrefix(trimmed, "$$")}
+			end := i + 1
+			for ; end < len(lines); end++ {
+				if strings.HasSuffix(strings.TrimSpace(lines[end]), "$$") {
+					break
+				}
+				math = append(math, lines[end])
+			}
+
+			if end < len(lines) {
+				flush()
+				math = append(math, strings.TrimSuffix(strings.TrimSpace(lines[end]), "$$"))
+				rendered.WriteString(displayMath(strings.TrimSpace(strings.Join(math, "\n"))))
+				i = end + 1
+				continue
+			}
+		}
+
+		if len(trimmed) > 4 && strings.HasPrefix(trimmed, "$$") &&
+			strings.HasSuffix(trimmed, "$$") && !strings.Contains(trimmed[2:len(trimmed)-2], "$$") {
+			flush()
+			rendered.WriteString(displayMath(strings.TrimSpace(trimmed[2 : len(trimmed)-2])))
+			i++
+			continue
+		}
+
+		if match := heading.FindStringSubmatch(line); match != nil {
+			flush()
+			tag := "h" + strconv.Itoa(len(match[1]))
+			rendered.WriteString("<" + tag + ">" + r.inline(match[2]) + "</" + tag + ">\n")
+			i++
+			continue
+		}
+
+		if thematicBreak.MatchString(line) {
+			flush()
+			rendered.WriteString("<hr>\n")
+			i++
+			continue
+		}
+
+		if blockQuote.MatchString(line) {
+			flush()
+			quoted := []string{}
+			for ; i < len(lines) && blockQuote.MatchString(lines[i]); i++ {
+				quoted = append(quoted, blockQuote.ReplaceAllString(lines[i], ""))
+			}
+			rendered.WriteString("<blockquote>\n" + r.blocks(quoted) + "</blockquote>\n")
+			continue
+		}
+
+		if match := listItem.FindStringSubmatch(line); match != nil {
+			flush()
+			i = r.list(&rendered, lines, i, match[2] != "")
+			continue
+		}
+
+		paragraph = append(paragraph, trimmed)
+		i++
+	}
+	flush()
+
+	return rendered.String()
+}
+
+// list renders the list starting at the given line and returns the line
+// after it. Lines that do not start a new item continue the last one.
+func (r *markdownRenderer) list(
+	rendered *strings.Builder,
+	lines []string,
+	i int,
+	ordered bool,
+) int {
+	tag := "ul"
+	start := ""
+	if ordered {
+		tag = "ol"
+		first, _ := strconv.Atoi(listItem.FindStringSubmatch(lines[i])[2])
+		if first != 1 {
+			start = ` start="` + strconv.Itoa(first) + `"`
+		}
+	}
+
+	items := [][]string{}
+	for ; i < len(lines); i++ {
+		trimmed := strings.TrimSpace(lines[i])
+		match := listItem.FindStringSubmatch(lines[i])
+
+		switch {
+		case match != nil && (match[2] != "") == ordered:
+			items = append(items, []string{strings.TrimSpace(lines[i][len(match[0]):])})
+		case match != nil:
+			// an item of the other kind starts a new list
+			return r.writeList(rendered, tag, start, items, i)
+		case trimmed == "":
+			next := i + 1
+			if next < len(lines) && listItem.MatchString(lines[next]) {
+				continue
+			}
+			return r.writeList(rendered, tag, start, items, i)
+		default:
+			items[len(items)-1] = append(items[len(items)-1], trimmed)
+		}
+	}
+
+	return r.writeList(rendered, tag, start, items, i)
+}
+
+func (r *markdownRenderer) writeList(
+	rendered *strings.Builder,
+	tag, start string,
+	items [][]string,
+	next int,
+) int {
+	rendered.WriteString("<" + tag + start + ">\n")
+	for _, item := range items {
+		rendered.WriteString("<li>" + r.inline(strings.Join(item, "\n")) + "</li>\n")
+	}
+	rendered.WriteString("</" + tag + ">\n")
+
+	return next
+}
+
+func displayMath(latex string) string {
+	return `<div class="math display">\[` + html.EscapeString(latex) + `\]</div>` + "\n"
+}
+
+func inlineMath(latex string) string {
+	return `<span class="math inline">\(` + html.EscapeString(latex) + `\)</span>`
+}
+
+// inline renders the inline markdown of a block: code, math, html, links,
+// images and emphasis. Line breaks within the block are kept.
+func (r *markdownRenderer) inline(text string) string {
+	var escaped strings.Builder
+	for i := 0; i < len(text); {
+		c := text[i]
+
+		if n := protectedLength(text[i:]); n > 0 {
+			escaped.WriteString(r.hold(r.protected(text[i : i+n])))
+			i += n
+			continue
+		}
+
+		switch {
+		case c == '\\' && i+1 < len(text) && strings.IndexByte(markdownPunctuation, text[i+1]) >= 0:
+			escaped.WriteString(r.hold(html.EscapeString(text[i+1 : i+2])))
+			i += 2
+		case c == '<':
+			if match := autolink.FindStringSubmatch(text[i:]); match != nil {
+				url := html.EscapeString(match[1])
+				escaped.WriteString(r.hold(`<a href="` + url + `">` + url + "</a>"))
+				i += len(match[0])
+				continue
+			}
+
+			if n, tag := skipHTML(text[i:]); n > 0 {
+				escaped.WriteString(r.hold(tag))
+				i += n
+				continue
+			}
+
+			escaped.WriteString("&lt;")
+			i++
+		case c == '&':
+			if entity := htmlEntity.FindString(text[i:]); entity != "" {
+				escaped.WriteString(entity)
+				i += len(entity)
+				continue
+			}
+
+			escaped.WriteString("&amp;")
+			i++
+		default:
+			escaped.WriteString(html.EscapeString(text[i : i+1]))
+			i++
+		}
+	}
+
+	rendered := image.ReplaceAllStringFunc(escaped.String(), func(match string) string {
+		parts := image.FindStringSubmatch(match)
+		url, ok := safeURL(parts[2], true)
+		if !ok || strings.Contains(url, "\x00") {
+			return parts[1]
+		}
+
+		return r.hold(`<img src="` + html.EscapeString(url) + `" alt="` +
+			r.attribute(parts[1]) + `"` + r.title(parts[3]) + ">")
+	})
+
+	rendered = link.ReplaceAllStringFunc(rendered, func(match string) string {
+		parts := link.FindStringSubmatch(match)
+		url, ok := safeURL(parts[2], false)
+		if !ok || strings.Contains(url, "\x00") {
+			return r.emphasize(parts[1])
+		}
+
+		return r.hold(`<a href="` + html.EscapeString(url) + `"` + r.title(parts[3]) + ">" +
+			r.emphasize(parts[1]) + "</a>")
+	})
+
+	return strings.ReplaceAll(r.emphasize(rendered), "\n", "<br>\n")
+}
+
+const markdownPunctuation = "!\"#$%&'()*+,-./:;<=>?@[\\]^_`{|}~"
+
+func (r *markdownRenderer) title(text string) string {
+	if text == "" {
+		return ""
+	}
+
+	return ` title="` + r.attribute(text) + `"`
+}
+
+// attribute returns the escaped value of an attribute taken from inline
+// markdown. The html held in it is restored first and escaped as text, so
+// that it can not end the attribute.
+func (r *markdownRenderer) attribute(text string) string {
+	return html.EscapeString(html.UnescapeString(r.restore(text)))
+}
+
+// protected renders code or math.
+func (r *markdownRenderer) protected(text string) string {
+	switch {
+	case strings.HasPrefix(text, `\(`):
+		return inlineMath(text[2 : len(text)-2])
+	case strings.HasPrefix(text, `\[`), strings.HasPrefix(text, "$$"):
+		latex := html.EscapeString(text[2 : len(text)-2])
+		return `<span class="math display">\[` + latex + `\]</span>`
+	case strings.HasPrefix(text, "$"):
+		return inlineMath(text[1 : len(text)-1])
+	}
+
+	run := len(text) - len(strings.TrimLeft(text, "`"))
+	code := text[run : len(text)-run]
+	padded := strings.HasPrefix(code, " ") && strings.HasSuffix(code, " ")
+	if padded && strings.TrimSpace(code) != "" {
+		code = code[1 : len(code)-1]
+	}
+
+	return "<code>" + html.EscapeString(strings.ReplaceAll(code, "\n", " ")) + "</code>"
+}
+
+func (r *markdownRenderer) emphasize(text string) string {
+	for _, rule := range emphasis {
+		if rule.pattern.NumSubexp() == 1 {
+			text = rule.pattern.ReplaceAllString(text, "<"+rule.tag+">$1</"+rule.tag+">")
+			continue
+		}
+
+		text = rule.pattern.ReplaceAllString(text, "$1<"+rule.tag+">$2</"+rule.tag+">$3")
+	}
+
+	return text
+}
+
+var anyTag = regexp.MustCompile(`<(/?)([a-zA-Z][a-zA-Z0-9]*)[^<>]*>`)
+
+// balanceHTML closes the tags left open and removes closing tags that were
+// never opened, so that rendered content can not break the page it is shown
+// in.
+func balanceHTML(text string) string {
+	var balanced strings.Builder
+	open := []string{}
+	pos := 0
+	for _, match := range anyTag.FindAllStringSubmatchIndex(text, -1) {
+		balanced.WriteString(text[pos:match[0]])
+		pos = match[1]
+
+		name := strings.ToLower(text[match[4]:match[5]])
+		if voidTags[name] {
+			balanced.WriteString(text[match[0]:match[1]])
+			continue
+		}
+
+		if match[3] == match[2] {
+			open = append(open, name)
+			balanced.WriteString(text[match[0]:match[1]])
+			continue
+		}
+
+		index := len(open) - 1
+		for index >= 0 && open[index] != name {
+			index--
+		}
+		if index < 0 {
+			continue
+		}
+
+		for len(open) > index {
+			balanced.WriteString("</" + open[len(open)-1] + ">")
+			open = open[:len(open)-1]
+		}
+	}
+	balanced.WriteString(text[pos:])
+
+	for len(open) > 0 {
+		balanced.WriteString("</" + open[len(open)-1] + ">")
+		open = open[:len(open)-1]
+	}
+
+	return strings.TrimSpace(balanced.String())
+}
@@ -0,0 +1,175 @@
+package format
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSanitizeMarkdown(t *testing.T) {
+	tests := []struct {
+		testName string
+		text     string
+		want     string
+	}{
+		{
+			"Plain Markdown",
+			"**bold** and `code` with $x < y$",
+			"**bold** and `code` with $x < y$",
+		},
+		{
+			"Script",
+			"before<script>alert(1)</script>after",
+			"beforeafter",
+		},
+		{
+			"Event Handler",
+			`<b onclick="alert(1)">bold</b><img src="x" onerror="alert(1)">`,
+			`<b>bold</b>`,
+		},
+		{
+			"Javascript Link",
+			`[click](javascript:alert(1)) <a href=" java	script:alert(1)">a</a>`,
+			`click <a>a</a>`,
+		},
+		{
+			"Unsafe Link",
+			`[x](javascript:alert(1))`,
+			`x`,
+		},
+		{
+			"Link Title",
+			`[a](http://a "<b>x</b>")`,
+			`[a](http://a "&lt;b&gt;x&lt;/b&gt;")`,
+		},
+		{
+			"Nested Link Text",
+			`[a [b] <i onclick="x">c</i>](<media:abc> 'd') ![cat](data:image/png)`,
+			`[a [b] <i>c</i>](<media:abc> "d") cat`,
+		},
+		{
+			"Unknown Tag",
+			`<details><summary>hint</summary>text</details>`,
+			`hinttext`,
+		},
+		{
+			"Code Is Kept",
+			"```html\n<script>x</script>\n```\n`<b onclick>`",
+			"```html\n<script>x</script>\n```\n`<b onclick>`",
+		},
+		{
+			"Comparison",
+			"1 < 2 and a <b",
+			"1 < 2 and a &lt;b",
+		},
+		{
+			"Media Image",
+			`<img src="media:abc" alt="cat">`,
+			`<img src="media:abc" alt="cat">`,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.testName, func(t *testing.T) {
+			assert.Equal(t, test.want, SanitizeMarkdown(test.text))
+		})
+	}
+}
+
+func TestRenderMarkdown(t *testing.T) {
+	mediaURL := func(mediaID string) string {
+		return "/decks/deck/media/" + mediaID
+	}
+
+	tests := []struct {
+		testName string
+		text     string
+		want     string
+	}{
+		{
+			"Emphasis",
+			"**hablar** is _to speak_ ~~not~~",
+			"<p><strong>hablar</strong> is <em>to speak</em> <del>not</del></p>",
+		},
+		{
+			"Line Breaks",
+			"first\nsecond",
+			"<p>first<br>\nsecond</p>",
+		},
+		{
+			"Inline Math",
+			`$a^2 + b^2 = c^2$ costs $5 and \(x < y\)`,
+			`<p><span class="math inline">\(a^2 + b^2 = c^2\)</span> costs $5 and ` +
+				`<span class="math inline">\(x &lt; y\)</span></p>`,
+		},
+		{
+			"Display Math",
+			"$$\n\\int_0^1 x*y*z\n$$",
+			`<div class="math display">\[\int_0^1 x*y*z\]</div>`,
+		},
+		{
+			"Code",
+			"`<b>` and\n```go\nfmt.Println(\"<hi>\")\n```",
+			"<p><code>&lt;b&gt;</code> and</p>\n<pre><code class=\"language-go\">" +
+				"fmt.Println(&#34;&lt;hi&gt;&#34;)</code></pre>",
+		},
+		{
+			"Heading And List",
+			"# Verbs\n1. hablar\n2. comer",
+			"<h1>Verbs</h1>\n<ol>\n<li>hablar</li>\n<li>comer</li>\n</ol>",
+		},
+		{
+			"Quote",
+			"> to *speak*",
+			"<blockquote>\n<p>to <em>speak</em></p>\n</blockquote>",
+		},
+		{
+			"Links",
+			`[docs](https://example.com "Docs") [*bad*](javascript:void)`,
+			`<p><a href="https://example.com" title="Docs">docs</a> <em>bad</em></p>`,
+		},
+		{
+			"Media Image",
+			"![cat](media:abc)",
+			`<p><img src="/decks/deck/media/abc" alt="cat"></p>`,
+		},
+		{
+			"Unsafe HTML",
+			`<b onclick="x">bold<script>alert(1)</script> <i>open`,
+			"<p><b>bold <i>open</i></b></p>",
+		},
+		{
+			"Stray Closing Tag",
+			"text</div></p>",
+			"<p>text</p>",
+		},
+		{
+			"Entities",
+			"&amp; &copy; & <3",
+			"<p>&amp; © &amp; &lt;3</p>",
+		},
+		{
+			"HTML In Link Title",
+			`[x](http://a "<a title=' onmouseover=alert(1) x'>")`,
+			`<p><a href="http://a" title="&lt;a title=&#34; onmouseover=alert(1) x&#34;&gt;">` +
+				"x</a></p>",
+		},
+		{
+			"HTML In Image Alt",
+			`![<a title=' onerror=alert(1) x'>](media:abc)`,
+			`<p><img src="/decks/deck/media/abc" ` +
+				`alt="&lt;a title=&#34; onerror=alert(1) x&#34;&gt;"></p>`,
+		},
+		{
+			"Media HTML Image",
+			`<img src="media:abc" onerror="alert(1)"> <a href="MEDIA:def">file</a>`,
+			`<p><img src="/decks/deck/media/abc"> <a href="/decks/deck/media/def">file</a></p>`,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.testName, func(t *testing.T) {
+			assert.Equal(t, test.want, RenderMarkdown(test.text, mediaURL))
+		})
+	}
+}
@@ -0,0 +1,262 @@
+package format
+
+import (
+	"bufio"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+var ErrUnknownColumn = errors.New("unknown column")
+
+// TextCard is a card read from or written to a plain text format. Line is
+// the line the card starts on.
+type TextCard struct {
+	Line     int
+	Question string
+	Answer   string
+}
+
+type ParseError struct {
+	Line    int
+	Message string
+}
+
+// ColumnMapping selects the columns of a delimited file by header name or by
+// their position starting at 1.
+type ColumnMapping struct {
+	Question string
+	Answer   string
+}
+
+func ReadDelimited(
+	r io.Reader,
+	delimiter rune,
+	header bool,
+	mapping ColumnMapping,
+) ([]TextCard, []ParseError, error) {
+	reader := csv.NewReader(r)
+	reader.Comma = delimiter
+	reader.FieldsPerRecord = -1
+	reader.LazyQuotes = delimiter == '\t'
+
+	cards := []TextCard{}
+	parseErrors := []ParseError{}
+	questionColumn, answerColumn := -1, -1
+
+	if !header {
+		var err error
+		if questionColumn, err = columnIndex(mapping.Question, "1", nil); err != nil {
+			return nil, nil, err
+		}
+		if answerColumn, err = columnIndex(mapping.Answer, "2", nil); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+
+		var csvErr *csv.ParseError
+		if errors.As(err, &csvErr) {
+			parseErrors = append(parseErrors, ParseError{
+				Line:    csvErr.Line,
+				Message: csvErr.Err.Error(),
+			})
+			continue
+		}
+		if err != nil {
+			return nil, nil, err
+		}
+
+		line, _ := reader.FieldPos(0)
+
+		if questionColumn == -1 {
+			if questionColumn, err = columnIndex(mapping.Question, "1", record); err != nil {
+				return nil, nil, err
+			}
+			if answerColumn, err = columnIndex(mapping.Answer, "2", record); err != nil {
+				return nil, nil, err
+			}
+			continue
+		}
+
+		if len(record) <= questionColumn || len(record) <= answerColumn {
+			parseErrors = append(parseErrors, ParseError{
+				Line:    line,
+				Message: fmt.Sprintf("line has only %d columns", len(record)),
+			})
+			continue
+		}
+
+		card := TextCard{
+			Line:     line,
+			Question: strings.TrimSpace(record[questionColumn]),
+			Answer:   strings.TrimSpace(record[answerColumn]),
+		}
+		if parseError, ok := validateTextCard(&card); !ok {
+			parseErrors = append(parseErrors, parseError)
+			continue
+		}
+
+		cards = append(cards, card)
+	}
+
+	return cards, parseErrors, nil
+}
+
+func columnIndex(column, defaultColumn string, header []string) (int, error) {
+	if column == "" {
+		column = defaultColumn
+	}
+
+	for i, name := range header {
+		if strings.EqualFold(strings.TrimSpace(name), column) {
+			return i, nil
+		}
+	}
+
+	index, err := strconv.Atoi(column)
+	if err != nil || index < 1 {
+		return 0, fmt.Errorf("%w: %s", ErrUnknownColumn, column)
+	}
+
+	return index - 1, nil
+}
+
+func validateTextCard(card *TextCard) (ParseError, bool) {
+	if card.Question == "" {
+		return ParseError{Line: card.Line, Message: "question is empty"}, false
+	}
+
+	if card.Answer == "" {
+		return ParseError{Line: card.Line, Message: "answer is empty"}, false
+	}
+
+	return ParseError{}, true
+}
+
+func WriteDelimited(w io.Writer, delimiter rune, cards []TextCard) error {
+	writer := csv.NewWriter(w)
+	writer.Comma = delimiter
+
+	for _, card := range cards {
+		if err := writer.Write([]string{card.Question, card.Answer}); err != nil {
+			return err
+		}
+	}
+
+	writer.Flush()
+	return writer.Error()
+}
+
+// markdownLabelPattern matches the "Front:/Back:" and "Q:/A:" lines card
+// generation produces, also when they are formatted as list items or bold.
+var markdownLabelPattern = regexp.MustCompile(
+	`(?i)^(?:[-*+]\s+|\d+[.)]\s+)?\**(front|back|question|answer|q|a)\**\s*:\s*\**\s*(.*)$`,
+)
+
+func markdownLabel(line string) (bool, string, bool) {
+	match := markdownLabelPattern.FindStringSubmatch(line)
+	if match == nil {
+		return false, "", false
+	}
+
+	switch strings.ToLower(match[1]) {
+	case "front", "question", "q":
+		return true, match[2], true
+	default:
+		return false, match[2], true
+	}
+}
+
+func ReadMarkdown(r io.Reader) ([]TextCard, []ParseError, error) {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+
+	cards := []TextCard{}
+	parseErrors := []ParseError{}
+
+	var current *TextCard
+	var field *string
+	hasAnswer := false
+
+	flush := func() {
+		if current == nil {
+			return
+		}
+
+		current.Question = strings.TrimSpace(current.Question)
+		current.Answer = strings.TrimSpace(current.Answer)
+		if parseError, ok := validateTextCard(current); ok {
+			cards = append(cards, *current)
+		} else {
+			parseErrors = append(parseErrors, parseError)
+		}
+
+		current, field, hasAnswer = nil, nil, false
+	}
+
+	line := 0
+	for scanner.Scan() {
+		line++
+		text := scanner.Text()
+		trimmed := strings.TrimSpace(text)
+
+		isQuestion, value, isLabel := markdownLabel(trimmed)
+		switch {
+		case isLabel && isQuestion:
+			flush()
+			current = &TextCard{Line: line, Question: value}
+			field = &current.Question
+		case isLabel && (current == nil || hasAnswer):
+			flush()
+			parseErrors = append(parseErrors, ParseError{
+				Line:    line,
+				Message: "answer without a question",
+			})
+		case isLabel:
+			current.Answer = value
+			field = &current.Answer
+			hasAnswer = true
+		case field != nil:
+			*field += "\n" + text
+		case trimmed != "" && !strings.HasPrefix(trimmed, "#") && trimmed != "---":
+			parseErrors = append(parseErrors, ParseError{
+				Line:    line,
+				Message: "text outside of a card",
+			})
+		}
+	}
+	flush()
+
+	if err := scanner.Err(); err != nil {
+		return nil, nil, err
+	}
+
+	return cards, parseErrors, nil
+}
+
+func WriteMarkdown(w io.Writer, cards []TextCard) error {
+	for i, card := range cards {
+		if i > 0 {
+			if _, err := io.WriteString(w, "\n"); err != nil {
+				return err
+			}
+		}
+
+		_, err := fmt.Fprintf(w, "Q: %s\nA: %s\n", card.Question, card.Answer)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,98 @@
+package format
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestReadDelimited(t *testing.T) {
+	file := "id,Front,Back\n1,hablar,to speak\n2,comer\n3,\"beber\",\"to\ndrink\"\n4,,empty\n"
+
+	cards, parseErrors, err := ReadDelimited(
+		strings.NewReader(file),
+		',',
+		true,
+		ColumnMapping{Question: "front", Answer: "3"},
+	)
+
+	assert.Nil(t, err)
+	assert.Equal(t, []TextCard{
+		{Line: 2, Question: "hablar", Answer: "to speak"},
+		{Line: 4, Question: "beber", Answer: "to\ndrink"},
+	}, cards)
+	assert.Equal(t, []ParseError{
+		{Line: 3, Message: "line has only 2 columns"},
+		{Line: 6, Message: "question is empty"},
+	}, parseErrors)
+}
+
+func TestReadDelimitedUnknownColumn(t *testing.T) {
+	_, _, err := ReadDelimited(
+		strings.NewReader("Front\tBack\n"),
+		'\t',
+		true,
+		ColumnMapping{Question: "Question"},
+	)
+
+	assert.ErrorIs(t, err, ErrUnknownColumn)
+}
+
+func TestReadMarkdown(t *testing.T) {
+	file := `# Spanish
+
+Front: hablar
+Back: to speak
+
+- **Q:** comer
+- **A:** to eat
+  or to have lunch
+
+some note
+A: answer without question
+Q: beber
+`
+
+	cards, parseErrors, err := ReadMarkdown(strings.NewReader(file))
+
+	assert.Nil(t, err)
+	assert.Equal(t, []TextCard{
+		{Line: 3, Question: "hablar", Answer: "to speak"},
+		{Line: 6, Question: "comer", Answer: "to eat\n  or to have lunch\n\nsome note"},
+	}, cards)
+	assert.Equal(t, []ParseError{
+		{Line: 11, Message: "answer without a question"},
+		{Line: 12, Message: "answer is empty"},
+	}, parseErrors)
+}
+
+func withoutLines(cards []TextCard) []TextCard {
+	for i := range cards {
+		cards[i].Line = 0
+	}
+
+	return cards
+}
+
+func TestTextRoundTrip(t *testing.T) {
+	cards := []TextCard{
+		{Question: "hablar", Answer: "to speak"},
+		{Question: "comer, beber", Answer: "to eat,\nto drink"},
+	}
+
+	var markdown bytes.Buffer
+	assert.Nil(t, WriteMarkdown(&markdown, cards))
+	read, parseErrors, err := ReadMarkdown(&markdown)
+	assert.Nil(t, err)
+	assert.Empty(t, parseErrors)
+	assert.Equal(t, cards, withoutLines(read))
+
+	var csv bytes.Buffer
+	assert.Nil(t, WriteDelimited(&csv, ',', cards))
+	read, parseErrors, err = ReadDelimited(&csv, ',', false, ColumnMapping{})
+	assert.Nil(t, err)
+	assert.Empty(t, parseErrors)
+	assert.Equal(t, cards, withoutLines(read))
+}
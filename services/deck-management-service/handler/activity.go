@@ -0,0 +1,54 @@
+package handler
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/moshrank/spacey-backend/pkg/httpconst"
+	"github.com/moshrank/spacey-backend/pkg/logger"
+	"github.com/moshrank/spacey-backend/services/deck-management-service/entity"
+)
+
+type ActivityHandler struct {
+	logger          logger.LoggerInterface
+	activityUseCase entity.ActivityUseCaseInterface
+}
+
+type ActivityHandlerInterface interface {
+	GetActivity(c *gin.Context)
+}
+
+func NewActivityHandler(
+	loggerObj logger.LoggerInterface,
+	activityUseCase entity.ActivityUseCaseInterface,
+) ActivityHandlerInterface {
+	return &ActivityHandler{
+		logger:          loggerObj,
+		activityUseCase: activityUseCase,
+	}
+}
+
+func (h *ActivityHandler) GetActivity(c *gin.Context) {
+	userID := c.Request.URL.Query().Get("userID")
+
+	if userID == "" {
+		httpconst.WriteUnauthorized(c, "missing user id")
+		return
+	}
+
+	var page entity.PageReq
+	if err := c.ShouldBindQuery(&page); err != nil {
+		httpconst.WriteBadRequest(c, err.Error())
+		return
+	}
+
+	deckID := c.Param("deckID")
+	activities, next, err := h.activityUseCase.GetActivity(userID, deckID, &page)
+	if err != nil {
+		if !writeKnownError(c, err) {
+			httpconst.WriteDatabaseError(c)
+		}
+		return
+	}
+
+	writeNextCursor(c, next)
+	httpconst.WriteSuccess(c, activities)
+}
@@ -0,0 +1,103 @@
+package handler
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/moshrank/spacey-backend/services/deck-management-service/entity"
+	log "github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type ActivityUseCaseMock struct {
+	mock.Mock
+}
+
+func (u *ActivityUseCaseMock) GetActivity(
+	userID, deckID string,
+	page *entity.PageReq,
+) ([]entity.ActivityRes, string, error) {
+	args := u.Called(userID, deckID, page)
+	return args.Get(0).([]entity.ActivityRes), args.String(1), args.Error(2)
+}
+
+func TestGetActivity(t *testing.T) {
+	tests := []struct {
+		testName       string
+		userID         string
+		deckID         string
+		query          string
+		wantStatusCode int
+		wantCursor     string
+	}{
+		{
+			"First Page",
+			"test_user_id",
+			"test_deck_id",
+			"limit=2",
+			200,
+			"next_cursor",
+		},
+		{
+			"Invalid Cursor",
+			"test_user_id",
+			"test_deck_id",
+			"cursor=invalid",
+			400,
+			"",
+		},
+		{
+			"Not A Member",
+			"test_user_id",
+			"other_deck_id",
+			"",
+			403,
+			"",
+		},
+		{
+			"Missing User ID",
+			"",
+			"test_deck_id",
+			"",
+			401,
+			"",
+		},
+	}
+
+	activityUseCaseMock := new(ActivityUseCaseMock)
+
+	var handler = NewActivityHandler(log.New(), activityUseCaseMock)
+
+	activityUseCaseMock.On("GetActivity", "test_user_id", "test_deck_id", &entity.PageReq{Limit: 2}).
+		Return([]entity.ActivityRes{}, "next_cursor", nil)
+	activityUseCaseMock.On(
+		"GetActivity",
+		"test_user_id",
+		"test_deck_id",
+		&entity.PageReq{Cursor: "invalid"},
+	).Return([]entity.ActivityRes{}, "", entity.ErrInvalidCursor)
+	activityUseCaseMock.On("GetActivity", "test_user_id", "other_deck_id", &entity.PageReq{}).
+		Return([]entity.ActivityRes{}, "", entity.ErrForbidden)
+
+	for _, test := range tests {
+
+		t.Run(test.testName, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(w)
+			c.Request, _ = http.NewRequest("GET", "/decks/:deckID/activity?"+test.query, nil)
+			c.Params = []gin.Param{{Key: "deckID", Value: test.deckID}}
+
+			q := c.Request.URL.Query()
+			q.Add("userID", test.userID)
+			c.Request.URL.RawQuery = q.Encode()
+
+			handler.GetActivity(c)
+
+			assert.Equal(t, test.wantStatusCode, c.Writer.Status())
+			assert.Equal(t, test.wantCursor, w.Header().Get(NextCursorHeader))
+		})
+	}
+}
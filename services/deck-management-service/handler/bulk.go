@@ -0,0 +1,84 @@
+package handler
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/moshrank/spacey-backend/pkg/httpconst"
+	"github.com/moshrank/spacey-backend/pkg/logger"
+	"github.com/moshrank/spacey-backend/pkg/validator"
+	"github.com/moshrank/spacey-backend/services/deck-management-service/entity"
+)
+
+type BulkHandler struct {
+	logger      logger.LoggerInterface
+	bulkUseCase entity.BulkUseCaseInterface
+	validator   validator.ValidatorInterface
+}
+
+type BulkHandlerInterface interface {
+	BulkCards(c *gin.Context)
+	FindReplace(c *gin.Context)
+}
+
+func NewBulkHandler(
+	loggerObj logger.LoggerInterface,
+	bulkUseCase entity.BulkUseCaseInterface,
+	validatorObj validator.ValidatorInterface,
+) BulkHandlerInterface {
+	return &BulkHandler{
+		logger:      loggerObj,
+		bulkUseCase: bulkUseCase,
+		validator:   validatorObj,
+	}
+}
+
+func (h *BulkHandler) BulkCards(c *gin.Context) {
+	userID := c.Request.URL.Query().Get("userID")
+
+	if userID == "" {
+		httpconst.WriteUnauthorized(c, "missing user id")
+		return
+	}
+
+	var req entity.BulkCardReq
+	if err := h.validator.ValidateJSON(c, &req); err != nil {
+		return
+	}
+
+	deckID := c.Param("deckID")
+
+	res, err := h.bulkUseCase.BulkCards(userID, deckID, &req)
+	if err != nil {
+		if !writeKnownError(c, err) {
+			httpconst.WriteDatabaseError(c)
+		}
+		return
+	}
+
+	httpconst.WriteSuccess(c, res)
+}
+
+func (h *BulkHandler) FindReplace(c *gin.Context) {
+	userID := c.Request.URL.Query().Get("userID")
+
+	if userID == "" {
+		httpconst.WriteUnauthorized(c, "missing user id")
+		return
+	}
+
+	var req entity.FindReplaceReq
+	if err := h.validator.ValidateJSON(c, &req); err != nil {
+		return
+	}
+
+	deckID := c.Param("deckID")
+
+	res, err := h.bulkUseCase.FindReplace(userID, deckID, &req)
+	if err != nil {
+		if !writeKnownError(c, err) {
+			httpconst.WriteDatabaseError(c)
+		}
+		return
+	}
+
+	httpconst.WriteSuccess(c, res)
+}
@@ -0,0 +1,177 @@
+package handler
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/moshrank/spacey-backend/pkg/validator"
+	"github.com/moshrank/spacey-backend/services/deck-management-service/entity"
+	log "github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type BulkUseCaseMock struct {
+	mock.Mock
+}
+
+func (u *BulkUseCaseMock) BulkCards(
+	userID, deckID string,
+	req *entity.BulkCardReq,
+) (*entity.BulkCardRes, error) {
+	args := u.Called(userID, deckID, req)
+	return args.Get(0).(*entity.BulkCardRes), args.Error(1)
+}
+
+func (u *BulkUseCaseMock) FindReplace(
+	userID, deckID string,
+	req *entity.FindReplaceReq,
+) (*entity.FindReplaceRes, error) {
+	args := u.Called(userID, deckID, req)
+	return args.Get(0).(*entity.FindReplaceRes), args.Error(1)
+}
+
+func TestBulkCards(t *testing.T) {
+	tests := []struct {
+		testName       string
+		body           string
+		userID         string
+		wantStatusCode int
+	}{
+		{
+			"Move",
+			`{"action": "move", "cardIDs": ["1", "2"], "targetDeckID": "target_deck_id"}`,
+			"test_user_id",
+			200,
+		},
+		{
+			"Delete Without Target",
+			`{"action": "delete", "cardIDs": ["1"]}`,
+			"test_user_id",
+			200,
+		},
+		{
+			"Copy Without Target",
+			`{"action": "copy", "cardIDs": ["1"]}`,
+			"test_user_id",
+			400,
+		},
+		{
+			"Unknown Action",
+			`{"action": "merge", "cardIDs": ["1"], "targetDeckID": "target_deck_id"}`,
+			"test_user_id",
+			400,
+		},
+		{
+			"No Cards",
+			`{"action": "delete", "cardIDs": []}`,
+			"test_user_id",
+			400,
+		},
+		{
+			"Same Deck",
+			`{"action": "move", "cardIDs": ["1"], "targetDeckID": "test_deck_id"}`,
+			"test_user_id",
+			400,
+		},
+		{
+			"Missing User ID",
+			`{"action": "delete", "cardIDs": ["1"]}`,
+			"",
+			401,
+		},
+	}
+
+	bulkUseCaseMock := new(BulkUseCaseMock)
+
+	var handler = NewBulkHandler(log.New(), bulkUseCaseMock, validator.NewValidator())
+
+	bulkUseCaseMock.On(
+		"BulkCards",
+		"test_user_id",
+		"test_deck_id",
+		mock.MatchedBy(func(req *entity.BulkCardReq) bool {
+			return req.TargetDeckID == "test_deck_id"
+		}),
+	).Return(&entity.BulkCardRes{}, entity.ErrSameDeck)
+	bulkUseCaseMock.On("BulkCards", "test_user_id", "test_deck_id", mock.Anything).
+		Return(&entity.BulkCardRes{Action: entity.BulkMove, Cards: 2}, nil)
+
+	for _, test := range tests {
+
+		t.Run(test.testName, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(w)
+			c.Request, _ = http.NewRequest(
+				"POST",
+				"/decks/:deckID/bulk",
+				bytes.NewBuffer([]byte(test.body)),
+			)
+			c.Params = []gin.Param{{Key: "deckID", Value: "test_deck_id"}}
+
+			q := c.Request.URL.Query()
+			q.Add("userID", test.userID)
+			c.Request.URL.RawQuery = q.Encode()
+
+			handler.BulkCards(c)
+
+			assert.Equal(t, test.wantStatusCode, c.Writer.Status())
+		})
+	}
+}
+
+func TestFindReplace(t *testing.T) {
+	tests := []struct {
+		testName       string
+		body           string
+		wantStatusCode int
+	}{
+		{
+			"Valid Request",
+			`{"find": "colour", "replace": "color", "fields": ["question"]}`,
+			200,
+		},
+		{
+			"Missing Find",
+			`{"replace": "color"}`,
+			400,
+		},
+		{
+			"Unknown Field",
+			`{"find": "colour", "replace": "color", "fields": ["deckID"]}`,
+			400,
+		},
+	}
+
+	bulkUseCaseMock := new(BulkUseCaseMock)
+
+	var handler = NewBulkHandler(log.New(), bulkUseCaseMock, validator.NewValidator())
+
+	bulkUseCaseMock.On("FindReplace", "test_user_id", "test_deck_id", mock.Anything).
+		Return(&entity.FindReplaceRes{Cards: 1, Replacements: 1}, nil)
+
+	for _, test := range tests {
+
+		t.Run(test.testName, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(w)
+			c.Request, _ = http.NewRequest(
+				"POST",
+				"/decks/:deckID/replace",
+				bytes.NewBuffer([]byte(test.body)),
+			)
+			c.Params = []gin.Param{{Key: "deckID", Value: "test_deck_id"}}
+
+			q := c.Request.URL.Query()
+			q.Add("userID", "test_user_id")
+			c.Request.URL.RawQuery = q.Encode()
+
+			handler.FindReplace(c)
+
+			assert.Equal(t, test.wantStatusCode, c.Writer.Status())
+		})
+	}
+}
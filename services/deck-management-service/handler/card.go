@@ -19,6 +19,7 @@ type CardHandlerInterface interface {
 	CreateCards(c *gin.Context)
 	UpdateCard(c *gin.Context)
 	DeleteCard(c *gin.Context)
+	GetCards(c *gin.Context)
 }
 
 func NewCardHandler(
@@ -43,17 +44,31 @@ func (h *CardHandler) CreateCard(c *gin.Context) {
 
 	deckID := c.Param("deckID")
 
+	var duplicates entity.DuplicateReq
+	if err := c.ShouldBindQuery(&duplicates); err != nil {
+		httpconst.WriteBadRequest(c, err.Error())
+		return
+	}
+
 	var card entity.CardReq
 	if err := h.validator.ValidateJSON(c, &card); err != nil {
 		return
 	}
 
-	cardRes, err := h.cardUseCase.CreateCard(deckID, userID, &card)
+	cardRes, err := h.cardUseCase.CreateCard(deckID, userID, &card, &duplicates)
 	if err != nil {
-		httpconst.WriteBadRequest(c, err.Error())
+		if !writeKnownError(c, err) {
+			httpconst.WriteBadRequest(c, err.Error())
+		}
 		return
 	}
 
+	if cardRes.Skipped {
+		httpconst.WriteSuccess(c, cardRes)
+		return
+	}
+
+	writeETag(c, cardRes.Version)
 	httpconst.WriteCreated(c, cardRes)
 }
 
@@ -73,12 +88,21 @@ func (h *CardHandler) UpdateCard(c *gin.Context) {
 	deckID := c.Param("deckID")
 	cardID := c.Param("id")
 
-	cardRes, err := h.cardUseCase.UpdateCard(cardID, userID, deckID, &card)
+	versions, err := ifMatch(c)
 	if err != nil {
 		httpconst.WriteBadRequest(c, err.Error())
 		return
 	}
 
+	cardRes, err := h.cardUseCase.UpdateCard(cardID, userID, deckID, &card, versions)
+	if err != nil {
+		if !writeKnownError(c, err) {
+			httpconst.WriteBadRequest(c, err.Error())
+		}
+		return
+	}
+
+	writeETag(c, cardRes.Version)
 	httpconst.WriteSuccess(c, cardRes)
 }
 
@@ -93,11 +117,19 @@ func (h *CardHandler) DeleteCard(c *gin.Context) {
 	deckID := c.Param("deckID")
 	cardID := c.Param("id")
 
-	if err := h.cardUseCase.DeleteCard(userID, deckID, cardID); err != nil {
+	versions, err := ifMatch(c)
+	if err != nil {
 		httpconst.WriteBadRequest(c, err.Error())
 		return
 	}
 
+	if err := h.cardUseCase.DeleteCard(userID, deckID, cardID, versions); err != nil {
+		if !writeKnownError(c, err) {
+			httpconst.WriteBadRequest(c, err.Error())
+		}
+		return
+	}
+
 	httpconst.WriteSuccess(c, "Deleted Card")
 }
 
@@ -111,16 +143,51 @@ func (h *CardHandler) CreateCards(c *gin.Context) {
 
 	deckID := c.Param("deckID")
 
+	var duplicates entity.DuplicateReq
+	if err := c.ShouldBindQuery(&duplicates); err != nil {
+		httpconst.WriteBadRequest(c, err.Error())
+		return
+	}
+
 	var cards []entity.CardReq
 	if err := h.validator.ValidateJSON(c, &cards); err != nil {
 		return
 	}
 
-	cardsRes, err := h.cardUseCase.CreateCards(deckID, userID, cards)
+	cardsRes, err := h.cardUseCase.CreateCards(deckID, userID, cards, &duplicates)
 	if err != nil {
-		httpconst.WriteBadRequest(c, err.Error())
+		if !writeKnownError(c, err) {
+			httpconst.WriteBadRequest(c, err.Error())
+		}
 		return
 	}
 
 	httpconst.WriteCreated(c, cardsRes)
 }
+
+func (h *CardHandler) GetCards(c *gin.Context) {
+	userID := c.Request.URL.Query().Get("userID")
+
+	if userID == "" {
+		httpconst.WriteUnauthorized(c, "missing user id")
+		return
+	}
+
+	var page entity.PageReq
+	if err := c.ShouldBindQuery(&page); err != nil {
+		httpconst.WriteBadRequest(c, err.Error())
+		return
+	}
+
+	deckID := c.Param("deckID")
+	cards, next, err := h.cardUseCase.GetCards(userID, deckID, &page)
+	if err != nil {
+		if !writeKnownError(c, err) {
+			httpconst.WriteDatabaseError(c)
+		}
+		return
+	}
+
+	writeNextCursor(c, next)
+	httpconst.WriteSuccess(c, cards)
+}
@@ -22,32 +22,51 @@ type CardUseCaseMock struct {
 func (m *CardUseCaseMock) CreateCard(
 	deckID, userID string,
 	Card *entity.CardReq,
+	duplicates *entity.DuplicateReq,
 ) (*entity.CardRes, error) {
-	args := m.Called(deckID, userID, Card)
+	args := m.Called(deckID, userID, Card, duplicates)
 	return args.Get(0).(*entity.CardRes), args.Error(1)
 }
 
 func (m *CardUseCaseMock) UpdateCard(
 	cardID, userID, deckID string,
 	card *entity.CardReq,
+	ifMatch entity.IfMatch,
 ) (*entity.CardRes, error) {
-	args := m.Called(cardID, userID, deckID, card)
+	args := m.Called(cardID, userID, deckID, card, ifMatch)
 	return args.Get(0).(*entity.CardRes), args.Error(1)
 }
 
-func (m *CardUseCaseMock) DeleteCard(userID, deckID, cardID string) error {
-	args := m.Called(userID, deckID, cardID)
+func (m *CardUseCaseMock) DeleteCard(
+	userID, deckID, cardID string,
+	ifMatch entity.IfMatch,
+) error {
+	args := m.Called(userID, deckID, cardID, ifMatch)
 	return args.Error(0)
 }
 
 func (m *CardUseCaseMock) CreateCards(
 	deckID, userID string,
 	card []entity.CardReq,
+	duplicates *entity.DuplicateReq,
 ) ([]entity.CardRes, error) {
-	args := m.Called(deckID, userID, card)
+	args := m.Called(deckID, userID, card, duplicates)
 	return args.Get(0).([]entity.CardRes), args.Error(1)
 }
 
+func (m *CardUseCaseMock) GetCards(
+	userID, deckID string,
+	page *entity.PageReq,
+) ([]entity.CardRes, string, error) {
+	args := m.Called(userID, deckID, page)
+	return args.Get(0).([]entity.CardRes), args.String(1), args.Error(2)
+}
+
+func (m *CardUseCaseMock) SanitizeStoredCards() (int, error) {
+	args := m.Called()
+	return args.Int(0), args.Error(1)
+}
+
 func TestCreateCard(t *testing.T) {
 	tests := []struct {
 		testName       string
@@ -85,6 +104,75 @@ func TestCreateCard(t *testing.T) {
 			"test_user_id",
 			400,
 		},
+		{
+			"Valid Tags",
+			`{"question": "Q", "answer": "A", "deckID": "test_deck_id", "tags": ["verbs"]}`,
+			"test_user_id",
+			201,
+		},
+		{
+			"Empty Tag",
+			`{"question": "Q", "answer": "A", "deckID": "test_deck_id", "tags": [""]}`,
+			"test_user_id",
+			400,
+		},
+		{
+			"Cloze Without Answer",
+			`{"question": "{{c1::Paris}}", "deckID": "test_deck_id", "type": "cloze"}`,
+			"test_user_id",
+			201,
+		},
+		{
+			"Custom Card",
+			`{"deckID": "test_deck_id", "type": "custom", "noteTypeID": "vocabulary",
+				"fields": {"Word": "hablar", "Meaning": "to speak"}}`,
+			"test_user_id",
+			201,
+		},
+		{
+			"Custom Card Without Note Type",
+			`{"deckID": "test_deck_id", "type": "custom", "fields": {"Word": "hablar"}}`,
+			"test_user_id",
+			400,
+		},
+		{
+			"Generated Card With Source",
+			`{"question": "hablar", "answer": "to speak", "deckID": "test_deck_id",
+				"source": "generated",
+				"sourceRef": {"noteID": "note_id", "startIndex": 10, "endIndex": 42}}`,
+			"test_user_id",
+			201,
+		},
+		{
+			"Generated Card With Empty Source Span",
+			`{"question": "hablar", "answer": "to speak", "deckID": "test_deck_id",
+				"source": "generated",
+				"sourceRef": {"noteID": "note_id", "startIndex": 10, "endIndex": 10}}`,
+			"test_user_id",
+			400,
+		},
+		{
+			"Image Occlusion Without Answer",
+			`{"question": "media:61f0c1e5d3b7a0c3f8a1b2c4", "deckID": "test_deck_id",
+				"type": "image_occlusion",
+				"masks": [{"x": 0.1, "y": 0.2, "width": 0.3, "height": 0.1}]}`,
+			"test_user_id",
+			201,
+		},
+		{
+			"Mask Outside Of Image",
+			`{"question": "media:61f0c1e5d3b7a0c3f8a1b2c4", "deckID": "test_deck_id",
+				"type": "image_occlusion",
+				"masks": [{"x": 1.5, "y": 0.2, "width": 0.3, "height": 0.1}]}`,
+			"test_user_id",
+			400,
+		},
+		{
+			"Unknown Type",
+			`{"question": "Q", "answer": "A", "deckID": "test_deck_id", "type": "essay"}`,
+			"test_user_id",
+			400,
+		},
 		{
 			"Missing User ID",
 			"{\"question\": \"Test Question\", \"answer\": \"Test Answer\", \"deckID\": \"test_deck_id\"}",
@@ -97,7 +185,7 @@ func TestCreateCard(t *testing.T) {
 
 	var handler = NewCardHandler(log.New(), cardUseCaseMock, validator.NewValidator())
 
-	cardUseCaseMock.On("CreateCard", mock.Anything, mock.Anything, mock.Anything).
+	cardUseCaseMock.On("CreateCard", mock.Anything, mock.Anything, mock.Anything, mock.Anything).
 		Return(&entity.CardRes{}, nil)
 
 	for _, test := range tests {
@@ -129,6 +217,53 @@ func TestCreateCard(t *testing.T) {
 	}
 }
 
+func TestCreateDuplicateCard(t *testing.T) {
+	tests := []struct {
+		testName       string
+		duplicates     string
+		wantStatusCode int
+	}{
+		{"Skipped Duplicate", "account", 200},
+		{"Unknown Scope", "folder", 400},
+	}
+
+	cardUseCaseMock := new(CardUseCaseMock)
+
+	var handler = NewCardHandler(log.New(), cardUseCaseMock, validator.NewValidator())
+
+	cardUseCaseMock.On(
+		"CreateCard",
+		"test_deck_id",
+		"test_user_id",
+		mock.Anything,
+		&entity.DuplicateReq{Duplicates: entity.DuplicateScopeAccount, SkipDuplicates: true},
+	).Return(&entity.CardRes{Skipped: true, Duplicate: &entity.DuplicateRes{Exact: true}}, nil)
+
+	for _, test := range tests {
+		t.Run(test.testName, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(w)
+			c.Request, _ = http.NewRequest(
+				"POST",
+				"decks/:deckID/cards",
+				bytes.NewBuffer([]byte(`{"question": "Q", "answer": "A", "deckID": "test_deck_id"}`)),
+			)
+
+			q := c.Request.URL.Query()
+			q.Add("userID", "test_user_id")
+			q.Add("duplicates", test.duplicates)
+			q.Add("skipDuplicates", "true")
+			c.Request.URL.RawQuery = q.Encode()
+
+			c.Params = []gin.Param{{Key: "deckID", Value: "test_deck_id"}}
+
+			handler.CreateCard(c)
+
+			assert.Equal(t, test.wantStatusCode, c.Writer.Status())
+		})
+	}
+}
+
 func TestCreateCards(t *testing.T) {
 	body := `[{"question": "Test Question", "answer": "Test Answer", "deckID": "test_deck_id"}]`
 	expBody := `{"message": "Created", "data": [{"id": "test_card_id", "question": "Test Question", "answer": "Test Answer", "deckID": "test_deck_id"}]}`
@@ -138,7 +273,13 @@ func TestCreateCards(t *testing.T) {
 
 	var handler = NewCardHandler(log.New(), cardUseCaseMock, validator.NewValidator())
 
-	cardUseCaseMock.On("CreateCards", mock.Anything, mock.Anything, mock.Anything).
+	cardUseCaseMock.On(
+		"CreateCards",
+		mock.Anything,
+		mock.Anything,
+		mock.Anything,
+		&entity.DuplicateReq{Duplicates: entity.DuplicateScopeDeck},
+	).
 		Return([]entity.CardRes{
 			{
 				ID:       "test_card_id",
@@ -222,8 +363,14 @@ func TestUpdateCard(t *testing.T) {
 
 	var handler = NewCardHandler(log.New(), cardUseCaseMock, validator.NewValidator())
 
-	cardUseCaseMock.On("UpdateCard", mock.Anything, mock.Anything, mock.Anything, mock.Anything).
-		Return(&entity.CardRes{}, nil)
+	cardUseCaseMock.On(
+		"UpdateCard",
+		mock.Anything,
+		mock.Anything,
+		mock.Anything,
+		mock.Anything,
+		mock.Anything,
+	).Return(&entity.CardRes{}, nil)
 
 	for _, test := range tests {
 
@@ -258,20 +405,97 @@ func TestUpdateCard(t *testing.T) {
 	}
 }
 
+func TestUpdateCardVersion(t *testing.T) {
+	cardUseCaseMock := new(CardUseCaseMock)
+
+	var handler = NewCardHandler(log.New(), cardUseCaseMock, validator.NewValidator())
+
+	cardUseCaseMock.On(
+		"UpdateCard",
+		"test_card_id",
+		"test_user_id",
+		"test_deck_id",
+		mock.Anything,
+		entity.IfMatch{3},
+	).Return(&entity.CardRes{ID: "test_card_id", Version: 4}, nil)
+
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request, _ = http.NewRequest(
+		"PUT",
+		"/decks/:deckID/cards/:id",
+		bytes.NewBuffer([]byte(`{"question": "Q", "answer": "A", "deckID": "test_deck_id"}`)),
+	)
+	c.Request.Header.Set("If-Match", `"3"`)
+
+	q := c.Request.URL.Query()
+	q.Add("userID", "test_user_id")
+	c.Request.URL.RawQuery = q.Encode()
+
+	c.Params = []gin.Param{
+		{Key: "deckID", Value: "test_deck_id"},
+		{Key: "id", Value: "test_card_id"},
+	}
+
+	handler.UpdateCard(c)
+
+	assert.Equal(t, 200, c.Writer.Status())
+	assert.Equal(t, `"4"`, w.Header().Get("ETag"))
+}
+
 func TestDeleteCard(t *testing.T) {
 	tests := []struct {
 		testName       string
 		userID         string
+		ifMatch        string
 		wantStatusCode int
 	}{
 		{
 			"Valid User ID",
 			"test_user_id",
+			"",
+			200,
+		},
+		{
+			"Matching Version",
+			"test_user_id",
+			`"3"`,
 			200,
 		},
+		{
+			"Changed Card",
+			"test_user_id",
+			`"2"`,
+			412,
+		},
+		{
+			"Any Version",
+			"test_user_id",
+			"*",
+			200,
+		},
+		{
+			"Weak Tag",
+			"test_user_id",
+			`W/"3"`,
+			412,
+		},
+		{
+			"Several Versions",
+			"test_user_id",
+			`"1", "3"`,
+			200,
+		},
+		{
+			"Malformed If-Match",
+			"test_user_id",
+			"3",
+			400,
+		},
 		{
 			"Missing User ID",
 			"",
+			"",
 			401,
 		},
 	}
@@ -280,7 +504,12 @@ func TestDeleteCard(t *testing.T) {
 
 	var handler = NewCardHandler(log.New(), cardStoreMock, validator.NewValidator())
 
-	cardStoreMock.On("DeleteCard", mock.Anything, mock.Anything, mock.Anything).Return(nil)
+	cardStoreMock.On("DeleteCard", mock.Anything, mock.Anything, mock.Anything, entity.IfMatch{2}).
+		Return(entity.ErrVersionConflict)
+	cardStoreMock.On("DeleteCard", mock.Anything, mock.Anything, mock.Anything, entity.IfMatch{}).
+		Return(entity.ErrVersionConflict)
+	cardStoreMock.On("DeleteCard", mock.Anything, mock.Anything, mock.Anything, mock.Anything).
+		Return(nil)
 
 	for _, test := range tests {
 
@@ -292,6 +521,9 @@ func TestDeleteCard(t *testing.T) {
 				"decks/:deckID/cards/:id",
 				nil,
 			)
+			if test.ifMatch != "" {
+				c.Request.Header.Set("If-Match", test.ifMatch)
+			}
 
 			q := c.Request.URL.Query()
 			q.Add("userID", test.userID)
@@ -315,3 +547,112 @@ func TestDeleteCard(t *testing.T) {
 		})
 	}
 }
+
+func TestGetCards(t *testing.T) {
+	tests := []struct {
+		testName       string
+		userID         string
+		query          string
+		wantStatusCode int
+		wantCursor     string
+	}{
+		{
+			"First Page",
+			"test_user_id",
+			"limit=2",
+			200,
+			"next_cursor",
+		},
+		{
+			"Last Page",
+			"test_user_id",
+			"limit=2&cursor=next_cursor",
+			200,
+			"",
+		},
+		{
+			"Limit Too High",
+			"test_user_id",
+			"limit=1000",
+			400,
+			"",
+		},
+		{
+			"Invalid Cursor",
+			"test_user_id",
+			"cursor=invalid",
+			400,
+			"",
+		},
+		{
+			"Missing User ID",
+			"",
+			"",
+			401,
+			"",
+		},
+	}
+
+	cardUseCaseMock := new(CardUseCaseMock)
+
+	var handler = NewCardHandler(log.New(), cardUseCaseMock, validator.NewValidator())
+
+	cardUseCaseMock.On("GetCards", "test_user_id", "test", &entity.PageReq{Limit: 2}).
+		Return([]entity.CardRes{}, "next_cursor", nil)
+	cardUseCaseMock.On(
+		"GetCards",
+		"test_user_id",
+		"test",
+		&entity.PageReq{Limit: 2, Cursor: "next_cursor"},
+	).Return([]entity.CardRes{}, "", nil)
+	cardUseCaseMock.On("GetCards", "test_user_id", "test", &entity.PageReq{Cursor: "invalid"}).
+		Return([]entity.CardRes{}, "", entity.ErrInvalidCursor)
+
+	for _, test := range tests {
+
+		t.Run(test.testName, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(w)
+			c.Request, _ = http.NewRequest(
+				"GET",
+				"decks/:deckID/cards?"+test.query,
+				nil,
+			)
+
+			q := c.Request.URL.Query()
+			q.Add("userID", test.userID)
+			c.Request.URL.RawQuery = q.Encode()
+
+			c.Params = []gin.Param{
+				{
+					Key:   "deckID",
+					Value: "test",
+				},
+			}
+
+			handler.GetCards(c)
+
+			assert.Equal(t, test.wantStatusCode, c.Writer.Status())
+			assert.Equal(t, test.wantCursor, w.Header().Get(NextCursorHeader))
+		})
+	}
+}
+
+func TestGetCardsVersions(t *testing.T) {
+	cardUseCaseMock := new(CardUseCaseMock)
+	cardUseCaseMock.On("GetCards", "test_user_id", "test", &entity.PageReq{}).
+		Return([]entity.CardRes{{ID: "card_1", Version: 2}}, "", nil)
+
+	var handler = NewCardHandler(log.New(), cardUseCaseMock, validator.NewValidator())
+
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request, _ = http.NewRequest("GET", "decks/:deckID/cards?userID=test_user_id", nil)
+	c.Params = []gin.Param{{Key: "deckID", Value: "test"}}
+
+	handler.GetCards(c)
+
+	// every card carries the version a change of it is sent with in If-Match
+	assert.Equal(t, 200, c.Writer.Status())
+	assert.Contains(t, w.Body.String(), `"version":2`)
+}
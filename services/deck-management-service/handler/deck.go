@@ -20,6 +20,10 @@ type DeckHandlerInterface interface {
 	CreateDeck(c *gin.Context)
 	UpdateDeck(c *gin.Context)
 	DeleteDeck(c *gin.Context)
+	ForkDeck(c *gin.Context)
+	PullDeck(c *gin.Context)
+	GetDeckTree(c *gin.Context)
+	MoveDeck(c *gin.Context)
 }
 
 func NewDeckHandler(
@@ -49,10 +53,13 @@ func (h *DeckHandler) CreateDeck(c *gin.Context) {
 
 	deckRes, err := h.deckUseCase.CreateDeck(userID, &deck)
 	if err != nil {
-		httpconst.WriteDatabaseError(c)
+		if !writeKnownError(c, err) {
+			httpconst.WriteDatabaseError(c)
+		}
 		return
 	}
 
+	writeETag(c, deckRes.Version)
 	httpconst.WriteCreated(c, deckRes)
 
 }
@@ -68,10 +75,13 @@ func (h *DeckHandler) GetDeck(c *gin.Context) {
 	deckID := c.Param("deckID")
 	deck, err := h.deckUseCase.GetDeck(userID, deckID)
 	if err != nil {
-		httpconst.WriteDatabaseError(c)
+		if !writeKnownError(c, err) {
+			httpconst.WriteDatabaseError(c)
+		}
 		return
 	}
 
+	writeETag(c, deck.Version)
 	httpconst.WriteSuccess(c, deck)
 }
 
@@ -83,13 +93,33 @@ func (h *DeckHandler) GetDecks(c *gin.Context) {
 		return
 	}
 
-	decks, err := h.deckUseCase.GetDecks(userID)
+	var req entity.DeckListReq
+	if err := c.ShouldBindQuery(&req); err != nil {
+		httpconst.WriteBadRequest(c, err.Error())
+		return
+	}
+
+	decks, next, err := h.deckUseCase.GetDecks(userID, &req)
 	if err != nil {
 		httpconst.WriteBadRequest(c, err.Error())
 		return
 	}
 
-	httpconst.WriteSuccess(c, decks)
+	writeNextCursor(c, next)
+
+	fields := req.FieldList()
+	if fields == nil {
+		httpconst.WriteSuccess(c, decks)
+		return
+	}
+
+	selected, err := selectFields(decks, fields)
+	if err != nil {
+		httpconst.WriteDatabaseError(c)
+		return
+	}
+
+	httpconst.WriteSuccess(c, selected)
 }
 
 func (h *DeckHandler) UpdateDeck(c *gin.Context) {
@@ -111,12 +141,21 @@ func (h *DeckHandler) UpdateDeck(c *gin.Context) {
 		return
 	}
 
-	deckRes, err := h.deckUseCase.UpdateDeck(userID, deckID, &deck)
+	versions, err := ifMatch(c)
 	if err != nil {
-		httpconst.WriteDatabaseError(c)
+		httpconst.WriteBadRequest(c, err.Error())
 		return
 	}
 
+	deckRes, err := h.deckUseCase.UpdateDeck(userID, deckID, &deck, versions)
+	if err != nil {
+		if !writeKnownError(c, err) {
+			httpconst.WriteDatabaseError(c)
+		}
+		return
+	}
+
+	writeETag(c, deckRes.Version)
 	httpconst.WriteSuccess(c, deckRes)
 }
 
@@ -129,10 +168,101 @@ func (h *DeckHandler) DeleteDeck(c *gin.Context) {
 	}
 
 	deckID := c.Param("deckID")
-	if err := h.deckUseCase.DeleteDeck(userID, deckID); err != nil {
-		httpconst.WriteDatabaseError(c)
+	versions, err := ifMatch(c)
+	if err != nil {
+		httpconst.WriteBadRequest(c, err.Error())
+		return
+	}
+
+	if err := h.deckUseCase.DeleteDeck(userID, deckID, versions); err != nil {
+		if !writeKnownError(c, err) {
+			httpconst.WriteDatabaseError(c)
+		}
 		return
 	}
 
 	httpconst.WriteSuccess(c, map[string]string{"Message": "Deck deleted"})
 }
+
+func (h *DeckHandler) ForkDeck(c *gin.Context) {
+	userID := c.Request.URL.Query().Get("userID")
+
+	if userID == "" {
+		httpconst.WriteUnauthorized(c, "missing user id")
+		return
+	}
+
+	deckID := c.Param("deckID")
+	deckRes, err := h.deckUseCase.ForkDeck(userID, deckID)
+	if err != nil {
+		if !writeKnownError(c, err) {
+			httpconst.WriteDatabaseError(c)
+		}
+		return
+	}
+
+	httpconst.WriteCreated(c, deckRes)
+}
+
+func (h *DeckHandler) PullDeck(c *gin.Context) {
+	userID := c.Request.URL.Query().Get("userID")
+
+	if userID == "" {
+		httpconst.WriteUnauthorized(c, "missing user id")
+		return
+	}
+
+	deckID := c.Param("deckID")
+	deckRes, err := h.deckUseCase.PullDeck(userID, deckID)
+	if err != nil {
+		if !writeKnownError(c, err) {
+			httpconst.WriteDatabaseError(c)
+		}
+		return
+	}
+
+	httpconst.WriteSuccess(c, deckRes)
+}
+
+func (h *DeckHandler) GetDeckTree(c *gin.Context) {
+	userID := c.Request.URL.Query().Get("userID")
+
+	if userID == "" {
+		httpconst.WriteUnauthorized(c, "missing user id")
+		return
+	}
+
+	tree, err := h.deckUseCase.GetDeckTree(userID)
+	if err != nil {
+		httpconst.WriteDatabaseError(c)
+		return
+	}
+
+	httpconst.WriteSuccess(c, tree)
+}
+
+func (h *DeckHandler) MoveDeck(c *gin.Context) {
+	userID := c.Request.URL.Query().Get("userID")
+
+	if userID == "" {
+		httpconst.WriteUnauthorized(c, "missing user id")
+		return
+	}
+
+	var move entity.DeckMoveReq
+	if err := h.validator.ValidateJSON(c, &move); err != nil {
+		return
+	}
+
+	deckID := c.Param("deckID")
+	deckRes, err := h.deckUseCase.MoveDeck(userID, deckID, &move)
+	if err != nil {
+		if !writeKnownError(c, err) {
+			httpconst.WriteDatabaseError(c)
+		}
+		return
+	}
+
+	writeETag(c, deckRes.Version)
+	httpconst.WriteSuccess(c, deckRes)
+}
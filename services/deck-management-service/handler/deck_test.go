@@ -23,9 +23,12 @@ func (u *DeckUseCaseMock) CreateDeck(userID string, deck *entity.DeckReq) (*enti
 	return args.Get(0).(*entity.DeckRes), args.Error(1)
 }
 
-func (u *DeckUseCaseMock) GetDecks(userID string) ([]entity.DeckRes, error) {
-	args := u.Called(userID)
-	return args.Get(0).([]entity.DeckRes), args.Error(1)
+func (u *DeckUseCaseMock) GetDecks(
+	userID string,
+	req *entity.DeckListReq,
+) ([]entity.DeckRes, string, error) {
+	args := u.Called(userID, req)
+	return args.Get(0).([]entity.DeckRes), args.String(1), args.Error(2)
 }
 
 func (u *DeckUseCaseMock) GetDeck(userID, DeckID string) (*entity.DeckRes, error) {
@@ -36,16 +39,40 @@ func (u *DeckUseCaseMock) GetDeck(userID, DeckID string) (*entity.DeckRes, error
 func (u *DeckUseCaseMock) UpdateDeck(
 	userID, DeckID string,
 	deck *entity.DeckReq,
+	ifMatch entity.IfMatch,
 ) (*entity.DeckRes, error) {
-	args := u.Called(userID, DeckID, deck)
+	args := u.Called(userID, DeckID, deck, ifMatch)
 	return args.Get(0).(*entity.DeckRes), args.Error(1)
 }
 
-func (u *DeckUseCaseMock) DeleteDeck(userID, deckID string) error {
-	args := u.Called(userID, deckID)
+func (u *DeckUseCaseMock) DeleteDeck(userID, deckID string, ifMatch entity.IfMatch) error {
+	args := u.Called(userID, deckID, ifMatch)
 	return args.Error(0)
 }
 
+func (u *DeckUseCaseMock) ForkDeck(userID, deckID string) (*entity.DeckRes, error) {
+	args := u.Called(userID, deckID)
+	return args.Get(0).(*entity.DeckRes), args.Error(1)
+}
+
+func (u *DeckUseCaseMock) PullDeck(userID, deckID string) (*entity.DeckRes, error) {
+	args := u.Called(userID, deckID)
+	return args.Get(0).(*entity.DeckRes), args.Error(1)
+}
+
+func (u *DeckUseCaseMock) GetDeckTree(userID string) ([]entity.DeckTreeRes, error) {
+	args := u.Called(userID)
+	return args.Get(0).([]entity.DeckTreeRes), args.Error(1)
+}
+
+func (u *DeckUseCaseMock) MoveDeck(
+	userID, deckID string,
+	move *entity.DeckMoveReq,
+) (*entity.DeckRes, error) {
+	args := u.Called(userID, deckID, move)
+	return args.Get(0).(*entity.DeckRes), args.Error(1)
+}
+
 var validatorObj = validator.NewValidator()
 
 func TestCreateDeck(t *testing.T) {
@@ -142,7 +169,8 @@ func TestGetDecks(t *testing.T) {
 
 	var handler = NewDeckHandler(log.New(), deckUseCaseMock, validatorObj)
 
-	deckUseCaseMock.On("GetDecks", mock.Anything).Return([]entity.DeckRes{}, nil)
+	deckUseCaseMock.On("GetDecks", mock.Anything, mock.Anything).
+		Return([]entity.DeckRes{}, "", nil)
 
 	for _, test := range tests {
 
@@ -165,6 +193,71 @@ func TestGetDecks(t *testing.T) {
 	}
 }
 
+func TestGetDecksFields(t *testing.T) {
+	tests := []struct {
+		testName       string
+		query          string
+		wantStatusCode int
+		wantBody       string
+	}{
+		{
+			"Sorted",
+			"sort=name&order=desc",
+			200,
+			"",
+		},
+		{
+			"Selected Fields",
+			"fields=name,color",
+			200,
+			`{"message":"Success","data":[{"color":"Test Color","id":"1","name":"Test Deck"}]}`,
+		},
+		{
+			"Invalid Sort",
+			"sort=color",
+			400,
+			"",
+		},
+	}
+
+	deckUseCaseMock := new(DeckUseCaseMock)
+
+	var handler = NewDeckHandler(log.New(), deckUseCaseMock, validatorObj)
+
+	deckUseCaseMock.On("GetDecks", "test_user_id", mock.Anything).Return([]entity.DeckRes{
+		{
+			ID:          "1",
+			Name:        "Test Deck",
+			Description: "Test Description",
+			Color:       "Test Color",
+			Role:        "owner",
+			Cards:       []entity.CardRes{},
+		},
+	}, "next_cursor", nil)
+
+	for _, test := range tests {
+
+		t.Run(test.testName, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(w)
+			c.Request, _ = http.NewRequest("GET", "/decks?"+test.query, nil)
+			q := c.Request.URL.Query()
+			q.Add("userID", "test_user_id")
+			c.Request.URL.RawQuery = q.Encode()
+
+			handler.GetDecks(c)
+
+			assert.Equal(t, test.wantStatusCode, c.Writer.Status())
+			if test.wantStatusCode == 200 {
+				assert.Equal(t, "next_cursor", w.Header().Get(NextCursorHeader))
+			}
+			if test.wantBody != "" {
+				assert.JSONEq(t, test.wantBody, w.Body.String())
+			}
+		})
+	}
+}
+
 func TestGetDeck(t *testing.T) {
 	tests := []struct {
 		testName       string
@@ -225,6 +318,7 @@ func TestUpdateDeck(t *testing.T) {
 		body           string
 		userID         string
 		deckID         string
+		ifMatch        string
 		wantStatusCode int
 	}{
 		{
@@ -232,13 +326,31 @@ func TestUpdateDeck(t *testing.T) {
 			`{"name": "Test Deck", "description": "Test Description", "color": "#FFFFFF"}`,
 			"test_user_id",
 			"test_deck_id",
+			"",
 			200,
 		},
+		{
+			"Matching Version",
+			`{"name": "Test Deck", "description": "Test Description", "color": "#FFFFFF"}`,
+			"test_user_id",
+			"test_deck_id",
+			`"4"`,
+			200,
+		},
+		{
+			"Changed Deck",
+			`{"name": "Test Deck", "description": "Test Description", "color": "#FFFFFF"}`,
+			"test_user_id",
+			"test_deck_id",
+			`"3", W/"4"`,
+			412,
+		},
 		{
 			"Invalid Deck",
 			`{"name": ""}`,
 			"test_user_id",
 			"test_deck_id",
+			"",
 			400,
 		},
 		{
@@ -246,6 +358,7 @@ func TestUpdateDeck(t *testing.T) {
 			`{"name": "Test Deck"}`,
 			"",
 			"test_deck_id",
+			"",
 			401,
 		},
 		{
@@ -253,6 +366,7 @@ func TestUpdateDeck(t *testing.T) {
 			"",
 			"test_user_id",
 			"test_deck_id",
+			"",
 			400,
 		},
 	}
@@ -261,8 +375,15 @@ func TestUpdateDeck(t *testing.T) {
 
 	var handler = NewDeckHandler(log.New(), deckUseCaseMock, validatorObj)
 
-	deckUseCaseMock.On("UpdateDeck", mock.Anything, mock.Anything, mock.Anything).
-		Return(&entity.DeckRes{}, nil)
+	deckUseCaseMock.On(
+		"UpdateDeck",
+		mock.Anything,
+		mock.Anything,
+		mock.Anything,
+		entity.IfMatch{3},
+	).Return(&entity.DeckRes{}, entity.ErrVersionConflict)
+	deckUseCaseMock.On("UpdateDeck", mock.Anything, mock.Anything, mock.Anything, mock.Anything).
+		Return(&entity.DeckRes{Version: 5}, nil)
 
 	for _, test := range tests {
 
@@ -274,6 +395,9 @@ func TestUpdateDeck(t *testing.T) {
 				"/decks/:deckID",
 				bytes.NewBuffer([]byte(test.body)),
 			)
+			if test.ifMatch != "" {
+				c.Request.Header.Set("If-Match", test.ifMatch)
+			}
 
 			q := c.Request.URL.Query()
 			q.Add("userID", test.userID)
@@ -289,6 +413,9 @@ func TestUpdateDeck(t *testing.T) {
 			handler.UpdateDeck(c)
 
 			assert.Equal(t, test.wantStatusCode, c.Writer.Status())
+			if test.wantStatusCode == 200 {
+				assert.Equal(t, `"5"`, w.Header().Get(ETagHeader))
+			}
 		})
 	}
 }
@@ -318,7 +445,7 @@ func TestDeleteDeck(t *testing.T) {
 
 	var handler = NewDeckHandler(log.New(), deckUseCaseMock, validatorObj)
 
-	deckUseCaseMock.On("DeleteDeck", mock.Anything, mock.Anything).Return(nil)
+	deckUseCaseMock.On("DeleteDeck", mock.Anything, mock.Anything, mock.Anything).Return(nil)
 
 	for _, test := range tests {
 
@@ -346,3 +473,130 @@ func TestDeleteDeck(t *testing.T) {
 		})
 	}
 }
+
+func TestForkDeck(t *testing.T) {
+	tests := []struct {
+		testName       string
+		userID         string
+		deckID         string
+		wantStatusCode int
+	}{
+		{
+			"Valid Deck",
+			"test_user_id",
+			"test_deck_id",
+			201,
+		},
+		{
+			"Unknown Deck",
+			"test_user_id",
+			"unknown_deck_id",
+			404,
+		},
+		{
+			"Missing User ID",
+			"",
+			"test_deck_id",
+			401,
+		},
+	}
+
+	deckUseCaseMock := new(DeckUseCaseMock)
+
+	var handler = NewDeckHandler(log.New(), deckUseCaseMock, validatorObj)
+
+	deckUseCaseMock.On("ForkDeck", mock.Anything, "test_deck_id").Return(&entity.DeckRes{}, nil)
+	deckUseCaseMock.On("ForkDeck", mock.Anything, "unknown_deck_id").
+		Return(&entity.DeckRes{}, entity.ErrDeckNotFound)
+
+	for _, test := range tests {
+
+		t.Run(test.testName, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(w)
+			c.Request, _ = http.NewRequest(
+				"POST",
+				"/decks/:deckID/fork",
+				nil,
+			)
+			c.Params = []gin.Param{
+				{
+					Key:   "deckID",
+					Value: test.deckID,
+				},
+			}
+			q := c.Request.URL.Query()
+			q.Add("userID", test.userID)
+			c.Request.URL.RawQuery = q.Encode()
+
+			handler.ForkDeck(c)
+
+			assert.Equal(t, test.wantStatusCode, c.Writer.Status())
+		})
+	}
+}
+
+func TestMoveDeck(t *testing.T) {
+	tests := []struct {
+		testName       string
+		userID         string
+		body           string
+		wantStatusCode int
+	}{
+		{
+			"Below Other Deck",
+			"test_user_id",
+			`{"parentID": "parent_deck_id"}`,
+			200,
+		},
+		{
+			"Below Sub-Deck",
+			"test_user_id",
+			`{"parentID": "sub_deck_id"}`,
+			400,
+		},
+		{
+			"Missing User ID",
+			"",
+			`{"parentID": "parent_deck_id"}`,
+			401,
+		},
+	}
+
+	deckUseCaseMock := new(DeckUseCaseMock)
+
+	var handler = NewDeckHandler(log.New(), deckUseCaseMock, validatorObj)
+
+	deckUseCaseMock.On("MoveDeck", "test_user_id", "test_deck_id", &entity.DeckMoveReq{
+		ParentID: "parent_deck_id",
+	}).Return(&entity.DeckRes{ParentID: "parent_deck_id"}, nil)
+	deckUseCaseMock.On("MoveDeck", "test_user_id", "test_deck_id", &entity.DeckMoveReq{
+		ParentID: "sub_deck_id",
+	}).Return((*entity.DeckRes)(nil), entity.ErrInvalidParent)
+
+	for _, test := range tests {
+
+		t.Run(test.testName, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(w)
+			c.Request, _ = http.NewRequest(
+				"POST",
+				"/decks/:deckID/move",
+				bytes.NewBuffer([]byte(test.body)),
+			)
+			c.Params = []gin.Param{
+				{
+					Key:   "deckID",
+					Value: "test_deck_id",
+				},
+			}
+			q := c.Request.URL.Query()
+			q.Add("userID", test.userID)
+			c.Request.URL.RawQuery = q.Encode()
+
+			handler.MoveDeck(c)
+
+			assert.Equal(t, test.wantStatusCode, c.Writer.Status())
+		})
+	}
+}
@@ -0,0 +1,58 @@
+package handler
+
+import (
+	"errors"
+
+	"github.com/gin-gonic/gin"
+	"github.com/moshrank/spacey-backend/pkg/httpconst"
+	"github.com/moshrank/spacey-backend/services/deck-management-service/entity"
+	"github.com/moshrank/spacey-backend/services/deck-management-service/format"
+)
+
+// writeKnownError writes the response for errors the use cases return on
+// purpose and reports whether err was one of them.
+func writeKnownError(c *gin.Context, err error) bool {
+	switch {
+	case errors.Is(err, entity.ErrDeckNotFound),
+		errors.Is(err, entity.ErrMemberNotFound),
+		errors.Is(err, entity.ErrCardNotFound),
+		errors.Is(err, entity.ErrRevisionNotFound),
+		errors.Is(err, entity.ErrNothingToUndo),
+		errors.Is(err, entity.ErrMediaNotFound),
+		errors.Is(err, entity.ErrNoteTypeNotFound),
+		errors.Is(err, entity.ErrUserNotFound):
+		httpconst.WriteNotFound(c, err.Error())
+	case errors.Is(err, entity.ErrForbidden):
+		httpconst.WriteForbidden(c, err.Error())
+	case errors.Is(err, entity.ErrVersionConflict):
+		httpconst.WritePreconditionFailed(c, err.Error())
+	case errors.Is(err, entity.ErrDeckNotForked),
+		errors.Is(err, entity.ErrInvalidParent),
+		errors.Is(err, entity.ErrInvalidColor),
+		errors.Is(err, entity.ErrMemberExists),
+		errors.Is(err, format.ErrInvalidAnkiPackage),
+		errors.Is(err, format.ErrUnsupportedAnkiPackage),
+		errors.Is(err, format.ErrUnknownColumn),
+		errors.Is(err, entity.ErrUnknownFormat),
+		errors.Is(err, entity.ErrInvalidCursor),
+		errors.Is(err, entity.ErrInvalidField),
+		errors.Is(err, entity.ErrSameDeck),
+		errors.Is(err, entity.ErrInvalidCloze),
+		errors.Is(err, entity.ErrInvalidTags),
+		errors.Is(err, entity.ErrInvalidChoices),
+		errors.Is(err, entity.ErrInvalidTypedAnswer),
+		errors.Is(err, entity.ErrInvalidOcclusion),
+		errors.Is(err, entity.ErrInvalidNoteType),
+		errors.Is(err, entity.ErrInvalidTemplate),
+		errors.Is(err, entity.ErrInvalidNote),
+		errors.Is(err, entity.ErrNoteTypeInUse),
+		errors.Is(err, entity.ErrUnsupportedMediaType),
+		errors.Is(err, entity.ErrMediaTooLarge),
+		errors.Is(err, entity.ErrInvalidMediaRef):
+		httpconst.WriteBadRequest(c, err.Error())
+	default:
+		return false
+	}
+
+	return true
+}
@@ -0,0 +1,101 @@
+package handler
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/moshrank/spacey-backend/pkg/httpconst"
+	"github.com/moshrank/spacey-backend/pkg/logger"
+	"github.com/moshrank/spacey-backend/pkg/validator"
+	"github.com/moshrank/spacey-backend/services/deck-management-service/entity"
+)
+
+type LintHandler struct {
+	logger      logger.LoggerInterface
+	lintUseCase entity.LintUseCaseInterface
+	validator   validator.ValidatorInterface
+}
+
+type LintHandlerInterface interface {
+	LintDeck(c *gin.Context)
+	GetLintSettings(c *gin.Context)
+	UpdateLintSettings(c *gin.Context)
+}
+
+func NewLintHandler(
+	loggerObj logger.LoggerInterface,
+	lintUseCase entity.LintUseCaseInterface,
+	validatorObj validator.ValidatorInterface,
+) LintHandlerInterface {
+	return &LintHandler{
+		logger:      loggerObj,
+		lintUseCase: lintUseCase,
+		validator:   validatorObj,
+	}
+}
+
+func (h *LintHandler) LintDeck(c *gin.Context) {
+	userID := c.Request.URL.Query().Get("userID")
+
+	if userID == "" {
+		httpconst.WriteUnauthorized(c, "missing user id")
+		return
+	}
+
+	deckID := c.Param("deckID")
+
+	lintRes, err := h.lintUseCase.LintDeck(userID, deckID)
+	if err != nil {
+		if !writeKnownError(c, err) {
+			httpconst.WriteDatabaseError(c)
+		}
+		return
+	}
+
+	httpconst.WriteSuccess(c, lintRes)
+}
+
+func (h *LintHandler) GetLintSettings(c *gin.Context) {
+	userID := c.Request.URL.Query().Get("userID")
+
+	if userID == "" {
+		httpconst.WriteUnauthorized(c, "missing user id")
+		return
+	}
+
+	deckID := c.Param("deckID")
+
+	settings, err := h.lintUseCase.GetLintSettings(userID, deckID)
+	if err != nil {
+		if !writeKnownError(c, err) {
+			httpconst.WriteDatabaseError(c)
+		}
+		return
+	}
+
+	httpconst.WriteSuccess(c, settings)
+}
+
+func (h *LintHandler) UpdateLintSettings(c *gin.Context) {
+	userID := c.Request.URL.Query().Get("userID")
+
+	if userID == "" {
+		httpconst.WriteUnauthorized(c, "missing user id")
+		return
+	}
+
+	var settingsReq entity.LintSettingsReq
+	if err := h.validator.ValidateJSON(c, &settingsReq); err != nil {
+		return
+	}
+
+	deckID := c.Param("deckID")
+
+	settings, err := h.lintUseCase.UpdateLintSettings(userID, deckID, &settingsReq)
+	if err != nil {
+		if !writeKnownError(c, err) {
+			httpconst.WriteDatabaseError(c)
+		}
+		return
+	}
+
+	httpconst.WriteSuccess(c, settings)
+}
@@ -0,0 +1,164 @@
+package handler
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/moshrank/spacey-backend/services/deck-management-service/entity"
+	log "github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type LintUseCaseMock struct {
+	mock.Mock
+}
+
+func (u *LintUseCaseMock) LintDeck(userID, deckID string) (*entity.LintRes, error) {
+	args := u.Called(userID, deckID)
+	return args.Get(0).(*entity.LintRes), args.Error(1)
+}
+
+func (u *LintUseCaseMock) GetLintSettings(
+	userID, deckID string,
+) (*entity.LintSettingsRes, error) {
+	args := u.Called(userID, deckID)
+	return args.Get(0).(*entity.LintSettingsRes), args.Error(1)
+}
+
+func (u *LintUseCaseMock) UpdateLintSettings(
+	userID, deckID string,
+	req *entity.LintSettingsReq,
+) (*entity.LintSettingsRes, error) {
+	args := u.Called(userID, deckID, req)
+	return args.Get(0).(*entity.LintSettingsRes), args.Error(1)
+}
+
+func TestLintDeck(t *testing.T) {
+	tests := []struct {
+		testName       string
+		deckID         string
+		userID         string
+		wantStatusCode int
+	}{
+		{
+			"Known Deck",
+			"test_deck_id",
+			"test_user_id",
+			200,
+		},
+		{
+			"Unknown Deck",
+			"unknown_deck_id",
+			"test_user_id",
+			404,
+		},
+		{
+			"Missing User ID",
+			"test_deck_id",
+			"",
+			401,
+		},
+	}
+
+	lintUseCaseMock := new(LintUseCaseMock)
+
+	var handler = NewLintHandler(log.New(), lintUseCaseMock, validatorObj)
+
+	lintUseCaseMock.On("LintDeck", "test_user_id", "test_deck_id").
+		Return(&entity.LintRes{Rules: entity.LintRules}, nil)
+	lintUseCaseMock.On("LintDeck", "test_user_id", "unknown_deck_id").
+		Return((*entity.LintRes)(nil), entity.ErrDeckNotFound)
+
+	for _, test := range tests {
+
+		t.Run(test.testName, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(w)
+			c.Request, _ = http.NewRequest("POST", "/decks/:deckID/lint", nil)
+			c.Params = []gin.Param{{Key: "deckID", Value: test.deckID}}
+
+			q := c.Request.URL.Query()
+			q.Add("userID", test.userID)
+			c.Request.URL.RawQuery = q.Encode()
+
+			handler.LintDeck(c)
+
+			assert.Equal(t, test.wantStatusCode, c.Writer.Status())
+		})
+	}
+}
+
+func TestUpdateLintSettings(t *testing.T) {
+	tests := []struct {
+		testName       string
+		body           string
+		userID         string
+		wantStatusCode int
+	}{
+		{
+			"Known Rules",
+			`{"disabledRules": ["answer-too-long", "near-duplicate"]}`,
+			"test_user_id",
+			200,
+		},
+		{
+			"No Disabled Rules",
+			`{"disabledRules": []}`,
+			"test_user_id",
+			200,
+		},
+		{
+			"Unknown Rule",
+			`{"disabledRules": ["spelling"]}`,
+			"test_user_id",
+			400,
+		},
+		{
+			"Viewer",
+			`{"disabledRules": []}`,
+			"viewer_user_id",
+			403,
+		},
+		{
+			"Missing User ID",
+			`{}`,
+			"",
+			401,
+		},
+	}
+
+	lintUseCaseMock := new(LintUseCaseMock)
+
+	var handler = NewLintHandler(log.New(), lintUseCaseMock, validatorObj)
+
+	lintUseCaseMock.On("UpdateLintSettings", "test_user_id", "test_deck_id", mock.Anything).
+		Return(&entity.LintSettingsRes{}, nil)
+	lintUseCaseMock.On("UpdateLintSettings", "viewer_user_id", "test_deck_id", mock.Anything).
+		Return((*entity.LintSettingsRes)(nil), entity.ErrForbidden)
+
+	for _, test := range tests {
+
+		t.Run(test.testName, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(w)
+			c.Request, _ = http.NewRequest(
+				"PUT",
+				"/decks/:deckID/lint/settings",
+				bytes.NewBuffer([]byte(test.body)),
+			)
+			c.Params = []gin.Param{{Key: "deckID", Value: "test_deck_id"}}
+
+			q := c.Request.URL.Query()
+			q.Add("userID", test.userID)
+			c.Request.URL.RawQuery = q.Encode()
+
+			handler.UpdateLintSettings(c)
+
+			assert.Equal(t, test.wantStatusCode, c.Writer.Status())
+		})
+	}
+}
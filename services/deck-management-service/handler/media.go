@@ -0,0 +1,109 @@
+package handler
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/moshrank/spacey-backend/config"
+	"github.com/moshrank/spacey-backend/pkg/httpconst"
+	"github.com/moshrank/spacey-backend/pkg/logger"
+	"github.com/moshrank/spacey-backend/services/deck-management-service/entity"
+)
+
+// multipartOverhead is the room left for the multipart envelope around an
+// uploaded media file.
+const multipartOverhead = 1 << 20
+
+type MediaHandler struct {
+	logger       logger.LoggerInterface
+	mediaUseCase entity.MediaUseCaseInterface
+	maxBodySize  int64
+}
+
+type MediaHandlerInterface interface {
+	Upload(c *gin.Context)
+	GetMedia(c *gin.Context)
+}
+
+func NewMediaHandler(
+	loggerObj logger.LoggerInterface,
+	mediaUseCase entity.MediaUseCaseInterface,
+	cfg config.ConfigInterface,
+) MediaHandlerInterface {
+	return &MediaHandler{
+		logger:       loggerObj,
+		mediaUseCase: mediaUseCase,
+		maxBodySize:  int64(cfg.GetMaxMediaSizeMB())<<20 + multipartOverhead,
+	}
+}
+
+func (h *MediaHandler) Upload(c *gin.Context) {
+	userID := c.Request.URL.Query().Get("userID")
+
+	if userID == "" {
+		httpconst.WriteUnauthorized(c, "missing user id")
+		return
+	}
+
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, h.maxBodySize)
+
+	fileHeader, err := c.FormFile("file")
+	if err != nil {
+		httpconst.WriteBadRequest(c, "missing media file")
+		return
+	}
+
+	file, err := fileHeader.Open()
+	if err != nil {
+		httpconst.WriteBadRequest(c, "could not read media file")
+		return
+	}
+	defer file.Close()
+
+	deckID := c.Param("deckID")
+
+	res, err := h.mediaUseCase.Upload(userID, deckID, fileHeader.Filename, file)
+	if err != nil {
+		if !writeKnownError(c, err) {
+			h.logger.Error(err)
+			httpconst.WriteInternalServerError(c, "could not store media file")
+		}
+		return
+	}
+
+	httpconst.WriteCreated(c, res)
+}
+
+func (h *MediaHandler) GetMedia(c *gin.Context) {
+	userID := c.Request.URL.Query().Get("userID")
+
+	if userID == "" {
+		httpconst.WriteUnauthorized(c, "missing user id")
+		return
+	}
+
+	deckID := c.Param("deckID")
+	mediaID := c.Param("mediaID")
+
+	file, err := h.mediaUseCase.GetMedia(userID, deckID, mediaID)
+	if err != nil {
+		if !writeKnownError(c, err) {
+			httpconst.WriteDatabaseError(c)
+		}
+		return
+	}
+	defer file.Content.Close()
+
+	c.DataFromReader(
+		http.StatusOK,
+		file.Media.Size,
+		file.Media.ContentType,
+		file.Content,
+		map[string]string{
+			"Content-Disposition":    fmt.Sprintf("inline; filename=%q", file.Media.Name),
+			"Cache-Control":          "private, max-age=86400, immutable",
+			"X-Content-Type-Options": "nosniff",
+		},
+	)
+}
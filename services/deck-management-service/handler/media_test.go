@@ -0,0 +1,185 @@
+package handler
+
+import (
+	"bytes"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/moshrank/spacey-backend/config"
+	"github.com/moshrank/spacey-backend/services/deck-management-service/entity"
+	log "github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type MediaUseCaseMock struct {
+	mock.Mock
+}
+
+func (u *MediaUseCaseMock) Upload(
+	userID, deckID, name string,
+	content io.Reader,
+) (*entity.MediaRes, error) {
+	args := u.Called(userID, deckID, name, content)
+	return args.Get(0).(*entity.MediaRes), args.Error(1)
+}
+
+func (u *MediaUseCaseMock) GetMedia(userID, deckID, mediaID string) (*entity.MediaFile, error) {
+	args := u.Called(userID, deckID, mediaID)
+	return args.Get(0).(*entity.MediaFile), args.Error(1)
+}
+
+func (u *MediaUseCaseMock) CollectGarbage() (int, error) {
+	args := u.Called()
+	return args.Int(0), args.Error(1)
+}
+
+var mediaConfig = &config.Config{MaxMediaSizeMB: 1}
+
+func TestUploadMedia(t *testing.T) {
+	tests := []struct {
+		testName       string
+		fileName       string
+		userID         string
+		wantStatusCode int
+	}{
+		{
+			"Image",
+			"map.png",
+			"test_user_id",
+			201,
+		},
+		{
+			"Unsupported Type",
+			"page.html",
+			"test_user_id",
+			400,
+		},
+		{
+			"Missing File",
+			"",
+			"test_user_id",
+			400,
+		},
+		{
+			"Missing User ID",
+			"map.png",
+			"",
+			401,
+		},
+	}
+
+	mediaUseCaseMock := new(MediaUseCaseMock)
+
+	var handler = NewMediaHandler(log.New(), mediaUseCaseMock, mediaConfig)
+
+	mediaUseCaseMock.On("Upload", "test_user_id", "test_deck_id", "map.png", mock.Anything).
+		Return(&entity.MediaRes{ID: "test_media_id", Ref: "media:test_media_id"}, nil)
+	mediaUseCaseMock.On("Upload", "test_user_id", "test_deck_id", "page.html", mock.Anything).
+		Return((*entity.MediaRes)(nil), entity.ErrUnsupportedMediaType)
+
+	for _, test := range tests {
+
+		t.Run(test.testName, func(t *testing.T) {
+			body := new(bytes.Buffer)
+			writer := multipart.NewWriter(body)
+			if test.fileName != "" {
+				part, _ := writer.CreateFormFile("file", test.fileName)
+				part.Write([]byte("content"))
+			}
+			writer.Close()
+
+			w := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(w)
+			c.Request, _ = http.NewRequest("POST", "/decks/:deckID/media", body)
+			c.Request.Header.Set("Content-Type", writer.FormDataContentType())
+			c.Params = []gin.Param{
+				{
+					Key:   "deckID",
+					Value: "test_deck_id",
+				},
+			}
+			q := c.Request.URL.Query()
+			q.Add("userID", test.userID)
+			c.Request.URL.RawQuery = q.Encode()
+
+			handler.Upload(c)
+
+			assert.Equal(t, test.wantStatusCode, c.Writer.Status())
+		})
+	}
+}
+
+func TestGetMedia(t *testing.T) {
+	tests := []struct {
+		testName       string
+		mediaID        string
+		userID         string
+		wantStatusCode int
+	}{
+		{
+			"Existing Media",
+			"test_media_id",
+			"test_user_id",
+			200,
+		},
+		{
+			"Unknown Media",
+			"unknown_media_id",
+			"test_user_id",
+			404,
+		},
+		{
+			"Missing User ID",
+			"test_media_id",
+			"",
+			401,
+		},
+	}
+
+	mediaUseCaseMock := new(MediaUseCaseMock)
+
+	var handler = NewMediaHandler(log.New(), mediaUseCaseMock, mediaConfig)
+
+	mediaUseCaseMock.On("GetMedia", "test_user_id", "test_deck_id", "test_media_id").
+		Return(&entity.MediaFile{
+			Media:   entity.Media{Name: "map.png", ContentType: "image/png", Size: 7},
+			Content: io.NopCloser(bytes.NewReader([]byte("content"))),
+		}, nil)
+	mediaUseCaseMock.On("GetMedia", "test_user_id", "test_deck_id", "unknown_media_id").
+		Return((*entity.MediaFile)(nil), entity.ErrMediaNotFound)
+
+	for _, test := range tests {
+
+		t.Run(test.testName, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(w)
+			c.Request, _ = http.NewRequest("GET", "/decks/:deckID/media/:mediaID", nil)
+			c.Params = []gin.Param{
+				{
+					Key:   "deckID",
+					Value: "test_deck_id",
+				},
+				{
+					Key:   "mediaID",
+					Value: test.mediaID,
+				},
+			}
+			q := c.Request.URL.Query()
+			q.Add("userID", test.userID)
+			c.Request.URL.RawQuery = q.Encode()
+
+			handler.GetMedia(c)
+
+			assert.Equal(t, test.wantStatusCode, c.Writer.Status())
+			if test.wantStatusCode == 200 {
+				assert.Equal(t, "image/png", w.Header().Get("Content-Type"))
+				assert.Equal(t, "nosniff", w.Header().Get("X-Content-Type-Options"))
+			}
+		})
+	}
+}
@@ -0,0 +1,169 @@
+package handler
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/moshrank/spacey-backend/pkg/httpconst"
+	"github.com/moshrank/spacey-backend/pkg/logger"
+	"github.com/moshrank/spacey-backend/pkg/validator"
+	"github.com/moshrank/spacey-backend/services/deck-management-service/entity"
+)
+
+type MemberHandler struct {
+	logger        logger.LoggerInterface
+	memberUseCase entity.MemberUseCaseInterface
+	validator     validator.ValidatorInterface
+}
+
+type MemberHandlerInterface interface {
+	GetMembers(c *gin.Context)
+	InviteMember(c *gin.Context)
+	UpdateMember(c *gin.Context)
+	RemoveMember(c *gin.Context)
+	GetInvitations(c *gin.Context)
+	AcceptInvitation(c *gin.Context)
+}
+
+func NewMemberHandler(
+	loggerObj logger.LoggerInterface,
+	memberUseCase entity.MemberUseCaseInterface,
+	validatorObj validator.ValidatorInterface,
+) MemberHandlerInterface {
+	return &MemberHandler{
+		logger:        loggerObj,
+		memberUseCase: memberUseCase,
+		validator:     validatorObj,
+	}
+}
+
+func (h *MemberHandler) GetMembers(c *gin.Context) {
+	userID := c.Request.URL.Query().Get("userID")
+
+	if userID == "" {
+		httpconst.WriteUnauthorized(c, "missing user id")
+		return
+	}
+
+	deckID := c.Param("deckID")
+
+	members, err := h.memberUseCase.GetMembers(userID, deckID)
+	if err != nil {
+		if !writeKnownError(c, err) {
+			httpconst.WriteDatabaseError(c)
+		}
+		return
+	}
+
+	httpconst.WriteSuccess(c, members)
+}
+
+func (h *MemberHandler) InviteMember(c *gin.Context) {
+	userID := c.Request.URL.Query().Get("userID")
+
+	if userID == "" {
+		httpconst.WriteUnauthorized(c, "missing user id")
+		return
+	}
+
+	var member entity.MemberReq
+	if err := h.validator.ValidateJSON(c, &member); err != nil {
+		return
+	}
+
+	deckID := c.Param("deckID")
+
+	memberRes, err := h.memberUseCase.InviteMember(userID, deckID, &member)
+	if err != nil {
+		if !writeKnownError(c, err) {
+			h.logger.Error(err)
+			httpconst.WriteInternalServerError(c, "could not invite user")
+		}
+		return
+	}
+
+	httpconst.WriteCreated(c, memberRes)
+}
+
+func (h *MemberHandler) UpdateMember(c *gin.Context) {
+	userID := c.Request.URL.Query().Get("userID")
+
+	if userID == "" {
+		httpconst.WriteUnauthorized(c, "missing user id")
+		return
+	}
+
+	var member entity.MemberRoleReq
+	if err := h.validator.ValidateJSON(c, &member); err != nil {
+		return
+	}
+
+	deckID := c.Param("deckID")
+	memberID := c.Param("memberID")
+
+	memberRes, err := h.memberUseCase.UpdateMember(userID, deckID, memberID, member.Role)
+	if err != nil {
+		if !writeKnownError(c, err) {
+			httpconst.WriteDatabaseError(c)
+		}
+		return
+	}
+
+	httpconst.WriteSuccess(c, memberRes)
+}
+
+func (h *MemberHandler) RemoveMember(c *gin.Context) {
+	userID := c.Request.URL.Query().Get("userID")
+
+	if userID == "" {
+		httpconst.WriteUnauthorized(c, "missing user id")
+		return
+	}
+
+	deckID := c.Param("deckID")
+	memberID := c.Param("memberID")
+
+	if err := h.memberUseCase.RemoveMember(userID, deckID, memberID); err != nil {
+		if !writeKnownError(c, err) {
+			httpconst.WriteDatabaseError(c)
+		}
+		return
+	}
+
+	httpconst.WriteSuccess(c, map[string]string{"Message": "Member removed"})
+}
+
+func (h *MemberHandler) GetInvitations(c *gin.Context) {
+	userID := c.Request.URL.Query().Get("userID")
+
+	if userID == "" {
+		httpconst.WriteUnauthorized(c, "missing user id")
+		return
+	}
+
+	invitations, err := h.memberUseCase.GetInvitations(userID)
+	if err != nil {
+		httpconst.WriteDatabaseError(c)
+		return
+	}
+
+	httpconst.WriteSuccess(c, invitations)
+}
+
+func (h *MemberHandler) AcceptInvitation(c *gin.Context) {
+	userID := c.Request.URL.Query().Get("userID")
+
+	if userID == "" {
+		httpconst.WriteUnauthorized(c, "missing user id")
+		return
+	}
+
+	deckID := c.Param("deckID")
+
+	if err := h.memberUseCase.AcceptInvitation(userID, deckID); err != nil {
+		if !writeKnownError(c, err) {
+			httpconst.WriteDatabaseError(c)
+		}
+		return
+	}
+
+	httpconst.WriteSuccess(c, map[string]string{"Message": "Invitation accepted"})
+}
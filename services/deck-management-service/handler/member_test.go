@@ -0,0 +1,134 @@
+package handler
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/moshrank/spacey-backend/services/deck-management-service/entity"
+	log "github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type MemberUseCaseMock struct {
+	mock.Mock
+}
+
+func (u *MemberUseCaseMock) GetMembers(userID, deckID string) ([]entity.MemberRes, error) {
+	args := u.Called(userID, deckID)
+	return args.Get(0).([]entity.MemberRes), args.Error(1)
+}
+
+func (u *MemberUseCaseMock) InviteMember(
+	userID, deckID string,
+	member *entity.MemberReq,
+) (*entity.MemberRes, error) {
+	args := u.Called(userID, deckID, member)
+	return args.Get(0).(*entity.MemberRes), args.Error(1)
+}
+
+func (u *MemberUseCaseMock) UpdateMember(
+	userID, deckID, memberID, role string,
+) (*entity.MemberRes, error) {
+	args := u.Called(userID, deckID, memberID, role)
+	return args.Get(0).(*entity.MemberRes), args.Error(1)
+}
+
+func (u *MemberUseCaseMock) RemoveMember(userID, deckID, memberID string) error {
+	args := u.Called(userID, deckID, memberID)
+	return args.Error(0)
+}
+
+func (u *MemberUseCaseMock) GetInvitations(userID string) ([]entity.InvitationRes, error) {
+	args := u.Called(userID)
+	return args.Get(0).([]entity.InvitationRes), args.Error(1)
+}
+
+func (u *MemberUseCaseMock) AcceptInvitation(userID, deckID string) error {
+	args := u.Called(userID, deckID)
+	return args.Error(0)
+}
+
+func TestInviteMember(t *testing.T) {
+	tests := []struct {
+		testName       string
+		body           string
+		userID         string
+		wantStatusCode int
+	}{
+		{
+			"Valid Invitation",
+			`{"email": "test@spacey.com", "role": "editor"}`,
+			"test_user_id",
+			201,
+		},
+		{
+			"Invalid Role",
+			`{"email": "test@spacey.com", "role": "admin"}`,
+			"test_user_id",
+			400,
+		},
+		{
+			"Owner Role",
+			`{"email": "test@spacey.com", "role": "owner"}`,
+			"test_user_id",
+			400,
+		},
+		{
+			"Invalid Email",
+			`{"email": "test", "role": "viewer"}`,
+			"test_user_id",
+			400,
+		},
+		{
+			"Not Owner",
+			`{"email": "test@spacey.com", "role": "editor"}`,
+			"editor_user_id",
+			403,
+		},
+		{
+			"Missing User ID",
+			`{"email": "test@spacey.com", "role": "editor"}`,
+			"",
+			401,
+		},
+	}
+
+	memberUseCaseMock := new(MemberUseCaseMock)
+
+	var handler = NewMemberHandler(log.New(), memberUseCaseMock, validatorObj)
+
+	memberUseCaseMock.On("InviteMember", "test_user_id", mock.Anything, mock.Anything).
+		Return(&entity.MemberRes{}, nil)
+	memberUseCaseMock.On("InviteMember", "editor_user_id", mock.Anything, mock.Anything).
+		Return(&entity.MemberRes{}, entity.ErrForbidden)
+
+	for _, test := range tests {
+
+		t.Run(test.testName, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(w)
+			c.Request, _ = http.NewRequest(
+				"POST",
+				"/decks/:deckID/members",
+				bytes.NewBuffer([]byte(test.body)),
+			)
+			c.Params = []gin.Param{
+				{
+					Key:   "deckID",
+					Value: "test_deck_id",
+				},
+			}
+			q := c.Request.URL.Query()
+			q.Add("userID", test.userID)
+			c.Request.URL.RawQuery = q.Encode()
+
+			handler.InviteMember(c)
+
+			assert.Equal(t, test.wantStatusCode, c.Writer.Status())
+		})
+	}
+}
@@ -0,0 +1,145 @@
+package handler
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/moshrank/spacey-backend/pkg/httpconst"
+	"github.com/moshrank/spacey-backend/pkg/logger"
+	"github.com/moshrank/spacey-backend/pkg/validator"
+	"github.com/moshrank/spacey-backend/services/deck-management-service/entity"
+)
+
+type NoteTypeHandler struct {
+	logger          logger.LoggerInterface
+	noteTypeUseCase entity.NoteTypeUseCaseInterface
+	validator       validator.ValidatorInterface
+}
+
+type NoteTypeHandlerInterface interface {
+	GetNoteTypes(c *gin.Context)
+	GetNoteType(c *gin.Context)
+	CreateNoteType(c *gin.Context)
+	UpdateNoteType(c *gin.Context)
+	DeleteNoteType(c *gin.Context)
+}
+
+func NewNoteTypeHandler(
+	loggerObj logger.LoggerInterface,
+	noteTypeUseCase entity.NoteTypeUseCaseInterface,
+	validatorObj validator.ValidatorInterface,
+) NoteTypeHandlerInterface {
+	return &NoteTypeHandler{
+		logger:          loggerObj,
+		noteTypeUseCase: noteTypeUseCase,
+		validator:       validatorObj,
+	}
+}
+
+func (h *NoteTypeHandler) GetNoteTypes(c *gin.Context) {
+	userID := c.Request.URL.Query().Get("userID")
+
+	if userID == "" {
+		httpconst.WriteUnauthorized(c, "missing user id")
+		return
+	}
+
+	noteTypes, err := h.noteTypeUseCase.GetNoteTypes(userID)
+	if err != nil {
+		httpconst.WriteDatabaseError(c)
+		return
+	}
+
+	httpconst.WriteSuccess(c, noteTypes)
+}
+
+func (h *NoteTypeHandler) GetNoteType(c *gin.Context) {
+	userID := c.Request.URL.Query().Get("userID")
+
+	if userID == "" {
+		httpconst.WriteUnauthorized(c, "missing user id")
+		return
+	}
+
+	noteTypeID := c.Param("noteTypeID")
+
+	noteType, err := h.noteTypeUseCase.GetNoteType(userID, noteTypeID)
+	if err != nil {
+		if !writeKnownError(c, err) {
+			httpconst.WriteDatabaseError(c)
+		}
+		return
+	}
+
+	httpconst.WriteSuccess(c, noteType)
+}
+
+func (h *NoteTypeHandler) CreateNoteType(c *gin.Context) {
+	userID := c.Request.URL.Query().Get("userID")
+
+	if userID == "" {
+		httpconst.WriteUnauthorized(c, "missing user id")
+		return
+	}
+
+	var noteType entity.NoteTypeReq
+	if err := h.validator.ValidateJSON(c, &noteType); err != nil {
+		return
+	}
+
+	noteTypeRes, err := h.noteTypeUseCase.CreateNoteType(userID, &noteType)
+	if err != nil {
+		if !writeKnownError(c, err) {
+			h.logger.Error(err)
+			httpconst.WriteInternalServerError(c, "could not create note type")
+		}
+		return
+	}
+
+	httpconst.WriteCreated(c, noteTypeRes)
+}
+
+func (h *NoteTypeHandler) UpdateNoteType(c *gin.Context) {
+	userID := c.Request.URL.Query().Get("userID")
+
+	if userID == "" {
+		httpconst.WriteUnauthorized(c, "missing user id")
+		return
+	}
+
+	var noteType entity.NoteTypeReq
+	if err := h.validator.ValidateJSON(c, &noteType); err != nil {
+		return
+	}
+
+	noteTypeID := c.Param("noteTypeID")
+
+	noteTypeRes, err := h.noteTypeUseCase.UpdateNoteType(userID, noteTypeID, &noteType)
+	if err != nil {
+		if !writeKnownError(c, err) {
+			h.logger.Error(err)
+			httpconst.WriteInternalServerError(c, "could not update note type")
+		}
+		return
+	}
+
+	httpconst.WriteSuccess(c, noteTypeRes)
+}
+
+func (h *NoteTypeHandler) DeleteNoteType(c *gin.Context) {
+	userID := c.Request.URL.Query().Get("userID")
+
+	if userID == "" {
+		httpconst.WriteUnauthorized(c, "missing user id")
+		return
+	}
+
+	noteTypeID := c.Param("noteTypeID")
+
+	if err := h.noteTypeUseCase.DeleteNoteType(userID, noteTypeID); err != nil {
+		if !writeKnownError(c, err) {
+			httpconst.WriteDatabaseError(c)
+		}
+		return
+	}
+
+	httpconst.WriteSuccess(c, map[string]string{"Message": "Note type deleted"})
+}
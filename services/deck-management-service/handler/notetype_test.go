@@ -0,0 +1,188 @@
+package handler
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/moshrank/spacey-backend/services/deck-management-service/entity"
+	log "github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type NoteTypeUseCaseMock struct {
+	mock.Mock
+}
+
+func (u *NoteTypeUseCaseMock) GetNoteTypes(userID string) ([]entity.NoteTypeRes, error) {
+	args := u.Called(userID)
+	return args.Get(0).([]entity.NoteTypeRes), args.Error(1)
+}
+
+func (u *NoteTypeUseCaseMock) GetNoteType(
+	userID, noteTypeID string,
+) (*entity.NoteTypeRes, error) {
+	args := u.Called(userID, noteTypeID)
+	return args.Get(0).(*entity.NoteTypeRes), args.Error(1)
+}
+
+func (u *NoteTypeUseCaseMock) CreateNoteType(
+	userID string,
+	noteType *entity.NoteTypeReq,
+) (*entity.NoteTypeRes, error) {
+	args := u.Called(userID, noteType)
+	return args.Get(0).(*entity.NoteTypeRes), args.Error(1)
+}
+
+func (u *NoteTypeUseCaseMock) UpdateNoteType(
+	userID, noteTypeID string,
+	noteType *entity.NoteTypeReq,
+) (*entity.NoteTypeRes, error) {
+	args := u.Called(userID, noteTypeID, noteType)
+	return args.Get(0).(*entity.NoteTypeRes), args.Error(1)
+}
+
+func (u *NoteTypeUseCaseMock) DeleteNoteType(userID, noteTypeID string) error {
+	args := u.Called(userID, noteTypeID)
+	return args.Error(0)
+}
+
+func TestCreateNoteType(t *testing.T) {
+	tests := []struct {
+		testName       string
+		body           string
+		userID         string
+		wantStatusCode int
+	}{
+		{
+			"Valid Note Type",
+			`{
+				"name": "Vocabulary",
+				"fields": ["Word", "Meaning"],
+				"templates": [{"name": "Recognition", "front": "{{Word}}", "back": "{{Meaning}}"}]
+			}`,
+			"test_user_id",
+			201,
+		},
+		{
+			"Missing Templates",
+			`{"name": "Vocabulary", "fields": ["Word", "Meaning"]}`,
+			"test_user_id",
+			400,
+		},
+		{
+			"Invalid Template",
+			`{
+				"name": "Vocabulary",
+				"fields": ["Word"],
+				"templates": [{"name": "Recognition", "front": "{{Word}}", "back": "{{Meaning}}"}]
+			}`,
+			"invalid_user_id",
+			400,
+		},
+		{
+			"Missing User ID",
+			`{}`,
+			"",
+			401,
+		},
+	}
+
+	noteTypeUseCaseMock := new(NoteTypeUseCaseMock)
+
+	var handler = NewNoteTypeHandler(log.New(), noteTypeUseCaseMock, validatorObj)
+
+	noteTypeUseCaseMock.On("CreateNoteType", "test_user_id", mock.Anything).
+		Return(&entity.NoteTypeRes{}, nil)
+	noteTypeUseCaseMock.On("CreateNoteType", "invalid_user_id", mock.Anything).
+		Return((*entity.NoteTypeRes)(nil), entity.ErrInvalidTemplate)
+
+	for _, test := range tests {
+
+		t.Run(test.testName, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(w)
+			c.Request, _ = http.NewRequest(
+				"POST",
+				"/note-types",
+				bytes.NewBuffer([]byte(test.body)),
+			)
+			q := c.Request.URL.Query()
+			q.Add("userID", test.userID)
+			c.Request.URL.RawQuery = q.Encode()
+
+			handler.CreateNoteType(c)
+
+			assert.Equal(t, test.wantStatusCode, c.Writer.Status())
+		})
+	}
+}
+
+func TestDeleteNoteType(t *testing.T) {
+	tests := []struct {
+		testName       string
+		noteTypeID     string
+		userID         string
+		wantStatusCode int
+	}{
+		{
+			"Unused Note Type",
+			"unused",
+			"test_user_id",
+			200,
+		},
+		{
+			"Note Type In Use",
+			"used",
+			"test_user_id",
+			400,
+		},
+		{
+			"Unknown Note Type",
+			"unknown",
+			"test_user_id",
+			404,
+		},
+		{
+			"Missing User ID",
+			"unused",
+			"",
+			401,
+		},
+	}
+
+	noteTypeUseCaseMock := new(NoteTypeUseCaseMock)
+
+	var handler = NewNoteTypeHandler(log.New(), noteTypeUseCaseMock, validatorObj)
+
+	noteTypeUseCaseMock.On("DeleteNoteType", "test_user_id", "unused").Return(nil)
+	noteTypeUseCaseMock.On("DeleteNoteType", "test_user_id", "used").
+		Return(entity.ErrNoteTypeInUse)
+	noteTypeUseCaseMock.On("DeleteNoteType", "test_user_id", "unknown").
+		Return(entity.ErrNoteTypeNotFound)
+
+	for _, test := range tests {
+
+		t.Run(test.testName, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(w)
+			c.Request, _ = http.NewRequest("DELETE", "/note-types/:noteTypeID", nil)
+			c.Params = []gin.Param{
+				{
+					Key:   "noteTypeID",
+					Value: test.noteTypeID,
+				},
+			}
+			q := c.Request.URL.Query()
+			q.Add("userID", test.userID)
+			c.Request.URL.RawQuery = q.Encode()
+
+			handler.DeleteNoteType(c)
+
+			assert.Equal(t, test.wantStatusCode, c.Writer.Status())
+		})
+	}
+}
@@ -0,0 +1,44 @@
+package handler
+
+import (
+	"encoding/json"
+
+	"github.com/gin-gonic/gin"
+)
+
+// NextCursorHeader carries the cursor of the next page of a listing. It is
+// not set on the last page.
+const NextCursorHeader = "X-Next-Cursor"
+
+func writeNextCursor(c *gin.Context, next string) {
+	if next != "" {
+		c.Header(NextCursorHeader, next)
+	}
+}
+
+// selectFields reduces each item to the given JSON fields. The id is always
+// kept so that the items can still be referenced.
+func selectFields(items interface{}, fields []string) ([]map[string]interface{}, error) {
+	raw, err := json.Marshal(items)
+	if err != nil {
+		return nil, err
+	}
+
+	var all []map[string]interface{}
+	if err := json.Unmarshal(raw, &all); err != nil {
+		return nil, err
+	}
+
+	selected := make([]map[string]interface{}, 0, len(all))
+	for _, item := range all {
+		projected := map[string]interface{}{"id": item["id"]}
+		for _, field := range fields {
+			if value, ok := item[field]; ok {
+				projected[field] = value
+			}
+		}
+		selected = append(selected, projected)
+	}
+
+	return selected, nil
+}
@@ -0,0 +1,95 @@
+package handler
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/moshrank/spacey-backend/pkg/httpconst"
+	"github.com/moshrank/spacey-backend/pkg/logger"
+	"github.com/moshrank/spacey-backend/services/deck-management-service/entity"
+)
+
+type RevisionHandler struct {
+	logger          logger.LoggerInterface
+	revisionUseCase entity.RevisionUseCaseInterface
+}
+
+type RevisionHandlerInterface interface {
+	GetRevisions(c *gin.Context)
+	RestoreRevision(c *gin.Context)
+	Undo(c *gin.Context)
+}
+
+func NewRevisionHandler(
+	loggerObj logger.LoggerInterface,
+	revisionUseCase entity.RevisionUseCaseInterface,
+) RevisionHandlerInterface {
+	return &RevisionHandler{
+		logger:          loggerObj,
+		revisionUseCase: revisionUseCase,
+	}
+}
+
+func (h *RevisionHandler) GetRevisions(c *gin.Context) {
+	userID := c.Request.URL.Query().Get("userID")
+
+	if userID == "" {
+		httpconst.WriteUnauthorized(c, "missing user id")
+		return
+	}
+
+	deckID := c.Param("deckID")
+	cardID := c.Param("id")
+
+	revisions, err := h.revisionUseCase.GetRevisions(userID, deckID, cardID)
+	if err != nil {
+		if !writeKnownError(c, err) {
+			httpconst.WriteDatabaseError(c)
+		}
+		return
+	}
+
+	httpconst.WriteSuccess(c, revisions)
+}
+
+func (h *RevisionHandler) RestoreRevision(c *gin.Context) {
+	userID := c.Request.URL.Query().Get("userID")
+
+	if userID == "" {
+		httpconst.WriteUnauthorized(c, "missing user id")
+		return
+	}
+
+	deckID := c.Param("deckID")
+	cardID := c.Param("id")
+	revisionID := c.Param("revisionID")
+
+	card, err := h.revisionUseCase.RestoreRevision(userID, deckID, cardID, revisionID)
+	if err != nil {
+		if !writeKnownError(c, err) {
+			httpconst.WriteDatabaseError(c)
+		}
+		return
+	}
+
+	httpconst.WriteSuccess(c, card)
+}
+
+func (h *RevisionHandler) Undo(c *gin.Context) {
+	userID := c.Request.URL.Query().Get("userID")
+
+	if userID == "" {
+		httpconst.WriteUnauthorized(c, "missing user id")
+		return
+	}
+
+	deckID := c.Param("deckID")
+
+	res, err := h.revisionUseCase.Undo(userID, deckID)
+	if err != nil {
+		if !writeKnownError(c, err) {
+			httpconst.WriteDatabaseError(c)
+		}
+		return
+	}
+
+	httpconst.WriteSuccess(c, res)
+}
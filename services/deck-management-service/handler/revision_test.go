@@ -0,0 +1,164 @@
+package handler
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/moshrank/spacey-backend/services/deck-management-service/entity"
+	log "github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type RevisionUseCaseMock struct {
+	mock.Mock
+}
+
+func (u *RevisionUseCaseMock) GetRevisions(
+	userID, deckID, cardID string,
+) ([]entity.RevisionRes, error) {
+	args := u.Called(userID, deckID, cardID)
+	return args.Get(0).([]entity.RevisionRes), args.Error(1)
+}
+
+func (u *RevisionUseCaseMock) RestoreRevision(
+	userID, deckID, cardID, revisionID string,
+) (*entity.CardRes, error) {
+	args := u.Called(userID, deckID, cardID, revisionID)
+	return args.Get(0).(*entity.CardRes), args.Error(1)
+}
+
+func (u *RevisionUseCaseMock) Undo(userID, deckID string) (*entity.UndoRes, error) {
+	args := u.Called(userID, deckID)
+	return args.Get(0).(*entity.UndoRes), args.Error(1)
+}
+
+func TestRestoreRevision(t *testing.T) {
+	tests := []struct {
+		testName       string
+		revisionID     string
+		userID         string
+		wantStatusCode int
+	}{
+		{
+			"Known Revision",
+			"test_revision_id",
+			"test_user_id",
+			200,
+		},
+		{
+			"Unknown Revision",
+			"unknown_revision_id",
+			"test_user_id",
+			404,
+		},
+		{
+			"Missing User ID",
+			"test_revision_id",
+			"",
+			401,
+		},
+	}
+
+	revisionUseCaseMock := new(RevisionUseCaseMock)
+
+	var handler = NewRevisionHandler(log.New(), revisionUseCaseMock)
+
+	revisionUseCaseMock.On(
+		"RestoreRevision",
+		"test_user_id",
+		"test_deck_id",
+		"test_card_id",
+		"test_revision_id",
+	).Return(&entity.CardRes{ID: "test_card_id"}, nil)
+	revisionUseCaseMock.On(
+		"RestoreRevision",
+		"test_user_id",
+		"test_deck_id",
+		"test_card_id",
+		"unknown_revision_id",
+	).Return(&entity.CardRes{}, entity.ErrRevisionNotFound)
+
+	for _, test := range tests {
+
+		t.Run(test.testName, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(w)
+			c.Request, _ = http.NewRequest(
+				"POST",
+				"/decks/:deckID/cards/:id/revisions/:revisionID/restore",
+				nil,
+			)
+			c.Params = []gin.Param{
+				{Key: "deckID", Value: "test_deck_id"},
+				{Key: "id", Value: "test_card_id"},
+				{Key: "revisionID", Value: test.revisionID},
+			}
+
+			q := c.Request.URL.Query()
+			q.Add("userID", test.userID)
+			c.Request.URL.RawQuery = q.Encode()
+
+			handler.RestoreRevision(c)
+
+			assert.Equal(t, test.wantStatusCode, c.Writer.Status())
+		})
+	}
+}
+
+func TestUndo(t *testing.T) {
+	tests := []struct {
+		testName       string
+		deckID         string
+		userID         string
+		wantStatusCode int
+	}{
+		{
+			"Recent Operation",
+			"test_deck_id",
+			"test_user_id",
+			200,
+		},
+		{
+			"Nothing To Undo",
+			"other_deck_id",
+			"test_user_id",
+			404,
+		},
+		{
+			"Missing User ID",
+			"test_deck_id",
+			"",
+			401,
+		},
+	}
+
+	revisionUseCaseMock := new(RevisionUseCaseMock)
+
+	var handler = NewRevisionHandler(log.New(), revisionUseCaseMock)
+
+	revisionUseCaseMock.On("Undo", "test_user_id", "test_deck_id").
+		Return(&entity.UndoRes{Operation: entity.OperationImport, RemovedCards: 3}, nil)
+	revisionUseCaseMock.On("Undo", "test_user_id", "other_deck_id").
+		Return(&entity.UndoRes{}, entity.ErrNothingToUndo)
+
+	for _, test := range tests {
+
+		t.Run(test.testName, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(w)
+			c.Request, _ = http.NewRequest("POST", "/decks/:deckID/undo", nil)
+			c.Params = []gin.Param{{Key: "deckID", Value: test.deckID}}
+
+			q := c.Request.URL.Query()
+			q.Add("userID", test.userID)
+			c.Request.URL.RawQuery = q.Encode()
+
+			handler.Undo(c)
+
+			assert.Equal(t, test.wantStatusCode, c.Writer.Status())
+		})
+	}
+}
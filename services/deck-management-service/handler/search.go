@@ -0,0 +1,52 @@
+package handler
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/moshrank/spacey-backend/pkg/httpconst"
+	"github.com/moshrank/spacey-backend/pkg/logger"
+	"github.com/moshrank/spacey-backend/services/deck-management-service/entity"
+)
+
+type SearchHandler struct {
+	logger        logger.LoggerInterface
+	searchUseCase entity.SearchUseCaseInterface
+}
+
+type SearchHandlerInterface interface {
+	Search(c *gin.Context)
+}
+
+func NewSearchHandler(
+	loggerObj logger.LoggerInterface,
+	searchUseCase entity.SearchUseCaseInterface,
+) SearchHandlerInterface {
+	return &SearchHandler{
+		logger:        loggerObj,
+		searchUseCase: searchUseCase,
+	}
+}
+
+func (h *SearchHandler) Search(c *gin.Context) {
+	userID := c.Request.URL.Query().Get("userID")
+
+	if userID == "" {
+		httpconst.WriteUnauthorized(c, "missing user id")
+		return
+	}
+
+	var req entity.SearchReq
+	if err := c.ShouldBindQuery(&req); err != nil {
+		httpconst.WriteBadRequest(c, err.Error())
+		return
+	}
+
+	res, err := h.searchUseCase.Search(userID, &req)
+	if err != nil {
+		if !writeKnownError(c, err) {
+			httpconst.WriteDatabaseError(c)
+		}
+		return
+	}
+
+	httpconst.WriteSuccess(c, res)
+}
@@ -0,0 +1,88 @@
+package handler
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/moshrank/spacey-backend/services/deck-management-service/entity"
+	log "github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type SearchUseCaseMock struct {
+	mock.Mock
+}
+
+func (u *SearchUseCaseMock) Search(
+	userID string,
+	req *entity.SearchReq,
+) (*entity.SearchRes, error) {
+	args := u.Called(userID, req)
+	return args.Get(0).(*entity.SearchRes), args.Error(1)
+}
+
+func TestSearch(t *testing.T) {
+	tests := []struct {
+		testName       string
+		query          string
+		userID         string
+		wantStatusCode int
+	}{
+		{
+			"Valid Search",
+			"q=verbs&tag=spanish&tag=grammar",
+			"test_user_id",
+			200,
+		},
+		{
+			"Missing Query",
+			"tag=spanish",
+			"test_user_id",
+			400,
+		},
+		{
+			"Foreign Deck",
+			"q=verbs&deckID=foreign_deck_id",
+			"test_user_id",
+			403,
+		},
+		{
+			"Missing User ID",
+			"q=verbs",
+			"",
+			401,
+		},
+	}
+
+	searchUseCaseMock := new(SearchUseCaseMock)
+
+	var handler = NewSearchHandler(log.New(), searchUseCaseMock)
+
+	searchUseCaseMock.On("Search", "test_user_id", &entity.SearchReq{
+		Query: "verbs",
+		Tags:  []string{"spanish", "grammar"},
+	}).Return(&entity.SearchRes{}, nil)
+	searchUseCaseMock.On("Search", "test_user_id", &entity.SearchReq{
+		Query:  "verbs",
+		DeckID: "foreign_deck_id",
+	}).Return(&entity.SearchRes{}, entity.ErrForbidden)
+
+	for _, test := range tests {
+
+		t.Run(test.testName, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(w)
+			c.Request, _ = http.NewRequest("GET", "/search?"+test.query, nil)
+			q := c.Request.URL.Query()
+			q.Add("userID", test.userID)
+			c.Request.URL.RawQuery = q.Encode()
+
+			handler.Search(c)
+
+			assert.Equal(t, test.wantStatusCode, c.Writer.Status())
+		})
+	}
+}
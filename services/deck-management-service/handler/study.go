@@ -0,0 +1,48 @@
+package handler
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/moshrank/spacey-backend/pkg/httpconst"
+	"github.com/moshrank/spacey-backend/pkg/logger"
+	"github.com/moshrank/spacey-backend/services/deck-management-service/entity"
+)
+
+type StudyHandler struct {
+	logger       logger.LoggerInterface
+	studyUseCase entity.StudyUseCaseInterface
+}
+
+type StudyHandlerInterface interface {
+	GetQueue(c *gin.Context)
+}
+
+func NewStudyHandler(
+	loggerObj logger.LoggerInterface,
+	studyUseCase entity.StudyUseCaseInterface,
+) StudyHandlerInterface {
+	return &StudyHandler{
+		logger:       loggerObj,
+		studyUseCase: studyUseCase,
+	}
+}
+
+func (h *StudyHandler) GetQueue(c *gin.Context) {
+	userID := c.Request.URL.Query().Get("userID")
+
+	if userID == "" {
+		httpconst.WriteUnauthorized(c, "missing user id")
+		return
+	}
+
+	deckID := c.Param("deckID")
+	queue, err := h.studyUseCase.GetQueue(userID, deckID)
+	if err != nil {
+		if !writeKnownError(c, err) {
+			h.logger.Error(err)
+			httpconst.WriteInternalServerError(c, "could not load learning queue")
+		}
+		return
+	}
+
+	httpconst.WriteSuccess(c, queue)
+}
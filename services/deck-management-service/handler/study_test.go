@@ -0,0 +1,90 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/moshrank/spacey-backend/services/deck-management-service/entity"
+	log "github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type StudyUseCaseMock struct {
+	mock.Mock
+}
+
+func (u *StudyUseCaseMock) GetQueue(userID, deckID string) ([]entity.StudyCardRes, error) {
+	args := u.Called(userID, deckID)
+	return args.Get(0).([]entity.StudyCardRes), args.Error(1)
+}
+
+func TestGetQueue(t *testing.T) {
+	tests := []struct {
+		testName       string
+		deckID         string
+		userID         string
+		wantStatusCode int
+	}{
+		{
+			"Valid Deck",
+			"test_deck_id",
+			"test_user_id",
+			200,
+		},
+		{
+			"Unknown Deck",
+			"unknown_deck_id",
+			"test_user_id",
+			404,
+		},
+		{
+			"Learning Service Down",
+			"offline_deck_id",
+			"test_user_id",
+			500,
+		},
+		{
+			"Missing User ID",
+			"test_deck_id",
+			"",
+			401,
+		},
+	}
+
+	studyUseCaseMock := new(StudyUseCaseMock)
+
+	var handler = NewStudyHandler(log.New(), studyUseCaseMock)
+
+	studyUseCaseMock.On("GetQueue", "test_user_id", "test_deck_id").
+		Return([]entity.StudyCardRes{}, nil)
+	studyUseCaseMock.On("GetQueue", "test_user_id", "unknown_deck_id").
+		Return([]entity.StudyCardRes(nil), entity.ErrDeckNotFound)
+	studyUseCaseMock.On("GetQueue", "test_user_id", "offline_deck_id").
+		Return([]entity.StudyCardRes(nil), errors.New("learning service responded with status 503"))
+
+	for _, test := range tests {
+
+		t.Run(test.testName, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(w)
+			c.Request, _ = http.NewRequest("GET", "/decks/:deckID/study", nil)
+			c.Params = []gin.Param{
+				{
+					Key:   "deckID",
+					Value: test.deckID,
+				},
+			}
+			q := c.Request.URL.Query()
+			q.Add("userID", test.userID)
+			c.Request.URL.RawQuery = q.Encode()
+
+			handler.GetQueue(c)
+
+			assert.Equal(t, test.wantStatusCode, c.Writer.Status())
+		})
+	}
+}
@@ -0,0 +1,85 @@
+package handler
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/moshrank/spacey-backend/pkg/httpconst"
+	"github.com/moshrank/spacey-backend/pkg/logger"
+	"github.com/moshrank/spacey-backend/pkg/validator"
+	"github.com/moshrank/spacey-backend/services/deck-management-service/entity"
+)
+
+type SyncHandler struct {
+	logger      logger.LoggerInterface
+	syncUseCase entity.SyncUseCaseInterface
+	validator   validator.ValidatorInterface
+}
+
+type SyncHandlerInterface interface {
+	GetChanges(c *gin.Context)
+	PushChanges(c *gin.Context)
+}
+
+func NewSyncHandler(
+	loggerObj logger.LoggerInterface,
+	syncUseCase entity.SyncUseCaseInterface,
+	validatorObj validator.ValidatorInterface,
+) SyncHandlerInterface {
+	return &SyncHandler{
+		logger:      loggerObj,
+		syncUseCase: syncUseCase,
+		validator:   validatorObj,
+	}
+}
+
+func (h *SyncHandler) GetChanges(c *gin.Context) {
+	userID := c.Request.URL.Query().Get("userID")
+
+	if userID == "" {
+		httpconst.WriteUnauthorized(c, "missing user id")
+		return
+	}
+
+	var req entity.SyncReq
+	if err := c.ShouldBindQuery(&req); err != nil {
+		httpconst.WriteBadRequest(c, err.Error())
+		return
+	}
+
+	res, err := h.syncUseCase.GetChanges(userID, &req)
+	if err != nil {
+		if !writeKnownError(c, err) {
+			h.logger.Error(err)
+			httpconst.WriteInternalServerError(c, "could not load changes")
+		}
+		return
+	}
+
+	httpconst.WriteSuccess(c, res)
+}
+
+// PushChanges applies the changes of a client. Changes that conflict or are
+// rejected are reported in the response instead of failing the request.
+func (h *SyncHandler) PushChanges(c *gin.Context) {
+	userID := c.Request.URL.Query().Get("userID")
+
+	if userID == "" {
+		httpconst.WriteUnauthorized(c, "missing user id")
+		return
+	}
+
+	var req entity.SyncPushReq
+	if err := h.validator.ValidateJSON(c, &req); err != nil {
+		return
+	}
+
+	res, err := h.syncUseCase.PushChanges(userID, &req)
+	if err != nil {
+		if !writeKnownError(c, err) {
+			h.logger.Error(err)
+			httpconst.WriteInternalServerError(c, "could not apply changes")
+		}
+		return
+	}
+
+	httpconst.WriteSuccess(c, res)
+}
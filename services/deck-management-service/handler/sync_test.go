@@ -0,0 +1,176 @@
+package handler
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/moshrank/spacey-backend/pkg/validator"
+	"github.com/moshrank/spacey-backend/services/deck-management-service/entity"
+	log "github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type SyncUseCaseMock struct {
+	mock.Mock
+}
+
+func (u *SyncUseCaseMock) GetChanges(userID string, req *entity.SyncReq) (*entity.SyncRes, error) {
+	args := u.Called(userID, req)
+	return args.Get(0).(*entity.SyncRes), args.Error(1)
+}
+
+func (u *SyncUseCaseMock) PushChanges(
+	userID string,
+	req *entity.SyncPushReq,
+) (*entity.SyncPushRes, error) {
+	args := u.Called(userID, req)
+	return args.Get(0).(*entity.SyncPushRes), args.Error(1)
+}
+
+func TestGetChanges(t *testing.T) {
+	tests := []struct {
+		testName       string
+		since          string
+		userID         string
+		wantStatusCode int
+	}{
+		{
+			"All Changes",
+			"",
+			"test_user_id",
+			200,
+		},
+		{
+			"Changes Since Cursor",
+			"cursor",
+			"test_user_id",
+			200,
+		},
+		{
+			"Invalid Cursor",
+			"invalid",
+			"test_user_id",
+			400,
+		},
+		{
+			"Missing User ID",
+			"",
+			"",
+			401,
+		},
+	}
+
+	syncUseCaseMock := new(SyncUseCaseMock)
+
+	var handler = NewSyncHandler(log.New(), syncUseCaseMock, validator.NewValidator())
+
+	syncUseCaseMock.On("GetChanges", "test_user_id", &entity.SyncReq{Since: "invalid"}).
+		Return((*entity.SyncRes)(nil), entity.ErrInvalidCursor)
+	syncUseCaseMock.On("GetChanges", "test_user_id", mock.Anything).
+		Return(&entity.SyncRes{Cursor: "next"}, nil)
+
+	for _, test := range tests {
+
+		t.Run(test.testName, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(w)
+			c.Request, _ = http.NewRequest("GET", "/sync", nil)
+
+			q := c.Request.URL.Query()
+			q.Add("userID", test.userID)
+			if test.since != "" {
+				q.Add("since", test.since)
+			}
+			c.Request.URL.RawQuery = q.Encode()
+
+			handler.GetChanges(c)
+
+			assert.Equal(t, test.wantStatusCode, c.Writer.Status())
+		})
+	}
+}
+
+func TestPushChanges(t *testing.T) {
+	tests := []struct {
+		testName       string
+		body           string
+		userID         string
+		wantStatusCode int
+	}{
+		{
+			"Changes",
+			`{
+				"decks": [{"clientID": "d1", "deck": {"name": "Verbs", "color": "#fff"}}],
+				"cards": [
+					{
+						"clientID": "c1",
+						"deckID": "d1",
+						"card": {"question": "Q", "answer": "A", "deckID": "d1"}
+					},
+					{"id": "card_2", "deckID": "1", "version": 3, "deleted": true}
+				],
+				"reviews": [{"deckID": "d1", "cardID": "c1", "reviewedAt": "2022-03-01T12:00:00Z"}]
+			}`,
+			"test_user_id",
+			200,
+		},
+		{
+			"Update Without Version",
+			`{"decks": [{"id": "1", "deck": {"name": "Verbs", "color": "#fff"}}]}`,
+			"test_user_id",
+			400,
+		},
+		{
+			"Create Without Client ID",
+			`{"cards": [{"deckID": "1", "card": {"question": "Q", "answer": "A", "deckID": "1"}}]}`,
+			"test_user_id",
+			400,
+		},
+		{
+			"Update Without Card",
+			`{"cards": [{"id": "card_2", "deckID": "1", "version": 3}]}`,
+			"test_user_id",
+			400,
+		},
+		{
+			"Invalid Deck",
+			`{"decks": [{"clientID": "d1", "deck": {"color": "#fff"}}]}`,
+			"test_user_id",
+			400,
+		},
+		{
+			"Missing User ID",
+			`{}`,
+			"",
+			401,
+		},
+	}
+
+	syncUseCaseMock := new(SyncUseCaseMock)
+
+	var handler = NewSyncHandler(log.New(), syncUseCaseMock, validator.NewValidator())
+
+	syncUseCaseMock.On("PushChanges", "test_user_id", mock.Anything).
+		Return(&entity.SyncPushRes{}, nil)
+
+	for _, test := range tests {
+
+		t.Run(test.testName, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(w)
+			c.Request, _ = http.NewRequest("POST", "/sync", bytes.NewBuffer([]byte(test.body)))
+
+			q := c.Request.URL.Query()
+			q.Add("userID", test.userID)
+			c.Request.URL.RawQuery = q.Encode()
+
+			handler.PushChanges(c)
+
+			assert.Equal(t, test.wantStatusCode, c.Writer.Status())
+		})
+	}
+}
@@ -0,0 +1,191 @@
+package handler
+
+import (
+	"fmt"
+	"net/http"
+	"path"
+
+	"github.com/gin-gonic/gin"
+	"github.com/moshrank/spacey-backend/pkg/httpconst"
+	"github.com/moshrank/spacey-backend/pkg/logger"
+	"github.com/moshrank/spacey-backend/services/deck-management-service/entity"
+)
+
+const maxImportSize = 256 << 20
+
+type TransferHandler struct {
+	logger          logger.LoggerInterface
+	transferUseCase entity.TransferUseCaseInterface
+}
+
+type TransferHandlerInterface interface {
+	ImportAnki(c *gin.Context)
+	ExportAnki(c *gin.Context)
+	ImportCards(c *gin.Context)
+	ExportCards(c *gin.Context)
+}
+
+func NewTransferHandler(
+	loggerObj logger.LoggerInterface,
+	transferUseCase entity.TransferUseCaseInterface,
+) TransferHandlerInterface {
+	return &TransferHandler{
+		logger:          loggerObj,
+		transferUseCase: transferUseCase,
+	}
+}
+
+func (h *TransferHandler) ImportAnki(c *gin.Context) {
+	userID := c.Request.URL.Query().Get("userID")
+
+	if userID == "" {
+		httpconst.WriteUnauthorized(c, "missing user id")
+		return
+	}
+
+	var duplicates entity.DuplicateReq
+	if err := c.ShouldBindQuery(&duplicates); err != nil {
+		httpconst.WriteBadRequest(c, err.Error())
+		return
+	}
+
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, maxImportSize)
+
+	fileHeader, err := c.FormFile("file")
+	if err != nil {
+		httpconst.WriteBadRequest(c, "missing anki package")
+		return
+	}
+
+	file, err := fileHeader.Open()
+	if err != nil {
+		httpconst.WriteBadRequest(c, "could not read anki package")
+		return
+	}
+	defer file.Close()
+
+	history := c.Query("history") == "true"
+
+	res, err := h.transferUseCase.ImportAnki(userID, file, fileHeader.Size, history, &duplicates)
+	if err != nil {
+		if !writeKnownError(c, err) {
+			h.logger.Error(err)
+			httpconst.WriteInternalServerError(c, "could not import anki package")
+		}
+		return
+	}
+
+	httpconst.WriteCreated(c, res)
+}
+
+func writeExportFile(c *gin.Context, file *entity.ExportFile) {
+	c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=%q", file.Name))
+	c.Data(http.StatusOK, file.ContentType, file.Content)
+}
+
+func (h *TransferHandler) ExportAnki(c *gin.Context) {
+	userID := c.Request.URL.Query().Get("userID")
+
+	if userID == "" {
+		httpconst.WriteUnauthorized(c, "missing user id")
+		return
+	}
+
+	deckID := c.Param("deckID")
+	scheduling := c.Query("scheduling") == "true"
+
+	file, err := h.transferUseCase.ExportAnki(userID, deckID, scheduling)
+	if err != nil {
+		if !writeKnownError(c, err) {
+			h.logger.Error(err)
+			httpconst.WriteInternalServerError(c, "could not export deck")
+		}
+		return
+	}
+
+	writeExportFile(c, file)
+}
+
+func (h *TransferHandler) ImportCards(c *gin.Context) {
+	userID := c.Request.URL.Query().Get("userID")
+
+	if userID == "" {
+		httpconst.WriteUnauthorized(c, "missing user id")
+		return
+	}
+
+	var req entity.CardImportReq
+	if err := c.ShouldBindQuery(&req); err != nil {
+		httpconst.WriteBadRequest(c, err.Error())
+		return
+	}
+
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, maxImportSize)
+
+	fileHeader, err := c.FormFile("file")
+	if err != nil {
+		httpconst.WriteBadRequest(c, "missing file")
+		return
+	}
+
+	file, err := fileHeader.Open()
+	if err != nil {
+		httpconst.WriteBadRequest(c, "could not read file")
+		return
+	}
+	defer file.Close()
+
+	deckID := c.Param("deckID")
+
+	res, err := h.transferUseCase.ImportCards(userID, deckID, file, &req)
+	if err != nil {
+		if !writeKnownError(c, err) {
+			h.logger.Error(err)
+			httpconst.WriteInternalServerError(c, "could not import cards")
+		}
+		return
+	}
+
+	if req.DryRun {
+		httpconst.WriteSuccess(c, res)
+		return
+	}
+
+	httpconst.WriteCreated(c, res)
+}
+
+var exportFormats = map[string]string{
+	".csv": entity.FormatCSV,
+	".tsv": entity.FormatTSV,
+	".md":  entity.FormatMarkdown,
+}
+
+// ExportCards exports a deck in the format given by the file extension of
+// the requested path, e.g. /decks/:deckID/export.csv.
+func (h *TransferHandler) ExportCards(c *gin.Context) {
+	userID := c.Request.URL.Query().Get("userID")
+
+	if userID == "" {
+		httpconst.WriteUnauthorized(c, "missing user id")
+		return
+	}
+
+	fileFormat, ok := exportFormats[path.Ext(c.Request.URL.Path)]
+	if !ok {
+		httpconst.WriteBadRequest(c, entity.ErrUnknownFormat.Error())
+		return
+	}
+
+	deckID := c.Param("deckID")
+
+	file, err := h.transferUseCase.ExportCards(userID, deckID, fileFormat)
+	if err != nil {
+		if !writeKnownError(c, err) {
+			h.logger.Error(err)
+			httpconst.WriteInternalServerError(c, "could not export deck")
+		}
+		return
+	}
+
+	writeExportFile(c, file)
+}
@@ -0,0 +1,300 @@
+package handler
+
+import (
+	"bytes"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/moshrank/spacey-backend/services/deck-management-service/entity"
+	"github.com/moshrank/spacey-backend/services/deck-management-service/format"
+	log "github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type TransferUseCaseMock struct {
+	mock.Mock
+}
+
+func (u *TransferUseCaseMock) ImportAnki(
+	userID string,
+	file io.ReaderAt,
+	size int64,
+	history bool,
+	duplicates *entity.DuplicateReq,
+) (*entity.AnkiImportRes, error) {
+	args := u.Called(userID, file, size, history, duplicates)
+	return args.Get(0).(*entity.AnkiImportRes), args.Error(1)
+}
+
+func (u *TransferUseCaseMock) ExportAnki(
+	userID, deckID string,
+	scheduling bool,
+) (*entity.ExportFile, error) {
+	args := u.Called(userID, deckID, scheduling)
+	return args.Get(0).(*entity.ExportFile), args.Error(1)
+}
+
+func (u *TransferUseCaseMock) ImportCards(
+	userID, deckID string,
+	file io.Reader,
+	req *entity.CardImportReq,
+) (*entity.CardImportRes, error) {
+	args := u.Called(userID, deckID, file, req)
+	return args.Get(0).(*entity.CardImportRes), args.Error(1)
+}
+
+func (u *TransferUseCaseMock) ExportCards(
+	userID, deckID, fileFormat string,
+) (*entity.ExportFile, error) {
+	args := u.Called(userID, deckID, fileFormat)
+	return args.Get(0).(*entity.ExportFile), args.Error(1)
+}
+
+func TestImportAnki(t *testing.T) {
+	tests := []struct {
+		testName       string
+		userID         string
+		withFile       bool
+		wantStatusCode int
+	}{
+		{
+			"Valid Package",
+			"test_user_id",
+			true,
+			201,
+		},
+		{
+			"Invalid Package",
+			"invalid_user_id",
+			true,
+			400,
+		},
+		{
+			"Missing File",
+			"test_user_id",
+			false,
+			400,
+		},
+		{
+			"Missing User ID",
+			"",
+			true,
+			401,
+		},
+	}
+
+	transferUseCaseMock := new(TransferUseCaseMock)
+
+	var handler = NewTransferHandler(log.New(), transferUseCaseMock)
+
+	transferUseCaseMock.On(
+		"ImportAnki",
+		"test_user_id",
+		mock.Anything,
+		mock.Anything,
+		true,
+		&entity.DuplicateReq{Duplicates: entity.DuplicateScopeDeck},
+	).Return(&entity.AnkiImportRes{}, nil)
+	transferUseCaseMock.On(
+		"ImportAnki",
+		"invalid_user_id",
+		mock.Anything,
+		mock.Anything,
+		true,
+		mock.Anything,
+	).Return(&entity.AnkiImportRes{}, format.ErrInvalidAnkiPackage)
+
+	for _, test := range tests {
+
+		t.Run(test.testName, func(t *testing.T) {
+			var body bytes.Buffer
+			form := multipart.NewWriter(&body)
+			if test.withFile {
+				file, _ := form.CreateFormFile("file", "deck.apkg")
+				file.Write([]byte("package"))
+			}
+			form.Close()
+
+			w := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(w)
+			c.Request, _ = http.NewRequest("POST", "/decks/import/anki", &body)
+			c.Request.Header.Set("Content-Type", form.FormDataContentType())
+			q := c.Request.URL.Query()
+			q.Add("userID", test.userID)
+			q.Add("history", "true")
+			c.Request.URL.RawQuery = q.Encode()
+
+			handler.ImportAnki(c)
+
+			assert.Equal(t, test.wantStatusCode, c.Writer.Status())
+		})
+	}
+}
+
+func TestExportAnki(t *testing.T) {
+	transferUseCaseMock := new(TransferUseCaseMock)
+
+	var handler = NewTransferHandler(log.New(), transferUseCaseMock)
+
+	transferUseCaseMock.On("ExportAnki", "test_user_id", "test_deck_id", true).
+		Return(&entity.ExportFile{
+			Name:        "Spanish.apkg",
+			ContentType: "application/apkg",
+			Content:     []byte("package"),
+		}, nil)
+
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request, _ = http.NewRequest("GET", "/decks/:deckID/export.apkg", nil)
+	c.Params = []gin.Param{
+		{
+			Key:   "deckID",
+			Value: "test_deck_id",
+		},
+	}
+	q := c.Request.URL.Query()
+	q.Add("userID", "test_user_id")
+	q.Add("scheduling", "true")
+	c.Request.URL.RawQuery = q.Encode()
+
+	handler.ExportAnki(c)
+
+	assert.Equal(t, 200, w.Code)
+	assert.Equal(t, `attachment; filename="Spanish.apkg"`, w.Header().Get("Content-Disposition"))
+	assert.Equal(t, "package", w.Body.String())
+}
+
+func TestImportCards(t *testing.T) {
+	tests := []struct {
+		testName       string
+		query          string
+		wantStatusCode int
+	}{
+		{
+			"Import",
+			"format=csv&header=true&question=Front&answer=Back",
+			201,
+		},
+		{
+			"Dry Run",
+			"format=markdown&dryRun=true",
+			200,
+		},
+		{
+			"Unknown Format",
+			"format=xlsx",
+			400,
+		},
+		{
+			"Missing Format",
+			"",
+			400,
+		},
+	}
+
+	transferUseCaseMock := new(TransferUseCaseMock)
+
+	var handler = NewTransferHandler(log.New(), transferUseCaseMock)
+
+	transferUseCaseMock.
+		On("ImportCards", "test_user_id", "test_deck_id", mock.Anything, mock.Anything).
+		Return(&entity.CardImportRes{}, nil)
+
+	for _, test := range tests {
+
+		t.Run(test.testName, func(t *testing.T) {
+			var body bytes.Buffer
+			form := multipart.NewWriter(&body)
+			file, _ := form.CreateFormFile("file", "cards.csv")
+			file.Write([]byte("Front,Back\nhablar,to speak\n"))
+			form.Close()
+
+			w := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(w)
+			c.Request, _ = http.NewRequest(
+				"POST",
+				"/decks/:deckID/import?userID=test_user_id&"+test.query,
+				&body,
+			)
+			c.Request.Header.Set("Content-Type", form.FormDataContentType())
+			c.Params = []gin.Param{
+				{
+					Key:   "deckID",
+					Value: "test_deck_id",
+				},
+			}
+
+			handler.ImportCards(c)
+
+			assert.Equal(t, test.wantStatusCode, c.Writer.Status())
+		})
+	}
+}
+
+func TestExportCards(t *testing.T) {
+	tests := []struct {
+		testName       string
+		path           string
+		wantFormat     string
+		wantStatusCode int
+	}{
+		{
+			"CSV",
+			"/decks/test_deck_id/export.csv",
+			entity.FormatCSV,
+			200,
+		},
+		{
+			"Markdown",
+			"/decks/test_deck_id/export.md",
+			entity.FormatMarkdown,
+			200,
+		},
+		{
+			"Unknown Format",
+			"/decks/test_deck_id/export.xlsx",
+			"",
+			400,
+		},
+	}
+
+	transferUseCaseMock := new(TransferUseCaseMock)
+
+	var handler = NewTransferHandler(log.New(), transferUseCaseMock)
+
+	transferUseCaseMock.On("ExportCards", "test_user_id", "test_deck_id", mock.Anything).
+		Return(&entity.ExportFile{Name: "deck", ContentType: "text/plain"}, nil)
+
+	for _, test := range tests {
+
+		t.Run(test.testName, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(w)
+			c.Request, _ = http.NewRequest("GET", test.path+"?userID=test_user_id", nil)
+			c.Params = []gin.Param{
+				{
+					Key:   "deckID",
+					Value: "test_deck_id",
+				},
+			}
+
+			handler.ExportCards(c)
+
+			assert.Equal(t, test.wantStatusCode, w.Code)
+			if test.wantFormat != "" {
+				transferUseCaseMock.AssertCalled(
+					t,
+					"ExportCards",
+					"test_user_id",
+					"test_deck_id",
+					test.wantFormat,
+				)
+			}
+		})
+	}
+}
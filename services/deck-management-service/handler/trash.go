@@ -0,0 +1,86 @@
+package handler
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/moshrank/spacey-backend/pkg/httpconst"
+	"github.com/moshrank/spacey-backend/pkg/logger"
+	"github.com/moshrank/spacey-backend/services/deck-management-service/entity"
+)
+
+type TrashHandler struct {
+	logger       logger.LoggerInterface
+	trashUseCase entity.TrashUseCaseInterface
+}
+
+type TrashHandlerInterface interface {
+	GetTrash(c *gin.Context)
+	RestoreDeck(c *gin.Context)
+	RestoreCard(c *gin.Context)
+}
+
+func NewTrashHandler(
+	loggerObj logger.LoggerInterface,
+	trashUseCase entity.TrashUseCaseInterface,
+) TrashHandlerInterface {
+	return &TrashHandler{
+		logger:       loggerObj,
+		trashUseCase: trashUseCase,
+	}
+}
+
+func (h *TrashHandler) GetTrash(c *gin.Context) {
+	userID := c.Request.URL.Query().Get("userID")
+
+	if userID == "" {
+		httpconst.WriteUnauthorized(c, "missing user id")
+		return
+	}
+
+	trash, err := h.trashUseCase.GetTrash(userID)
+	if err != nil {
+		httpconst.WriteDatabaseError(c)
+		return
+	}
+
+	httpconst.WriteSuccess(c, trash)
+}
+
+func (h *TrashHandler) RestoreDeck(c *gin.Context) {
+	userID := c.Request.URL.Query().Get("userID")
+
+	if userID == "" {
+		httpconst.WriteUnauthorized(c, "missing user id")
+		return
+	}
+
+	deckID := c.Param("deckID")
+	if err := h.trashUseCase.RestoreDeck(userID, deckID); err != nil {
+		if !writeKnownError(c, err) {
+			httpconst.WriteDatabaseError(c)
+		}
+		return
+	}
+
+	httpconst.WriteSuccess(c, map[string]string{"Message": "Deck restored"})
+}
+
+func (h *TrashHandler) RestoreCard(c *gin.Context) {
+	userID := c.Request.URL.Query().Get("userID")
+
+	if userID == "" {
+		httpconst.WriteUnauthorized(c, "missing user id")
+		return
+	}
+
+	deckID := c.Param("deckID")
+	cardID := c.Param("id")
+
+	if err := h.trashUseCase.RestoreCard(userID, deckID, cardID); err != nil {
+		if !writeKnownError(c, err) {
+			httpconst.WriteDatabaseError(c)
+		}
+		return
+	}
+
+	httpconst.WriteSuccess(c, map[string]string{"Message": "Card restored"})
+}
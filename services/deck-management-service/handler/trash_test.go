@@ -0,0 +1,95 @@
+package handler
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/moshrank/spacey-backend/services/deck-management-service/entity"
+	log "github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type TrashUseCaseMock struct {
+	mock.Mock
+}
+
+func (u *TrashUseCaseMock) GetTrash(userID string) (*entity.TrashRes, error) {
+	args := u.Called(userID)
+	return args.Get(0).(*entity.TrashRes), args.Error(1)
+}
+
+func (u *TrashUseCaseMock) RestoreDeck(userID, deckID string) error {
+	args := u.Called(userID, deckID)
+	return args.Error(0)
+}
+
+func (u *TrashUseCaseMock) RestoreCard(userID, deckID, cardID string) error {
+	args := u.Called(userID, deckID, cardID)
+	return args.Error(0)
+}
+
+func (u *TrashUseCaseMock) Purge() (int64, error) {
+	args := u.Called()
+	return args.Get(0).(int64), args.Error(1)
+}
+
+func TestRestoreDeck(t *testing.T) {
+	tests := []struct {
+		testName       string
+		deckID         string
+		userID         string
+		wantStatusCode int
+	}{
+		{
+			"Deleted Deck",
+			"deleted_deck_id",
+			"test_user_id",
+			200,
+		},
+		{
+			"Deck Not In Trash",
+			"test_deck_id",
+			"test_user_id",
+			404,
+		},
+		{
+			"Missing User ID",
+			"deleted_deck_id",
+			"",
+			401,
+		},
+	}
+
+	trashUseCaseMock := new(TrashUseCaseMock)
+
+	var handler = NewTrashHandler(log.New(), trashUseCaseMock)
+
+	trashUseCaseMock.On("RestoreDeck", "test_user_id", "deleted_deck_id").Return(nil)
+	trashUseCaseMock.On("RestoreDeck", "test_user_id", "test_deck_id").
+		Return(entity.ErrDeckNotFound)
+
+	for _, test := range tests {
+
+		t.Run(test.testName, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(w)
+			c.Request, _ = http.NewRequest("POST", "/decks/:deckID/restore", nil)
+			c.Params = []gin.Param{
+				{
+					Key:   "deckID",
+					Value: test.deckID,
+				},
+			}
+			q := c.Request.URL.Query()
+			q.Add("userID", test.userID)
+			c.Request.URL.RawQuery = q.Encode()
+
+			handler.RestoreDeck(c)
+
+			assert.Equal(t, test.wantStatusCode, c.Writer.Status())
+		})
+	}
+}
@@ -0,0 +1,66 @@
+package handler
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/moshrank/spacey-backend/services/deck-management-service/entity"
+)
+
+const (
+	// ETagHeader holds the version of a deck or card.
+	ETagHeader = "ETag"
+	// IfMatchHeader holds the versions a change or deletion is limited to.
+	IfMatchHeader = "If-Match"
+)
+
+func writeETag(c *gin.Context, version int) {
+	if version > 0 {
+		c.Header(ETagHeader, strconv.Quote(strconv.Itoa(version)))
+	}
+}
+
+// ifMatch parses the versions of the If-Match header. A missing header or
+// "*" matches every version. If-Match compares tags strongly, so weak tags
+// never match, and neither do tags that name no version of this service. A
+// header with only such tags fails with 412 Precondition Failed like a
+// changed version does, while a header that is no list of entity tags is
+// rejected.
+func ifMatch(c *gin.Context) (entity.IfMatch, error) {
+	header := strings.TrimSpace(c.GetHeader(IfMatchHeader))
+	if header == "" || header == "*" {
+		return nil, nil
+	}
+
+	versions := entity.IfMatch{}
+	rest := header
+	for {
+		// lists may contain empty elements
+		rest = strings.TrimLeft(rest, ", \t")
+		if rest == "" {
+			return versions, nil
+		}
+
+		weak := strings.HasPrefix(rest, "W/")
+		rest = strings.TrimPrefix(rest, "W/")
+		if !strings.HasPrefix(rest, `"`) {
+			return nil, entity.ErrInvalidIfMatch
+		}
+
+		end := strings.Index(rest[1:], `"`) + 1
+		if end == 0 {
+			return nil, entity.ErrInvalidIfMatch
+		}
+
+		tag := rest[1:end]
+		rest = strings.TrimLeft(rest[end+1:], " \t")
+		if rest != "" && rest[0] != ',' {
+			return nil, entity.ErrInvalidIfMatch
+		}
+
+		if version, err := strconv.Atoi(tag); err == nil && !weak {
+			versions = append(versions, version)
+		}
+	}
+}
@@ -0,0 +1,48 @@
+package handler
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/moshrank/spacey-backend/services/deck-management-service/entity"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIfMatch(t *testing.T) {
+	tests := []struct {
+		testName     string
+		header       string
+		wantVersions entity.IfMatch
+		wantErr      error
+	}{
+		{"Missing", "", nil, nil},
+		{"Any Version", "*", nil, nil},
+		{"Version", `"3"`, entity.IfMatch{3}, nil},
+		{"Weak Tag", `W/"3"`, entity.IfMatch{}, nil},
+		{"Foreign Tag", `"xyzzy"`, entity.IfMatch{}, nil},
+		{"List", `"1", W/"2", "xyzzy", "4"`, entity.IfMatch{1, 4}, nil},
+		{"Empty List Elements", ` , "1",, "2" ,`, entity.IfMatch{1, 2}, nil},
+		{"Comma In Tag", `"1,2", "3"`, entity.IfMatch{3}, nil},
+		{"Unquoted", "3", nil, entity.ErrInvalidIfMatch},
+		{"Unterminated", `"3`, nil, entity.ErrInvalidIfMatch},
+		{"Missing Comma", `"1" "2"`, nil, entity.ErrInvalidIfMatch},
+		{"Any Version In List", `*, "3"`, nil, entity.ErrInvalidIfMatch},
+	}
+
+	for _, test := range tests {
+		t.Run(test.testName, func(t *testing.T) {
+			c, _ := gin.CreateTestContext(httptest.NewRecorder())
+			c.Request, _ = http.NewRequest("DELETE", "/decks/:deckID", nil)
+			if test.header != "" {
+				c.Request.Header.Set(IfMatchHeader, test.header)
+			}
+
+			versions, err := ifMatch(c)
+
+			assert.Equal(t, test.wantErr, err)
+			assert.Equal(t, test.wantVersions, versions)
+		})
+	}
+}
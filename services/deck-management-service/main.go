@@ -9,6 +9,7 @@ import (
 	"github.com/moshrank/spacey-backend/pkg/logger"
 	"github.com/moshrank/spacey-backend/pkg/middleware"
 	"github.com/moshrank/spacey-backend/pkg/validator"
+	"github.com/moshrank/spacey-backend/services/deck-management-service/external"
 	"github.com/moshrank/spacey-backend/services/deck-management-service/handler"
 	"github.com/moshrank/spacey-backend/services/deck-management-service/store.go"
 	"github.com/moshrank/spacey-backend/services/deck-management-service/usecase"
@@ -21,6 +22,18 @@ func runServer(
 	lifecycle fx.Lifecycle,
 	cardHandler handler.CardHandlerInterface,
 	deckHandler handler.DeckHandlerInterface,
+	memberHandler handler.MemberHandlerInterface,
+	transferHandler handler.TransferHandlerInterface,
+	searchHandler handler.SearchHandlerInterface,
+	trashHandler handler.TrashHandlerInterface,
+	revisionHandler handler.RevisionHandlerInterface,
+	bulkHandler handler.BulkHandlerInterface,
+	mediaHandler handler.MediaHandlerInterface,
+	studyHandler handler.StudyHandlerInterface,
+	syncHandler handler.SyncHandlerInterface,
+	noteTypeHandler handler.NoteTypeHandlerInterface,
+	activityHandler handler.ActivityHandlerInterface,
+	lintHandler handler.LintHandlerInterface,
 	cfg config.ConfigInterface,
 	log logger.LoggerInterface,
 ) {
@@ -34,15 +47,63 @@ func runServer(
 		router.GET("ping", heha.Ping)
 
 		router.GET("decks", deckHandler.GetDecks)
+		router.GET("decks/tree", deckHandler.GetDeckTree)
 		router.GET("decks/:deckID", deckHandler.GetDeck)
 		router.POST("decks", deckHandler.CreateDeck)
 		router.PUT("decks/:deckID", deckHandler.UpdateDeck)
 		router.DELETE("decks/:deckID", deckHandler.DeleteDeck)
+		router.POST("decks/:deckID/fork", deckHandler.ForkDeck)
+		router.POST("decks/:deckID/pull", deckHandler.PullDeck)
+		router.POST("decks/:deckID/move", deckHandler.MoveDeck)
+		router.GET("decks/:deckID/study", studyHandler.GetQueue)
+		router.POST("decks/:deckID/restore", trashHandler.RestoreDeck)
+		router.POST("decks/:deckID/undo", revisionHandler.Undo)
+		router.GET("decks/:deckID/activity", activityHandler.GetActivity)
+		router.POST("decks/:deckID/lint", lintHandler.LintDeck)
+		router.GET("decks/:deckID/lint/settings", lintHandler.GetLintSettings)
+		router.PUT("decks/:deckID/lint/settings", lintHandler.UpdateLintSettings)
+		router.POST("decks/:deckID/bulk", bulkHandler.BulkCards)
+		router.POST("decks/:deckID/replace", bulkHandler.FindReplace)
+		router.POST("decks/:deckID/media", mediaHandler.Upload)
+		router.GET("decks/:deckID/media/:mediaID", mediaHandler.GetMedia)
+		router.POST("decks/import/anki", transferHandler.ImportAnki)
+		router.GET("decks/:deckID/export.apkg", transferHandler.ExportAnki)
+		router.POST("decks/:deckID/import", transferHandler.ImportCards)
+		router.GET("decks/:deckID/export.csv", transferHandler.ExportCards)
+		router.GET("decks/:deckID/export.tsv", transferHandler.ExportCards)
+		router.GET("decks/:deckID/export.md", transferHandler.ExportCards)
+
+		router.GET("decks/:deckID/members", memberHandler.GetMembers)
+		router.POST("decks/:deckID/members", memberHandler.InviteMember)
+		router.POST("decks/:deckID/members/accept", memberHandler.AcceptInvitation)
+		router.PUT("decks/:deckID/members/:memberID", memberHandler.UpdateMember)
+		router.DELETE("decks/:deckID/members/:memberID", memberHandler.RemoveMember)
+		router.GET("invitations", memberHandler.GetInvitations)
 
 		router.POST("decks/:deckID/card", cardHandler.CreateCard)
+		router.GET("decks/:deckID/cards", cardHandler.GetCards)
 		router.POST("decks/:deckID/cards", cardHandler.CreateCards)
 		router.PUT("decks/:deckID/cards/:id", cardHandler.UpdateCard)
 		router.DELETE("decks/:deckID/cards/:id", cardHandler.DeleteCard)
+		router.POST("decks/:deckID/cards/:id/restore", trashHandler.RestoreCard)
+		router.GET("decks/:deckID/cards/:id/revisions", revisionHandler.GetRevisions)
+		router.POST(
+			"decks/:deckID/cards/:id/revisions/:revisionID/restore",
+			revisionHandler.RestoreRevision,
+		)
+
+		router.GET("note-types", noteTypeHandler.GetNoteTypes)
+		router.GET("note-types/:noteTypeID", noteTypeHandler.GetNoteType)
+		router.POST("note-types", noteTypeHandler.CreateNoteType)
+		router.PUT("note-types/:noteTypeID", noteTypeHandler.UpdateNoteType)
+		router.DELETE("note-types/:noteTypeID", noteTypeHandler.DeleteNoteType)
+
+		router.GET("trash", trashHandler.GetTrash)
+
+		router.GET("search", searchHandler.Search)
+
+		router.GET("sync", syncHandler.GetChanges)
+		router.POST("sync", syncHandler.PushChanges)
 
 		log.Info("Starting server on port: " + port)
 		router.Run(":" + port)
@@ -59,10 +120,47 @@ func main() {
 		fx.Provide(validator.NewValidator),
 		fx.Provide(store.NewDeckStore),
 		fx.Provide(store.NewCardStore),
+		fx.Provide(store.NewUndoStore),
+		fx.Provide(store.NewMediaStore),
+		fx.Provide(store.NewNoteTypeStore),
+		fx.Provide(store.NewActivityStore),
+		fx.Provide(store.NewLocalBlobStore),
+		fx.Provide(external.NewUserService),
+		fx.Provide(external.NewLearningService),
+		fx.Provide(external.NewConfigService),
+		fx.Provide(usecase.NewPalette),
 		fx.Provide(usecase.NewCardUseCase),
 		fx.Provide(usecase.NewDeckUseCase),
+		fx.Provide(usecase.NewMemberUseCase),
+		fx.Provide(usecase.NewTransferUseCase),
+		fx.Provide(usecase.NewSearchUseCase),
+		fx.Provide(usecase.NewTrashUseCase),
+		fx.Provide(usecase.NewRevisionUseCase),
+		fx.Provide(usecase.NewBulkUseCase),
+		fx.Provide(usecase.NewMediaUseCase),
+		fx.Provide(usecase.NewStudyUseCase),
+		fx.Provide(usecase.NewSyncUseCase),
+		fx.Provide(usecase.NewNoteTypeUseCase),
+		fx.Provide(usecase.NewActivityUseCase),
+		fx.Provide(usecase.NewLintUseCase),
 		fx.Provide(handler.NewCardHandler),
 		fx.Provide(handler.NewDeckHandler),
+		fx.Provide(handler.NewMemberHandler),
+		fx.Provide(handler.NewTransferHandler),
+		fx.Provide(handler.NewSearchHandler),
+		fx.Provide(handler.NewTrashHandler),
+		fx.Provide(handler.NewRevisionHandler),
+		fx.Provide(handler.NewBulkHandler),
+		fx.Provide(handler.NewMediaHandler),
+		fx.Provide(handler.NewStudyHandler),
+		fx.Provide(handler.NewSyncHandler),
+		fx.Provide(handler.NewNoteTypeHandler),
+		fx.Provide(handler.NewActivityHandler),
+		fx.Provide(handler.NewLintHandler),
+		// registered first, since the server blocks in its start hook
+		fx.Invoke(runTrashPurge),
+		fx.Invoke(runPaletteRefresh),
+		fx.Invoke(runContentBackfill),
 		fx.Invoke(runServer),
 	).Start(context.TODO())
 }
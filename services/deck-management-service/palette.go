@@ -0,0 +1,87 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/moshrank/spacey-backend/pkg/logger"
+	"github.com/moshrank/spacey-backend/services/deck-management-service/entity"
+
+	"go.uber.org/fx"
+)
+
+const paletteRefreshInterval = 10 * time.Minute
+
+// runPaletteRefresh loads the deck colors from the config service at startup
+// and keeps them up to date. The service does not start without them, since
+// deck colors can not be checked. Whenever the colors change, the decks whose
+// color is not among them are reported.
+func runPaletteRefresh(
+	lifecycle fx.Lifecycle,
+	palette entity.PaletteInterface,
+	log logger.LoggerInterface,
+) {
+	var ticker *time.Ticker
+	done := make(chan struct{})
+
+	reportInvalidDecks := func() {
+		decks, err := palette.FindInvalidDecks()
+		if err != nil {
+			log.Error("failed to check deck colors: ", err)
+			return
+		}
+
+		for _, deck := range decks {
+			log.WithFields(map[string]interface{}{
+				"deckID": deck.ID,
+				"userID": deck.UserID,
+				"color":  deck.Color,
+			}).Warn("deck color is not in the palette")
+		}
+
+		if len(decks) > 0 {
+			log.Warn("decks with colors outside the palette: ", len(decks))
+		}
+	}
+
+	refresh := func() {
+		changed, err := palette.Refresh()
+		if err != nil {
+			log.Error("failed to load color palette: ", err)
+			return
+		}
+
+		if changed {
+			reportInvalidDecks()
+		}
+	}
+
+	lifecycle.Append(fx.Hook{
+		OnStart: func(context.Context) error {
+			// the palette is loaded before requests are served
+			if _, err := palette.Refresh(); err != nil {
+				return fmt.Errorf("failed to load color palette: %w", err)
+			}
+			reportInvalidDecks()
+
+			ticker = time.NewTicker(paletteRefreshInterval)
+			go func() {
+				for {
+					select {
+					case <-ticker.C:
+						refresh()
+					case <-done:
+						return
+					}
+				}
+			}()
+			return nil
+		},
+		OnStop: func(context.Context) error {
+			ticker.Stop()
+			close(done)
+			return nil
+		},
+	})
+}
@@ -0,0 +1,70 @@
+package main
+
+import (
+	"context"
+	"time"
+
+	"github.com/moshrank/spacey-backend/pkg/logger"
+	"github.com/moshrank/spacey-backend/services/deck-management-service/entity"
+
+	"go.uber.org/fx"
+)
+
+const purgeInterval = time.Hour
+
+// runTrashPurge periodically removes decks and cards whose retention period
+// in the trash has passed, and then the media files no card references
+// anymore.
+func runTrashPurge(
+	lifecycle fx.Lifecycle,
+	trashUseCase entity.TrashUseCaseInterface,
+	mediaUseCase entity.MediaUseCaseInterface,
+	log logger.LoggerInterface,
+) {
+	ticker := time.NewTicker(purgeInterval)
+	done := make(chan struct{})
+
+	purge := func() {
+		purged, err := trashUseCase.Purge()
+		if err != nil {
+			log.Error("failed to purge trash: ", err)
+			return
+		}
+
+		if purged > 0 {
+			log.Info("purged items from trash: ", purged)
+		}
+
+		collected, err := mediaUseCase.CollectGarbage()
+		if err != nil {
+			log.Error("failed to collect unused media: ", err)
+			return
+		}
+
+		if collected > 0 {
+			log.Info("removed unused media files: ", collected)
+		}
+	}
+
+	lifecycle.Append(fx.Hook{
+		OnStart: func(context.Context) error {
+			go func() {
+				purge()
+				for {
+					select {
+					case <-ticker.C:
+						purge()
+					case <-done:
+						return
+					}
+				}
+			}()
+			return nil
+		},
+		OnStop: func(context.Context) error {
+			ticker.Stop()
+			close(done)
+			return nil
+		},
+	})
+}
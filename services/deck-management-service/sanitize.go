@@ -0,0 +1,35 @@
+package main
+
+import (
+	"context"
+
+	"github.com/moshrank/spacey-backend/pkg/logger"
+	"github.com/moshrank/spacey-backend/services/deck-management-service/entity"
+
+	"go.uber.org/fx"
+)
+
+// runContentBackfill sanitizes the content of the cards that were stored
+// before card content was sanitized on write. It runs once in the background
+// at startup, rendered content is safe in the meantime.
+func runContentBackfill(
+	lifecycle fx.Lifecycle,
+	cardUseCase entity.CardUseCaseInterface,
+	log logger.LoggerInterface,
+) {
+	lifecycle.Append(fx.Hook{
+		OnStart: func(context.Context) error {
+			go func() {
+				sanitized, err := cardUseCase.SanitizeStoredCards()
+				if err != nil {
+					log.Error("failed to sanitize stored cards: ", err)
+				}
+
+				if sanitized > 0 {
+					log.Info("sanitized stored cards: ", sanitized)
+				}
+			}()
+			return nil
+		},
+	})
+}
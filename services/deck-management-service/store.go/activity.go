@@ -0,0 +1,77 @@
+package store
+
+import (
+	"context"
+
+	"github.com/moshrank/spacey-backend/pkg/db"
+	"github.com/moshrank/spacey-backend/pkg/logger"
+	"github.com/moshrank/spacey-backend/services/deck-management-service/entity"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+const ACTIVITY_COLLECTION = "activity"
+
+type ActivityStore struct {
+	db     db.DatabaseInterface
+	logger logger.LoggerInterface
+}
+
+func NewActivityStore(
+	db db.DatabaseInterface,
+	loggerObj logger.LoggerInterface,
+) entity.ActivityStoreInterface {
+	return &ActivityStore{
+		db:     db,
+		logger: loggerObj,
+	}
+}
+
+func (s *ActivityStore) Save(activity *entity.Activity) error {
+	_, err := s.db.CreateDocument(ACTIVITY_COLLECTION, activity)
+
+	return err
+}
+
+// FindByDeckID returns the activity of the deck, the latest first. Cards
+// moved or copied to the deck are part of its activity.
+func (s *ActivityStore) FindByDeckID(
+	deckID string,
+	page *entity.Page,
+) ([]entity.Activity, string, error) {
+	after, err := pageFilter("", true, page.Cursor, nil)
+	if err != nil {
+		return nil, "", err
+	}
+
+	opts := options.Find().SetSort(pageSort("", true))
+	if page.Limit > 0 {
+		opts.SetLimit(int64(page.Limit) + 1)
+	}
+
+	res, err := s.db.QueryDocuments(
+		ACTIVITY_COLLECTION,
+		bson.M{"$and": []bson.M{
+			{"$or": []bson.M{{"deck_id": deckID}, {"target_deck_id": deckID}}},
+			after,
+		}},
+		opts,
+	)
+	if err != nil {
+		return nil, "", err
+	}
+
+	activities := []entity.Activity{}
+	if err := res.All(context.TODO(), &activities); err != nil {
+		return nil, "", err
+	}
+
+	next := ""
+	if page.Limit > 0 && len(activities) > page.Limit {
+		activities = activities[:page.Limit]
+		next = encodeCursor("", activities[len(activities)-1].ID)
+	}
+
+	return activities, next, nil
+}
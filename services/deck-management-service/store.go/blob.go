@@ -0,0 +1,89 @@
+package store
+
+import (
+	"errors"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+
+	"github.com/moshrank/spacey-backend/config"
+	"github.com/moshrank/spacey-backend/services/deck-management-service/entity"
+)
+
+var errInvalidBlobKey = errors.New("invalid blob key")
+
+// LocalBlobStore keeps blobs as files in a directory of the local file
+// system.
+type LocalBlobStore struct {
+	root string
+}
+
+func NewLocalBlobStore(cfg config.ConfigInterface) entity.BlobStoreInterface {
+	return &LocalBlobStore{
+		root: cfg.GetMediaPath(),
+	}
+}
+
+// path returns the file of the blob. Keys are plain file names, so a blob
+// can never be outside of the root directory.
+func (s *LocalBlobStore) path(key string) (string, error) {
+	if key == "" || key != filepath.Base(key) || key == "." || key == ".." {
+		return "", errInvalidBlobKey
+	}
+
+	return filepath.Join(s.root, key), nil
+}
+
+// Put writes the blob to a temporary file first, so a failed write never
+// leaves a partial blob behind.
+func (s *LocalBlobStore) Put(key string, content io.Reader) error {
+	path, err := s.path(key)
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(s.root, 0o755); err != nil {
+		return err
+	}
+
+	file, err := os.CreateTemp(s.root, ".upload-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(file.Name())
+
+	if _, err := io.Copy(file, content); err != nil {
+		file.Close()
+		return err
+	}
+
+	if err := file.Close(); err != nil {
+		return err
+	}
+
+	return os.Rename(file.Name(), path)
+}
+
+func (s *LocalBlobStore) Get(key string) (io.ReadCloser, error) {
+	path, err := s.path(key)
+	if err != nil {
+		return nil, err
+	}
+
+	return os.Open(path)
+}
+
+// Delete removes the blob. Deleting a blob that does not exist is no error.
+func (s *LocalBlobStore) Delete(key string) error {
+	path, err := s.path(key)
+	if err != nil {
+		return err
+	}
+
+	if err := os.Remove(path); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return err
+	}
+
+	return nil
+}
@@ -1,14 +1,21 @@
 package store
 
 import (
+	"context"
+	"time"
+
 	"github.com/moshrank/spacey-backend/pkg/db"
 	"github.com/moshrank/spacey-backend/pkg/logger"
 	"github.com/moshrank/spacey-backend/services/deck-management-service/entity"
 
 	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo/options"
 )
 
+const CARD_COLLECTION = "card"
+const CARD_REVISION_COLLECTION = "cardRevision"
+
 type CardStore struct {
 	db     db.DatabaseInterface
 	logger logger.LoggerInterface
@@ -24,100 +31,599 @@ func NewCardStore(
 	}
 }
 
+func (s *CardStore) cardDocument(
+	id primitive.ObjectID,
+	deckID, userID string,
+	card *entity.Card,
+) bson.M {
+	return bson.M{
+		"_id":        id,
+		"question":   card.Question,
+		"answer":     card.Answer,
+		"user_id":    userID,
+		"deck_id":    deckID,
+		"tags":       card.Tags,
+		"source":     card.Source,
+		"type":       card.Type,
+		"note_id":    card.NoteID,
+		"ordinal":    card.Ordinal,
+		"cloze":      card.Cloze,
+		"choices":    card.Choices,
+		"accepted":   card.Accepted,
+		"occlusion":  card.Occlusion,
+		"custom":     card.Custom,
+		"source_ref": card.SourceRef,
+		"media":      card.MediaRefs(),
+		"origin":     card.Origin,
+		"version":    entity.FirstVersion,
+		"created_at": card.CreatedAt,
+		"updated_at": card.UpdatedAt,
+		"deleted_at": card.DeletedAt,
+	}
+}
+
+func (s *CardStore) cardFilter(cardID, userID, deckID string) (bson.M, error) {
+	cardObjID, err := primitive.ObjectIDFromHex(cardID)
+	if err != nil {
+		return nil, err
+	}
+
+	return bson.M{
+		"_id":        cardObjID,
+		"user_id":    userID,
+		"deck_id":    deckID,
+		"deleted_at": nil,
+	}, nil
+}
+
 func (s *CardStore) SaveCard(deckID, userID string, card *entity.Card) (string, error) {
 	id := primitive.NewObjectID()
 
-	deckIDObj, err := primitive.ObjectIDFromHex(deckID)
+	_, err := s.db.CreateDocument(CARD_COLLECTION, s.cardDocument(id, deckID, userID, card))
 	if err != nil {
 		return "", err
 	}
 
-	_, err = s.db.UpdateDocument(DECK_COLLECTION, bson.M{
-		"_id":     deckIDObj,
-		"user_id": userID,
-	}, bson.M{"$push": bson.M{"cards": bson.M{
-		"_id":        id,
+	err = s.saveRevisions(deckID, userID, []string{id.Hex()}, []entity.Card{*card})
+
+	return id.Hex(), err
+}
+
+func (s *CardStore) revisionDocument(cardID, deckID, userID string, card *entity.Card) bson.M {
+	return bson.M{
+		"card_id":    cardID,
+		"deck_id":    deckID,
+		"user_id":    userID,
 		"question":   card.Question,
 		"answer":     card.Answer,
-		"user_id":    userID,
-		"deck_id":    deckID,
-		"created_at": card.CreatedAt,
+		"tags":       card.Tags,
+		"source":     card.Source,
+		"type":       card.Type,
+		"note_id":    card.NoteID,
+		"ordinal":    card.Ordinal,
+		"cloze":      card.Cloze,
+		"choices":    card.Choices,
+		"accepted":   card.Accepted,
+		"occlusion":  card.Occlusion,
+		"custom":     card.Custom,
+		"media":      card.MediaRefs(),
+		"created_at": card.UpdatedAt,
+	}
+}
+
+// contentFields returns the fields of a card that are changed when its
+// content is edited.
+func (s *CardStore) contentFields(card *entity.Card) bson.M {
+	return bson.M{
+		"question":   card.Question,
+		"answer":     card.Answer,
+		"tags":       card.Tags,
+		"source":     card.Source,
+		"type":       card.Type,
+		"note_id":    card.NoteID,
+		"ordinal":    card.Ordinal,
+		"cloze":      card.Cloze,
+		"choices":    card.Choices,
+		"accepted":   card.Accepted,
+		"occlusion":  card.Occlusion,
+		"custom":     card.Custom,
+		"media":      card.MediaRefs(),
 		"updated_at": card.UpdatedAt,
-		"deleted_at": card.DeletedAt,
-	}}})
+	}
+}
 
-	return id.Hex(), err
+// saveRevisions stores the current versions of the given cards as
+// revisions.
+func (s *CardStore) saveRevisions(
+	deckID, userID string,
+	cardIDs []string,
+	cards []entity.Card,
+) error {
+	docs := []interface{}{}
+	for i := range cards {
+		docs = append(docs, s.revisionDocument(cardIDs[i], deckID, userID, &cards[i]))
+	}
+
+	_, err := s.db.CreateDocuments(CARD_REVISION_COLLECTION, docs)
+
+	return err
 }
 
-func (s *CardStore) UpdateCard(cardID, userID, deckID string, card *entity.Card) error {
-	deckObjID, err := primitive.ObjectIDFromHex(deckID)
+// updateCard changes the card and increments its version. If the card has a
+// version, the change only applies to that version of the card. Cards in the
+// trash can not be changed.
+func (s *CardStore) updateCard(cardID, userID, deckID string, card *entity.Card, set bson.M) error {
+	filter, err := s.cardFilter(cardID, userID, deckID)
 	if err != nil {
 		return err
 	}
 
-	cardObjID, err := primitive.ObjectIDFromHex(cardID)
+	if card.Version > 0 {
+		filter["version"] = card.Version
+	}
+
+	res, err := s.db.UpdateDocument(
+		CARD_COLLECTION,
+		filter,
+		bson.M{"$set": set, "$inc": bson.M{"version": 1}},
+	)
 	if err != nil {
 		return err
 	}
 
-	_, err = s.db.UpdateDocument(DECK_COLLECTION, bson.M{
-		"_id":       deckObjID,
-		"user_id":   userID,
-		"cards._id": cardObjID,
-	}, bson.M{"$set": bson.M{"cards.$.question": card.Question, "cards.$.answer": card.Answer, "cards.$.updated_at": card.UpdatedAt}})
+	if res.MatchedCount == 0 {
+		if card.Version > 0 {
+			return entity.ErrVersionConflict
+		}
+		return entity.ErrCardNotFound
+	}
 
-	return err
+	return s.saveRevisions(deckID, userID, []string{cardID}, []entity.Card{*card})
+}
+
+func (s *CardStore) UpdateCard(cardID, userID, deckID string, card *entity.Card) error {
+	return s.updateCard(cardID, userID, deckID, card, s.contentFields(card))
 }
 
-func (s *CardStore) DeleteCard(userID, deckID, cardID string) error {
-	deckObjID, err := primitive.ObjectIDFromHex(deckID)
+func (s *CardStore) SyncCard(cardID, userID, deckID string, card *entity.Card) error {
+	set := s.contentFields(card)
+	set["origin"] = card.Origin
+
+	return s.updateCard(cardID, userID, deckID, card, set)
+}
+
+// DeleteCard moves the card to the trash.
+func (s *CardStore) DeleteCard(userID, deckID, cardID string, deletedAt time.Time) error {
+	filter, err := s.cardFilter(cardID, userID, deckID)
 	if err != nil {
 		return err
 	}
 
-	cardObjID, err := primitive.ObjectIDFromHex(cardID)
+	_, err = s.db.UpdateDocument(
+		CARD_COLLECTION,
+		filter,
+		bson.M{"$set": bson.M{"deleted_at": deletedAt}},
+	)
+
+	return err
+}
+
+func (s *CardStore) FindDeleted(deckIDs []string) ([]entity.Card, error) {
+	res, err := s.db.QueryDocuments(
+		CARD_COLLECTION,
+		bson.M{"deck_id": bson.M{"$in": deckIDs}, "deleted_at": bson.M{"$ne": nil}},
+		options.Find().SetSort(bson.M{"deleted_at": -1}),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	cards := []entity.Card{}
+	err = res.All(context.TODO(), &cards)
+
+	return cards, err
+}
+
+func (s *CardStore) RestoreCard(userID, deckID, cardID string, restoredAt time.Time) error {
+	filter, err := s.cardFilter(cardID, userID, deckID)
+	if err != nil {
+		return entity.ErrCardNotFound
+	}
+
+	filter["deleted_at"] = bson.M{"$ne": nil}
+
+	res, err := s.db.UpdateDocument(
+		CARD_COLLECTION,
+		filter,
+		bson.M{"$set": bson.M{"deleted_at": nil, "updated_at": restoredAt}},
+	)
 	if err != nil {
 		return err
 	}
 
-	_, err = s.db.UpdateDocument(DECK_COLLECTION, bson.M{
-		"_id":     deckObjID,
-		"user_id": userID,
-	}, bson.M{"$pull": bson.M{"cards": bson.M{"_id": cardObjID}}})
+	if res.MatchedCount == 0 {
+		return entity.ErrCardNotFound
+	}
 
-	return err
+	return nil
 }
 
-func (s *CardStore) SaveCards(deckID, userID string, cards []entity.Card) ([]string, error) {
-	deckObjID, err := primitive.ObjectIDFromHex(deckID)
+// Purge permanently removes the cards deleted before the given time.
+// Purge removes the cards moved to the trash before the given time together
+// with their revisions, so that the media files only they reference can be
+// removed as well.
+func (s *CardStore) Purge(deletedBefore time.Time) (int64, error) {
+	filter := bson.M{"deleted_at": bson.M{"$lt": deletedBefore}}
+	res, err := s.db.QueryDocuments(
+		CARD_COLLECTION,
+		filter,
+		options.Find().SetProjection(bson.M{"_id": 1}),
+	)
 	if err != nil {
-		return nil, err
+		return 0, err
+	}
+
+	cards := []entity.Card{}
+	if err := res.All(context.TODO(), &cards); err != nil {
+		return 0, err
 	}
 
-	objCards := []bson.M{}
+	if len(cards) == 0 {
+		return 0, nil
+	}
 
-	var cardIDs []string
+	cardIDs := []string{}
 	for _, card := range cards {
+		cardIDs = append(cardIDs, card.ID)
+	}
+
+	_, err = s.db.DeleteDocuments(
+		CARD_REVISION_COLLECTION,
+		bson.M{"card_id": bson.M{"$in": cardIDs}},
+	)
+	if err != nil {
+		return 0, err
+	}
+
+	deleted, err := s.db.DeleteDocuments(CARD_COLLECTION, filter)
+	if err != nil {
+		return 0, err
+	}
+
+	return deleted.DeletedCount, nil
+}
+
+func (s *CardStore) SaveCards(deckID, userID string, cards []entity.Card) ([]string, error) {
+	if len(cards) == 0 {
+		return []string{}, nil
+	}
+
+	docs := []interface{}{}
+
+	var cardIDs []string
+	for i := range cards {
 		id := primitive.NewObjectID()
 
-		objCards = append(objCards, bson.M{
-			"_id":        id,
-			"question":   card.Question,
-			"answer":     card.Answer,
+		docs = append(docs, s.cardDocument(id, deckID, userID, &cards[i]))
+		cardIDs = append(cardIDs, id.Hex())
+	}
+
+	if _, err := s.db.CreateDocuments(CARD_COLLECTION, docs); err != nil {
+		return nil, err
+	}
+
+	return cardIDs, s.saveRevisions(deckID, userID, cardIDs, cards)
+}
+
+// DeleteCards moves the given cards of a deck to the trash.
+func (s *CardStore) DeleteCards(
+	userID, deckID string,
+	cardIDs []string,
+	deletedAt time.Time,
+) error {
+	_, err := s.db.UpdateDocuments(
+		CARD_COLLECTION,
+		bson.M{
+			"_id":        bson.M{"$in": objectIDs(cardIDs)},
 			"user_id":    userID,
 			"deck_id":    deckID,
-			"created_at": card.CreatedAt,
-			"updated_at": card.UpdatedAt,
-			"deleted_at": card.DeletedAt,
-		})
+			"deleted_at": nil,
+		},
+		bson.M{"$set": bson.M{"deleted_at": deletedAt}},
+	)
 
-		cardIDs = append(cardIDs, id.Hex())
+	return err
+}
+
+// MoveCards moves the given cards of a deck together with their revisions
+// to the target deck. The cards lose their origin, since it belongs to the
+// deck they came from.
+func (s *CardStore) MoveCards(
+	userID, deckID string,
+	cardIDs []string,
+	target *entity.Deck,
+	movedAt time.Time,
+) error {
+	_, err := s.db.UpdateDocuments(
+		CARD_COLLECTION,
+		bson.M{
+			"_id":        bson.M{"$in": objectIDs(cardIDs)},
+			"user_id":    userID,
+			"deck_id":    deckID,
+			"deleted_at": nil,
+		},
+		bson.M{"$set": bson.M{
+			"user_id":    target.UserID,
+			"deck_id":    target.ID,
+			"origin":     nil,
+			"updated_at": movedAt,
+		}},
+	)
+	if err != nil {
+		return err
+	}
+
+	_, err = s.db.UpdateDocuments(
+		CARD_REVISION_COLLECTION,
+		bson.M{"card_id": bson.M{"$in": cardIDs}, "deck_id": deckID},
+		bson.M{"$set": bson.M{"user_id": target.UserID, "deck_id": target.ID}},
+	)
+
+	return err
+}
+
+// FindRevisions returns the revisions of a card, latest first.
+func (s *CardStore) FindRevisions(deckID, cardID string) ([]entity.CardRevision, error) {
+	res, err := s.db.QueryDocuments(
+		CARD_REVISION_COLLECTION,
+		bson.M{"card_id": cardID, "deck_id": deckID},
+		options.Find().SetSort(bson.D{{Key: "created_at", Value: -1}, {Key: "_id", Value: -1}}),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	revisions := []entity.CardRevision{}
+	err = res.All(context.TODO(), &revisions)
+
+	return revisions, err
+}
+
+func (s *CardStore) FindRevision(
+	deckID, cardID, revisionID string,
+) (*entity.CardRevision, error) {
+	id, err := primitive.ObjectIDFromHex(revisionID)
+	if err != nil {
+		return nil, err
+	}
+
+	var revision entity.CardRevision
+	err = s.db.QueryDocument(
+		CARD_REVISION_COLLECTION,
+		bson.M{"_id": id, "card_id": cardID, "deck_id": deckID},
+	).Decode(&revision)
+
+	return &revision, err
+}
+
+// CountByDeckIDs returns the number of cards of each of the decks. Decks
+// without cards are left out.
+func (s *CardStore) CountByDeckIDs(deckIDs []string) (map[string]int, error) {
+	cur, err := s.db.Aggregate(CARD_COLLECTION, []bson.M{
+		{"$match": bson.M{"deck_id": bson.M{"$in": deckIDs}, "deleted_at": nil}},
+		{"$group": bson.M{"_id": "$deck_id", "count": bson.M{"$sum": 1}}},
+	})
+	if err != nil {
+		return nil, err
 	}
 
-	_, err = s.db.UpdateDocument(DECK_COLLECTION, bson.M{
-		"_id":     deckObjID,
-		"user_id": userID,
-	}, bson.M{"$push": bson.M{"cards": bson.M{"$each": objCards}}})
+	var groups []struct {
+		DeckID string `bson:"_id"`
+		Count  int    `bson:"count"`
+	}
+	if err := cur.All(context.TODO(), &groups); err != nil {
+		return nil, err
+	}
+
+	counts := map[string]int{}
+	for _, group := range groups {
+		counts[group.DeckID] = group.Count
+	}
+
+	return counts, nil
+}
 
-	return cardIDs, err
+// FindByDeckID returns a page of the cards of a deck in the order they were
+// created together with the cursor of the next page.
+func (s *CardStore) FindByDeckID(deckID string, page *entity.Page) ([]entity.Card, string, error) {
+	after, err := pageFilter("", false, page.Cursor, nil)
+	if err != nil {
+		return nil, "", err
+	}
+
+	opts := options.Find().SetSort(pageSort("", false))
+	if page.Limit > 0 {
+		opts.SetLimit(int64(page.Limit) + 1)
+	}
+
+	res, err := s.db.QueryDocuments(
+		CARD_COLLECTION,
+		bson.M{"$and": []bson.M{{"deck_id": deckID, "deleted_at": nil}, after}},
+		opts,
+	)
+	if err != nil {
+		return nil, "", err
+	}
+
+	cards := []entity.Card{}
+	if err := res.All(context.TODO(), &cards); err != nil {
+		return nil, "", err
+	}
+
+	next := ""
+	if page.Limit > 0 && len(cards) > page.Limit {
+		cards = cards[:page.Limit]
+		next = encodeCursor("", cards[len(cards)-1].ID)
+	}
+
+	return cards, next, nil
+}
+
+// FindByIDs returns the given cards of a deck. Unknown ids are skipped.
+func (s *CardStore) FindByIDs(deckID string, cardIDs []string) ([]entity.Card, error) {
+	res, err := s.db.QueryDocuments(
+		CARD_COLLECTION,
+		bson.M{"_id": bson.M{"$in": objectIDs(cardIDs)}, "deck_id": deckID, "deleted_at": nil},
+		options.Find().SetSort(pageSort("", false)),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	cards := []entity.Card{}
+	err = res.All(context.TODO(), &cards)
+
+	return cards, err
+}
+
+// FindByNoteID returns the sibling cards of a note.
+func (s *CardStore) FindByNoteID(deckID, noteID string) ([]entity.Card, error) {
+	res, err := s.db.QueryDocuments(
+		CARD_COLLECTION,
+		bson.M{"note_id": noteID, "deck_id": deckID, "deleted_at": nil},
+		options.Find().SetSort(bson.M{"ordinal": 1}),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	cards := []entity.Card{}
+	err = res.All(context.TODO(), &cards)
+
+	return cards, err
+}
+
+// Search returns the cards whose question or answer match the text, best
+// matches first.
+func (s *CardStore) Search(query *entity.SearchQuery) ([]entity.CardMatch, error) {
+	filter := bson.M{
+		"$text":      bson.M{"$search": query.Text},
+		"deck_id":    bson.M{"$in": query.DeckIDs},
+		"deleted_at": nil,
+	}
+
+	if len(query.Tags) > 0 {
+		filter["tags"] = bson.M{"$all": query.Tags}
+	}
+
+	res, err := s.db.QueryDocuments(CARD_COLLECTION, filter, textSearchOptions(query.Limit))
+	if err != nil {
+		return nil, err
+	}
+
+	cards := []entity.CardMatch{}
+	err = res.All(context.TODO(), &cards)
+
+	return cards, err
+}
+
+// FindChanged returns the cards of the decks that were changed or deleted
+// after the given time. If the time is zero, it returns all cards of the
+// decks that are not deleted.
+func (s *CardStore) FindChanged(deckIDs []string, since time.Time) ([]entity.Card, error) {
+	if len(deckIDs) == 0 {
+		return []entity.Card{}, nil
+	}
+
+	filter := bson.M{"deck_id": bson.M{"$in": deckIDs}, "deleted_at": nil}
+	if !since.IsZero() {
+		filter = bson.M{
+			"deck_id": bson.M{"$in": deckIDs},
+			"$or": []bson.M{
+				{"updated_at": bson.M{"$gt": since}},
+				{"deleted_at": bson.M{"$gt": since}},
+			},
+		}
+	}
+
+	res, err := s.db.QueryDocuments(
+		CARD_COLLECTION,
+		filter,
+		options.Find().SetSort(bson.M{"_id": 1}),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	cards := []entity.Card{}
+	err = res.All(context.TODO(), &cards)
+
+	return cards, err
+}
+
+// FindByNoteTypeID returns the cards of the custom notes of the note type in
+// all decks, ordered by note.
+func (s *CardStore) FindByNoteTypeID(noteTypeID string) ([]entity.Card, error) {
+	res, err := s.db.QueryDocuments(
+		CARD_COLLECTION,
+		bson.M{"custom.note_type_id": noteTypeID, "deleted_at": nil},
+		options.Find().SetSort(bson.D{{Key: "note_id", Value: 1}, {Key: "ordinal", Value: 1}}),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	cards := []entity.Card{}
+	err = res.All(context.TODO(), &cards)
+
+	return cards, err
+}
+
+// HasNoteType reports whether a card uses the note type, including the cards
+// in the trash, since they can still be restored.
+func (s *CardStore) HasNoteType(noteTypeID string) (bool, error) {
+	count, err := s.db.CountDocuments(
+		CARD_COLLECTION,
+		bson.M{"custom.note_type_id": noteTypeID},
+		options.Count().SetLimit(1),
+	)
+
+	return count > 0, err
+}
+
+// FindUnsanitized returns up to limit cards that were stored before card
+// content was sanitized on write, including the cards in the trash.
+func (s *CardStore) FindUnsanitized(limit int) ([]entity.Card, error) {
+	res, err := s.db.QueryDocuments(
+		CARD_COLLECTION,
+		bson.M{"unsanitized": true},
+		options.Find().SetLimit(int64(limit)),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	cards := []entity.Card{}
+	err = res.All(context.TODO(), &cards)
+
+	return cards, err
+}
+
+// SaveSanitized stores the sanitized content of a card FindUnsanitized
+// returned. Sanitizing is no edit, so neither the version of the card changes
+// nor is a revision saved. If the card was edited in the meantime, nothing is
+// stored and the card is returned by FindUnsanitized again.
+func (s *CardStore) SaveSanitized(card *entity.Card) error {
+	cardObjID, err := primitive.ObjectIDFromHex(card.ID)
+	if err != nil {
+		return err
+	}
+
+	_, err = s.db.UpdateDocument(
+		CARD_COLLECTION,
+		bson.M{"_id": cardObjID, "version": card.Version},
+		bson.M{"$set": s.contentFields(card), "$unset": bson.M{"unsanitized": ""}},
+	)
+
+	return err
 }
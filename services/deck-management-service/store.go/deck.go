@@ -2,6 +2,8 @@ package store
 
 import (
 	"context"
+	"errors"
+	"time"
 
 	"github.com/moshrank/spacey-backend/pkg/db"
 	"github.com/moshrank/spacey-backend/pkg/logger"
@@ -9,6 +11,8 @@ import (
 
 	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
 )
 
 const DECK_COLLECTION = "deck"
@@ -28,31 +32,188 @@ func NewDeckStore(
 	}
 }
 
-func (s *DeckStore) FindByID(userID string, id string) (*entity.Deck, error) {
-	res := s.db.QueryDocument(DECK_COLLECTION, bson.M{"_id": id, "user_id": userID})
+func (s *DeckStore) acceptedMember(userID string) bson.M {
+	return bson.M{"$elemMatch": bson.M{"user_id": userID, "accepted": true}}
+}
+
+// findCards loads the cards of the given decks from the card collection in
+// the order they were created.
+func (s *DeckStore) findCards(decks []entity.Deck) error {
+	if len(decks) == 0 {
+		return nil
+	}
+
+	deckIDs := []string{}
+	for _, deck := range decks {
+		deckIDs = append(deckIDs, deck.ID)
+	}
+
+	res, err := s.db.QueryDocuments(
+		CARD_COLLECTION,
+		bson.M{"deck_id": bson.M{"$in": deckIDs}, "deleted_at": nil},
+		options.Find().SetSort(bson.M{"_id": 1}),
+	)
+	if err != nil {
+		return err
+	}
+
+	var cards []entity.Card
+	if err := res.All(context.TODO(), &cards); err != nil {
+		return err
+	}
+
+	cardsByDeck := map[string][]entity.Card{}
+	for _, card := range cards {
+		cardsByDeck[card.DeckID] = append(cardsByDeck[card.DeckID], card)
+	}
+
+	for i := range decks {
+		decks[i].Cards = cardsByDeck[decks[i].ID]
+		if decks[i].Cards == nil {
+			decks[i].Cards = []entity.Card{}
+		}
+	}
+
+	return nil
+}
+
+func (s *DeckStore) findOne(filter bson.M) (*entity.Deck, error) {
 	var deck entity.Deck
-	err := res.Decode(&deck)
+	err := s.db.QueryDocument(DECK_COLLECTION, filter).Decode(&deck)
 
 	return &deck, err
 }
 
-func (s *DeckStore) FindAll(userID string) ([]entity.Deck, error) {
+// FindByID returns the deck without its cards, which are loaded through the
+// card store.
+func (s *DeckStore) FindByID(userID string, id string) (*entity.Deck, error) {
+	idObj, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.findOne(bson.M{
+		"_id":        idObj,
+		"deleted_at": nil,
+		"$or": []bson.M{
+			{"user_id": userID},
+			{"members": s.acceptedMember(userID)},
+		},
+	})
+}
+
+// FindAccessibleByID returns the deck if the user owns it, is a member of
+// it or it is public, and ErrDeckNotFound otherwise.
+func (s *DeckStore) FindAccessibleByID(userID string, id string) (*entity.Deck, error) {
+	idObj, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return nil, entity.ErrDeckNotFound
+	}
+
+	deck, err := s.findOne(bson.M{
+		"_id":        idObj,
+		"deleted_at": nil,
+		"$or": []bson.M{
+			{"user_id": userID},
+			{"members": s.acceptedMember(userID)},
+			{"public": true},
+		},
+	})
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return nil, entity.ErrDeckNotFound
+	}
+
+	return deck, err
+}
+
+var deckSortFields = map[string]string{
+	entity.SortName:      "name",
+	entity.SortCreatedAt: "created_at",
+	entity.SortUpdatedAt: "updated_at",
+}
+
+func (s *DeckStore) sortValue(deck *entity.Deck, sort string) string {
+	var timestamp *time.Time
+
+	switch sort {
+	case entity.SortName:
+		return deck.Name
+	case entity.SortCreatedAt:
+		timestamp = deck.CreatedAt
+	case entity.SortUpdatedAt:
+		timestamp = deck.UpdatedAt
+	}
+
+	if timestamp == nil {
+		return ""
+	}
+
+	return timestamp.Format(time.RFC3339Nano)
+}
+
+func (s *DeckStore) parseSortValue(sort string) func(string) (interface{}, error) {
+	return func(value string) (interface{}, error) {
+		if sort == entity.SortName {
+			return value, nil
+		}
+
+		return time.Parse(time.RFC3339Nano, value)
+	}
+}
+
+// FindAll returns a page of the decks the user owns or is a member of
+// together with the cursor of the next page, which is empty on the last page.
+func (s *DeckStore) FindAll(userID string, query *entity.DeckQuery) ([]entity.Deck, string, error) {
+	field := deckSortFields[query.Sort]
+
+	after, err := pageFilter(field, query.Descending, query.Cursor, s.parseSortValue(query.Sort))
+	if err != nil {
+		return nil, "", err
+	}
+
+	opts := options.Find().SetSort(pageSort(field, query.Descending))
+	if query.Limit > 0 {
+		opts.SetLimit(int64(query.Limit) + 1)
+	}
+
 	res, err := s.db.QueryDocuments(
 		DECK_COLLECTION,
-		bson.M{"user_id": userID},
+		bson.M{"$and": []bson.M{
+			{"deleted_at": nil},
+			{"$or": []bson.M{
+				{"user_id": userID},
+				{"members": s.acceptedMember(userID)},
+			}},
+			after,
+		}},
+		opts,
 	)
 	if err != nil {
-		return nil, err
+		return nil, "", err
 	}
 
-	var decks []entity.Deck
-	err = res.All(context.TODO(), &decks)
+	decks := []entity.Deck{}
+	if err := res.All(context.TODO(), &decks); err != nil {
+		return nil, "", err
+	}
 
-	return decks, err
+	next := ""
+	if query.Limit > 0 && len(decks) > query.Limit {
+		decks = decks[:query.Limit]
+		last := &decks[len(decks)-1]
+		next = encodeCursor(s.sortValue(last, query.Sort), last.ID)
+	}
+
+	if query.WithCards {
+		err = s.findCards(decks)
+	}
 
+	return decks, next, err
 }
 
 func (s *DeckStore) Save(deck *entity.Deck) (string, error) {
+	deck.Version = entity.FirstVersion
+
 	res, err := s.db.CreateDocument(DECK_COLLECTION, deck)
 	if err != nil {
 		return "", err
@@ -63,37 +224,407 @@ func (s *DeckStore) Save(deck *entity.Deck) (string, error) {
 	return id.Hex(), err
 }
 
+// Update changes the deck and increments its version. If the deck has a
+// version, the change only applies to that version of the deck.
 func (s *DeckStore) Update(deck *entity.Deck) error {
 	id, err := primitive.ObjectIDFromHex(deck.ID)
 	if err != nil {
 		return err
 	}
 
-	_, err = s.db.UpdateDocument(
+	filter := bson.M{"_id": id, "user_id": deck.UserID, "deleted_at": nil}
+	if deck.Version > 0 {
+		filter["version"] = deck.Version
+	}
+
+	res, err := s.db.UpdateDocument(
 		DECK_COLLECTION,
-		bson.M{"_id": id, "user_id": deck.UserID},
+		filter,
 		bson.M{
 			"$set": bson.M{
 				"name":        deck.Name,
 				"description": deck.Description,
 				"color":       deck.Color,
+				"public":      deck.Public,
 				"updated_at":  deck.UpdatedAt,
 			},
+			"$inc": bson.M{"version": 1},
 		},
 	)
-	return err
+	if err != nil {
+		return err
+	}
+
+	if res.MatchedCount == 0 && deck.Version > 0 {
+		return entity.ErrVersionConflict
+	}
+
+	return nil
 }
 
-func (s *DeckStore) Delete(userID string, id string) error {
+func (s *DeckStore) UpdateOrigin(userID, id string, origin *entity.DeckOrigin) error {
 	idObj, err := primitive.ObjectIDFromHex(id)
 	if err != nil {
 		return err
 	}
 
-	_, err = s.db.DeleteDocument(
+	_, err = s.db.UpdateDocument(
 		DECK_COLLECTION,
 		bson.M{"_id": idObj, "user_id": userID},
+		bson.M{"$set": bson.M{"origin": origin}},
 	)
 
 	return err
 }
+
+// UpdateLintRules sets the lint rules that do not apply to the deck.
+func (s *DeckStore) UpdateLintRules(id string, disabledRules []string) error {
+	idObj, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return entity.ErrDeckNotFound
+	}
+
+	res, err := s.db.UpdateDocument(
+		DECK_COLLECTION,
+		bson.M{"_id": idObj, "deleted_at": nil},
+		bson.M{"$set": bson.M{"disabled_lint_rules": disabledRules}},
+	)
+	if err != nil {
+		return err
+	}
+
+	if res.MatchedCount == 0 {
+		return entity.ErrDeckNotFound
+	}
+
+	return nil
+}
+
+// UpdateParent moves the deck below the parent, or to the top level if the
+// parent is empty.
+func (s *DeckStore) UpdateParent(userID, id, parentID string, updatedAt time.Time) error {
+	idObj, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return err
+	}
+
+	update := bson.M{
+		"$set": bson.M{"parent_id": parentID, "updated_at": updatedAt},
+		"$inc": bson.M{"version": 1},
+	}
+	if parentID == "" {
+		update = bson.M{
+			"$set":   bson.M{"updated_at": updatedAt},
+			"$unset": bson.M{"parent_id": ""},
+			"$inc":   bson.M{"version": 1},
+		}
+	}
+
+	_, err = s.db.UpdateDocument(DECK_COLLECTION, bson.M{"_id": idObj, "user_id": userID}, update)
+
+	return err
+}
+
+// Delete moves the deck to the trash. Its cards are kept as they are and
+// come back when the deck is restored.
+func (s *DeckStore) Delete(userID string, id string, deletedAt time.Time) error {
+	idObj, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return err
+	}
+
+	_, err = s.db.UpdateDocument(
+		DECK_COLLECTION,
+		bson.M{"_id": idObj, "user_id": userID, "deleted_at": nil},
+		bson.M{"$set": bson.M{"deleted_at": deletedAt}},
+	)
+
+	return err
+}
+
+func (s *DeckStore) FindDeleted(userID string) ([]entity.Deck, error) {
+	res, err := s.db.QueryDocuments(
+		DECK_COLLECTION,
+		bson.M{"user_id": userID, "deleted_at": bson.M{"$ne": nil}},
+		options.Find().SetSort(bson.M{"deleted_at": -1}),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	decks := []entity.Deck{}
+	err = res.All(context.TODO(), &decks)
+
+	return decks, err
+}
+
+// Restore takes the deck out of the trash. Its cards count as changed at the
+// time of the restore, since clients drop them together with the deck.
+func (s *DeckStore) Restore(userID string, id string, restoredAt time.Time) error {
+	idObj, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return entity.ErrDeckNotFound
+	}
+
+	res, err := s.db.UpdateDocument(
+		DECK_COLLECTION,
+		bson.M{"_id": idObj, "user_id": userID, "deleted_at": bson.M{"$ne": nil}},
+		bson.M{"$set": bson.M{"deleted_at": nil, "updated_at": restoredAt}},
+	)
+	if err != nil {
+		return err
+	}
+
+	if res.MatchedCount == 0 {
+		return entity.ErrDeckNotFound
+	}
+
+	_, err = s.db.UpdateDocuments(
+		CARD_COLLECTION,
+		bson.M{"deck_id": id, "deleted_at": nil},
+		bson.M{"$set": bson.M{"updated_at": restoredAt}},
+	)
+
+	return err
+}
+
+// Purge permanently removes the decks deleted before the given time
+// together with all of their cards and card revisions.
+func (s *DeckStore) Purge(deletedBefore time.Time) (int64, error) {
+	filter := bson.M{"deleted_at": bson.M{"$lt": deletedBefore}}
+
+	res, err := s.db.QueryDocuments(
+		DECK_COLLECTION,
+		filter,
+		options.Find().SetProjection(bson.M{"_id": 1}),
+	)
+	if err != nil {
+		return 0, err
+	}
+
+	var decks []entity.Deck
+	if err := res.All(context.TODO(), &decks); err != nil {
+		return 0, err
+	}
+
+	if len(decks) == 0 {
+		return 0, nil
+	}
+
+	deckIDs := []string{}
+	for _, deck := range decks {
+		deckIDs = append(deckIDs, deck.ID)
+	}
+
+	for _, collection := range []string{CARD_REVISION_COLLECTION, CARD_COLLECTION} {
+		_, err = s.db.DeleteDocuments(collection, bson.M{"deck_id": bson.M{"$in": deckIDs}})
+		if err != nil {
+			return 0, err
+		}
+	}
+
+	deleted, err := s.db.DeleteDocuments(
+		DECK_COLLECTION,
+		bson.M{"_id": bson.M{"$in": objectIDs(deckIDs)}},
+	)
+	if err != nil {
+		return 0, err
+	}
+
+	return deleted.DeletedCount, nil
+}
+
+func (s *DeckStore) FindInvitations(userID string) ([]entity.Deck, error) {
+	res, err := s.db.QueryDocuments(
+		DECK_COLLECTION,
+		bson.M{
+			"deleted_at": nil,
+			"members":    bson.M{"$elemMatch": bson.M{"user_id": userID, "accepted": false}},
+		},
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	var decks []entity.Deck
+	err = res.All(context.TODO(), &decks)
+
+	return decks, err
+}
+
+func (s *DeckStore) AddMember(id string, member *entity.DeckMember) error {
+	idObj, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return err
+	}
+
+	res, err := s.db.UpdateDocument(
+		DECK_COLLECTION,
+		bson.M{
+			"_id":             idObj,
+			"user_id":         bson.M{"$ne": member.UserID},
+			"members.user_id": bson.M{"$ne": member.UserID},
+		},
+		bson.M{"$push": bson.M{"members": member}},
+	)
+	if err != nil {
+		return err
+	}
+
+	if res.MatchedCount == 0 {
+		return entity.ErrMemberExists
+	}
+
+	return nil
+}
+
+// updateMember changes the member of the deck. The filter can restrict the
+// deck further.
+func (s *DeckStore) updateMember(id, memberID string, filter bson.M, update bson.M) error {
+	idObj, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return err
+	}
+
+	filter["_id"] = idObj
+	filter["members.user_id"] = memberID
+	res, err := s.db.UpdateDocument(DECK_COLLECTION, filter, update)
+	if err != nil {
+		return err
+	}
+
+	if res.MatchedCount == 0 {
+		return entity.ErrMemberNotFound
+	}
+
+	return nil
+}
+
+// UpdateMember changes the role of the member. The deck counts as changed,
+// since the role of the user is part of the deck.
+func (s *DeckStore) UpdateMember(id, memberID, role string, updatedAt time.Time) error {
+	return s.updateMember(id, memberID, bson.M{}, bson.M{"$set": bson.M{
+		"members.$.role": role,
+		"updated_at":     updatedAt,
+	}})
+}
+
+// AcceptMember accepts the invitation of the member. Invitations to decks in
+// the trash can not be accepted.
+func (s *DeckStore) AcceptMember(id, memberID string, acceptedAt time.Time) error {
+	return s.updateMember(id, memberID, bson.M{"deleted_at": nil}, bson.M{"$set": bson.M{
+		"members.$.accepted":    true,
+		"members.$.accepted_at": acceptedAt,
+		"updated_at":            acceptedAt,
+	}})
+}
+
+// RemoveMember removes the member from the deck and records the removal, so
+// that the deck can be reported as gone to the clients of the member.
+func (s *DeckStore) RemoveMember(id, memberID string, removedAt time.Time) error {
+	return s.updateMember(id, memberID, bson.M{}, bson.M{
+		"$pull": bson.M{"members": bson.M{"user_id": memberID}},
+		"$push": bson.M{"removals": entity.DeckRemoval{UserID: memberID, RemovedAt: &removedAt}},
+	})
+}
+
+// FindChanged returns the decks of the user that were changed or deleted
+// after the given time, together with the decks the user was removed from
+// since then. If the time is zero, it returns all decks of the user that are
+// not deleted.
+func (s *DeckStore) FindChanged(userID string, since time.Time) ([]entity.Deck, error) {
+	access := []bson.M{
+		{"user_id": userID},
+		{"members": s.acceptedMember(userID)},
+	}
+
+	filter := bson.M{"deleted_at": nil, "$or": access}
+	if !since.IsZero() {
+		filter = bson.M{"$or": []bson.M{
+			{"$and": []bson.M{
+				{"$or": access},
+				{"$or": []bson.M{
+					{"updated_at": bson.M{"$gt": since}},
+					{"deleted_at": bson.M{"$gt": since}},
+				}},
+			}},
+			{"removals": bson.M{"$elemMatch": bson.M{
+				"user_id":    userID,
+				"removed_at": bson.M{"$gt": since},
+			}}},
+		}}
+	}
+
+	res, err := s.db.QueryDocuments(
+		DECK_COLLECTION,
+		filter,
+		options.Find().SetSort(bson.M{"_id": 1}),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	decks := []entity.Deck{}
+	err = res.All(context.TODO(), &decks)
+
+	return decks, err
+}
+
+// FindByColorNotIn returns the decks outside the trash whose color is not
+// one of the colors.
+func (s *DeckStore) FindByColorNotIn(colors []string) ([]entity.Deck, error) {
+	res, err := s.db.QueryDocuments(
+		DECK_COLLECTION,
+		bson.M{"deleted_at": nil, "color": bson.M{"$nin": colors}},
+		options.Find().
+			SetProjection(bson.M{"_id": 1, "user_id": 1, "name": 1, "color": 1}).
+			SetSort(bson.M{"_id": 1}),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	decks := []entity.Deck{}
+	err = res.All(context.TODO(), &decks)
+
+	return decks, err
+}
+
+func objectIDs(ids []string) []primitive.ObjectID {
+	objIDs := []primitive.ObjectID{}
+	for _, id := range ids {
+		if objID, err := primitive.ObjectIDFromHex(id); err == nil {
+			objIDs = append(objIDs, objID)
+		}
+	}
+
+	return objIDs
+}
+
+func textSearchOptions(limit int) *options.FindOptions {
+	score := bson.M{"score": bson.M{"$meta": "textScore"}}
+
+	return options.Find().SetProjection(score).SetSort(score).SetLimit(int64(limit))
+}
+
+// Search returns the decks whose name or description match the text, best
+// matches first.
+func (s *DeckStore) Search(query *entity.SearchQuery) ([]entity.DeckMatch, error) {
+	res, err := s.db.QueryDocuments(
+		DECK_COLLECTION,
+		bson.M{
+			"$text":      bson.M{"$search": query.Text},
+			"_id":        bson.M{"$in": objectIDs(query.DeckIDs)},
+			"deleted_at": nil,
+		},
+		textSearchOptions(query.Limit),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	decks := []entity.DeckMatch{}
+	err = res.All(context.TODO(), &decks)
+
+	return decks, err
+}
@@ -0,0 +1,107 @@
+package store
+
+import (
+	"context"
+	"time"
+
+	"github.com/moshrank/spacey-backend/pkg/db"
+	"github.com/moshrank/spacey-backend/pkg/logger"
+	"github.com/moshrank/spacey-backend/services/deck-management-service/entity"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+const MEDIA_COLLECTION = "media"
+
+type MediaStore struct {
+	db     db.DatabaseInterface
+	logger logger.LoggerInterface
+}
+
+func NewMediaStore(
+	db db.DatabaseInterface,
+	loggerObj logger.LoggerInterface,
+) entity.MediaStoreInterface {
+	return &MediaStore{
+		db:     db,
+		logger: loggerObj,
+	}
+}
+
+func (s *MediaStore) Save(media *entity.Media) (string, error) {
+	res, err := s.db.CreateDocument(MEDIA_COLLECTION, media)
+	if err != nil {
+		return "", err
+	}
+
+	return res.InsertedID.(primitive.ObjectID).Hex(), nil
+}
+
+func (s *MediaStore) FindByID(mediaID string) (*entity.Media, error) {
+	id, err := primitive.ObjectIDFromHex(mediaID)
+	if err != nil {
+		return nil, err
+	}
+
+	var media entity.Media
+	err = s.db.QueryDocument(MEDIA_COLLECTION, bson.M{"_id": id}).Decode(&media)
+
+	return &media, err
+}
+
+func (s *MediaStore) FindCreatedBefore(createdBefore time.Time) ([]entity.Media, error) {
+	res, err := s.db.QueryDocuments(
+		MEDIA_COLLECTION,
+		bson.M{"created_at": bson.M{"$lt": createdBefore}},
+		options.Find(),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	media := []entity.Media{}
+	err = res.All(context.TODO(), &media)
+
+	return media, err
+}
+
+// CountReferences counts the cards, card revisions and bulk operations
+// referencing the media file, including the cards in the trash, since they
+// can all still be restored.
+func (s *MediaStore) CountReferences(mediaID string) (int64, error) {
+	references := int64(0)
+	collections := []string{CARD_COLLECTION, CARD_REVISION_COLLECTION, BULK_OPERATION_COLLECTION}
+	for _, collection := range collections {
+		count, err := s.db.CountDocuments(collection, bson.M{"media": mediaID})
+		if err != nil {
+			return 0, err
+		}
+		references += count
+	}
+
+	return references, nil
+}
+
+// IsReferenced reports whether a card of the deck references the media file.
+func (s *MediaStore) IsReferenced(deckID, mediaID string) (bool, error) {
+	count, err := s.db.CountDocuments(
+		CARD_COLLECTION,
+		bson.M{"media": mediaID, "deck_id": deckID, "deleted_at": nil},
+		options.Count().SetLimit(1),
+	)
+
+	return count > 0, err
+}
+
+func (s *MediaStore) Delete(mediaID string) error {
+	id, err := primitive.ObjectIDFromHex(mediaID)
+	if err != nil {
+		return err
+	}
+
+	_, err = s.db.DeleteDocument(MEDIA_COLLECTION, bson.M{"_id": id})
+
+	return err
+}
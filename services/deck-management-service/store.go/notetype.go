@@ -0,0 +1,118 @@
+package store
+
+import (
+	"context"
+
+	"github.com/moshrank/spacey-backend/pkg/db"
+	"github.com/moshrank/spacey-backend/pkg/logger"
+	"github.com/moshrank/spacey-backend/services/deck-management-service/entity"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+const NOTE_TYPE_COLLECTION = "noteType"
+
+type NoteTypeStore struct {
+	db     db.DatabaseInterface
+	logger logger.LoggerInterface
+}
+
+func NewNoteTypeStore(
+	db db.DatabaseInterface,
+	loggerObj logger.LoggerInterface,
+) entity.NoteTypeStoreInterface {
+	return &NoteTypeStore{
+		db:     db,
+		logger: loggerObj,
+	}
+}
+
+func (s *NoteTypeStore) Save(noteType *entity.NoteType) (string, error) {
+	res, err := s.db.CreateDocument(NOTE_TYPE_COLLECTION, noteType)
+	if err != nil {
+		return "", err
+	}
+
+	return res.InsertedID.(primitive.ObjectID).Hex(), nil
+}
+
+func (s *NoteTypeStore) FindAll(userID string) ([]entity.NoteType, error) {
+	res, err := s.db.QueryDocuments(
+		NOTE_TYPE_COLLECTION,
+		bson.M{"user_id": userID},
+		options.Find().SetSort(bson.M{"name": 1}),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	noteTypes := []entity.NoteType{}
+	err = res.All(context.TODO(), &noteTypes)
+
+	return noteTypes, err
+}
+
+// FindByIDs returns the note types with the given ids regardless of their
+// owner, so the use cases decide who may use them.
+func (s *NoteTypeStore) FindByIDs(noteTypeIDs []string) ([]entity.NoteType, error) {
+	res, err := s.db.QueryDocuments(
+		NOTE_TYPE_COLLECTION,
+		bson.M{"_id": bson.M{"$in": objectIDs(noteTypeIDs)}},
+		options.Find(),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	noteTypes := []entity.NoteType{}
+	err = res.All(context.TODO(), &noteTypes)
+
+	return noteTypes, err
+}
+
+func (s *NoteTypeStore) Update(noteType *entity.NoteType) error {
+	id, err := primitive.ObjectIDFromHex(noteType.ID)
+	if err != nil {
+		return entity.ErrNoteTypeNotFound
+	}
+
+	res, err := s.db.UpdateDocument(
+		NOTE_TYPE_COLLECTION,
+		bson.M{"_id": id, "user_id": noteType.UserID},
+		bson.M{"$set": bson.M{
+			"name":       noteType.Name,
+			"fields":     noteType.Fields,
+			"templates":  noteType.Templates,
+			"updated_at": noteType.UpdatedAt,
+		}},
+	)
+	if err != nil {
+		return err
+	}
+
+	if res.MatchedCount == 0 {
+		return entity.ErrNoteTypeNotFound
+	}
+
+	return nil
+}
+
+func (s *NoteTypeStore) Delete(userID, noteTypeID string) error {
+	id, err := primitive.ObjectIDFromHex(noteTypeID)
+	if err != nil {
+		return entity.ErrNoteTypeNotFound
+	}
+
+	res, err := s.db.DeleteDocument(NOTE_TYPE_COLLECTION, bson.M{"_id": id, "user_id": userID})
+	if err != nil {
+		return err
+	}
+
+	if res.DeletedCount == 0 {
+		return entity.ErrNoteTypeNotFound
+	}
+
+	return nil
+}
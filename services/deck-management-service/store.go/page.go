@@ -0,0 +1,94 @@
+package store
+
+import (
+	"encoding/base64"
+	"encoding/json"
+
+	"github.com/moshrank/spacey-backend/services/deck-management-service/entity"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// pageCursor points to the last document of a page by its sort value and
+// id, since sort values do not have to be unique.
+type pageCursor struct {
+	Value string `json:"v,omitempty"`
+	ID    string `json:"id"`
+}
+
+func encodeCursor(value, id string) string {
+	cursor, _ := json.Marshal(pageCursor{Value: value, ID: id})
+
+	return base64.RawURLEncoding.EncodeToString(cursor)
+}
+
+func decodeCursor(cursor string) (*pageCursor, primitive.ObjectID, error) {
+	var decoded pageCursor
+
+	raw, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return nil, primitive.NilObjectID, entity.ErrInvalidCursor
+	}
+
+	if err := json.Unmarshal(raw, &decoded); err != nil {
+		return nil, primitive.NilObjectID, entity.ErrInvalidCursor
+	}
+
+	id, err := primitive.ObjectIDFromHex(decoded.ID)
+	if err != nil {
+		return nil, primitive.NilObjectID, entity.ErrInvalidCursor
+	}
+
+	return &decoded, id, nil
+}
+
+// pageFilter selects the documents after the cursor when sorted by field
+// and _id. An empty field sorts by _id only.
+func pageFilter(
+	field string,
+	descending bool,
+	cursor string,
+	parseValue func(string) (interface{}, error),
+) (bson.M, error) {
+	if cursor == "" {
+		return bson.M{}, nil
+	}
+
+	decoded, id, err := decodeCursor(cursor)
+	if err != nil {
+		return nil, err
+	}
+
+	op := "$gt"
+	if descending {
+		op = "$lt"
+	}
+
+	if field == "" {
+		return bson.M{"_id": bson.M{op: id}}, nil
+	}
+
+	value, err := parseValue(decoded.Value)
+	if err != nil {
+		return nil, entity.ErrInvalidCursor
+	}
+
+	return bson.M{"$or": []bson.M{
+		{field: bson.M{op: value}},
+		{field: value, "_id": bson.M{op: id}},
+	}}, nil
+}
+
+func pageSort(field string, descending bool) bson.D {
+	direction := 1
+	if descending {
+		direction = -1
+	}
+
+	if field == "" {
+		return bson.D{{Key: "_id", Value: direction}}
+	}
+
+	return bson.D{{Key: field, Value: direction}, {Key: "_id", Value: direction}}
+}
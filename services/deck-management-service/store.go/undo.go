@@ -0,0 +1,66 @@
+package store
+
+import (
+	"slices"
+
+	"github.com/moshrank/spacey-backend/pkg/db"
+	"github.com/moshrank/spacey-backend/pkg/logger"
+	"github.com/moshrank/spacey-backend/services/deck-management-service/entity"
+
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+// BULK_OPERATION_COLLECTION has a TTL index, so operations expire on their
+// own once they can no longer be undone.
+const BULK_OPERATION_COLLECTION = "bulkOperation"
+
+type UndoStore struct {
+	db     db.DatabaseInterface
+	logger logger.LoggerInterface
+}
+
+func NewUndoStore(
+	db db.DatabaseInterface,
+	loggerObj logger.LoggerInterface,
+) entity.UndoStoreInterface {
+	return &UndoStore{
+		db:     db,
+		logger: loggerObj,
+	}
+}
+
+// Save replaces the last bulk operation of the deck. The media files the
+// previous versions of changed cards reference are kept with it, so that
+// they are not removed while the operation can be undone.
+func (s *UndoStore) Save(operation *entity.BulkOperation) error {
+	if err := s.Delete(operation.DeckID); err != nil {
+		return err
+	}
+
+	operation.Media = []string{}
+	for i := range operation.ChangedCards {
+		for _, ref := range operation.ChangedCards[i].MediaRefs() {
+			if !slices.Contains(operation.Media, ref) {
+				operation.Media = append(operation.Media, ref)
+			}
+		}
+	}
+
+	_, err := s.db.CreateDocument(BULK_OPERATION_COLLECTION, operation)
+
+	return err
+}
+
+func (s *UndoStore) FindByDeckID(deckID string) (*entity.BulkOperation, error) {
+	var operation entity.BulkOperation
+	err := s.db.QueryDocument(BULK_OPERATION_COLLECTION, bson.M{"deck_id": deckID}).
+		Decode(&operation)
+
+	return &operation, err
+}
+
+func (s *UndoStore) Delete(deckID string) error {
+	_, err := s.db.DeleteDocuments(BULK_OPERATION_COLLECTION, bson.M{"deck_id": deckID})
+
+	return err
+}
@@ -0,0 +1,143 @@
+package usecase
+
+import (
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	mapper "github.com/PeteProgrammer/go-automapper"
+	"github.com/moshrank/spacey-backend/services/deck-management-service/entity"
+)
+
+const (
+	// maxActivityCardIDs limits the card ids kept per activity, so imports
+	// and bulk operations on thousands of cards stay small.
+	maxActivityCardIDs = 100
+	// maxActivityValueLength is the length values of changed fields are
+	// shortened to.
+	maxActivityValueLength = 100
+)
+
+// newActivity starts the record of a change the user made to the deck.
+func newActivity(
+	actorID, deckID, action string,
+	cardIDs []string,
+	timestamp time.Time,
+) entity.Activity {
+	activity := entity.Activity{
+		DeckID:    deckID,
+		ActorID:   actorID,
+		Action:    action,
+		Cards:     len(cardIDs),
+		CardIDs:   cardIDs,
+		CreatedAt: &timestamp,
+	}
+
+	if len(cardIDs) > maxActivityCardIDs {
+		activity.CardIDs = cardIDs[:maxActivityCardIDs]
+	}
+
+	return activity
+}
+
+// shortenValue cuts the value to maxActivityValueLength characters.
+func shortenValue(value string) string {
+	if utf8.RuneCountInString(value) <= maxActivityValueLength {
+		return value
+	}
+
+	return string([]rune(value)[:maxActivityValueLength-1]) + "…"
+}
+
+// addChange adds the field to the changes if its value changed.
+func addChange(changes []entity.ActivityChange, field, before, after string) []entity.ActivityChange {
+	if before == after {
+		return changes
+	}
+
+	return append(changes, entity.ActivityChange{
+		Field:  field,
+		Before: shortenValue(before),
+		After:  shortenValue(after),
+	})
+}
+
+// deckChanges summarizes how the deck changed.
+func deckChanges(before, after *entity.Deck) []entity.ActivityChange {
+	changes := []entity.ActivityChange{}
+	changes = addChange(changes, "name", before.Name, after.Name)
+	changes = addChange(changes, "description", before.Description, after.Description)
+	changes = addChange(changes, "color", before.Color, after.Color)
+	changes = addChange(
+		changes,
+		"public",
+		strconv.FormatBool(before.Public),
+		strconv.FormatBool(after.Public),
+	)
+	changes = addChange(changes, "parentID", before.ParentID, after.ParentID)
+
+	return changes
+}
+
+// cardChanges summarizes how the content of the card changed.
+func cardChanges(before, after *entity.Card) []entity.ActivityChange {
+	changes := []entity.ActivityChange{}
+	changes = addChange(changes, "question", before.Question, after.Question)
+	changes = addChange(changes, "answer", before.Answer, after.Answer)
+	changes = addChange(
+		changes,
+		"tags",
+		strings.Join(before.Tags, ", "),
+		strings.Join(after.Tags, ", "),
+	)
+	changes = addChange(changes, "type", cardType(before), cardType(after))
+
+	return changes
+}
+
+func cardType(card *entity.Card) string {
+	if card.Type == "" {
+		return entity.CardTypeBasic
+	}
+
+	return card.Type
+}
+
+type ActivityUseCase struct {
+	deckStore     entity.DeckStoreInterface
+	activityStore entity.ActivityStoreInterface
+}
+
+func NewActivityUseCase(
+	deckStore entity.DeckStoreInterface,
+	activityStore entity.ActivityStoreInterface,
+) entity.ActivityUseCaseInterface {
+	return &ActivityUseCase{
+		deckStore:     deckStore,
+		activityStore: activityStore,
+	}
+}
+
+// GetActivity returns the changes made to the deck, the latest first.
+func (u *ActivityUseCase) GetActivity(
+	userID, deckID string,
+	page *entity.PageReq,
+) ([]entity.ActivityRes, string, error) {
+	if _, err := authorizeDeck(u.deckStore, userID, deckID, entity.RoleViewer); err != nil {
+		return nil, "", err
+	}
+
+	activities, next, err := u.activityStore.FindByDeckID(
+		deckID,
+		&entity.Page{Limit: page.Limit, Cursor: page.Cursor},
+	)
+	if err != nil {
+		return nil, "", err
+	}
+
+	activitiesRes := []entity.ActivityRes{}
+	mapper.MapLoose(activities, &activitiesRes)
+
+	return activitiesRes, next, nil
+}
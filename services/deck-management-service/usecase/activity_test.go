@@ -0,0 +1,154 @@
+package usecase
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/moshrank/spacey-backend/services/deck-management-service/entity"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type ActivityStoreMock struct {
+	mock.Mock
+}
+
+func (s *ActivityStoreMock) Save(activity *entity.Activity) error {
+	args := s.Called(activity)
+	return args.Error(0)
+}
+
+func (s *ActivityStoreMock) FindByDeckID(
+	deckID string,
+	page *entity.Page,
+) ([]entity.Activity, string, error) {
+	args := s.Called(deckID, page)
+	return args.Get(0).([]entity.Activity), args.String(1), args.Error(2)
+}
+
+func newActivityStoreMock() *ActivityStoreMock {
+	activityStoreMock := new(ActivityStoreMock)
+	activityStoreMock.On("Save", mock.Anything).Return(nil)
+
+	return activityStoreMock
+}
+
+func TestGetActivity(t *testing.T) {
+	createdAt := time.Now()
+
+	activityStoreMock := new(ActivityStoreMock)
+	activityStoreMock.On("FindByDeckID", "test_deck_id", &entity.Page{Limit: 1}).
+		Return([]entity.Activity{
+			{
+				ID:        "activity_1",
+				DeckID:    "test_deck_id",
+				ActorID:   "2",
+				Action:    entity.ActivityCardUpdate,
+				Cards:     1,
+				CardIDs:   []string{"test_card_id"},
+				Changes:   []entity.ActivityChange{{Field: "answer", Before: "a", After: "b"}},
+				CreatedAt: &createdAt,
+			},
+		}, "next_cursor", nil)
+
+	activityUseCase := NewActivityUseCase(newOwnedDeckStoreMock(), activityStoreMock)
+
+	activities, next, err := activityUseCase.GetActivity(
+		"1",
+		"test_deck_id",
+		&entity.PageReq{Limit: 1},
+	)
+
+	assert.Nil(t, err)
+	assert.Equal(t, "next_cursor", next)
+	assert.Equal(t, []entity.ActivityRes{
+		{
+			ID:        "activity_1",
+			DeckID:    "test_deck_id",
+			ActorID:   "2",
+			Action:    entity.ActivityCardUpdate,
+			Cards:     1,
+			CardIDs:   []string{"test_card_id"},
+			Changes:   []entity.ActivityChange{{Field: "answer", Before: "a", After: "b"}},
+			CreatedAt: &createdAt,
+		},
+	}, activities)
+}
+
+func TestGetActivityNotMember(t *testing.T) {
+	deckStoreMock := new(DeckStoreMock)
+	deckStoreMock.On("FindByID", "2", "test_deck_id").
+		Return(&entity.Deck{}, errors.New("not found"))
+
+	activityStoreMock := new(ActivityStoreMock)
+	activityUseCase := NewActivityUseCase(deckStoreMock, activityStoreMock)
+
+	_, _, err := activityUseCase.GetActivity("2", "test_deck_id", &entity.PageReq{})
+
+	assert.Equal(t, entity.ErrDeckNotFound, err)
+	activityStoreMock.AssertNotCalled(t, "FindByDeckID", mock.Anything, mock.Anything)
+}
+
+func TestUpdateCardRecordsActivity(t *testing.T) {
+	cardStoreMock := new(CardStoreMock)
+	cardStoreMock.On("FindByIDs", "test_deck_id", []string{"test_card_id"}).
+		Return([]entity.Card{{ID: "test_card_id", Question: "Q", Answer: "A"}}, nil)
+	cardStoreMock.On("UpdateCard", "test_card_id", "1", "test_deck_id", mock.Anything).
+		Return(nil)
+
+	activityStoreMock := newActivityStoreMock()
+
+	cardUseCase := NewCardUseCase(
+		cardStoreMock,
+		newOwnedDeckStoreMock(),
+		newUndoStoreMock(),
+		new(NoteTypeStoreMock),
+		activityStoreMock,
+		new(MediaStoreMock),
+	)
+	_, err := cardUseCase.UpdateCard("test_card_id", "1", "test_deck_id", &entity.CardReq{
+		Question: "Q",
+		Answer:   "B",
+	}, nil)
+
+	assert.Nil(t, err)
+	activityStoreMock.AssertCalled(
+		t,
+		"Save",
+		mock.MatchedBy(func(activity *entity.Activity) bool {
+			return activity.ActorID == "1" &&
+				activity.DeckID == "test_deck_id" &&
+				activity.Action == entity.ActivityCardUpdate &&
+				assert.ObjectsAreEqual([]entity.ActivityChange{
+					{Field: "answer", Before: "A", After: "B"},
+				}, activity.Changes)
+		}),
+	)
+}
+
+func TestNewActivityLimitsCardIDs(t *testing.T) {
+	cardIDs := make([]string, maxActivityCardIDs+5)
+
+	activity := newActivity("1", "test_deck_id", entity.ActivityCardsImport, cardIDs, time.Now())
+
+	assert.Equal(t, maxActivityCardIDs+5, activity.Cards)
+	assert.Len(t, activity.CardIDs, maxActivityCardIDs)
+}
+
+func TestDeckChanges(t *testing.T) {
+	before := entity.Deck{Name: "Deck", Description: strings.Repeat("a", 200)}
+	after := entity.Deck{Name: "Deck", Description: "short", Public: true}
+
+	changes := deckChanges(&before, &after)
+
+	assert.Equal(t, []entity.ActivityChange{
+		{
+			Field:  "description",
+			Before: strings.Repeat("a", maxActivityValueLength-1) + "…",
+			After:  "short",
+		},
+		{Field: "public", Before: "false", After: "true"},
+	}, changes)
+}
@@ -0,0 +1,381 @@
+package usecase
+
+import (
+	"regexp"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/moshrank/spacey-backend/services/deck-management-service/entity"
+	"github.com/moshrank/spacey-backend/services/deck-management-service/external"
+	"github.com/moshrank/spacey-backend/services/deck-management-service/format"
+)
+
+type BulkUseCase struct {
+	deckStore       entity.DeckStoreInterface
+	cardStore       entity.CardStoreInterface
+	undoStore       entity.UndoStoreInterface
+	activityStore   entity.ActivityStoreInterface
+	mediaStore      entity.MediaStoreInterface
+	learningService external.LearningServiceInterface
+}
+
+func NewBulkUseCase(
+	deckStore entity.DeckStoreInterface,
+	cardStore entity.CardStoreInterface,
+	undoStore entity.UndoStoreInterface,
+	activityStore entity.ActivityStoreInterface,
+	mediaStore entity.MediaStoreInterface,
+	learningService external.LearningServiceInterface,
+) entity.BulkUseCaseInterface {
+	return &BulkUseCase{
+		deckStore:       deckStore,
+		cardStore:       cardStore,
+		undoStore:       undoStore,
+		activityStore:   activityStore,
+		mediaStore:      mediaStore,
+		learningService: learningService,
+	}
+}
+
+func cardIDsOf(cards []entity.Card) []string {
+	cardIDs := []string{}
+	for _, card := range cards {
+		cardIDs = append(cardIDs, card.ID)
+	}
+
+	return cardIDs
+}
+
+// BulkCards moves, copies or deletes the given cards of a deck. Cards that
+// are not in the deck are skipped.
+func (u *BulkUseCase) BulkCards(
+	userID, deckID string,
+	req *entity.BulkCardReq,
+) (*entity.BulkCardRes, error) {
+	role := entity.RoleEditor
+	if req.Action == entity.BulkCopy {
+		role = entity.RoleViewer
+	}
+
+	deck, err := authorizeDeck(u.deckStore, userID, deckID, role)
+	if err != nil {
+		return nil, err
+	}
+
+	cards, err := u.cardStore.FindByIDs(deckID, req.CardIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	if req.Action == entity.BulkDelete {
+		return u.deleteCards(userID, deck, cards)
+	}
+
+	if req.Action == entity.BulkMove && req.TargetDeckID == deckID {
+		return nil, entity.ErrSameDeck
+	}
+
+	target, err := authorizeDeck(u.deckStore, userID, req.TargetDeckID, entity.RoleEditor)
+	if err != nil {
+		return nil, err
+	}
+
+	if req.Action == entity.BulkMove {
+		return u.moveCards(userID, deck, target, cards)
+	}
+
+	return u.copyCards(userID, deck, target, cards)
+}
+
+func (u *BulkUseCase) deleteCards(
+	userID string,
+	deck *entity.Deck,
+	cards []entity.Card,
+) (*entity.BulkCardRes, error) {
+	res := entity.BulkCardRes{Action: entity.BulkDelete, Cards: len(cards)}
+	if len(cards) == 0 {
+		return &res, nil
+	}
+
+	timestamp := time.Now()
+	cardIDs := cardIDsOf(cards)
+
+	if err := u.cardStore.DeleteCards(deck.UserID, deck.ID, cardIDs, timestamp); err != nil {
+		return nil, err
+	}
+
+	operation := newBulkOperation(deck, entity.OperationDelete, timestamp)
+	operation.DeletedCards = cardIDs
+	if err := u.undoStore.Save(&operation); err != nil {
+		return nil, err
+	}
+
+	activity := newActivity(userID, deck.ID, entity.ActivityCardsDelete, cardIDs, timestamp)
+	if err := u.activityStore.Save(&activity); err != nil {
+		return nil, err
+	}
+
+	return &res, nil
+}
+
+// moveCards moves the cards and their learning history to the target deck.
+// Moves can not be undone, since the undo of a deck only covers its own
+// cards.
+func (u *BulkUseCase) moveCards(
+	userID string,
+	deck, target *entity.Deck,
+	cards []entity.Card,
+) (*entity.BulkCardRes, error) {
+	res := entity.BulkCardRes{Action: entity.BulkMove, Cards: len(cards)}
+	if len(cards) == 0 {
+		return &res, nil
+	}
+
+	timestamp := time.Now()
+	cardIDs := cardIDsOf(cards)
+
+	err := u.cardStore.MoveCards(deck.UserID, deck.ID, cardIDs, target, timestamp)
+	if err != nil {
+		return nil, err
+	}
+
+	activity := newActivity(userID, deck.ID, entity.ActivityCardsMove, cardIDs, timestamp)
+	activity.TargetDeckID = target.ID
+	if err := u.activityStore.Save(&activity); err != nil {
+		return nil, err
+	}
+
+	if err := u.learningService.MoveCards(cardIDs, target.ID); err != nil {
+		// the cards are already moved, so only the history stays behind
+		res.HistoryError = err.Error()
+	}
+
+	return &res, nil
+}
+
+// copyCards adds copies of the cards to the target deck. The copies start
+// without learning history.
+func (u *BulkUseCase) copyCards(
+	userID string,
+	deck, target *entity.Deck,
+	cards []entity.Card,
+) (*entity.BulkCardRes, error) {
+	res := entity.BulkCardRes{Action: entity.BulkCopy, CardIDs: []string{}}
+	if len(cards) == 0 {
+		return &res, nil
+	}
+
+	timestamp := time.Now()
+	noteIDs := map[string]string{}
+	copies := []entity.Card{}
+	for _, card := range cards {
+		copied := cardContent(&card)
+		copied.ID = ""
+		copied.UserID = target.UserID
+		copied.DeckID = target.ID
+		copied.Source = card.Source
+		copied.SourceRef = card.SourceRef
+		copied.CreatedAt = &timestamp
+		copied.UpdatedAt = &timestamp
+
+		// copies of siblings are siblings of a new note
+		if copied.NoteID != "" {
+			if _, ok := noteIDs[copied.NoteID]; !ok {
+				noteIDs[copied.NoteID] = newNoteID()
+			}
+			copied.NoteID = noteIDs[copied.NoteID]
+		}
+
+		copies = append(copies, copied)
+	}
+
+	cardIDs, err := u.cardStore.SaveCards(target.ID, target.UserID, copies)
+	if err != nil {
+		return nil, err
+	}
+
+	operation := newBulkOperation(target, entity.OperationCopy, timestamp)
+	operation.CreatedCards = cardIDs
+	if err := u.undoStore.Save(&operation); err != nil {
+		return nil, err
+	}
+
+	// the activity holds the ids of the copies
+	activity := newActivity(userID, deck.ID, entity.ActivityCardsCopy, cardIDs, timestamp)
+	activity.TargetDeckID = target.ID
+	if err := u.activityStore.Save(&activity); err != nil {
+		return nil, err
+	}
+
+	res.Cards = len(cardIDs)
+	res.CardIDs = cardIDs
+
+	return &res, nil
+}
+
+// replaceCardText replaces text in the given fields of the card. Cloze cards
+// are changed in their cloze text and rendered again, image occlusion cards
+// in the labels of their masks and their extra information, and the choices
+// of multiple choice cards are changed together with the answer.
+func replaceCardText(
+	card entity.Card,
+	fields []string,
+	replace func(text string) string,
+) entity.Card {
+	changed := card
+
+	if card.Cloze != nil {
+		cloze := *card.Cloze
+		if slices.Contains(fields, entity.FieldQuestion) {
+			cloze.Text = replace(cloze.Text)
+		}
+		if slices.Contains(fields, entity.FieldAnswer) {
+			cloze.Extra = replace(cloze.Extra)
+		}
+
+		changed.Cloze = &cloze
+		changed.Question, changed.Answer = renderCloze(&cloze, card.Ordinal)
+
+		return changed
+	}
+
+	if slices.Contains(fields, entity.FieldQuestion) {
+		changed.Question = replace(card.Question)
+	}
+
+	if card.Occlusion != nil {
+		if slices.Contains(fields, entity.FieldAnswer) {
+			occlusion := *card.Occlusion
+			occlusion.Extra = replace(occlusion.Extra)
+			occlusion.Masks = []entity.OcclusionMask{}
+			for _, mask := range card.Occlusion.Masks {
+				mask.Label = replace(mask.Label)
+				occlusion.Masks = append(occlusion.Masks, mask)
+			}
+
+			changed.Occlusion = &occlusion
+			changed.Answer = renderOcclusion(&occlusion, card.Ordinal)
+		}
+
+		return changed
+	}
+
+	if slices.Contains(fields, entity.FieldAnswer) {
+		changed.Answer = replace(card.Answer)
+
+		if len(card.Choices) > 0 {
+			changed.Choices = []string{}
+			for _, choice := range card.Choices {
+				changed.Choices = append(changed.Choices, replace(choice))
+			}
+		}
+	}
+
+	return changed
+}
+
+// FindReplace replaces the text in the questions and answers of all cards
+// of the deck. Only the question and answer are searched if no fields are
+// given.
+func (u *BulkUseCase) FindReplace(
+	userID, deckID string,
+	req *entity.FindReplaceReq,
+) (*entity.FindReplaceRes, error) {
+	deck, err := authorizeDeck(u.deckStore, userID, deckID, entity.RoleEditor)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := loadCards(u.cardStore, deck); err != nil {
+		return nil, err
+	}
+
+	expr := regexp.QuoteMeta(req.Find)
+	if !req.MatchCase {
+		expr = "(?i)" + expr
+	}
+	pattern := regexp.MustCompile(expr)
+
+	fields := req.Fields
+	if len(fields) == 0 {
+		fields = []string{entity.FieldQuestion, entity.FieldAnswer}
+	}
+
+	timestamp := time.Now()
+	operation := newBulkOperation(deck, entity.OperationReplace, timestamp)
+	res := entity.FindReplaceRes{}
+	originals := []entity.Card{}
+	updates := []entity.Card{}
+
+	for _, card := range deck.Cards {
+		replacements := 0
+		// a replacement may complete a tag, so the result is sanitized again
+		replace := func(text string) string {
+			replacements += len(pattern.FindAllStringIndex(text, -1))
+			return format.SanitizeMarkdown(pattern.ReplaceAllLiteralString(text, req.Replace))
+		}
+
+		changed := replaceCardText(card, fields, replace)
+		if replacements == 0 {
+			continue
+		}
+
+		// the fields of a custom note are changed along with its cards, so
+		// that editing the note later keeps the replacement
+		if card.Custom != nil {
+			custom := *card.Custom
+			custom.Fields = map[string]string{}
+			for field, value := range card.Custom.Fields {
+				custom.Fields[field] = format.SanitizeMarkdown(
+					pattern.ReplaceAllLiteralString(value, req.Replace),
+				)
+			}
+			changed.Custom = &custom
+		}
+
+		operation.ChangedCards = append(operation.ChangedCards, cardContent(&card))
+
+		changed.Source = entity.SourceManual
+		changed.UpdatedAt = &timestamp
+
+		originals = append(originals, card)
+		updates = append(updates, changed)
+		res.Cards++
+		res.Replacements += replacements
+	}
+
+	// a replacement may add media references, which are checked before any
+	// card is changed
+	err = checkMediaRefs(u.mediaStore, u.deckStore, userID, deckID, updates, originals)
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range updates {
+		err := u.cardStore.UpdateCard(originals[i].ID, deck.UserID, deckID, &updates[i])
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if res.Cards > 0 {
+		if err := u.undoStore.Save(&operation); err != nil {
+			return nil, err
+		}
+
+		activity := newActivity(
+			userID,
+			deckID,
+			entity.ActivityCardsReplace,
+			cardIDsOf(operation.ChangedCards),
+			timestamp,
+		)
+		activity.Changes = addChange(nil, strings.Join(fields, ", "), req.Find, req.Replace)
+		if err := u.activityStore.Save(&activity); err != nil {
+			return nil, err
+		}
+	}
+
+	return &res, nil
+}
@@ -0,0 +1,377 @@
+package usecase
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/moshrank/spacey-backend/services/deck-management-service/entity"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func newBulkDeckStoreMock() *DeckStoreMock {
+	deckStoreMock := new(DeckStoreMock)
+	deckStoreMock.On("FindByID", "1", "source_deck_id").
+		Return(&entity.Deck{ID: "source_deck_id", UserID: "1"}, nil)
+	deckStoreMock.On("FindByID", "1", "target_deck_id").
+		Return(&entity.Deck{ID: "target_deck_id", UserID: "2", Members: []entity.DeckMember{
+			{UserID: "1", Role: entity.RoleEditor, Accepted: true},
+		}}, nil)
+	deckStoreMock.On("FindByID", "1", "shared_deck_id").
+		Return(&entity.Deck{ID: "shared_deck_id", UserID: "2", Members: []entity.DeckMember{
+			{UserID: "1", Role: entity.RoleViewer, Accepted: true},
+		}}, nil)
+
+	return deckStoreMock
+}
+
+func TestBulkMoveCards(t *testing.T) {
+	cardStoreMock := new(CardStoreMock)
+	cardStoreMock.On("FindByIDs", "source_deck_id", []string{"card_1", "card_2", "unknown"}).
+		Return([]entity.Card{{ID: "card_1"}, {ID: "card_2"}}, nil)
+	cardStoreMock.On(
+		"MoveCards",
+		"1",
+		"source_deck_id",
+		[]string{"card_1", "card_2"},
+		mock.Anything,
+		mock.Anything,
+	).Return(nil)
+
+	learningServiceMock := new(LearningServiceMock)
+	learningServiceMock.On("MoveCards", []string{"card_1", "card_2"}, "target_deck_id").
+		Return(errors.New("learning service unavailable"))
+
+	bulkUseCase := NewBulkUseCase(
+		newBulkDeckStoreMock(),
+		cardStoreMock,
+		newUndoStoreMock(),
+		newActivityStoreMock(),
+		new(MediaStoreMock),
+		learningServiceMock,
+	)
+
+	res, err := bulkUseCase.BulkCards("1", "source_deck_id", &entity.BulkCardReq{
+		Action:       entity.BulkMove,
+		CardIDs:      []string{"card_1", "card_2", "unknown"},
+		TargetDeckID: "target_deck_id",
+	})
+
+	assert.Nil(t, err)
+	assert.Equal(t, &entity.BulkCardRes{
+		Action:       entity.BulkMove,
+		Cards:        2,
+		HistoryError: "learning service unavailable",
+	}, res)
+
+	target := cardStoreMock.Calls[1].Arguments.Get(3).(*entity.Deck)
+	assert.Equal(t, "target_deck_id", target.ID)
+	assert.Equal(t, "2", target.UserID)
+}
+
+func TestBulkMoveCardsForbidden(t *testing.T) {
+	tests := []struct {
+		testName     string
+		deckID       string
+		targetDeckID string
+		wantErr      error
+	}{
+		{
+			"Same Deck",
+			"source_deck_id",
+			"source_deck_id",
+			entity.ErrSameDeck,
+		},
+		{
+			"Viewer Of Target",
+			"source_deck_id",
+			"shared_deck_id",
+			entity.ErrForbidden,
+		},
+		{
+			"Viewer Of Source",
+			"shared_deck_id",
+			"source_deck_id",
+			entity.ErrForbidden,
+		},
+	}
+
+	cardStoreMock := new(CardStoreMock)
+	cardStoreMock.On("FindByIDs", mock.Anything, mock.Anything).
+		Return([]entity.Card{{ID: "card_1"}}, nil)
+
+	bulkUseCase := NewBulkUseCase(
+		newBulkDeckStoreMock(),
+		cardStoreMock,
+		newUndoStoreMock(),
+		newActivityStoreMock(),
+		new(MediaStoreMock),
+		new(LearningServiceMock),
+	)
+
+	for _, test := range tests {
+		t.Run(test.testName, func(t *testing.T) {
+			_, err := bulkUseCase.BulkCards("1", test.deckID, &entity.BulkCardReq{
+				Action:       entity.BulkMove,
+				CardIDs:      []string{"card_1"},
+				TargetDeckID: test.targetDeckID,
+			})
+
+			assert.Equal(t, test.wantErr, err)
+		})
+	}
+
+	cardStoreMock.AssertNotCalled(
+		t,
+		"MoveCards",
+		mock.Anything,
+		mock.Anything,
+		mock.Anything,
+		mock.Anything,
+		mock.Anything,
+	)
+}
+
+func TestBulkCopyCards(t *testing.T) {
+	cardStoreMock := new(CardStoreMock)
+	cardStoreMock.On("FindByIDs", "shared_deck_id", []string{"card_1"}).
+		Return([]entity.Card{{
+			ID:       "card_1",
+			Question: "Question",
+			Answer:   "Answer",
+			UserID:   "2",
+			DeckID:   "shared_deck_id",
+			Tags:     []string{"verbs"},
+			Source:   entity.SourceImport,
+			Origin:   &entity.CardOrigin{CardID: "origin_card"},
+		}}, nil)
+	cardStoreMock.On("SaveCards", "source_deck_id", "1", mock.Anything).
+		Return([]string{"copy_1"}, nil)
+
+	undoStoreMock := newUndoStoreMock()
+
+	bulkUseCase := NewBulkUseCase(
+		newBulkDeckStoreMock(),
+		cardStoreMock,
+		undoStoreMock,
+		newActivityStoreMock(),
+		new(MediaStoreMock),
+		new(LearningServiceMock),
+	)
+
+	res, err := bulkUseCase.BulkCards("1", "shared_deck_id", &entity.BulkCardReq{
+		Action:       entity.BulkCopy,
+		CardIDs:      []string{"card_1"},
+		TargetDeckID: "source_deck_id",
+	})
+
+	assert.Nil(t, err)
+	assert.Equal(t, &entity.BulkCardRes{
+		Action:  entity.BulkCopy,
+		Cards:   1,
+		CardIDs: []string{"copy_1"},
+	}, res)
+
+	copies := cardStoreMock.Calls[1].Arguments.Get(2).([]entity.Card)
+	assert.Equal(t, "Question", copies[0].Question)
+	assert.Equal(t, []string{"verbs"}, copies[0].Tags)
+	assert.Equal(t, "source_deck_id", copies[0].DeckID)
+	assert.Equal(t, "1", copies[0].UserID)
+	assert.Nil(t, copies[0].Origin)
+
+	operation := undoStoreMock.Calls[0].Arguments.Get(0).(*entity.BulkOperation)
+	assert.Equal(t, "source_deck_id", operation.DeckID)
+	assert.Equal(t, entity.OperationCopy, operation.Operation)
+	assert.Equal(t, []string{"copy_1"}, operation.CreatedCards)
+}
+
+func TestBulkDeleteCards(t *testing.T) {
+	cardStoreMock := new(CardStoreMock)
+	cardStoreMock.On("FindByIDs", "source_deck_id", []string{"card_1", "card_2"}).
+		Return([]entity.Card{{ID: "card_1"}, {ID: "card_2"}}, nil)
+	cardStoreMock.On(
+		"DeleteCards",
+		"1",
+		"source_deck_id",
+		[]string{"card_1", "card_2"},
+		mock.Anything,
+	).Return(nil)
+
+	undoStoreMock := newUndoStoreMock()
+
+	bulkUseCase := NewBulkUseCase(
+		newBulkDeckStoreMock(),
+		cardStoreMock,
+		undoStoreMock,
+		newActivityStoreMock(),
+		new(MediaStoreMock),
+		new(LearningServiceMock),
+	)
+
+	res, err := bulkUseCase.BulkCards("1", "source_deck_id", &entity.BulkCardReq{
+		Action:  entity.BulkDelete,
+		CardIDs: []string{"card_1", "card_2"},
+	})
+
+	assert.Nil(t, err)
+	assert.Equal(t, &entity.BulkCardRes{Action: entity.BulkDelete, Cards: 2}, res)
+
+	operation := undoStoreMock.Calls[0].Arguments.Get(0).(*entity.BulkOperation)
+	assert.Equal(t, entity.OperationDelete, operation.Operation)
+	assert.Equal(t, []string{"card_1", "card_2"}, operation.DeletedCards)
+}
+
+func TestFindReplace(t *testing.T) {
+	cardStoreMock := new(CardStoreMock)
+	cardStoreMock.On("FindByDeckID", "source_deck_id", &entity.Page{}).
+		Return([]entity.Card{
+			{ID: "card_1", Question: "Colour of the sky", Answer: "Blue colour"},
+			{ID: "card_2", Question: "Capital of France", Answer: "Paris"},
+			{ID: "card_3", Question: "COLOUR of grass", Answer: "Green"},
+		}, "", nil)
+	cardStoreMock.On("UpdateCard", mock.Anything, "1", "source_deck_id", mock.Anything).
+		Return(nil)
+
+	undoStoreMock := newUndoStoreMock()
+
+	bulkUseCase := NewBulkUseCase(
+		newBulkDeckStoreMock(),
+		cardStoreMock,
+		undoStoreMock,
+		newActivityStoreMock(),
+		new(MediaStoreMock),
+		new(LearningServiceMock),
+	)
+
+	res, err := bulkUseCase.FindReplace("1", "source_deck_id", &entity.FindReplaceReq{
+		Find:    "colour",
+		Replace: "color",
+	})
+
+	assert.Nil(t, err)
+	assert.Equal(t, &entity.FindReplaceRes{Cards: 2, Replacements: 3}, res)
+
+	first := cardStoreMock.Calls[1].Arguments.Get(3).(*entity.Card)
+	assert.Equal(t, "color of the sky", first.Question)
+	assert.Equal(t, "Blue color", first.Answer)
+
+	operation := undoStoreMock.Calls[0].Arguments.Get(0).(*entity.BulkOperation)
+	assert.Equal(t, entity.OperationReplace, operation.Operation)
+	assert.Equal(t, "Colour of the sky", operation.ChangedCards[0].Question)
+	assert.Equal(t, "COLOUR of grass", operation.ChangedCards[1].Question)
+}
+
+func TestFindReplaceMatchCase(t *testing.T) {
+	cardStoreMock := new(CardStoreMock)
+	cardStoreMock.On("FindByDeckID", "source_deck_id", &entity.Page{}).
+		Return([]entity.Card{
+			{ID: "card_1", Question: "Colour of the sky", Answer: "Blue colour"},
+		}, "", nil)
+	cardStoreMock.On("UpdateCard", mock.Anything, "1", "source_deck_id", mock.Anything).
+		Return(nil)
+
+	bulkUseCase := NewBulkUseCase(
+		newBulkDeckStoreMock(),
+		cardStoreMock,
+		newUndoStoreMock(),
+		newActivityStoreMock(),
+		new(MediaStoreMock),
+		new(LearningServiceMock),
+	)
+
+	res, err := bulkUseCase.FindReplace("1", "source_deck_id", &entity.FindReplaceReq{
+		Find:      "colour",
+		Replace:   "color",
+		Fields:    []string{entity.FieldQuestion},
+		MatchCase: true,
+	})
+
+	assert.Nil(t, err)
+	assert.Equal(t, &entity.FindReplaceRes{}, res)
+	cardStoreMock.AssertNotCalled(
+		t,
+		"UpdateCard",
+		mock.Anything,
+		mock.Anything,
+		mock.Anything,
+		mock.Anything,
+	)
+}
+
+func TestFindReplaceCloze(t *testing.T) {
+	cardStoreMock := new(CardStoreMock)
+	cardStoreMock.On("FindByDeckID", "source_deck_id", &entity.Page{}).
+		Return([]entity.Card{{
+			ID:       "card_1",
+			Question: "[...] is the capital",
+			Answer:   "Paris is the capital",
+			Type:     entity.CardTypeCloze,
+			Ordinal:  1,
+			Cloze:    &entity.ClozeNote{Text: "{{c1::Paris}} is the capital"},
+		}}, "", nil)
+	cardStoreMock.On("UpdateCard", "card_1", "1", "source_deck_id", mock.Anything).Return(nil)
+
+	bulkUseCase := NewBulkUseCase(
+		newBulkDeckStoreMock(),
+		cardStoreMock,
+		newUndoStoreMock(),
+		newActivityStoreMock(),
+		new(MediaStoreMock),
+		new(LearningServiceMock),
+	)
+
+	res, err := bulkUseCase.FindReplace("1", "source_deck_id", &entity.FindReplaceReq{
+		Find:    "Paris",
+		Replace: "Berlin",
+	})
+
+	assert.Nil(t, err)
+	assert.Equal(t, &entity.FindReplaceRes{Cards: 1, Replacements: 1}, res)
+
+	changed := cardStoreMock.Calls[1].Arguments.Get(3).(*entity.Card)
+	assert.Equal(t, "{{c1::Berlin}} is the capital", changed.Cloze.Text)
+	assert.Equal(t, "[...] is the capital", changed.Question)
+	assert.Equal(t, "Berlin is the capital", changed.Answer)
+}
+
+func TestFindReplaceImageOcclusion(t *testing.T) {
+	cardStoreMock := new(CardStoreMock)
+	cardStoreMock.On("FindByDeckID", "source_deck_id", &entity.Page{}).
+		Return([]entity.Card{{
+			ID:       "card_1",
+			Question: "media:61f0c1e5d3b7a0c3f8a1b2c4",
+			Answer:   "Femur",
+			Type:     entity.CardTypeImageOcclusion,
+			Ordinal:  1,
+			Occlusion: &entity.OcclusionNote{
+				Image: "61f0c1e5d3b7a0c3f8a1b2c4",
+				Masks: []entity.OcclusionMask{
+					{Ordinal: 1, Width: 0.1, Height: 0.1, Label: "Femur"},
+					{Ordinal: 2, X: 0.5, Width: 0.1, Height: 0.1, Label: "Femur head"},
+				},
+			},
+		}}, "", nil)
+	cardStoreMock.On("UpdateCard", "card_1", "1", "source_deck_id", mock.Anything).Return(nil)
+
+	bulkUseCase := NewBulkUseCase(
+		newBulkDeckStoreMock(),
+		cardStoreMock,
+		newUndoStoreMock(),
+		newActivityStoreMock(),
+		new(MediaStoreMock),
+		new(LearningServiceMock),
+	)
+
+	res, err := bulkUseCase.FindReplace("1", "source_deck_id", &entity.FindReplaceReq{
+		Find:    "Femur",
+		Replace: "Thigh bone",
+	})
+
+	assert.Nil(t, err)
+	assert.Equal(t, &entity.FindReplaceRes{Cards: 1, Replacements: 2}, res)
+
+	changed := cardStoreMock.Calls[1].Arguments.Get(3).(*entity.Card)
+	assert.Equal(t, "Thigh bone", changed.Answer)
+	assert.Equal(t, "Thigh bone head", changed.Occlusion.Masks[1].Label)
+	assert.Equal(t, "media:61f0c1e5d3b7a0c3f8a1b2c4", changed.Question)
+}
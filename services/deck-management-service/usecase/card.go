@@ -8,92 +8,564 @@ import (
 )
 
 type CardUseCase struct {
-	cardStore entity.CardStoreInterface
+	cardStore     entity.CardStoreInterface
+	deckStore     entity.DeckStoreInterface
+	undoStore     entity.UndoStoreInterface
+	noteTypeStore entity.NoteTypeStoreInterface
+	activityStore entity.ActivityStoreInterface
+	mediaStore    entity.MediaStoreInterface
 }
 
-func NewCardUseCase(cardStore entity.CardStoreInterface) entity.CardUseCaseInterface {
+func NewCardUseCase(
+	cardStore entity.CardStoreInterface,
+	deckStore entity.DeckStoreInterface,
+	undoStore entity.UndoStoreInterface,
+	noteTypeStore entity.NoteTypeStoreInterface,
+	activityStore entity.ActivityStoreInterface,
+	mediaStore entity.MediaStoreInterface,
+) entity.CardUseCaseInterface {
 	return &CardUseCase{
-		cardStore: cardStore,
+		cardStore:     cardStore,
+		deckStore:     deckStore,
+		undoStore:     undoStore,
+		noteTypeStore: noteTypeStore,
+		activityStore: activityStore,
+		mediaStore:    mediaStore,
 	}
 }
 
+// cardSource returns the source of a change requested by the client, which
+// is a manual edit unless the client says otherwise.
+func cardSource(source string) string {
+	if source == "" {
+		return entity.SourceManual
+	}
+
+	return source
+}
+
+// loadCards loads all cards of the deck, since decks are looked up without
+// their cards.
+func loadCards(cardStore entity.CardStoreInterface, deck *entity.Deck) error {
+	cards, _, err := cardStore.FindByDeckID(deck.ID, &entity.Page{})
+	if err != nil {
+		return err
+	}
+
+	deck.Cards = cards
+
+	return nil
+}
+
+// newCards expands the card requests into the cards to store in the deck.
+// Sibling cards of a note share a new note id.
+func newCards(
+	deck *entity.Deck,
+	deckID string,
+	reqs []entity.CardReq,
+	noteTypes map[string]*entity.NoteType,
+	timestamp time.Time,
+) ([]entity.Card, error) {
+	cards := []entity.Card{}
+	for i := range reqs {
+		expanded, err := expandCard(&reqs[i], noteTypes)
+		if err != nil {
+			return nil, err
+		}
+
+		noteID := ""
+		if hasSiblings(reqs[i].Type) {
+			noteID = newNoteID()
+		}
+
+		for _, card := range expanded {
+			card.NoteID = noteID
+			card.UserID = deck.UserID
+			card.DeckID = deckID
+			card.CreatedAt = &timestamp
+			card.UpdatedAt = &timestamp
+			card.DeletedAt = nil
+
+			cards = append(cards, card)
+		}
+	}
+
+	return cards, nil
+}
+
+// CreateCard creates the card and returns it. If the card is a note with
+// several sibling cards, all of them are created and the first one is
+// returned. A card that duplicates another card is flagged and, if
+// duplicates are skipped, returned without being created.
 func (c *CardUseCase) CreateCard(
 	deckID, userID string,
 	card *entity.CardReq,
+	duplicates *entity.DuplicateReq,
 ) (*entity.CardRes, error) {
-	var cardDB entity.Card
-	mapper.MapLoose(card, &cardDB)
+	deck, err := authorizeDeck(c.deckStore, userID, deckID, entity.RoleEditor)
+	if err != nil {
+		return nil, err
+	}
+
+	reqs := []entity.CardReq{*card}
+	noteTypes, err := loadNoteTypes(c.noteTypeStore, userID, deck, reqs, nil)
+	if err != nil {
+		return nil, err
+	}
 
 	timestamp := time.Now()
-	cardDB.CreatedAt = &timestamp
-	cardDB.UpdatedAt = &timestamp
-	cardDB.DeletedAt = nil
-	cardDB.UserID = userID
+	cardDBs, err := newCards(deck, deckID, reqs, noteTypes, timestamp)
+	if err != nil {
+		return nil, err
+	}
 
-	cardID, err := c.cardStore.SaveCard(deckID, userID, &cardDB)
+	err = checkMediaRefs(c.mediaStore, c.deckStore, userID, deckID, cardDBs, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	index, err := loadDuplicateIndex(c.deckStore, c.cardStore, userID, deckID, duplicates)
 	if err != nil {
 		return nil, err
 	}
 
 	var cardRes entity.CardRes
-	mapper.MapLoose(&cardDB, &cardRes)
-	cardRes.ID = cardID
+	mapper.MapLoose(&cardDBs[0], &cardRes)
+	renderCard(&cardRes)
+	cardRes.Duplicate = index.find(&cardDBs[0])
+
+	if skipDuplicate(duplicates, cardRes.Duplicate) {
+		cardRes.Skipped = true
+		return &cardRes, nil
+	}
+
+	var cardIDs []string
+	if len(cardDBs) == 1 {
+		cardRes.ID, err = c.cardStore.SaveCard(deckID, deck.UserID, &cardDBs[0])
+		if err != nil {
+			return nil, err
+		}
+		cardIDs = []string{cardRes.ID}
+	} else {
+		cardIDs, err = c.cardStore.SaveCards(deckID, deck.UserID, cardDBs)
+		if err != nil {
+			return nil, err
+		}
+
+		cardRes.ID = cardIDs[0]
+	}
+
+	activity := newActivity(userID, deckID, entity.ActivityCardCreate, cardIDs, timestamp)
+	if err := c.activityStore.Save(&activity); err != nil {
+		return nil, err
+	}
 
 	return &cardRes, nil
 }
 
+// UpdateCard changes the card if its version matches ifMatch. The siblings
+// of reversed, cloze, image occlusion and custom cards are changed with it:
+// siblings for new cloze deletions, masks or filled fields are created and
+// the ones whose deletion or mask was removed or whose fields were emptied
+// are moved to the trash. A card that is changed to a type without siblings
+// leaves its note.
 func (c *CardUseCase) UpdateCard(
 	cardID, userID, deckID string,
 	card *entity.CardReq,
+	ifMatch entity.IfMatch,
 ) (*entity.CardRes, error) {
-	var cardDB entity.Card
-	mapper.MapLoose(card, &cardDB)
+	deck, err := authorizeDeck(c.deckStore, userID, deckID, entity.RoleEditor)
+	if err != nil {
+		return nil, err
+	}
+
+	edited, err := c.findCard(deckID, cardID, ifMatch)
+	if err != nil {
+		return nil, err
+	}
+
+	if hasSiblings(card.Type) {
+		return c.updateNote(userID, deck, edited, deckID, card)
+	}
+
+	cards, err := expandCard(card, nil)
+	if err != nil {
+		return nil, err
+	}
 
+	err = checkMediaRefs(c.mediaStore, c.deckStore, userID, deckID, cards, []entity.Card{*edited})
+	if err != nil {
+		return nil, err
+	}
+
+	cardDB := cards[0]
 	timestamp := time.Now()
 	cardDB.UpdatedAt = &timestamp
-	cardDB.DeletedAt = nil
+	cardDB.Version = edited.Version
+	// the passage a card was generated from stays the same when it is edited
+	cardDB.SourceRef = edited.SourceRef
 
-	err := c.cardStore.UpdateCard(cardID, userID, deckID, &cardDB)
+	err = c.cardStore.UpdateCard(cardID, deck.UserID, deckID, &cardDB)
 	if err != nil {
 		return nil, err
 	}
+	cardDB.Version++
+
+	activity := newActivity(userID, deckID, entity.ActivityCardUpdate, []string{cardID}, timestamp)
+	activity.Changes = cardChanges(edited, &cardDB)
+	if err := c.activityStore.Save(&activity); err != nil {
+		return nil, err
+	}
 
 	var cardRes entity.CardRes
 	mapper.MapLoose(&cardDB, &cardRes)
+	cardRes.ID = cardID
+	cardRes.DeckID = deckID
+	renderCard(&cardRes)
 
 	return &cardRes, nil
 }
 
-func (c *CardUseCase) DeleteCard(userID, deckID, cardID string) error {
-	return c.cardStore.DeleteCard(userID, deckID, cardID)
+// findCard returns the card if its version matches ifMatch.
+func (c *CardUseCase) findCard(
+	deckID, cardID string,
+	ifMatch entity.IfMatch,
+) (*entity.Card, error) {
+	found, err := c.cardStore.FindByIDs(deckID, []string{cardID})
+	if err != nil {
+		return nil, err
+	}
+
+	if len(found) == 0 {
+		return nil, entity.ErrCardNotFound
+	}
+
+	if !ifMatch.Matches(found[0].Version) {
+		return nil, entity.ErrVersionConflict
+	}
+
+	return &found[0], nil
 }
 
+func (c *CardUseCase) updateNote(
+	userID string,
+	deck *entity.Deck,
+	edited *entity.Card,
+	deckID string,
+	card *entity.CardReq,
+) (*entity.CardRes, error) {
+	// the reverse card is edited the way round it is shown
+	req := *card
+	if edited.Type == entity.CardTypeReversed && edited.Ordinal == entity.OrdinalReverse {
+		req.Question, req.Answer = card.Answer, card.Question
+	}
+
+	noteTypes, err := loadNoteTypes(
+		c.noteTypeStore,
+		userID,
+		deck,
+		[]entity.CardReq{req},
+		edited,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	cards, err := expandCard(&req, noteTypes)
+	if err != nil {
+		return nil, err
+	}
+
+	siblings := map[int]entity.Card{}
+	noteID := edited.NoteID
+	if noteID == "" {
+		noteID = newNoteID()
+		siblings[cards[0].Ordinal] = *edited
+	} else {
+		noteCards, err := c.cardStore.FindByNoteID(deckID, noteID)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, sibling := range noteCards {
+			siblings[sibling.Ordinal] = sibling
+		}
+
+		// the edited card must still have the version ifMatch was checked
+		// against
+		siblings[edited.Ordinal] = *edited
+	}
+
+	previous := []entity.Card{}
+	for _, sibling := range siblings {
+		previous = append(previous, sibling)
+	}
+
+	err = checkMediaRefs(c.mediaStore, c.deckStore, userID, deckID, cards, previous)
+	if err != nil {
+		return nil, err
+	}
+
+	timestamp := time.Now()
+	cardsRes, err := saveNote(
+		c.cardStore,
+		deck.UserID,
+		deckID,
+		noteID,
+		cards,
+		siblings,
+		timestamp,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	cardIDs := []string{}
+	for _, cardRes := range cardsRes {
+		cardIDs = append(cardIDs, cardRes.ID)
+	}
+
+	for i := range cardsRes {
+		if cardsRes[i].ID != edited.ID {
+			continue
+		}
+
+		var updated entity.Card
+		mapper.MapLoose(&cardsRes[i], &updated)
+
+		activity := newActivity(userID, deckID, entity.ActivityCardUpdate, cardIDs, timestamp)
+		activity.Changes = cardChanges(edited, &updated)
+		if err := c.activityStore.Save(&activity); err != nil {
+			return nil, err
+		}
+
+		return &cardsRes[i], nil
+	}
+
+	// the deletion, mask or template of the edited card was removed
+	return nil, entity.ErrCardNotFound
+}
+
+// saveNote stores the cards of a note in place of its siblings with the same
+// ordinals. Cards without a sibling are created and the siblings left over
+// are moved to the trash. It returns the cards that replaced a sibling.
+func saveNote(
+	cardStore entity.CardStoreInterface,
+	ownerID, deckID, noteID string,
+	cards []entity.Card,
+	siblings map[int]entity.Card,
+	timestamp time.Time,
+) ([]entity.CardRes, error) {
+	added := []entity.Card{}
+	cardsRes := []entity.CardRes{}
+
+	for _, cardDB := range cards {
+		cardDB.NoteID = noteID
+		cardDB.UpdatedAt = &timestamp
+
+		sibling, ok := siblings[cardDB.Ordinal]
+		if !ok {
+			cardDB.UserID = ownerID
+			cardDB.DeckID = deckID
+			cardDB.CreatedAt = &timestamp
+			added = append(added, cardDB)
+			continue
+		}
+
+		delete(siblings, cardDB.Ordinal)
+
+		cardDB.Version = sibling.Version
+		cardDB.SourceRef = sibling.SourceRef
+		err := cardStore.UpdateCard(sibling.ID, ownerID, deckID, &cardDB)
+		if err != nil {
+			return nil, err
+		}
+		cardDB.Version++
+
+		var cardRes entity.CardRes
+		mapper.MapLoose(&cardDB, &cardRes)
+		cardRes.ID = sibling.ID
+		cardRes.DeckID = deckID
+		renderCard(&cardRes)
+		cardsRes = append(cardsRes, cardRes)
+	}
+
+	if len(added) > 0 {
+		if _, err := cardStore.SaveCards(deckID, ownerID, added); err != nil {
+			return nil, err
+		}
+	}
+
+	if len(siblings) > 0 {
+		removed := []string{}
+		for _, sibling := range siblings {
+			removed = append(removed, sibling.ID)
+		}
+
+		if err := cardStore.DeleteCards(ownerID, deckID, removed, timestamp); err != nil {
+			return nil, err
+		}
+	}
+
+	return cardsRes, nil
+}
+
+// DeleteCard moves the card to the trash if its version matches ifMatch.
+func (c *CardUseCase) DeleteCard(userID, deckID, cardID string, ifMatch entity.IfMatch) error {
+	deck, err := authorizeDeck(c.deckStore, userID, deckID, entity.RoleEditor)
+	if err != nil {
+		return err
+	}
+
+	if ifMatch != nil {
+		if _, err := c.findCard(deckID, cardID, ifMatch); err != nil {
+			return err
+		}
+	}
+
+	timestamp := time.Now()
+	if err := c.cardStore.DeleteCard(deck.UserID, deckID, cardID, timestamp); err != nil {
+		return err
+	}
+
+	activity := newActivity(userID, deckID, entity.ActivityCardDelete, []string{cardID}, timestamp)
+
+	return c.activityStore.Save(&activity)
+}
+
+// CreateCards creates the cards in the deck. Notes with several sibling
+// cards add all of them to the result. Cards that duplicate another card,
+// including an earlier one of the request, are flagged and, if duplicates are
+// skipped, returned without being created.
 func (c *CardUseCase) CreateCards(
 	deckID, userID string,
 	cards []entity.CardReq,
+	duplicates *entity.DuplicateReq,
 ) ([]entity.CardRes, error) {
-	var cardDBs []entity.Card
-	mapper.MapLoose(cards, &cardDBs)
+	deck, err := authorizeDeck(c.deckStore, userID, deckID, entity.RoleEditor)
+	if err != nil {
+		return nil, err
+	}
+
+	index, err := loadDuplicateIndex(c.deckStore, c.cardStore, userID, deckID, duplicates)
+	if err != nil {
+		return nil, err
+	}
+
+	noteTypes, err := loadNoteTypes(c.noteTypeStore, userID, deck, cards, nil)
+	if err != nil {
+		return nil, err
+	}
 
 	timestamp := time.Now()
-	for i := range cardDBs {
-		cardDBs[i].CreatedAt = &timestamp
-		cardDBs[i].UpdatedAt = &timestamp
-		cardDBs[i].DeletedAt = nil
-		cardDBs[i].UserID = userID
-		cardDBs[i].DeckID = deckID
+	cardDBs := []entity.Card{}
+	cardsRes := []entity.CardRes{}
+	created := []int{}
+	for i := range cards {
+		noteCards, err := newCards(deck, deckID, cards[i:i+1], noteTypes, timestamp)
+		if err != nil {
+			return nil, err
+		}
+
+		duplicate := index.find(&noteCards[0])
+		index.add(&noteCards[0])
+
+		if skipDuplicate(duplicates, duplicate) {
+			var cardRes entity.CardRes
+			mapper.MapLoose(&noteCards[0], &cardRes)
+			renderCard(&cardRes)
+			cardRes.Duplicate = duplicate
+			cardRes.Skipped = true
+			cardsRes = append(cardsRes, cardRes)
+			continue
+		}
+
+		for j := range noteCards {
+			var cardRes entity.CardRes
+			mapper.MapLoose(&noteCards[j], &cardRes)
+			renderCard(&cardRes)
+			cardRes.Duplicate = duplicate
+			created = append(created, len(cardsRes))
+			cardsRes = append(cardsRes, cardRes)
+		}
+		cardDBs = append(cardDBs, noteCards...)
 	}
 
-	cardIDs, err := c.cardStore.SaveCards(deckID, userID, cardDBs)
+	if len(cardDBs) == 0 {
+		return cardsRes, nil
+	}
+
+	err = checkMediaRefs(c.mediaStore, c.deckStore, userID, deckID, cardDBs, nil)
 	if err != nil {
 		return nil, err
 	}
 
-	var cardsRes []entity.CardRes
-	mapper.MapLoose(cardDBs, &cardsRes)
-	for i := range cardsRes {
-		cardsRes[i].ID = cardIDs[i]
+	cardIDs, err := c.cardStore.SaveCards(deckID, deck.UserID, cardDBs)
+	if err != nil {
+		return nil, err
+	}
+
+	operation := newBulkOperation(deck, entity.OperationCreate, timestamp)
+	operation.CreatedCards = cardIDs
+	if err := c.undoStore.Save(&operation); err != nil {
+		return nil, err
+	}
+
+	for i, cardIndex := range created {
+		cardsRes[cardIndex].ID = cardIDs[i]
+	}
+
+	activity := newActivity(userID, deckID, entity.ActivityCardCreate, cardIDs, timestamp)
+	if err := c.activityStore.Save(&activity); err != nil {
+		return nil, err
 	}
 
 	return cardsRes, nil
 }
+
+func (c *CardUseCase) GetCards(
+	userID, deckID string,
+	page *entity.PageReq,
+) ([]entity.CardRes, string, error) {
+	if _, err := authorizeDeck(c.deckStore, userID, deckID, entity.RoleViewer); err != nil {
+		return nil, "", err
+	}
+
+	cards, next, err := c.cardStore.FindByDeckID(
+		deckID,
+		&entity.Page{Limit: page.Limit, Cursor: page.Cursor},
+	)
+	if err != nil {
+		return nil, "", err
+	}
+
+	cardsRes := []entity.CardRes{}
+	mapper.MapLoose(cards, &cardsRes)
+	renderCards(cardsRes)
+
+	return cardsRes, next, nil
+}
+
+// sanitizeBatchSize is how many stored cards are sanitized at once.
+const sanitizeBatchSize = 100
+
+// SanitizeStoredCards sanitizes the content of the cards that were stored
+// before card content was sanitized on write, and returns how many it
+// sanitized.
+func (c *CardUseCase) SanitizeStoredCards() (int, error) {
+	sanitized := 0
+	for {
+		cards, err := c.cardStore.FindUnsanitized(sanitizeBatchSize)
+		if err != nil || len(cards) == 0 {
+			return sanitized, err
+		}
+
+		for i := range cards {
+			sanitizeCard(&cards[i])
+			if err := c.cardStore.SaveSanitized(&cards[i]); err != nil {
+				return sanitized, err
+			}
+			sanitized++
+		}
+	}
+}
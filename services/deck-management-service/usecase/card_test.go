@@ -1,7 +1,9 @@
 package usecase
 
 import (
+	"errors"
 	"testing"
+	"time"
 
 	"github.com/moshrank/spacey-backend/services/deck-management-service/entity"
 	"github.com/stretchr/testify/assert"
@@ -22,11 +24,34 @@ func (c *CardStoreMock) UpdateCard(cardID, userID, deckID string, card *entity.C
 	return args.Error(0)
 }
 
-func (c *CardStoreMock) DeleteCard(userID, deckID, cardID string) error {
-	args := c.Called(userID, deckID, cardID)
+func (c *CardStoreMock) SyncCard(cardID, userID, deckID string, card *entity.Card) error {
+	args := c.Called(cardID, userID, deckID, card)
+	return args.Error(0)
+}
+
+func (c *CardStoreMock) DeleteCard(
+	userID, deckID, cardID string,
+	deletedAt time.Time,
+) error {
+	args := c.Called(userID, deckID, cardID, deletedAt)
+	return args.Error(0)
+}
+
+func (c *CardStoreMock) FindDeleted(deckIDs []string) ([]entity.Card, error) {
+	args := c.Called(deckIDs)
+	return args.Get(0).([]entity.Card), args.Error(1)
+}
+
+func (c *CardStoreMock) RestoreCard(userID, deckID, cardID string, restoredAt time.Time) error {
+	args := c.Called(userID, deckID, cardID, restoredAt)
 	return args.Error(0)
 }
 
+func (c *CardStoreMock) Purge(deletedBefore time.Time) (int64, error) {
+	args := c.Called(deletedBefore)
+	return args.Get(0).(int64), args.Error(1)
+}
+
 func (c *CardStoreMock) SaveCards(
 	deckID, userID string,
 	cards []entity.Card,
@@ -35,6 +60,98 @@ func (c *CardStoreMock) SaveCards(
 	return args.Get(0).([]string), args.Error(1)
 }
 
+func (c *CardStoreMock) FindByDeckID(
+	deckID string,
+	page *entity.Page,
+) ([]entity.Card, string, error) {
+	args := c.Called(deckID, page)
+	return args.Get(0).([]entity.Card), args.String(1), args.Error(2)
+}
+
+func (c *CardStoreMock) FindByIDs(deckID string, cardIDs []string) ([]entity.Card, error) {
+	args := c.Called(deckID, cardIDs)
+	return args.Get(0).([]entity.Card), args.Error(1)
+}
+
+func (c *CardStoreMock) FindByNoteID(deckID, noteID string) ([]entity.Card, error) {
+	args := c.Called(deckID, noteID)
+	return args.Get(0).([]entity.Card), args.Error(1)
+}
+
+func (c *CardStoreMock) CountByDeckIDs(deckIDs []string) (map[string]int, error) {
+	args := c.Called(deckIDs)
+	return args.Get(0).(map[string]int), args.Error(1)
+}
+
+func (c *CardStoreMock) MoveCards(
+	userID, deckID string,
+	cardIDs []string,
+	target *entity.Deck,
+	movedAt time.Time,
+) error {
+	args := c.Called(userID, deckID, cardIDs, target, movedAt)
+	return args.Error(0)
+}
+
+func (c *CardStoreMock) Search(query *entity.SearchQuery) ([]entity.CardMatch, error) {
+	args := c.Called(query)
+	return args.Get(0).([]entity.CardMatch), args.Error(1)
+}
+
+func (c *CardStoreMock) DeleteCards(
+	userID, deckID string,
+	cardIDs []string,
+	deletedAt time.Time,
+) error {
+	args := c.Called(userID, deckID, cardIDs, deletedAt)
+	return args.Error(0)
+}
+
+func (c *CardStoreMock) FindRevisions(deckID, cardID string) ([]entity.CardRevision, error) {
+	args := c.Called(deckID, cardID)
+	return args.Get(0).([]entity.CardRevision), args.Error(1)
+}
+
+func (c *CardStoreMock) FindRevision(
+	deckID, cardID, revisionID string,
+) (*entity.CardRevision, error) {
+	args := c.Called(deckID, cardID, revisionID)
+	return args.Get(0).(*entity.CardRevision), args.Error(1)
+}
+
+func (c *CardStoreMock) FindChanged(deckIDs []string, since time.Time) ([]entity.Card, error) {
+	args := c.Called(deckIDs, since)
+	return args.Get(0).([]entity.Card), args.Error(1)
+}
+
+func (c *CardStoreMock) FindByNoteTypeID(noteTypeID string) ([]entity.Card, error) {
+	args := c.Called(noteTypeID)
+	return args.Get(0).([]entity.Card), args.Error(1)
+}
+
+func (c *CardStoreMock) HasNoteType(noteTypeID string) (bool, error) {
+	args := c.Called(noteTypeID)
+	return args.Bool(0), args.Error(1)
+}
+
+func (c *CardStoreMock) FindUnsanitized(limit int) ([]entity.Card, error) {
+	args := c.Called(limit)
+	return args.Get(0).([]entity.Card), args.Error(1)
+}
+
+func (c *CardStoreMock) SaveSanitized(card *entity.Card) error {
+	args := c.Called(card)
+	return args.Error(0)
+}
+
+func newOwnedDeckStoreMock() *DeckStoreMock {
+	deckStoreMock := new(DeckStoreMock)
+	deckStoreMock.On("FindByID", mock.Anything, mock.Anything).
+		Return(&entity.Deck{ID: "test_deck_id", UserID: "1"}, nil)
+
+	return deckStoreMock
+}
+
 func TestCreateCardValidCard(t *testing.T) {
 	inpCard := entity.CardReq{
 		Question: "Test Question",
@@ -43,23 +160,105 @@ func TestCreateCardValidCard(t *testing.T) {
 	}
 
 	expCard := entity.CardRes{
-		ID:       "test_card_id",
-		Question: "Test Question",
-		Answer:   "Test Answer",
-		DeckID:   "test_deck_id",
+		ID:           "test_card_id",
+		Question:     "Test Question",
+		Answer:       "Test Answer",
+		QuestionHTML: "<p>Test Question</p>",
+		AnswerHTML:   "<p>Test Answer</p>",
+		DeckID:       "test_deck_id",
+		Type:         entity.CardTypeBasic,
 	}
 
 	cardStoreMock := new(CardStoreMock)
 	cardStoreMock.On("SaveCard", mock.Anything, mock.Anything, mock.Anything).
 		Return("test_card_id", nil)
 
-	cardUseCase := NewCardUseCase(cardStoreMock)
-	card, err := cardUseCase.CreateCard("1", "1", &inpCard)
+	cardUseCase := NewCardUseCase(
+		cardStoreMock,
+		newOwnedDeckStoreMock(),
+		newUndoStoreMock(),
+		new(NoteTypeStoreMock),
+		newActivityStoreMock(),
+		new(MediaStoreMock),
+	)
+	card, err := cardUseCase.CreateCard("test_deck_id", "1", &inpCard, nil)
 
 	assert.Nil(t, err)
 	assert.Equal(t, &expCard, card)
 }
 
+func TestCreateCardWithSourceRef(t *testing.T) {
+	sourceRef := entity.SourceRef{NoteID: "note_id", StartIndex: 10, EndIndex: 42}
+	inpCard := entity.CardReq{
+		Question:  "hablar",
+		Answer:    "to speak",
+		DeckID:    "test_deck_id",
+		Type:      entity.CardTypeReversed,
+		Source:    entity.SourceGenerated,
+		SourceRef: &sourceRef,
+	}
+
+	cardStoreMock := new(CardStoreMock)
+	cardStoreMock.On(
+		"SaveCards",
+		"test_deck_id",
+		"1",
+		mock.MatchedBy(func(cards []entity.Card) bool {
+			return len(cards) == 2 && *cards[0].SourceRef == sourceRef &&
+				*cards[1].SourceRef == sourceRef
+		}),
+	).Return([]string{"card_1", "card_2"}, nil)
+
+	cardUseCase := NewCardUseCase(
+		cardStoreMock,
+		newOwnedDeckStoreMock(),
+		newUndoStoreMock(),
+		new(NoteTypeStoreMock),
+		newActivityStoreMock(),
+		new(MediaStoreMock),
+	)
+	card, err := cardUseCase.CreateCard("test_deck_id", "1", &inpCard, nil)
+
+	assert.Nil(t, err)
+	assert.Equal(t, &sourceRef, card.SourceRef)
+}
+
+func TestCreateCardSanitizesContent(t *testing.T) {
+	inpCard := entity.CardReq{
+		Question: `**hablar**<img src="media:abc" onerror="alert(1)">`,
+		Answer:   "to speak<script>alert(1)</script>",
+		DeckID:   "test_deck_id",
+	}
+
+	cardStoreMock := new(CardStoreMock)
+	cardStoreMock.On(
+		"SaveCard",
+		"test_deck_id",
+		"1",
+		mock.MatchedBy(func(card *entity.Card) bool {
+			return card.Question == `**hablar**<img src="media:abc">` && card.Answer == "to speak"
+		}),
+	).Return("test_card_id", nil)
+
+	cardUseCase := NewCardUseCase(
+		cardStoreMock,
+		newOwnedDeckStoreMock(),
+		newUndoStoreMock(),
+		new(NoteTypeStoreMock),
+		newActivityStoreMock(),
+		new(MediaStoreMock),
+	)
+	card, err := cardUseCase.CreateCard("test_deck_id", "1", &inpCard, nil)
+
+	assert.Nil(t, err)
+	assert.Equal(
+		t,
+		`<p><strong>hablar</strong><img src="/decks/test_deck_id/media/abc"></p>`,
+		card.QuestionHTML,
+	)
+	assert.Equal(t, "<p>to speak</p>", card.AnswerHTML)
+}
+
 func TestUpdateCard(t *testing.T) {
 	inpCard := entity.CardReq{
 		ID:       "test_card_id",
@@ -69,33 +268,117 @@ func TestUpdateCard(t *testing.T) {
 	}
 
 	expOutCard := entity.CardRes{
-		ID:       "test_card_id",
-		Question: "Test Question",
-		Answer:   "Test Answer",
-		DeckID:   "test_deck_id",
+		ID:           "test_card_id",
+		Question:     "Test Question",
+		Answer:       "Test Answer",
+		QuestionHTML: "<p>Test Question</p>",
+		AnswerHTML:   "<p>Test Answer</p>",
+		DeckID:       "test_deck_id",
+		Type:         entity.CardTypeBasic,
+		Version:      3,
 	}
 
 	cardStoreMock := new(CardStoreMock)
-	cardStoreMock.On("UpdateCard", mock.Anything, mock.Anything, mock.Anything, mock.Anything).
-		Return(nil)
+	cardStoreMock.On("FindByIDs", "test_deck_id", []string{"test_card_id"}).
+		Return([]entity.Card{{ID: "test_card_id", Version: 2}}, nil)
+	// the change is limited to the version the card was read in
+	cardStoreMock.On(
+		"UpdateCard",
+		"test_card_id",
+		"1",
+		"test_deck_id",
+		mock.MatchedBy(func(card *entity.Card) bool { return card.Version == 2 }),
+	).Return(nil)
 
-	cardUseCase := NewCardUseCase(cardStoreMock)
-	newCard, err := cardUseCase.UpdateCard("1", "1", "test_card_id", &inpCard)
+	cardUseCase := NewCardUseCase(
+		cardStoreMock,
+		newOwnedDeckStoreMock(),
+		newUndoStoreMock(),
+		new(NoteTypeStoreMock),
+		newActivityStoreMock(),
+		new(MediaStoreMock),
+	)
+	newCard, err := cardUseCase.UpdateCard("test_card_id", "1", "test_deck_id", &inpCard, nil)
 
 	assert.Nil(t, err)
 	assert.Equal(t, &expOutCard, newCard)
 }
 
+func TestUpdateChangedCard(t *testing.T) {
+	tests := []struct {
+		testName  string
+		ifMatch   entity.IfMatch
+		updateErr error
+	}{
+		{"Version Does Not Match", entity.IfMatch{1}, nil},
+		{"Changed Concurrently", entity.IfMatch{2}, entity.ErrVersionConflict},
+	}
+
+	for _, test := range tests {
+		t.Run(test.testName, func(t *testing.T) {
+			cardStoreMock := new(CardStoreMock)
+			cardStoreMock.On("FindByIDs", "test_deck_id", []string{"test_card_id"}).
+				Return([]entity.Card{{ID: "test_card_id", Version: 2}}, nil)
+			cardStoreMock.On("UpdateCard", "test_card_id", "1", "test_deck_id", mock.Anything).
+				Return(test.updateErr)
+
+			cardUseCase := NewCardUseCase(
+				cardStoreMock,
+				newOwnedDeckStoreMock(),
+				newUndoStoreMock(),
+				new(NoteTypeStoreMock),
+				newActivityStoreMock(),
+				new(MediaStoreMock),
+			)
+			_, err := cardUseCase.UpdateCard(
+				"test_card_id",
+				"1",
+				"test_deck_id",
+				&entity.CardReq{Question: "Q", Answer: "A"},
+				test.ifMatch,
+			)
+
+			assert.Equal(t, entity.ErrVersionConflict, err)
+		})
+	}
+}
+
 func TestDeleteCard(t *testing.T) {
 	cardStoreMock := new(CardStoreMock)
-	cardStoreMock.On("DeleteCard", mock.Anything, mock.Anything, mock.Anything).Return(nil)
+	cardStoreMock.On("DeleteCard", "1", "1", "test_card_id", mock.Anything).Return(nil)
 
-	cardUseCase := NewCardUseCase(cardStoreMock)
-	err := cardUseCase.DeleteCard("1", "1", "test_card_id")
+	cardUseCase := NewCardUseCase(
+		cardStoreMock,
+		newOwnedDeckStoreMock(),
+		newUndoStoreMock(),
+		new(NoteTypeStoreMock),
+		newActivityStoreMock(),
+		new(MediaStoreMock),
+	)
+	err := cardUseCase.DeleteCard("1", "1", "test_card_id", nil)
 
 	assert.Nil(t, err)
 }
 
+func TestDeleteChangedCard(t *testing.T) {
+	cardStoreMock := new(CardStoreMock)
+	cardStoreMock.On("FindByIDs", "1", []string{"test_card_id"}).
+		Return([]entity.Card{{ID: "test_card_id", Version: 2}}, nil)
+
+	cardUseCase := NewCardUseCase(
+		cardStoreMock,
+		newOwnedDeckStoreMock(),
+		newUndoStoreMock(),
+		new(NoteTypeStoreMock),
+		newActivityStoreMock(),
+		new(MediaStoreMock),
+	)
+	err := cardUseCase.DeleteCard("1", "1", "test_card_id", entity.IfMatch{1})
+
+	assert.Equal(t, entity.ErrVersionConflict, err)
+	cardStoreMock.AssertNotCalled(t, "DeleteCard", mock.Anything, mock.Anything, mock.Anything)
+}
+
 func TestCreateCards(t *testing.T) {
 	inpCards := []entity.CardReq{
 		{
@@ -107,10 +390,13 @@ func TestCreateCards(t *testing.T) {
 
 	expOutputCards := []entity.CardRes{
 		{
-			ID:       "test_card_id",
-			Question: "Test Question",
-			Answer:   "Test Answer",
-			DeckID:   "test_deck_id",
+			ID:           "test_card_id",
+			Question:     "Test Question",
+			Answer:       "Test Answer",
+			QuestionHTML: "<p>Test Question</p>",
+			AnswerHTML:   "<p>Test Answer</p>",
+			DeckID:       "test_deck_id",
+			Type:         entity.CardTypeBasic,
 		},
 	}
 
@@ -118,10 +404,150 @@ func TestCreateCards(t *testing.T) {
 	cardStoreMock.On("SaveCards", mock.Anything, mock.Anything, mock.Anything).
 		Return([]string{"test_card_id"}, nil)
 
-	cardUseCase := NewCardUseCase(cardStoreMock)
+	cardUseCase := NewCardUseCase(
+		cardStoreMock,
+		newOwnedDeckStoreMock(),
+		newUndoStoreMock(),
+		new(NoteTypeStoreMock),
+		newActivityStoreMock(),
+		new(MediaStoreMock),
+	)
 
-	cards, err := cardUseCase.CreateCards("test_deck_id", "1", inpCards)
+	cards, err := cardUseCase.CreateCards("test_deck_id", "1", inpCards, nil)
 
 	assert.Nil(t, err)
 	assert.Equal(t, expOutputCards, cards)
 }
+
+func TestCreateCardAsViewer(t *testing.T) {
+	deckStoreMock := new(DeckStoreMock)
+	deckStoreMock.On("FindByID", "2", "test_deck_id").Return(&entity.Deck{
+		ID:     "test_deck_id",
+		UserID: "1",
+		Members: []entity.DeckMember{
+			{UserID: "2", Role: entity.RoleViewer, Accepted: true},
+		},
+	}, nil)
+
+	cardStoreMock := new(CardStoreMock)
+
+	cardUseCase := NewCardUseCase(
+		cardStoreMock,
+		deckStoreMock,
+		newUndoStoreMock(),
+		new(NoteTypeStoreMock),
+		newActivityStoreMock(),
+		new(MediaStoreMock),
+	)
+	_, err := cardUseCase.CreateCard("test_deck_id", "2", &entity.CardReq{}, nil)
+
+	assert.Equal(t, entity.ErrForbidden, err)
+	cardStoreMock.AssertNotCalled(t, "SaveCard", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestCreateCardAsEditor(t *testing.T) {
+	deckStoreMock := new(DeckStoreMock)
+	deckStoreMock.On("FindByID", "2", "test_deck_id").Return(&entity.Deck{
+		ID:     "test_deck_id",
+		UserID: "1",
+		Members: []entity.DeckMember{
+			{UserID: "2", Role: entity.RoleEditor, Accepted: true},
+		},
+	}, nil)
+
+	cardStoreMock := new(CardStoreMock)
+	cardStoreMock.On("SaveCard", "test_deck_id", "1", mock.Anything).Return("test_card_id", nil)
+
+	cardUseCase := NewCardUseCase(
+		cardStoreMock,
+		deckStoreMock,
+		newUndoStoreMock(),
+		new(NoteTypeStoreMock),
+		newActivityStoreMock(),
+		new(MediaStoreMock),
+	)
+	card, err := cardUseCase.CreateCard("test_deck_id", "2", &entity.CardReq{}, nil)
+
+	assert.Nil(t, err)
+	assert.Equal(t, "test_card_id", card.ID)
+}
+
+func TestGetCards(t *testing.T) {
+	deckStoreMock := newOwnedDeckStoreMock()
+
+	cardStoreMock := new(CardStoreMock)
+	cardStoreMock.On("FindByDeckID", "test_deck_id", &entity.Page{Limit: 1, Cursor: "first"}).
+		Return([]entity.Card{{ID: "card_2", Question: "Test Question"}}, "second", nil)
+
+	cardUseCase := NewCardUseCase(
+		cardStoreMock,
+		deckStoreMock,
+		newUndoStoreMock(),
+		new(NoteTypeStoreMock),
+		newActivityStoreMock(),
+		new(MediaStoreMock),
+	)
+
+	cards, next, err := cardUseCase.GetCards(
+		"1",
+		"test_deck_id",
+		&entity.PageReq{Limit: 1, Cursor: "first"},
+	)
+
+	assert.Nil(t, err)
+	assert.Equal(t, "second", next)
+	assert.Equal(
+		t,
+		[]entity.CardRes{
+			{ID: "card_2", Question: "Test Question", QuestionHTML: "<p>Test Question</p>"},
+		},
+		cards,
+	)
+}
+
+func TestGetCardsNotMember(t *testing.T) {
+	deckStoreMock := new(DeckStoreMock)
+	deckStoreMock.On("FindByID", "2", "test_deck_id").
+		Return(&entity.Deck{}, errors.New("not found"))
+
+	cardStoreMock := new(CardStoreMock)
+	cardUseCase := NewCardUseCase(
+		cardStoreMock,
+		deckStoreMock,
+		newUndoStoreMock(),
+		new(NoteTypeStoreMock),
+		newActivityStoreMock(),
+		new(MediaStoreMock),
+	)
+
+	_, _, err := cardUseCase.GetCards("2", "test_deck_id", &entity.PageReq{})
+
+	assert.Equal(t, entity.ErrDeckNotFound, err)
+	cardStoreMock.AssertNotCalled(t, "FindByDeckID", mock.Anything, mock.Anything)
+}
+
+func TestSanitizeStoredCards(t *testing.T) {
+	cardStoreMock := new(CardStoreMock)
+	cardStoreMock.On("FindUnsanitized", sanitizeBatchSize).Return([]entity.Card{
+		{ID: "card_1", Question: `<b onclick="alert(1)">Q</b>`, Answer: "A", Version: 2},
+	}, nil).Once()
+	cardStoreMock.On("FindUnsanitized", sanitizeBatchSize).Return([]entity.Card{}, nil).Once()
+	cardStoreMock.On("SaveSanitized", &entity.Card{
+		ID: "card_1", Question: "<b>Q</b>", Answer: "A", Version: 2,
+	}).Return(nil)
+
+	cardUseCase := NewCardUseCase(
+		cardStoreMock,
+		new(DeckStoreMock),
+		newUndoStoreMock(),
+		new(NoteTypeStoreMock),
+		newActivityStoreMock(),
+		new(MediaStoreMock),
+	)
+
+	sanitized, err := cardUseCase.SanitizeStoredCards()
+
+	assert.Nil(t, err)
+	assert.Equal(t, 1, sanitized)
+	cardStoreMock.AssertExpectations(t)
+}
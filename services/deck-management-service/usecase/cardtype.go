@@ -0,0 +1,290 @@
+package usecase
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"regexp"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/moshrank/spacey-backend/services/deck-management-service/entity"
+	"github.com/moshrank/spacey-backend/services/deck-management-service/format"
+)
+
+// clozeDeletion matches deletions like {{c1::answer}} or {{c1::answer::hint}}.
+var clozeDeletion = regexp.MustCompile(`{{c(\d+)::(.*?)(?:::(.*?))?}}`)
+
+// newNoteID returns the id shared by the sibling cards of a note.
+func newNoteID() string {
+	id := make([]byte, 12)
+	rand.Read(id)
+
+	return hex.EncodeToString(id)
+}
+
+// renderCloze returns the question and answer of the cloze card with the
+// given ordinal. The question hides its deletions behind their hint.
+func renderCloze(cloze *entity.ClozeNote, ordinal int) (string, string) {
+	question := clozeDeletion.ReplaceAllStringFunc(cloze.Text, func(deletion string) string {
+		match := clozeDeletion.FindStringSubmatch(deletion)
+		if match[1] != strconv.Itoa(ordinal) {
+			return match[2]
+		}
+
+		if match[3] != "" {
+			return "[" + match[3] + "]"
+		}
+
+		return "[...]"
+	})
+
+	answer := clozeDeletion.ReplaceAllString(cloze.Text, "$2")
+	if cloze.Extra != "" {
+		answer += "\n\n" + cloze.Extra
+	}
+
+	return question, answer
+}
+
+// clozeOrdinals returns the ordinals of the deletions in the cloze text in
+// ascending order.
+func clozeOrdinals(text string) []int {
+	ordinals := []int{}
+	for _, match := range clozeDeletion.FindAllStringSubmatch(text, -1) {
+		ordinal, err := strconv.Atoi(match[1])
+		if err != nil || ordinal < 1 || slices.Contains(ordinals, ordinal) {
+			continue
+		}
+
+		ordinals = append(ordinals, ordinal)
+	}
+
+	sort.Ints(ordinals)
+
+	return ordinals
+}
+
+// occlusionMasks checks that the masks lie within the image and returns them
+// ordered by ordinal. Masks without an ordinal get the next free ones, so a
+// client keeps the learning history of a mask by sending its ordinal back.
+func occlusionMasks(masks []entity.OcclusionMask) ([]entity.OcclusionMask, error) {
+	// tolerates rounding errors of masks ending at the image border
+	const epsilon = 1e-9
+
+	if len(masks) == 0 {
+		return nil, entity.ErrInvalidOcclusion
+	}
+
+	ordinals := map[int]bool{}
+	next := 1
+	for _, mask := range masks {
+		if mask.X+mask.Width > 1+epsilon || mask.Y+mask.Height > 1+epsilon {
+			return nil, entity.ErrInvalidOcclusion
+		}
+
+		if mask.Ordinal == 0 {
+			continue
+		}
+
+		if ordinals[mask.Ordinal] {
+			return nil, entity.ErrInvalidOcclusion
+		}
+
+		ordinals[mask.Ordinal] = true
+		if mask.Ordinal >= next {
+			next = mask.Ordinal + 1
+		}
+	}
+
+	checked := []entity.OcclusionMask{}
+	for _, mask := range masks {
+		if mask.Ordinal == 0 {
+			mask.Ordinal = next
+			next++
+		}
+
+		checked = append(checked, mask)
+	}
+
+	sort.Slice(checked, func(i, j int) bool {
+		return checked[i].Ordinal < checked[j].Ordinal
+	})
+
+	return checked, nil
+}
+
+// renderOcclusion returns the answer of the image occlusion card with the
+// given ordinal, which is the label of its mask and the extra information.
+// The question is the same for all cards of the note.
+func renderOcclusion(occlusion *entity.OcclusionNote, ordinal int) string {
+	answer := ""
+	for _, mask := range occlusion.Masks {
+		if mask.Ordinal == ordinal {
+			answer = mask.Label
+		}
+	}
+
+	if occlusion.Extra != "" {
+		if answer != "" {
+			answer += "\n\n"
+		}
+		answer += occlusion.Extra
+	}
+
+	return answer
+}
+
+// expandCard checks a card request against the rules of its type and
+// returns the cards it stands for, ordered by ordinal. A reversed note
+// stands for a card in each direction, a cloze note for one card per
+// deletion, an image occlusion note for one card per mask and a custom note
+// for one card per template of its note type. Custom notes need their note
+// type among the given ones.
+func expandCard(
+	card *entity.CardReq,
+	noteTypes map[string]*entity.NoteType,
+) ([]entity.Card, error) {
+	card = sanitizeCardReq(card)
+
+	tags, err := normalizeTags(card.Tags)
+	if err != nil {
+		return nil, err
+	}
+
+	base := entity.Card{
+		Question:  card.Question,
+		Answer:    card.Answer,
+		Tags:      tags,
+		Source:    cardSource(card.Source),
+		Type:      card.Type,
+		SourceRef: card.SourceRef,
+	}
+
+	switch card.Type {
+	case entity.CardTypeReversed:
+		forward, reverse := base, base
+		forward.Ordinal = entity.OrdinalForward
+		reverse.Ordinal = entity.OrdinalReverse
+		reverse.Question, reverse.Answer = card.Answer, card.Question
+
+		return []entity.Card{forward, reverse}, nil
+	case entity.CardTypeCloze:
+		ordinals := clozeOrdinals(card.Question)
+		if len(ordinals) == 0 {
+			return nil, entity.ErrInvalidCloze
+		}
+
+		cloze := entity.ClozeNote{Text: card.Question, Extra: card.Answer}
+
+		cards := []entity.Card{}
+		for _, ordinal := range ordinals {
+			clozeCard := base
+			clozeCard.Ordinal = ordinal
+			clozeCard.Cloze = &cloze
+			question, answer := renderCloze(&cloze, ordinal)
+			// deletions may split up tags, so the rendered sides are
+			// sanitized again
+			clozeCard.Question = format.SanitizeMarkdown(question)
+			clozeCard.Answer = format.SanitizeMarkdown(answer)
+
+			cards = append(cards, clozeCard)
+		}
+
+		return cards, nil
+	case entity.CardTypeImageOcclusion:
+		images := (&entity.Card{Question: card.Question}).MediaRefs()
+		if len(images) == 0 {
+			return nil, entity.ErrInvalidOcclusion
+		}
+
+		masks, err := occlusionMasks(card.Masks)
+		if err != nil {
+			return nil, err
+		}
+
+		occlusion := entity.OcclusionNote{Image: images[0], Masks: masks, Extra: card.Answer}
+
+		cards := []entity.Card{}
+		for _, mask := range masks {
+			occlusionCard := base
+			occlusionCard.Ordinal = mask.Ordinal
+			occlusionCard.Occlusion = &occlusion
+			occlusionCard.Answer = renderOcclusion(&occlusion, mask.Ordinal)
+
+			cards = append(cards, occlusionCard)
+		}
+
+		return cards, nil
+	case entity.CardTypeCustom:
+		noteType, ok := noteTypes[card.NoteTypeID]
+		if !ok {
+			return nil, entity.ErrNoteTypeNotFound
+		}
+
+		base.Question, base.Answer = "", ""
+
+		cards, err := renderCustom(base, noteType, card.Fields)
+		if err != nil {
+			return nil, err
+		}
+
+		// templates may put fields together into tags
+		for i := range cards {
+			cards[i].Question = format.SanitizeMarkdown(cards[i].Question)
+			cards[i].Answer = format.SanitizeMarkdown(cards[i].Answer)
+		}
+
+		return cards, nil
+	case entity.CardTypeMultipleChoice:
+		choices := map[string]bool{}
+		for _, choice := range card.Choices {
+			choices[choice] = true
+		}
+
+		if len(choices) < 2 || len(choices) != len(card.Choices) || !choices[card.Answer] {
+			return nil, entity.ErrInvalidChoices
+		}
+
+		base.Choices = card.Choices
+	case entity.CardTypeTyped:
+		for _, answer := range append([]string{card.Answer}, card.Accepted...) {
+			if strings.ContainsAny(answer, "\r\n") {
+				return nil, entity.ErrInvalidTypedAnswer
+			}
+		}
+
+		base.Accepted = card.Accepted
+	default:
+		base.Type = entity.CardTypeBasic
+	}
+
+	return []entity.Card{base}, nil
+}
+
+// hasSiblings reports whether cards of the type are generated as siblings
+// of a note.
+func hasSiblings(cardType string) bool {
+	return cardType == entity.CardTypeReversed || cardType == entity.CardTypeCloze ||
+		cardType == entity.CardTypeImageOcclusion || cardType == entity.CardTypeCustom
+}
+
+// cardContent returns the content of a card without its metadata, e.g. to
+// remember it before it is changed.
+func cardContent(card *entity.Card) entity.Card {
+	return entity.Card{
+		ID:        card.ID,
+		Question:  card.Question,
+		Answer:    card.Answer,
+		Tags:      card.Tags,
+		Type:      card.Type,
+		NoteID:    card.NoteID,
+		Ordinal:   card.Ordinal,
+		Cloze:     card.Cloze,
+		Choices:   card.Choices,
+		Accepted:  card.Accepted,
+		Occlusion: card.Occlusion,
+		Custom:    card.Custom,
+	}
+}
@@ -0,0 +1,308 @@
+package usecase
+
+import (
+	"testing"
+
+	"github.com/moshrank/spacey-backend/services/deck-management-service/entity"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestExpandCard(t *testing.T) {
+	tests := []struct {
+		testName  string
+		card      entity.CardReq
+		wantCards []entity.Card
+		wantErr   error
+	}{
+		{
+			"Basic",
+			entity.CardReq{Question: "Q", Answer: "A"},
+			[]entity.Card{
+				{Question: "Q", Answer: "A", Source: entity.SourceManual, Type: "basic"},
+			},
+			nil,
+		},
+		{
+			"Reversed",
+			entity.CardReq{Question: "Hund", Answer: "dog", Type: "reversed"},
+			[]entity.Card{
+				{Question: "Hund", Answer: "dog", Source: "manual", Type: "reversed", Ordinal: 1},
+				{Question: "dog", Answer: "Hund", Source: "manual", Type: "reversed", Ordinal: 2},
+			},
+			nil,
+		},
+		{
+			"Multiple Choice",
+			entity.CardReq{
+				Question: "Capital of France?",
+				Answer:   "Paris",
+				Type:     "multiple_choice",
+				Choices:  []string{"Lyon", "Paris", "Nice"},
+			},
+			[]entity.Card{{
+				Question: "Capital of France?",
+				Answer:   "Paris",
+				Source:   "manual",
+				Type:     "multiple_choice",
+				Choices:  []string{"Lyon", "Paris", "Nice"},
+			}},
+			nil,
+		},
+		{
+			"Answer Not A Choice",
+			entity.CardReq{
+				Question: "Capital of France?",
+				Answer:   "Paris",
+				Type:     "multiple_choice",
+				Choices:  []string{"Lyon", "Nice"},
+			},
+			nil,
+			entity.ErrInvalidChoices,
+		},
+		{
+			"Duplicate Choices",
+			entity.CardReq{
+				Question: "Capital of France?",
+				Answer:   "Paris",
+				Type:     "multiple_choice",
+				Choices:  []string{"Paris", "Paris"},
+			},
+			nil,
+			entity.ErrInvalidChoices,
+		},
+		{
+			"Typed",
+			entity.CardReq{
+				Question: "Past tense of go",
+				Answer:   "went",
+				Type:     "typed",
+				Accepted: []string{"Went"},
+			},
+			[]entity.Card{{
+				Question: "Past tense of go",
+				Answer:   "went",
+				Source:   "manual",
+				Type:     "typed",
+				Accepted: []string{"Went"},
+			}},
+			nil,
+		},
+		{
+			"Multiline Typed Answer",
+			entity.CardReq{Question: "Q", Answer: "first\nsecond", Type: "typed"},
+			nil,
+			entity.ErrInvalidTypedAnswer,
+		},
+		{
+			"Occlusion Without Image",
+			entity.CardReq{
+				Question: "Name the bone",
+				Type:     "image_occlusion",
+				Masks:    []entity.OcclusionMask{{X: 0.1, Y: 0.1, Width: 0.2, Height: 0.2}},
+			},
+			nil,
+			entity.ErrInvalidOcclusion,
+		},
+		{
+			"Occlusion Without Masks",
+			entity.CardReq{Question: "media:61f0c1e5d3b7a0c3f8a1b2c4", Type: "image_occlusion"},
+			nil,
+			entity.ErrInvalidOcclusion,
+		},
+		{
+			"Mask Beyond Image",
+			entity.CardReq{
+				Question: "media:61f0c1e5d3b7a0c3f8a1b2c4",
+				Type:     "image_occlusion",
+				Masks:    []entity.OcclusionMask{{X: 0.9, Y: 0.1, Width: 0.2, Height: 0.2}},
+			},
+			nil,
+			entity.ErrInvalidOcclusion,
+		},
+		{
+			"Duplicate Mask Ordinals",
+			entity.CardReq{
+				Question: "media:61f0c1e5d3b7a0c3f8a1b2c4",
+				Type:     "image_occlusion",
+				Masks: []entity.OcclusionMask{
+					{Ordinal: 1, Width: 0.2, Height: 0.2},
+					{Ordinal: 1, X: 0.5, Width: 0.2, Height: 0.2},
+				},
+			},
+			nil,
+			entity.ErrInvalidOcclusion,
+		},
+		{
+			"Cloze Without Deletion",
+			entity.CardReq{Question: "Paris is the capital of France", Type: "cloze"},
+			nil,
+			entity.ErrInvalidCloze,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.testName, func(t *testing.T) {
+			cards, err := expandCard(&test.card, nil)
+
+			assert.Equal(t, test.wantErr, err)
+			assert.Equal(t, test.wantCards, cards)
+		})
+	}
+}
+
+func TestExpandCloze(t *testing.T) {
+	cards, err := expandCard(&entity.CardReq{
+		Question: "{{c2::Paris}} is the capital of {{c1::France::country}}, not {{c2::Lyon}}",
+		Answer:   "Since 508",
+		Type:     entity.CardTypeCloze,
+	}, nil)
+
+	assert.Nil(t, err)
+	assert.Len(t, cards, 2)
+
+	assert.Equal(t, 1, cards[0].Ordinal)
+	assert.Equal(t, "Paris is the capital of [country], not Lyon", cards[0].Question)
+	assert.Equal(t, "Paris is the capital of France, not Lyon\n\nSince 508", cards[0].Answer)
+
+	assert.Equal(t, 2, cards[1].Ordinal)
+	assert.Equal(t, "[...] is the capital of France, not [...]", cards[1].Question)
+	assert.Equal(t, cards[0].Answer, cards[1].Answer)
+	assert.Equal(t, "Since 508", cards[1].Cloze.Extra)
+}
+
+func TestExpandImageOcclusion(t *testing.T) {
+	cards, err := expandCard(&entity.CardReq{
+		Question: "Name the bone ![skull](media:61f0c1e5d3b7a0c3f8a1b2c4)",
+		Answer:   "Gray's Anatomy",
+		Type:     entity.CardTypeImageOcclusion,
+		Masks: []entity.OcclusionMask{
+			{X: 0.1, Y: 0.1, Width: 0.2, Height: 0.2, Label: "frontal"},
+			{Ordinal: 3, X: 0.7, Y: 0.4, Width: 0.3, Height: 0.6, Label: "mandible"},
+			{X: 0.4, Y: 0.1, Width: 0.2, Height: 0.2},
+		},
+	}, nil)
+
+	assert.Nil(t, err)
+	assert.Len(t, cards, 3)
+
+	assert.Equal(t, "61f0c1e5d3b7a0c3f8a1b2c4", cards[0].Occlusion.Image)
+	assert.Equal(t, []int{3, 4, 5}, []int{
+		cards[0].Ordinal,
+		cards[1].Ordinal,
+		cards[2].Ordinal,
+	})
+	assert.Equal(t, "Name the bone ![skull](media:61f0c1e5d3b7a0c3f8a1b2c4)", cards[1].Question)
+	assert.Equal(t, "mandible\n\nGray's Anatomy", cards[0].Answer)
+	assert.Equal(t, "frontal\n\nGray's Anatomy", cards[1].Answer)
+	assert.Equal(t, "Gray's Anatomy", cards[2].Answer)
+}
+
+func TestCreateReversedCard(t *testing.T) {
+	cardStoreMock := new(CardStoreMock)
+	cardStoreMock.On("SaveCards", "test_deck_id", "1", mock.Anything).
+		Return([]string{"forward_id", "reverse_id"}, nil)
+
+	cardUseCase := NewCardUseCase(
+		cardStoreMock,
+		newOwnedDeckStoreMock(),
+		newUndoStoreMock(),
+		new(NoteTypeStoreMock),
+		newActivityStoreMock(),
+		new(MediaStoreMock),
+	)
+	card, err := cardUseCase.CreateCard("test_deck_id", "1", &entity.CardReq{
+		Question: "Hund",
+		Answer:   "dog",
+		Type:     entity.CardTypeReversed,
+	}, nil)
+
+	assert.Nil(t, err)
+	assert.Equal(t, "forward_id", card.ID)
+	assert.Equal(t, "Hund", card.Question)
+
+	saved := cardStoreMock.Calls[0].Arguments.Get(2).([]entity.Card)
+	assert.Len(t, saved, 2)
+	assert.NotEmpty(t, saved[0].NoteID)
+	assert.Equal(t, saved[0].NoteID, saved[1].NoteID)
+	assert.Equal(t, "dog", saved[1].Question)
+}
+
+func TestUpdateClozeCard(t *testing.T) {
+	cloze := &entity.ClozeNote{Text: "{{c1::Paris}} is in {{c2::France}}"}
+
+	cardStoreMock := new(CardStoreMock)
+	cardStoreMock.On("FindByIDs", "test_deck_id", []string{"card_2"}).
+		Return([]entity.Card{
+			{ID: "card_2", Type: "cloze", NoteID: "note", Ordinal: 2, Cloze: cloze},
+		}, nil)
+	cardStoreMock.On("FindByNoteID", "test_deck_id", "note").
+		Return([]entity.Card{
+			{ID: "card_1", Type: "cloze", NoteID: "note", Ordinal: 1, Cloze: cloze},
+			{ID: "card_2", Type: "cloze", NoteID: "note", Ordinal: 2, Cloze: cloze},
+		}, nil)
+	cardStoreMock.On("UpdateCard", "card_2", "1", "test_deck_id", mock.Anything).Return(nil)
+	cardStoreMock.On("SaveCards", "test_deck_id", "1", mock.Anything).
+		Return([]string{"card_3"}, nil)
+	cardStoreMock.On("DeleteCards", "1", "test_deck_id", []string{"card_1"}, mock.Anything).
+		Return(nil)
+
+	cardUseCase := NewCardUseCase(
+		cardStoreMock,
+		newOwnedDeckStoreMock(),
+		newUndoStoreMock(),
+		new(NoteTypeStoreMock),
+		newActivityStoreMock(),
+		new(MediaStoreMock),
+	)
+	card, err := cardUseCase.UpdateCard("card_2", "1", "test_deck_id", &entity.CardReq{
+		Question: "Paris is in {{c2::France}}, {{c3::Europe}}",
+		Type:     entity.CardTypeCloze,
+	}, nil)
+
+	assert.Nil(t, err)
+	assert.Equal(t, "card_2", card.ID)
+	assert.Equal(t, "Paris is in [...], Europe", card.Question)
+
+	added := cardStoreMock.Calls[3].Arguments.Get(2).([]entity.Card)
+	assert.Len(t, added, 1)
+	assert.Equal(t, 3, added[0].Ordinal)
+	assert.Equal(t, "note", added[0].NoteID)
+}
+
+func TestUpdateReverseCard(t *testing.T) {
+	cardStoreMock := new(CardStoreMock)
+	cardStoreMock.On("FindByIDs", "test_deck_id", []string{"reverse_id"}).
+		Return([]entity.Card{
+			{ID: "reverse_id", Type: "reversed", NoteID: "note", Ordinal: 2},
+		}, nil)
+	cardStoreMock.On("FindByNoteID", "test_deck_id", "note").
+		Return([]entity.Card{
+			{ID: "forward_id", Type: "reversed", NoteID: "note", Ordinal: 1},
+			{ID: "reverse_id", Type: "reversed", NoteID: "note", Ordinal: 2},
+		}, nil)
+	cardStoreMock.On("UpdateCard", mock.Anything, "1", "test_deck_id", mock.Anything).Return(nil)
+
+	cardUseCase := NewCardUseCase(
+		cardStoreMock,
+		newOwnedDeckStoreMock(),
+		newUndoStoreMock(),
+		new(NoteTypeStoreMock),
+		newActivityStoreMock(),
+		new(MediaStoreMock),
+	)
+	card, err := cardUseCase.UpdateCard("reverse_id", "1", "test_deck_id", &entity.CardReq{
+		Question: "cat",
+		Answer:   "Katze",
+		Type:     entity.CardTypeReversed,
+	}, nil)
+
+	assert.Nil(t, err)
+	assert.Equal(t, "cat", card.Question)
+	assert.Equal(t, "Katze", card.Answer)
+
+	forward := cardStoreMock.Calls[2].Arguments.Get(3).(*entity.Card)
+	assert.Equal(t, "forward_id", cardStoreMock.Calls[2].Arguments.String(0))
+	assert.Equal(t, "Katze", forward.Question)
+	assert.Equal(t, "cat", forward.Answer)
+}
@@ -0,0 +1,120 @@
+package usecase
+
+import (
+	"slices"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/moshrank/spacey-backend/services/deck-management-service/entity"
+	"github.com/moshrank/spacey-backend/services/deck-management-service/format"
+)
+
+// sanitizeAll sanitizes every text of the slice in place.
+func sanitizeAll(texts []string) {
+	for i, text := range texts {
+		texts[i] = format.SanitizeMarkdown(text)
+	}
+}
+
+// sanitizeCardReq returns a copy of the card with its content sanitized, so
+// no unsafe html is stored whatever the client sends.
+func sanitizeCardReq(card *entity.CardReq) *entity.CardReq {
+	sanitized := *card
+	sanitized.Question = format.SanitizeMarkdown(card.Question)
+	sanitized.Answer = format.SanitizeMarkdown(card.Answer)
+	sanitized.Choices = append([]string(nil), card.Choices...)
+	sanitizeAll(sanitized.Choices)
+	sanitized.Accepted = append([]string(nil), card.Accepted...)
+	sanitizeAll(sanitized.Accepted)
+
+	if card.Masks != nil {
+		sanitized.Masks = make([]entity.OcclusionMask, len(card.Masks))
+		for i, mask := range card.Masks {
+			mask.Label = format.SanitizeMarkdown(mask.Label)
+			sanitized.Masks[i] = mask
+		}
+	}
+
+	if card.Fields != nil {
+		sanitized.Fields = map[string]string{}
+		for name, value := range card.Fields {
+			sanitized.Fields[name] = format.SanitizeMarkdown(value)
+		}
+	}
+
+	return &sanitized
+}
+
+// normalizeTags trims the tags and drops empty and repeated ones. It fails if
+// the card still has more tags than allowed or a tag is too long.
+func normalizeTags(tags []string) ([]string, error) {
+	var normalized []string
+	for _, tag := range tags {
+		tag = strings.TrimSpace(tag)
+		if tag == "" || slices.Contains(normalized, tag) {
+			continue
+		}
+
+		if utf8.RuneCountInString(tag) > entity.MaxTagLength {
+			return nil, entity.ErrInvalidTags
+		}
+
+		normalized = append(normalized, tag)
+	}
+
+	if len(normalized) > entity.MaxTags {
+		return nil, entity.ErrInvalidTags
+	}
+
+	return normalized, nil
+}
+
+// sanitizeCard sanitizes the content of a card that did not pass through
+// expandCard, like imported cards or cards changed by find and replace.
+func sanitizeCard(card *entity.Card) {
+	card.Question = format.SanitizeMarkdown(card.Question)
+	card.Answer = format.SanitizeMarkdown(card.Answer)
+	sanitizeAll(card.Choices)
+	sanitizeAll(card.Accepted)
+
+	if card.Cloze != nil {
+		card.Cloze.Text = format.SanitizeMarkdown(card.Cloze.Text)
+		card.Cloze.Extra = format.SanitizeMarkdown(card.Cloze.Extra)
+	}
+
+	if card.Occlusion != nil {
+		card.Occlusion.Extra = format.SanitizeMarkdown(card.Occlusion.Extra)
+		for i := range card.Occlusion.Masks {
+			card.Occlusion.Masks[i].Label = format.SanitizeMarkdown(
+				card.Occlusion.Masks[i].Label,
+			)
+		}
+	}
+
+	if card.Custom != nil {
+		for name, value := range card.Custom.Fields {
+			card.Custom.Fields[name] = format.SanitizeMarkdown(value)
+		}
+	}
+}
+
+// mediaURL returns the gateway url the media files of the deck are served
+// at, for rendered content to reference them.
+func mediaURL(deckID string) format.MediaURL {
+	return func(mediaID string) string {
+		return "/decks/" + deckID + "/media/" + mediaID
+	}
+}
+
+// renderCard adds the html of the question and answer to the card, for
+// clients that can not render markdown and math themselves.
+func renderCard(card *entity.CardRes) {
+	card.QuestionHTML = format.RenderMarkdown(card.Question, mediaURL(card.DeckID))
+	card.AnswerHTML = format.RenderMarkdown(card.Answer, mediaURL(card.DeckID))
+}
+
+func renderCards(cards []entity.CardRes) {
+	for i := range cards {
+		renderCard(&cards[i])
+	}
+}
@@ -1,6 +1,7 @@
 package usecase
 
 import (
+	"slices"
 	"time"
 
 	mapper "github.com/PeteProgrammer/go-automapper"
@@ -8,86 +9,711 @@ import (
 )
 
 type DeckUseCase struct {
-	deckStore entity.DeckStoreInterface
+	deckStore     entity.DeckStoreInterface
+	cardStore     entity.CardStoreInterface
+	undoStore     entity.UndoStoreInterface
+	noteTypeStore entity.NoteTypeStoreInterface
+	activityStore entity.ActivityStoreInterface
+	palette       entity.PaletteInterface
 }
 
-func NewDeckUseCase(deckStore entity.DeckStoreInterface) entity.DeckUseCaseInterface {
+func NewDeckUseCase(
+	deckStore entity.DeckStoreInterface,
+	cardStore entity.CardStoreInterface,
+	undoStore entity.UndoStoreInterface,
+	noteTypeStore entity.NoteTypeStoreInterface,
+	activityStore entity.ActivityStoreInterface,
+	palette entity.PaletteInterface,
+) entity.DeckUseCaseInterface {
 	return &DeckUseCase{
-		deckStore: deckStore,
+		deckStore:     deckStore,
+		cardStore:     cardStore,
+		undoStore:     undoStore,
+		noteTypeStore: noteTypeStore,
+		activityStore: activityStore,
+		palette:       palette,
 	}
 }
 
+// subDeckIDs returns the ids of all sub-decks of the deck among the given
+// decks, the closest ones first.
+func subDeckIDs(decks []entity.Deck, deckID string) []string {
+	children := map[string][]string{}
+	for _, deck := range decks {
+		if deck.ParentID != "" {
+			children[deck.ParentID] = append(children[deck.ParentID], deck.ID)
+		}
+	}
+
+	ids := []string{}
+	visited := map[string]bool{deckID: true}
+	queue := []string{deckID}
+	for len(queue) > 0 {
+		for _, child := range children[queue[0]] {
+			if visited[child] {
+				continue
+			}
+
+			visited[child] = true
+			ids = append(ids, child)
+			queue = append(queue, child)
+		}
+
+		queue = queue[1:]
+	}
+
+	return ids
+}
+
+// checkParent makes sure that a deck of the owner can be placed below the
+// parent, which the user has to own as well.
+func checkParent(deckStore entity.DeckStoreInterface, userID, ownerID, parentID string) error {
+	parent, err := authorizeDeck(deckStore, userID, parentID, entity.RoleOwner)
+	if err != nil {
+		return err
+	}
+
+	if parent.UserID != ownerID {
+		return entity.ErrInvalidParent
+	}
+
+	return nil
+}
+
 func (u *DeckUseCase) CreateDeck(userID string, deck *entity.DeckReq) (*entity.DeckRes, error) {
+	color, err := u.palette.Match(deck.Color)
+	if err != nil {
+		return nil, err
+	}
+
+	if deck.ParentID != "" {
+		if err := checkParent(u.deckStore, userID, userID, deck.ParentID); err != nil {
+			return nil, err
+		}
+	}
+
 	var deckDB entity.Deck
 	mapper.MapLoose(deck, &deckDB)
 
 	timestamp := time.Now()
+	deckDB.Color = color
 	deckDB.CreatedAt = &timestamp
 	deckDB.UpdatedAt = &timestamp
 	deckDB.DeletedAt = nil
 	deckDB.UserID = userID
 	deckDB.Cards = []entity.Card{}
+	deckDB.Members = []entity.DeckMember{}
 
 	deckID, err := u.deckStore.Save(&deckDB)
 	if err != nil {
 		return nil, err
 	}
 
+	activity := newActivity(userID, deckID, entity.ActivityDeckCreate, nil, timestamp)
+	if err := u.activityStore.Save(&activity); err != nil {
+		return nil, err
+	}
+
 	var deckRes entity.DeckRes
 	mapper.MapLoose(&deckDB, &deckRes)
 	deckRes.ID = deckID
+	deckRes.Role = entity.RoleOwner
 
 	return &deckRes, nil
 }
 
-func (u *DeckUseCase) GetDecks(userID string) ([]entity.DeckRes, error) {
-	decks, err := u.deckStore.FindAll(userID)
+// deckQuery translates the listing request. Cards are included unless they
+// are excluded explicitly or not among the selected fields.
+func (u *DeckUseCase) deckQuery(req *entity.DeckListReq) (*entity.DeckQuery, error) {
+	query := entity.DeckQuery{
+		Page:       entity.Page{Limit: req.Limit, Cursor: req.Cursor},
+		Sort:       req.Sort,
+		Descending: req.Order == entity.OrderDesc,
+		WithCards:  true,
+	}
+
+	if fields := req.FieldList(); fields != nil {
+		query.WithCards = false
+		for _, field := range fields {
+			if !slices.Contains(entity.DeckResFields, field) {
+				return nil, entity.ErrInvalidField
+			}
+
+			query.WithCards = query.WithCards || field == "cards"
+		}
+	}
+
+	if req.IncludeCards != nil {
+		query.WithCards = *req.IncludeCards
+	}
+
+	return &query, nil
+}
+
+func (u *DeckUseCase) GetDecks(
+	userID string,
+	req *entity.DeckListReq,
+) ([]entity.DeckRes, string, error) {
+	query, err := u.deckQuery(req)
 	if err != nil {
-		return nil, err
+		return nil, "", err
+	}
+
+	decks, next, err := u.deckStore.FindAll(userID, query)
+	if err != nil {
+		return nil, "", err
 	}
 
 	var decksRes []entity.DeckRes
 	mapper.MapLoose(decks, &decksRes)
+	for i := range decksRes {
+		decksRes[i].Role = decks[i].Role(userID)
+		renderCards(decksRes[i].Cards)
+	}
 
-	return decksRes, nil
+	return decksRes, next, nil
 }
 
 func (u *DeckUseCase) GetDeck(userID, DeckID string) (*entity.DeckRes, error) {
-	deck, err := u.deckStore.FindByID(userID, DeckID)
+	deck, err := authorizeDeck(u.deckStore, userID, DeckID, entity.RoleViewer)
 	if err != nil {
 		return nil, err
 	}
 
+	if err := loadCards(u.cardStore, deck); err != nil {
+		return nil, err
+	}
+
 	var deckRes entity.DeckRes
 	mapper.MapLoose(deck, &deckRes)
+	deckRes.Role = deck.Role(userID)
+	renderCards(deckRes.Cards)
 
 	return &deckRes, nil
 }
 
+// UpdateDeck changes the deck if its version matches ifMatch.
 func (u *DeckUseCase) UpdateDeck(
 	userID, DeckID string,
 	deck *entity.DeckReq,
+	ifMatch entity.IfMatch,
 ) (*entity.DeckRes, error) {
+	currentDeck, err := authorizeDeck(u.deckStore, userID, DeckID, entity.RoleEditor)
+	if err != nil {
+		return nil, err
+	}
+
+	if !ifMatch.Matches(currentDeck.Version) {
+		return nil, entity.ErrVersionConflict
+	}
+
+	// only the owner decides whether the deck is published
+	if deck.Public != currentDeck.Public && currentDeck.Role(userID) != entity.RoleOwner {
+		return nil, entity.ErrForbidden
+	}
+
+	// decks stored with a color outside the palette can keep it
+	color := deck.Color
+	if color != currentDeck.Color {
+		color, err = u.palette.Match(deck.Color)
+		if err != nil {
+			return nil, err
+		}
+	}
+
 	var deckDB entity.Deck
 	mapper.MapLoose(deck, &deckDB)
 
 	timestamp := time.Now()
+	deckDB.Color = color
 	deckDB.UpdatedAt = &timestamp
 	deckDB.ID = DeckID
-	deckDB.UserID = userID
+	deckDB.UserID = currentDeck.UserID
+	deckDB.ParentID = currentDeck.ParentID
+	deckDB.Version = currentDeck.Version
 
-	err := u.deckStore.Update(&deckDB)
+	err = u.deckStore.Update(&deckDB)
 	if err != nil {
 		return nil, err
 	}
+	deckDB.Version++
+
+	activity := newActivity(userID, DeckID, entity.ActivityDeckUpdate, nil, timestamp)
+	activity.Changes = deckChanges(currentDeck, &deckDB)
+	if err := u.activityStore.Save(&activity); err != nil {
+		return nil, err
+	}
 
 	var deckRes entity.DeckRes
 	mapper.MapLoose(&deckDB, &deckRes)
 	deckRes.ID = DeckID
+	deckRes.Role = currentDeck.Role(userID)
+
+	return &deckRes, nil
+}
+
+// DeleteDeck moves the deck to the trash together with all of its
+// sub-decks if its version matches ifMatch.
+func (u *DeckUseCase) DeleteDeck(userID, DeckID string, ifMatch entity.IfMatch) error {
+	deck, err := authorizeDeck(u.deckStore, userID, DeckID, entity.RoleOwner)
+	if err != nil {
+		return err
+	}
+
+	if !ifMatch.Matches(deck.Version) {
+		return entity.ErrVersionConflict
+	}
+
+	decks, _, err := u.deckStore.FindAll(deck.UserID, &entity.DeckQuery{})
+	if err != nil {
+		return err
+	}
+
+	// the deepest sub-decks go first and the deck itself last, so that the
+	// deletion can be repeated if it fails halfway
+	subDecks := subDeckIDs(decks, DeckID)
+	timestamp := time.Now()
+	for i := len(subDecks) - 1; i >= 0; i-- {
+		if err := u.deckStore.Delete(deck.UserID, subDecks[i], timestamp); err != nil {
+			return err
+		}
+	}
+
+	if err := u.deckStore.Delete(deck.UserID, DeckID, timestamp); err != nil {
+		return err
+	}
+
+	activity := newActivity(userID, DeckID, entity.ActivityDeckDelete, nil, timestamp)
+
+	return u.activityStore.Save(&activity)
+}
+
+// GetDeckTree returns the decks the user can access as a tree sorted by
+// name. Decks whose parent the user can not access are at the top level.
+func (u *DeckUseCase) GetDeckTree(userID string) ([]entity.DeckTreeRes, error) {
+	decks, _, err := u.deckStore.FindAll(userID, &entity.DeckQuery{Sort: entity.SortName})
+	if err != nil {
+		return nil, err
+	}
+
+	deckIDs := []string{}
+	accessible := map[string]bool{}
+	for _, deck := range decks {
+		deckIDs = append(deckIDs, deck.ID)
+		accessible[deck.ID] = true
+	}
+
+	counts, err := u.cardStore.CountByDeckIDs(deckIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	children := map[string][]entity.Deck{}
+	for _, deck := range decks {
+		parentID := deck.ParentID
+		if !accessible[parentID] {
+			parentID = ""
+		}
+
+		children[parentID] = append(children[parentID], deck)
+	}
+
+	var tree func(parentID, path string) []entity.DeckTreeRes
+	tree = func(parentID, path string) []entity.DeckTreeRes {
+		nodes := []entity.DeckTreeRes{}
+		for _, deck := range children[parentID] {
+			node := entity.DeckTreeRes{
+				ID:        deck.ID,
+				Name:      deck.Name,
+				Path:      path + deck.Name,
+				Color:     deck.Color,
+				Role:      deck.Role(userID),
+				CardCount: counts[deck.ID],
+			}
+
+			node.Children = tree(deck.ID, node.Path+entity.DeckPathSeparator)
+			node.TotalCardCount = node.CardCount
+			for _, child := range node.Children {
+				node.TotalCardCount += child.TotalCardCount
+			}
+
+			nodes = append(nodes, node)
+		}
+
+		return nodes
+	}
+
+	return tree("", ""), nil
+}
+
+// MoveDeck places the deck below another deck of its owner. A deck can not
+// be moved below itself or one of its sub-decks.
+func (u *DeckUseCase) MoveDeck(
+	userID, DeckID string,
+	move *entity.DeckMoveReq,
+) (*entity.DeckRes, error) {
+	deck, err := authorizeDeck(u.deckStore, userID, DeckID, entity.RoleOwner)
+	if err != nil {
+		return nil, err
+	}
+
+	if move.ParentID != "" {
+		if err := checkParent(u.deckStore, userID, deck.UserID, move.ParentID); err != nil {
+			return nil, err
+		}
+
+		decks, _, err := u.deckStore.FindAll(deck.UserID, &entity.DeckQuery{})
+		if err != nil {
+			return nil, err
+		}
+
+		if move.ParentID == DeckID || slices.Contains(subDeckIDs(decks, DeckID), move.ParentID) {
+			return nil, entity.ErrInvalidParent
+		}
+	}
+
+	timestamp := time.Now()
+	err = u.deckStore.UpdateParent(deck.UserID, DeckID, move.ParentID, timestamp)
+	if err != nil {
+		return nil, err
+	}
+
+	activity := newActivity(userID, DeckID, entity.ActivityDeckMove, nil, timestamp)
+	activity.Changes = addChange(nil, "parentID", deck.ParentID, move.ParentID)
+	if err := u.activityStore.Save(&activity); err != nil {
+		return nil, err
+	}
+
+	deck.ParentID = move.ParentID
+	deck.UpdatedAt = &timestamp
+	deck.Version++
+
+	var deckRes entity.DeckRes
+	mapper.MapLoose(deck, &deckRes)
+	deckRes.Role = deck.Role(userID)
+
+	return &deckRes, nil
+}
+
+// forkNotes translates the notes and note types of origin cards into the
+// ones of their copies in a fork, so the copies neither share notes with the
+// origin nor are rendered by the note types of another user.
+type forkNotes struct {
+	noteIDs     map[string]string
+	noteTypeIDs map[string]string
+}
+
+func (n *forkNotes) noteID(originID string) string {
+	if originID == "" {
+		return ""
+	}
+
+	if _, ok := n.noteIDs[originID]; !ok {
+		n.noteIDs[originID] = newNoteID()
+	}
+
+	return n.noteIDs[originID]
+}
+
+func (n *forkNotes) custom(origin *entity.CustomNote) *entity.CustomNote {
+	if origin == nil {
+		return nil
+	}
+
+	return &entity.CustomNote{
+		NoteTypeID: n.noteTypeIDs[origin.NoteTypeID],
+		Fields:     origin.Fields,
+	}
+}
+
+// newForkNotes copies the note types of the origin cards into the account of
+// the user, reusing the copies earlier forks and pulls made. The notes of the
+// fork are taken from the cards that were already copied.
+func (u *DeckUseCase) newForkNotes(
+	userID string,
+	originCards []entity.Card,
+	forkedCards map[string]entity.Card,
+) (*forkNotes, error) {
+	notes := forkNotes{noteIDs: map[string]string{}, noteTypeIDs: map[string]string{}}
+
+	originTypeIDs := []string{}
+	for _, card := range originCards {
+		if card.Custom != nil {
+			originTypeIDs = append(originTypeIDs, card.Custom.NoteTypeID)
+		}
+
+		forked, ok := forkedCards[card.ID]
+		if ok && card.NoteID != "" && forked.NoteID != "" {
+			notes.noteIDs[card.NoteID] = forked.NoteID
+		}
+	}
+
+	if len(originTypeIDs) == 0 {
+		return &notes, nil
+	}
+
+	ownNoteTypes, err := u.noteTypeStore.FindAll(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, noteType := range ownNoteTypes {
+		notes.noteTypeIDs[noteType.ID] = noteType.ID
+		if noteType.OriginID != "" {
+			notes.noteTypeIDs[noteType.OriginID] = noteType.ID
+		}
+	}
+
+	missing := []string{}
+	for _, id := range originTypeIDs {
+		if _, ok := notes.noteTypeIDs[id]; !ok && !slices.Contains(missing, id) {
+			missing = append(missing, id)
+		}
+	}
+
+	if len(missing) == 0 {
+		return &notes, nil
+	}
+
+	noteTypes, err := u.noteTypeStore.FindByIDs(missing)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(noteTypes) != len(missing) {
+		return nil, entity.ErrNoteTypeNotFound
+	}
+
+	timestamp := time.Now()
+	for _, noteType := range noteTypes {
+		copied := noteType
+		copied.ID = ""
+		copied.UserID = userID
+		copied.OriginID = noteType.ID
+		copied.CreatedAt = &timestamp
+		copied.UpdatedAt = &timestamp
+
+		id, err := u.noteTypeStore.Save(&copied)
+		if err != nil {
+			return nil, err
+		}
+
+		notes.noteTypeIDs[noteType.ID] = id
+	}
+
+	return &notes, nil
+}
+
+func (u *DeckUseCase) copyCard(
+	card entity.Card,
+	userID, deckID string,
+	notes *forkNotes,
+	timestamp time.Time,
+) entity.Card {
+	copied := cardContent(&card)
+	copied.ID = ""
+	copied.UserID = userID
+	copied.DeckID = deckID
+	copied.NoteID = notes.noteID(card.NoteID)
+	copied.Custom = notes.custom(card.Custom)
+	copied.Source = entity.SourceSync
+	copied.Origin = &entity.CardOrigin{
+		CardID:   card.ID,
+		SyncedAt: &timestamp,
+	}
+	copied.CreatedAt = &timestamp
+	copied.UpdatedAt = &timestamp
+
+	return copied
+}
+
+func (u *DeckUseCase) ForkDeck(userID, DeckID string) (*entity.DeckRes, error) {
+	origin, err := u.deckStore.FindAccessibleByID(userID, DeckID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := loadCards(u.cardStore, origin); err != nil {
+		return nil, err
+	}
+
+	notes, err := u.newForkNotes(userID, origin.Cards, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	timestamp := time.Now()
+	deckDB := entity.Deck{
+		Name:        origin.Name,
+		Description: origin.Description,
+		Color:       origin.Color,
+		UserID:      userID,
+		Public:      false,
+		Origin: &entity.DeckOrigin{
+			DeckID:   DeckID,
+			SyncedAt: &timestamp,
+		},
+		Members:   []entity.DeckMember{},
+		Cards:     []entity.Card{},
+		CreatedAt: &timestamp,
+		UpdatedAt: &timestamp,
+		DeletedAt: nil,
+	}
+
+	deckID, err := u.deckStore.Save(&deckDB)
+	if err != nil {
+		return nil, err
+	}
+
+	cards := []entity.Card{}
+	for _, card := range origin.Cards {
+		cards = append(cards, u.copyCard(card, userID, deckID, notes, timestamp))
+	}
+
+	if len(cards) > 0 {
+		cardIDs, err := u.cardStore.SaveCards(deckID, userID, cards)
+		if err != nil {
+			return nil, err
+		}
+
+		for i := range cards {
+			cards[i].ID = cardIDs[i]
+		}
+	}
+
+	deckDB.Cards = cards
+
+	activity := newActivity(userID, deckID, entity.ActivityDeckFork, cardIDsOf(cards), timestamp)
+	if err := u.activityStore.Save(&activity); err != nil {
+		return nil, err
+	}
+
+	var deckRes entity.DeckRes
+	mapper.MapLoose(&deckDB, &deckRes)
+	deckRes.ID = deckID
+	deckRes.Role = entity.RoleOwner
+	renderCards(deckRes.Cards)
 
 	return &deckRes, nil
 }
 
-func (u *DeckUseCase) DeleteDeck(userID, DeckID string) error {
-	return u.deckStore.Delete(userID, DeckID)
+func (u *DeckUseCase) PullDeck(userID, DeckID string) (*entity.DeckRes, error) {
+	deck, err := authorizeDeck(u.deckStore, userID, DeckID, entity.RoleEditor)
+	if err != nil {
+		return nil, err
+	}
+
+	if deck.Origin == nil || deck.Origin.SyncedAt == nil {
+		return nil, entity.ErrDeckNotForked
+	}
+
+	origin, err := u.deckStore.FindAccessibleByID(userID, deck.Origin.DeckID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := loadCards(u.cardStore, deck); err != nil {
+		return nil, err
+	}
+
+	if err := loadCards(u.cardStore, origin); err != nil {
+		return nil, err
+	}
+
+	forkedCards := map[string]entity.Card{}
+	for _, card := range deck.Cards {
+		if card.Origin != nil {
+			forkedCards[card.Origin.CardID] = card
+		}
+	}
+
+	notes, err := u.newForkNotes(deck.UserID, origin.Cards, forkedCards)
+	if err != nil {
+		return nil, err
+	}
+
+	timestamp := time.Now()
+	lastSync := *deck.Origin.SyncedAt
+	newCards := []entity.Card{}
+	operation := newBulkOperation(deck, entity.OperationPull, timestamp)
+
+	for _, originCard := range origin.Cards {
+		card, ok := forkedCards[originCard.ID]
+		if !ok {
+			// cards that already existed at the last sync were deleted by the user
+			if originCard.CreatedAt != nil && !originCard.CreatedAt.After(lastSync) {
+				continue
+			}
+
+			newCards = append(
+				newCards,
+				u.copyCard(originCard, deck.UserID, DeckID, notes, timestamp),
+			)
+			continue
+		}
+
+		if card.Origin.SyncedAt == nil || originCard.UpdatedAt == nil {
+			continue
+		}
+
+		changedUpstream := originCard.UpdatedAt.After(*card.Origin.SyncedAt)
+		editedLocally := card.UpdatedAt != nil && card.UpdatedAt.After(*card.Origin.SyncedAt)
+		if !changedUpstream || editedLocally {
+			continue
+		}
+
+		operation.ChangedCards = append(operation.ChangedCards, cardContent(&card))
+
+		card.Question = originCard.Question
+		card.Answer = originCard.Answer
+		card.Tags = originCard.Tags
+		card.Type = originCard.Type
+		card.NoteID = notes.noteID(originCard.NoteID)
+		card.Ordinal = originCard.Ordinal
+		card.Cloze = originCard.Cloze
+		card.Choices = originCard.Choices
+		card.Accepted = originCard.Accepted
+		card.Occlusion = originCard.Occlusion
+		card.Custom = notes.custom(originCard.Custom)
+		card.Source = entity.SourceSync
+		card.Origin.SyncedAt = &timestamp
+		card.UpdatedAt = &timestamp
+
+		if err := u.cardStore.SyncCard(card.ID, deck.UserID, DeckID, &card); err != nil {
+			return nil, err
+		}
+	}
+
+	if len(newCards) > 0 {
+		cardIDs, err := u.cardStore.SaveCards(DeckID, deck.UserID, newCards)
+		if err != nil {
+			return nil, err
+		}
+
+		operation.CreatedCards = cardIDs
+	}
+
+	deck.Origin.SyncedAt = &timestamp
+	if err := u.deckStore.UpdateOrigin(deck.UserID, DeckID, deck.Origin); err != nil {
+		return nil, err
+	}
+
+	if len(operation.CreatedCards) > 0 || len(operation.ChangedCards) > 0 {
+		if err := u.undoStore.Save(&operation); err != nil {
+			return nil, err
+		}
+
+		activity := newActivity(
+			userID,
+			DeckID,
+			entity.ActivityDeckPull,
+			append(cardIDsOf(operation.ChangedCards), operation.CreatedCards...),
+			timestamp,
+		)
+		if err := u.activityStore.Save(&activity); err != nil {
+			return nil, err
+		}
+	}
+
+	return u.GetDeck(userID, DeckID)
 }
@@ -1,7 +1,9 @@
 package usecase
 
 import (
+	"errors"
 	"testing"
+	"time"
 
 	"github.com/moshrank/spacey-backend/services/deck-management-service/entity"
 	"github.com/stretchr/testify/assert"
@@ -17,9 +19,12 @@ func (s *DeckStoreMock) Save(deck *entity.Deck) (string, error) {
 	return args.Get(0).(string), args.Error(1)
 }
 
-func (s *DeckStoreMock) FindAll(userID string) ([]entity.Deck, error) {
-	args := s.Called(userID)
-	return args.Get(0).([]entity.Deck), args.Error(1)
+func (s *DeckStoreMock) FindAll(
+	userID string,
+	query *entity.DeckQuery,
+) ([]entity.Deck, string, error) {
+	args := s.Called(userID, query)
+	return args.Get(0).([]entity.Deck), args.String(1), args.Error(2)
 }
 
 func (s *DeckStoreMock) FindByID(userID, deckID string) (*entity.Deck, error) {
@@ -27,35 +32,118 @@ func (s *DeckStoreMock) FindByID(userID, deckID string) (*entity.Deck, error) {
 	return args.Get(0).(*entity.Deck), args.Error(1)
 }
 
+func (s *DeckStoreMock) FindAccessibleByID(userID, deckID string) (*entity.Deck, error) {
+	args := s.Called(userID, deckID)
+	return args.Get(0).(*entity.Deck), args.Error(1)
+}
+
+func (s *DeckStoreMock) UpdateOrigin(userID, deckID string, origin *entity.DeckOrigin) error {
+	args := s.Called(userID, deckID, origin)
+	return args.Error(0)
+}
+
+func (s *DeckStoreMock) UpdateParent(userID, deckID, parentID string, updatedAt time.Time) error {
+	args := s.Called(userID, deckID, parentID, updatedAt)
+	return args.Error(0)
+}
+
 func (s *DeckStoreMock) Update(deck *entity.Deck) error {
 	args := s.Called(deck)
 	return args.Error(0)
 }
 
-func (s *DeckStoreMock) Delete(userID, deckID string) error {
-	args := s.Called(userID, deckID)
+func (s *DeckStoreMock) Delete(userID, deckID string, deletedAt time.Time) error {
+	args := s.Called(userID, deckID, deletedAt)
+	return args.Error(0)
+}
+
+func (s *DeckStoreMock) FindDeleted(userID string) ([]entity.Deck, error) {
+	args := s.Called(userID)
+	return args.Get(0).([]entity.Deck), args.Error(1)
+}
+
+func (s *DeckStoreMock) Restore(userID, deckID string, restoredAt time.Time) error {
+	args := s.Called(userID, deckID, restoredAt)
+	return args.Error(0)
+}
+
+func (s *DeckStoreMock) Purge(deletedBefore time.Time) (int64, error) {
+	args := s.Called(deletedBefore)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+func (s *DeckStoreMock) FindInvitations(userID string) ([]entity.Deck, error) {
+	args := s.Called(userID)
+	return args.Get(0).([]entity.Deck), args.Error(1)
+}
+
+func (s *DeckStoreMock) AddMember(deckID string, member *entity.DeckMember) error {
+	args := s.Called(deckID, member)
+	return args.Error(0)
+}
+
+func (s *DeckStoreMock) UpdateMember(deckID, memberID, role string, updatedAt time.Time) error {
+	args := s.Called(deckID, memberID, role, updatedAt)
+	return args.Error(0)
+}
+
+func (s *DeckStoreMock) AcceptMember(deckID, memberID string, acceptedAt time.Time) error {
+	args := s.Called(deckID, memberID, acceptedAt)
+	return args.Error(0)
+}
+
+func (s *DeckStoreMock) RemoveMember(deckID, memberID string, removedAt time.Time) error {
+	args := s.Called(deckID, memberID, removedAt)
+	return args.Error(0)
+}
+
+func (s *DeckStoreMock) FindChanged(userID string, since time.Time) ([]entity.Deck, error) {
+	args := s.Called(userID, since)
+	return args.Get(0).([]entity.Deck), args.Error(1)
+}
+
+func (s *DeckStoreMock) FindByColorNotIn(colors []string) ([]entity.Deck, error) {
+	args := s.Called(colors)
+	return args.Get(0).([]entity.Deck), args.Error(1)
+}
+
+func (s *DeckStoreMock) UpdateLintRules(deckID string, disabledRules []string) error {
+	args := s.Called(deckID, disabledRules)
 	return args.Error(0)
 }
 
+func (s *DeckStoreMock) Search(query *entity.SearchQuery) ([]entity.DeckMatch, error) {
+	args := s.Called(query)
+	return args.Get(0).([]entity.DeckMatch), args.Error(1)
+}
+
 func TestCreateDeck(t *testing.T) {
 	expDeck := entity.DeckRes{
 		ID:          "1",
 		Name:        "Test Deck",
 		Description: "Test Description",
-		Color:       "Test Color",
+		Color:       "#FFEC87",
+		Role:        "owner",
 		Cards:       []entity.CardRes{},
 	}
 
 	inpDeck := entity.DeckReq{
 		Name:        "Test Deck",
 		Description: "Test Description",
-		Color:       "Test Color",
+		Color:       "#FFEC87",
 	}
 
 	deckStoreMock := new(DeckStoreMock)
 	deckStoreMock.On("Save", mock.Anything).Return("1", nil)
 
-	deckUseCase := NewDeckUseCase(deckStoreMock)
+	deckUseCase := NewDeckUseCase(
+		deckStoreMock,
+		new(CardStoreMock),
+		newUndoStoreMock(),
+		new(NoteTypeStoreMock),
+		newActivityStoreMock(),
+		newTestPalette(deckStoreMock),
+	)
 
 	deck, err := deckUseCase.CreateDeck("1", &inpDeck)
 
@@ -72,12 +160,13 @@ func TestGetDecks(t *testing.T) {
 			Name:        "Test Deck",
 			Description: "Test Description",
 			Color:       "Test Color",
+			Role:        "owner",
 			Cards:       []entity.CardRes{},
 		},
 	}
 
 	deckStoreMock := new(DeckStoreMock)
-	deckStoreMock.On("FindAll", mock.Anything).Return([]entity.Deck{
+	deckStoreMock.On("FindAll", "1", &entity.DeckQuery{WithCards: true}).Return([]entity.Deck{
 		{
 			ID:          "1",
 			Name:        "Test Deck",
@@ -89,16 +178,98 @@ func TestGetDecks(t *testing.T) {
 			UpdatedAt:   nil,
 			DeletedAt:   nil,
 		},
-	}, nil)
+	}, "", nil)
 
-	deckUseCase := NewDeckUseCase(deckStoreMock)
+	deckUseCase := NewDeckUseCase(
+		deckStoreMock,
+		new(CardStoreMock),
+		newUndoStoreMock(),
+		new(NoteTypeStoreMock),
+		newActivityStoreMock(),
+		newTestPalette(new(DeckStoreMock)),
+	)
 
-	decks, err := deckUseCase.GetDecks("1")
+	decks, next, err := deckUseCase.GetDecks("1", &entity.DeckListReq{})
 
 	assert.Nil(t, err)
+	assert.Equal(t, "", next)
 	assert.Equal(t, expDecks, decks)
 }
 
+func TestGetDecksQuery(t *testing.T) {
+	includeCards := true
+
+	tests := []struct {
+		testName  string
+		req       entity.DeckListReq
+		wantQuery *entity.DeckQuery
+		wantErr   error
+	}{
+		{
+			"Sorted Page",
+			entity.DeckListReq{
+				PageReq: entity.PageReq{Limit: 10, Cursor: "cursor"},
+				Sort:    entity.SortUpdatedAt,
+				Order:   entity.OrderDesc,
+			},
+			&entity.DeckQuery{
+				Page:       entity.Page{Limit: 10, Cursor: "cursor"},
+				Sort:       entity.SortUpdatedAt,
+				Descending: true,
+				WithCards:  true,
+			},
+			nil,
+		},
+		{
+			"Fields Without Cards",
+			entity.DeckListReq{Fields: "name, color"},
+			&entity.DeckQuery{},
+			nil,
+		},
+		{
+			"Fields With Cards",
+			entity.DeckListReq{Fields: "name,cards"},
+			&entity.DeckQuery{WithCards: true},
+			nil,
+		},
+		{
+			"Include Cards",
+			entity.DeckListReq{Fields: "name", IncludeCards: &includeCards},
+			&entity.DeckQuery{WithCards: true},
+			nil,
+		},
+		{
+			"Invalid Field",
+			entity.DeckListReq{Fields: "name,user_id"},
+			nil,
+			entity.ErrInvalidField,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.testName, func(t *testing.T) {
+			deckStoreMock := new(DeckStoreMock)
+			deckStoreMock.On("FindAll", "1", mock.Anything).Return([]entity.Deck{}, "", nil)
+
+			deckUseCase := NewDeckUseCase(
+				deckStoreMock,
+				new(CardStoreMock),
+				newUndoStoreMock(),
+				new(NoteTypeStoreMock),
+				newActivityStoreMock(),
+				newTestPalette(new(DeckStoreMock)),
+			)
+
+			_, _, err := deckUseCase.GetDecks("1", &test.req)
+
+			assert.Equal(t, test.wantErr, err)
+			if test.wantQuery != nil {
+				deckStoreMock.AssertCalled(t, "FindAll", "1", test.wantQuery)
+			}
+		})
+	}
+}
+
 func TestGetDeck(t *testing.T) {
 	expDeck := entity.DeckRes{
 
@@ -106,6 +277,7 @@ func TestGetDeck(t *testing.T) {
 		Name:        "Test Deck",
 		Description: "Test Description",
 		Color:       "Test Color",
+		Role:        "owner",
 		Cards:       []entity.CardRes{},
 	}
 
@@ -123,7 +295,17 @@ func TestGetDeck(t *testing.T) {
 		DeletedAt:   nil,
 	}, nil)
 
-	deckUseCase := NewDeckUseCase(deckStoreMock)
+	cardStoreMock := new(CardStoreMock)
+	cardStoreMock.On("FindByDeckID", "1", mock.Anything).Return([]entity.Card{}, "", nil)
+
+	deckUseCase := NewDeckUseCase(
+		deckStoreMock,
+		cardStoreMock,
+		newUndoStoreMock(),
+		new(NoteTypeStoreMock),
+		newActivityStoreMock(),
+		newTestPalette(new(DeckStoreMock)),
+	)
 
 	decks, err := deckUseCase.GetDeck("1", "1")
 
@@ -136,32 +318,511 @@ func TestUpdateDeck(t *testing.T) {
 		ID:          "1",
 		Name:        "Test Deck",
 		Description: "Test Description",
-		Color:       "Test Color",
+		Color:       "#FFEC87",
+		Role:        "owner",
 		Cards:       []entity.CardRes{},
+		Version:     3,
 	}
 
 	inpDeck := entity.DeckReq{
 		Name:        "Test Deck",
 		Description: "Test Description",
-		Color:       "Test Color",
+		Color:       "#FFEC87",
 	}
 
 	deckStoreMock := new(DeckStoreMock)
-	deckStoreMock.On("Update", mock.Anything).Return(nil)
-	deckUseCase := NewDeckUseCase(deckStoreMock)
-
-	deck, err := deckUseCase.UpdateDeck("1", "1", &inpDeck)
+	deckStoreMock.On("FindByID", "1", "1").
+		Return(&entity.Deck{ID: "1", UserID: "1", Version: 2}, nil)
+	deckStoreMock.On("Update", mock.MatchedBy(func(deck *entity.Deck) bool {
+		return deck.Version == 2
+	})).Return(nil)
+	deckUseCase := NewDeckUseCase(
+		deckStoreMock,
+		new(CardStoreMock),
+		newUndoStoreMock(),
+		new(NoteTypeStoreMock),
+		newActivityStoreMock(),
+		newTestPalette(new(DeckStoreMock)),
+	)
+
+	deck, err := deckUseCase.UpdateDeck("1", "1", &inpDeck, entity.IfMatch{2})
 
 	assert.Nil(t, err)
 	assert.Equal(t, &expDeck, deck)
 }
 
+func TestUpdateChangedDeck(t *testing.T) {
+	deckStoreMock := new(DeckStoreMock)
+	deckStoreMock.On("FindByID", "1", "1").
+		Return(&entity.Deck{ID: "1", UserID: "1", Version: 2}, nil)
+	deckUseCase := NewDeckUseCase(
+		deckStoreMock,
+		new(CardStoreMock),
+		newUndoStoreMock(),
+		new(NoteTypeStoreMock),
+		newActivityStoreMock(),
+		newTestPalette(new(DeckStoreMock)),
+	)
+
+	deckReq := entity.DeckReq{Name: "Test Deck"}
+	_, err := deckUseCase.UpdateDeck("1", "1", &deckReq, entity.IfMatch{1})
+
+	assert.Equal(t, entity.ErrVersionConflict, err)
+	deckStoreMock.AssertNotCalled(t, "Update", mock.Anything)
+}
+
+func TestUpdateDeckPublic(t *testing.T) {
+	tests := []struct {
+		testName string
+		userID   string
+		err      error
+	}{
+		{testName: "Owner", userID: "1", err: nil},
+		{testName: "Editor", userID: "2", err: entity.ErrForbidden},
+	}
+
+	for _, test := range tests {
+		t.Run(test.testName, func(t *testing.T) {
+			deckStoreMock := new(DeckStoreMock)
+			deckStoreMock.On("FindByID", test.userID, "1").Return(&entity.Deck{
+				ID:     "1",
+				UserID: "1",
+				Members: []entity.DeckMember{
+					{UserID: "2", Role: entity.RoleEditor, Accepted: true},
+				},
+			}, nil)
+			deckStoreMock.On("Update", mock.Anything).Return(nil)
+			deckUseCase := NewDeckUseCase(
+				deckStoreMock,
+				new(CardStoreMock),
+				newUndoStoreMock(),
+				new(NoteTypeStoreMock),
+				newActivityStoreMock(),
+				newTestPalette(new(DeckStoreMock)),
+			)
+
+			deckReq := entity.DeckReq{Name: "Test Deck", Public: true}
+			_, err := deckUseCase.UpdateDeck(test.userID, "1", &deckReq, nil)
+
+			assert.Equal(t, test.err, err)
+		})
+	}
+}
+
 func TestDeleteDeck(t *testing.T) {
 	deckStoreMock := new(DeckStoreMock)
-	deckStoreMock.On("Delete", mock.Anything, mock.Anything).Return(nil)
-	deckUseCase := NewDeckUseCase(deckStoreMock)
+	deckStoreMock.On("FindByID", "1", "1").Return(&entity.Deck{ID: "1", UserID: "1"}, nil)
+	deckStoreMock.On("FindAll", "1", &entity.DeckQuery{}).Return([]entity.Deck{
+		{ID: "1", UserID: "1"},
+		{ID: "2", UserID: "1", ParentID: "1"},
+		{ID: "3", UserID: "1", ParentID: "2"},
+		{ID: "4", UserID: "1"},
+	}, "", nil)
+	deckStoreMock.On("Delete", "1", mock.Anything, mock.Anything).Return(nil)
+	deckUseCase := NewDeckUseCase(
+		deckStoreMock,
+		new(CardStoreMock),
+		newUndoStoreMock(),
+		new(NoteTypeStoreMock),
+		newActivityStoreMock(),
+		newTestPalette(new(DeckStoreMock)),
+	)
+
+	err := deckUseCase.DeleteDeck("1", "1", nil)
+
+	assert.Nil(t, err)
+
+	deleted := []string{}
+	for _, call := range deckStoreMock.Calls {
+		if call.Method == "Delete" {
+			deleted = append(deleted, call.Arguments.String(1))
+		}
+	}
+	assert.Equal(t, []string{"3", "2", "1"}, deleted)
+}
+
+func TestCreateDeckBelowForeignDeck(t *testing.T) {
+	deckStoreMock := new(DeckStoreMock)
+	deckStoreMock.On("FindByID", "1", "shared_deck").Return(&entity.Deck{
+		ID:      "shared_deck",
+		UserID:  "2",
+		Members: []entity.DeckMember{{UserID: "1", Role: entity.RoleOwner, Accepted: true}},
+	}, nil)
+	deckUseCase := NewDeckUseCase(
+		deckStoreMock,
+		new(CardStoreMock),
+		newUndoStoreMock(),
+		new(NoteTypeStoreMock),
+		newActivityStoreMock(),
+		newTestPalette(new(DeckStoreMock)),
+	)
+
+	_, err := deckUseCase.CreateDeck("1", &entity.DeckReq{
+		Name:     "Drugs",
+		Color:    "#FFEC87",
+		ParentID: "shared_deck",
+	})
+
+	assert.Equal(t, entity.ErrInvalidParent, err)
+	deckStoreMock.AssertNotCalled(t, "Save", mock.Anything)
+}
+
+func TestMoveDeck(t *testing.T) {
+	tests := []struct {
+		testName string
+		parentID string
+		wantErr  error
+	}{
+		{
+			"Below Other Deck",
+			"other",
+			nil,
+		},
+		{
+			"To Top Level",
+			"",
+			nil,
+		},
+		{
+			"Below Itself",
+			"medicine",
+			entity.ErrInvalidParent,
+		},
+		{
+			"Below Sub-Deck",
+			"drugs",
+			entity.ErrInvalidParent,
+		},
+	}
+
+	decks := []entity.Deck{
+		{ID: "medicine", UserID: "1"},
+		{ID: "cardio", UserID: "1", ParentID: "medicine"},
+		{ID: "drugs", UserID: "1", ParentID: "cardio"},
+		{ID: "other", UserID: "1"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.testName, func(t *testing.T) {
+			deckStoreMock := new(DeckStoreMock)
+			for i := range decks {
+				deckStoreMock.On("FindByID", "1", decks[i].ID).Return(&decks[i], nil)
+			}
+			deckStoreMock.On("FindAll", "1", &entity.DeckQuery{}).Return(decks, "", nil)
+			deckStoreMock.On("UpdateParent", "1", "medicine", test.parentID, mock.Anything).
+				Return(nil)
+
+			deckUseCase := NewDeckUseCase(
+				deckStoreMock,
+				new(CardStoreMock),
+				newUndoStoreMock(),
+				new(NoteTypeStoreMock),
+				newActivityStoreMock(),
+				newTestPalette(new(DeckStoreMock)),
+			)
+
+			deck, err := deckUseCase.MoveDeck("1", "medicine", &entity.DeckMoveReq{
+				ParentID: test.parentID,
+			})
+
+			assert.Equal(t, test.wantErr, err)
+			if test.wantErr == nil {
+				assert.Equal(t, test.parentID, deck.ParentID)
+				deckStoreMock.AssertCalled(
+					t,
+					"UpdateParent",
+					"1",
+					"medicine",
+					test.parentID,
+					mock.Anything,
+				)
+			} else {
+				deckStoreMock.AssertNotCalled(
+					t,
+					"UpdateParent",
+					"1",
+					"medicine",
+					test.parentID,
+					mock.Anything,
+				)
+			}
+		})
+	}
+}
+
+func TestGetDeckTree(t *testing.T) {
+	deckStoreMock := new(DeckStoreMock)
+	deckStoreMock.On("FindAll", "1", &entity.DeckQuery{Sort: entity.SortName}).
+		Return([]entity.Deck{
+			{ID: "cardio", Name: "Cardio", UserID: "1", ParentID: "medicine"},
+			{ID: "drugs", Name: "Drugs", UserID: "1", ParentID: "cardio"},
+			{ID: "medicine", Name: "Medicine", UserID: "1"},
+			{
+				ID:       "shared",
+				Name:     "Shared",
+				UserID:   "2",
+				ParentID: "hidden",
+				Members:  []entity.DeckMember{{UserID: "1", Role: entity.RoleViewer, Accepted: true}},
+			},
+		}, "", nil)
+
+	cardStoreMock := new(CardStoreMock)
+	cardStoreMock.On("CountByDeckIDs", []string{"cardio", "drugs", "medicine", "shared"}).
+		Return(map[string]int{"cardio": 2, "drugs": 3, "medicine": 1}, nil)
+
+	deckUseCase := NewDeckUseCase(
+		deckStoreMock,
+		cardStoreMock,
+		newUndoStoreMock(),
+		new(NoteTypeStoreMock),
+		newActivityStoreMock(),
+		newTestPalette(new(DeckStoreMock)),
+	)
+
+	tree, err := deckUseCase.GetDeckTree("1")
+
+	assert.Nil(t, err)
+	assert.Len(t, tree, 2)
+
+	medicine := tree[0]
+	assert.Equal(t, "Medicine", medicine.Path)
+	assert.Equal(t, 1, medicine.CardCount)
+	assert.Equal(t, 6, medicine.TotalCardCount)
+	assert.Equal(t, "Medicine::Cardio::Drugs", medicine.Children[0].Children[0].Path)
+	assert.Equal(t, 5, medicine.Children[0].TotalCardCount)
+
+	shared := tree[1]
+	assert.Equal(t, "Shared", shared.Path)
+	assert.Equal(t, entity.RoleViewer, shared.Role)
+	assert.Equal(t, []entity.DeckTreeRes{}, shared.Children)
+}
+
+func TestForkDeck(t *testing.T) {
+	deckStoreMock := new(DeckStoreMock)
+	deckStoreMock.On("FindAccessibleByID", "2", "1").Return(&entity.Deck{
+		ID:          "1",
+		Name:        "Test Deck",
+		UserID:      "1",
+		Description: "Test Description",
+		Color:       "Test Color",
+		Public:      true,
+	}, nil)
+	deckStoreMock.On("Save", mock.Anything).Return("2", nil)
+
+	cardStoreMock := new(CardStoreMock)
+	cardStoreMock.On("FindByDeckID", "1", mock.Anything).Return([]entity.Card{
+		{ID: "card_1", Question: "Test Question", Answer: "Test Answer"},
+	}, "", nil)
+	cardStoreMock.On("SaveCards", "2", "2", mock.Anything).Return([]string{"card_2"}, nil)
+
+	deckUseCase := NewDeckUseCase(
+		deckStoreMock,
+		cardStoreMock,
+		newUndoStoreMock(),
+		new(NoteTypeStoreMock),
+		newActivityStoreMock(),
+		newTestPalette(new(DeckStoreMock)),
+	)
+
+	deck, err := deckUseCase.ForkDeck("2", "1")
+
+	assert.Nil(t, err)
+	assert.Equal(t, "2", deck.ID)
+	assert.False(t, deck.Public)
+	assert.Equal(t, "1", deck.Origin.DeckID)
+	assert.Equal(t, 1, len(deck.Cards))
+	assert.Equal(t, "card_2", deck.Cards[0].ID)
+
+	savedCards := cardStoreMock.Calls[1].Arguments.Get(2).([]entity.Card)
+	assert.Equal(t, "card_1", savedCards[0].Origin.CardID)
+}
+
+func TestForkDeckNotes(t *testing.T) {
+	deckStoreMock := new(DeckStoreMock)
+	deckStoreMock.On("FindAccessibleByID", "2", "1").
+		Return(&entity.Deck{ID: "1", UserID: "1", Public: true}, nil)
+	deckStoreMock.On("Save", mock.Anything).Return("2", nil)
+
+	custom := &entity.CustomNote{NoteTypeID: "type_1", Fields: map[string]string{"Word": "犬"}}
+	cardStoreMock := new(CardStoreMock)
+	cardStoreMock.On("FindByDeckID", "1", mock.Anything).Return([]entity.Card{
+		{ID: "card_1", Type: entity.CardTypeCustom, NoteID: "note_1", Ordinal: 1, Custom: custom},
+		{ID: "card_2", Type: entity.CardTypeCustom, NoteID: "note_1", Ordinal: 2, Custom: custom},
+	}, "", nil)
+	cardStoreMock.On("SaveCards", "2", "2", mock.Anything).
+		Return([]string{"card_3", "card_4"}, nil)
+
+	noteTypeStoreMock := new(NoteTypeStoreMock)
+	noteTypeStoreMock.On("FindAll", "2").Return([]entity.NoteType{}, nil)
+	noteTypeStoreMock.On("FindByIDs", []string{"type_1"}).
+		Return([]entity.NoteType{{ID: "type_1", UserID: "1", Name: "Vocabulary"}}, nil)
+	noteTypeStoreMock.On("Save", mock.MatchedBy(func(noteType *entity.NoteType) bool {
+		return noteType.UserID == "2" && noteType.OriginID == "type_1"
+	})).Return("type_2", nil)
+
+	deckUseCase := NewDeckUseCase(
+		deckStoreMock,
+		cardStoreMock,
+		newUndoStoreMock(),
+		noteTypeStoreMock,
+		newActivityStoreMock(),
+		newTestPalette(new(DeckStoreMock)),
+	)
+
+	_, err := deckUseCase.ForkDeck("2", "1")
+
+	assert.Nil(t, err)
+	noteTypeStoreMock.AssertNumberOfCalls(t, "Save", 1)
+
+	savedCards := cardStoreMock.Calls[1].Arguments.Get(2).([]entity.Card)
+	assert.Equal(t, "type_2", savedCards[0].Custom.NoteTypeID)
+	assert.Equal(t, "type_2", savedCards[1].Custom.NoteTypeID)
+	assert.NotEqual(t, "note_1", savedCards[0].NoteID)
+	assert.Equal(t, savedCards[0].NoteID, savedCards[1].NoteID)
+	assert.Equal(t, "type_1", custom.NoteTypeID)
+}
 
-	err := deckUseCase.DeleteDeck("1", "1")
+func TestForkDeckStoreError(t *testing.T) {
+	tests := []struct {
+		testName string
+		err      error
+	}{
+		{testName: "Deck Not Found", err: entity.ErrDeckNotFound},
+		{testName: "Database Error", err: errors.New("connection refused")},
+	}
+
+	for _, test := range tests {
+		t.Run(test.testName, func(t *testing.T) {
+			deckStoreMock := new(DeckStoreMock)
+			deckStoreMock.On("FindAccessibleByID", "2", "1").Return((*entity.Deck)(nil), test.err)
+
+			deckUseCase := NewDeckUseCase(
+				deckStoreMock,
+				new(CardStoreMock),
+				newUndoStoreMock(),
+				new(NoteTypeStoreMock),
+				newActivityStoreMock(),
+				newTestPalette(new(DeckStoreMock)),
+			)
+
+			_, err := deckUseCase.ForkDeck("2", "1")
+
+			assert.Equal(t, test.err, err)
+		})
+	}
+}
+
+func TestPullDeck(t *testing.T) {
+	lastSync := time.Now().Add(-time.Hour)
+	before := lastSync.Add(-time.Hour)
+	after := lastSync.Add(time.Minute)
+
+	fork := &entity.Deck{
+		ID:     "2",
+		UserID: "2",
+		Origin: &entity.DeckOrigin{DeckID: "1", SyncedAt: &lastSync},
+	}
+
+	forkCards := []entity.Card{
+		{
+			ID:        "unchanged",
+			Question:  "Old Question",
+			UpdatedAt: &lastSync,
+			Origin:    &entity.CardOrigin{CardID: "origin_updated", SyncedAt: &lastSync},
+		},
+		{
+			ID:        "edited",
+			Question:  "My Question",
+			UpdatedAt: &after,
+			Origin:    &entity.CardOrigin{CardID: "origin_both_updated", SyncedAt: &lastSync},
+		},
+	}
+
+	origin := &entity.Deck{ID: "1"}
+
+	originCards := []entity.Card{
+		{ID: "origin_updated", Question: "New Question", CreatedAt: &before, UpdatedAt: &after},
+		{
+			ID:        "origin_both_updated",
+			Question:  "Their Question",
+			CreatedAt: &before,
+			UpdatedAt: &after,
+		},
+		{ID: "origin_deleted_locally", CreatedAt: &before, UpdatedAt: &before},
+		{ID: "origin_new", Question: "New Card", CreatedAt: &after, UpdatedAt: &after},
+	}
+
+	deckStoreMock := new(DeckStoreMock)
+	deckStoreMock.On("FindByID", "2", "2").Return(fork, nil)
+	deckStoreMock.On("FindAccessibleByID", "2", "1").Return(origin, nil)
+	deckStoreMock.On("UpdateOrigin", "2", "2", mock.Anything).Return(nil)
+
+	cardStoreMock := new(CardStoreMock)
+	cardStoreMock.On("FindByDeckID", "2", mock.Anything).Return(forkCards, "", nil)
+	cardStoreMock.On("FindByDeckID", "1", mock.Anything).Return(originCards, "", nil)
+	cardStoreMock.On("SyncCard", "unchanged", "2", "2", mock.Anything).Return(nil)
+	cardStoreMock.On("SaveCards", "2", "2", mock.Anything).Return([]string{"new"}, nil)
+
+	deckUseCase := NewDeckUseCase(
+		deckStoreMock,
+		cardStoreMock,
+		newUndoStoreMock(),
+		new(NoteTypeStoreMock),
+		newActivityStoreMock(),
+		newTestPalette(new(DeckStoreMock)),
+	)
+
+	_, err := deckUseCase.PullDeck("2", "2")
 
 	assert.Nil(t, err)
+	cardStoreMock.AssertNumberOfCalls(t, "SyncCard", 1)
+
+	syncedCard := cardStoreMock.Calls[2].Arguments.Get(3).(*entity.Card)
+	assert.Equal(t, "New Question", syncedCard.Question)
+
+	newCards := cardStoreMock.Calls[3].Arguments.Get(2).([]entity.Card)
+	assert.Equal(t, 1, len(newCards))
+	assert.Equal(t, "origin_new", newCards[0].Origin.CardID)
+}
+
+func TestPullDeckNotForked(t *testing.T) {
+	deckStoreMock := new(DeckStoreMock)
+	deckStoreMock.On("FindByID", "1", "1").Return(&entity.Deck{ID: "1", UserID: "1"}, nil)
+
+	deckUseCase := NewDeckUseCase(
+		deckStoreMock,
+		new(CardStoreMock),
+		newUndoStoreMock(),
+		new(NoteTypeStoreMock),
+		newActivityStoreMock(),
+		newTestPalette(new(DeckStoreMock)),
+	)
+
+	_, err := deckUseCase.PullDeck("1", "1")
+
+	assert.Equal(t, entity.ErrDeckNotForked, err)
+}
+
+func TestDeleteDeckAsEditor(t *testing.T) {
+	deckStoreMock := new(DeckStoreMock)
+	deckStoreMock.On("FindByID", "2", "1").Return(&entity.Deck{
+		ID:     "1",
+		UserID: "1",
+		Members: []entity.DeckMember{
+			{UserID: "2", Role: entity.RoleEditor, Accepted: true},
+		},
+	}, nil)
+	deckUseCase := NewDeckUseCase(
+		deckStoreMock,
+		new(CardStoreMock),
+		newUndoStoreMock(),
+		new(NoteTypeStoreMock),
+		newActivityStoreMock(),
+		newTestPalette(new(DeckStoreMock)),
+	)
+
+	err := deckUseCase.DeleteDeck("2", "1", nil)
+
+	assert.Equal(t, entity.ErrForbidden, err)
+	deckStoreMock.AssertNotCalled(t, "Delete", mock.Anything, mock.Anything, mock.Anything)
 }
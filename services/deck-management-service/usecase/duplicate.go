@@ -0,0 +1,262 @@
+package usecase
+
+import (
+	"math"
+	"strings"
+	"unicode"
+
+	"github.com/moshrank/spacey-backend/services/deck-management-service/entity"
+)
+
+const (
+	// Questions shorter than minFuzzyLength only match exactly, since a few
+	// changed characters already turn them into a different question.
+	minFuzzyLength = 12
+	// Questions longer than maxFuzzyLength only match exactly to bound the
+	// cost of the edit distance.
+	maxFuzzyLength = 1000
+	// minTrigramSimilarity filters the candidates before the edit distance
+	// is computed. It is low enough to keep every pair that reaches
+	// minSimilarity.
+	minTrigramSimilarity = 0.3
+	minSimilarity        = 0.85
+)
+
+type duplicateCandidate struct {
+	card     entity.Card
+	text     []rune
+	trigrams map[string]struct{}
+}
+
+// duplicateIndex finds the card a new card duplicates. A nil index finds no
+// duplicates, so that detection can be turned off.
+type duplicateIndex struct {
+	candidates []duplicateCandidate
+	exact      map[string]int
+}
+
+func newDuplicateIndex() *duplicateIndex {
+	return &duplicateIndex{exact: map[string]int{}}
+}
+
+// loadDuplicateIndex indexes the cards that cards added to the deck are
+// compared with, which depends on the requested scope.
+func loadDuplicateIndex(
+	deckStore entity.DeckStoreInterface,
+	cardStore entity.CardStoreInterface,
+	userID, deckID string,
+	req *entity.DuplicateReq,
+) (*duplicateIndex, error) {
+	if req == nil {
+		return nil, nil
+	}
+
+	switch req.Duplicates {
+	case entity.DuplicateScopeDeck:
+		cards, _, err := cardStore.FindByDeckID(deckID, &entity.Page{})
+		if err != nil {
+			return nil, err
+		}
+
+		index := newDuplicateIndex()
+		for _, card := range cards {
+			index.add(&card)
+		}
+
+		return index, nil
+	case entity.DuplicateScopeAccount:
+		decks, _, err := deckStore.FindAll(userID, &entity.DeckQuery{WithCards: true})
+		if err != nil {
+			return nil, err
+		}
+
+		index := newDuplicateIndex()
+		for _, deck := range decks {
+			for _, card := range deck.Cards {
+				index.add(&card)
+			}
+		}
+
+		return index, nil
+	}
+
+	return nil, nil
+}
+
+// skipDuplicate reports whether the card is left out because it is a
+// duplicate.
+func skipDuplicate(req *entity.DuplicateReq, duplicate *entity.DuplicateRes) bool {
+	return req != nil && req.SkipDuplicates && duplicate != nil
+}
+
+// duplicateText returns the text cards are compared by. Sibling cards of a
+// note are compared by the text of their note.
+func duplicateText(card *entity.Card) string {
+	switch {
+	case card.Cloze != nil:
+		return card.Cloze.Text
+	case card.Occlusion != nil:
+		return card.Occlusion.Image
+	default:
+		return card.Question
+	}
+}
+
+// normalizeText lower cases the text and reduces it to its letters and
+// numbers separated by single spaces.
+func normalizeText(text string) string {
+	var normalized strings.Builder
+	separate := false
+	for _, r := range strings.ToLower(text) {
+		if !unicode.IsLetter(r) && !unicode.IsNumber(r) {
+			separate = true
+			continue
+		}
+
+		if separate && normalized.Len() > 0 {
+			normalized.WriteByte(' ')
+		}
+		separate = false
+		normalized.WriteRune(r)
+	}
+
+	return normalized.String()
+}
+
+func trigrams(text []rune) map[string]struct{} {
+	padded := append(append([]rune{' '}, text...), ' ')
+
+	grams := map[string]struct{}{}
+	for i := 0; i+3 <= len(padded); i++ {
+		grams[string(padded[i:i+3])] = struct{}{}
+	}
+
+	return grams
+}
+
+// trigramSimilarity returns the jaccard index of the trigram sets.
+func trigramSimilarity(a, b map[string]struct{}) float64 {
+	shared := 0
+	for gram := range a {
+		if _, ok := b[gram]; ok {
+			shared++
+		}
+	}
+
+	return float64(shared) / float64(len(a)+len(b)-shared)
+}
+
+// levenshtein returns the number of inserted, deleted and substituted runes
+// needed to turn a into b.
+func levenshtein(a, b []rune) int {
+	previous := make([]int, len(b)+1)
+	current := make([]int, len(b)+1)
+	for j := range previous {
+		previous[j] = j
+	}
+
+	for i := 1; i <= len(a); i++ {
+		current[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			current[j] = min(previous[j]+1, current[j-1]+1, previous[j-1]+cost)
+		}
+		previous, current = current, previous
+	}
+
+	return previous[len(b)]
+}
+
+func (i *duplicateIndex) add(card *entity.Card) {
+	if i == nil {
+		return
+	}
+
+	text := normalizeText(duplicateText(card))
+	if text == "" {
+		return
+	}
+
+	if _, ok := i.exact[text]; !ok {
+		i.exact[text] = len(i.candidates)
+	}
+
+	runes := []rune(text)
+	i.candidates = append(i.candidates, duplicateCandidate{
+		card:     *card,
+		text:     runes,
+		trigrams: trigrams(runes),
+	})
+}
+
+// find returns the most similar indexed card if the card is a duplicate of
+// it, exact duplicates taking precedence.
+func (i *duplicateIndex) find(card *entity.Card) *entity.DuplicateRes {
+	if i == nil {
+		return nil
+	}
+
+	text := normalizeText(duplicateText(card))
+	if text == "" {
+		return nil
+	}
+
+	if j, ok := i.exact[text]; ok {
+		return newDuplicateRes(&i.candidates[j].card, 1, true)
+	}
+
+	runes := []rune(text)
+	if !fuzzyLength(runes) {
+		return nil
+	}
+	grams := trigrams(runes)
+
+	var best *duplicateCandidate
+	bestSimilarity := 0.0
+	for j := range i.candidates {
+		candidate := &i.candidates[j]
+		if !fuzzyLength(candidate.text) {
+			continue
+		}
+
+		longer := float64(max(len(runes), len(candidate.text)))
+		// the difference in length is a lower bound of the edit distance
+		lengthDiff := math.Abs(float64(len(runes) - len(candidate.text)))
+		if 1-lengthDiff/longer < minSimilarity {
+			continue
+		}
+
+		if trigramSimilarity(grams, candidate.trigrams) < minTrigramSimilarity {
+			continue
+		}
+
+		similarity := 1 - float64(levenshtein(runes, candidate.text))/longer
+		if similarity >= minSimilarity && similarity > bestSimilarity {
+			best = candidate
+			bestSimilarity = similarity
+		}
+	}
+
+	if best == nil {
+		return nil
+	}
+
+	return newDuplicateRes(&best.card, bestSimilarity, false)
+}
+
+func fuzzyLength(text []rune) bool {
+	return len(text) >= minFuzzyLength && len(text) <= maxFuzzyLength
+}
+
+func newDuplicateRes(card *entity.Card, similarity float64, exact bool) *entity.DuplicateRes {
+	return &entity.DuplicateRes{
+		CardID:     card.ID,
+		DeckID:     card.DeckID,
+		Question:   card.Question,
+		Similarity: math.Round(similarity*100) / 100,
+		Exact:      exact,
+	}
+}
@@ -0,0 +1,239 @@
+package usecase
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/moshrank/spacey-backend/services/deck-management-service/entity"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestNormalizeText(t *testing.T) {
+	assert.Equal(
+		t,
+		"what is the capital of france",
+		normalizeText(" What is the\ncapital of France?"),
+	)
+	assert.Equal(t, "größe 2", normalizeText("Größe (2)"))
+	assert.Equal(t, "", normalizeText("?!"))
+}
+
+func TestLevenshtein(t *testing.T) {
+	assert.Equal(t, 3, levenshtein([]rune("kitten"), []rune("sitting")))
+	assert.Equal(t, 0, levenshtein([]rune("hund"), []rune("hund")))
+	assert.Equal(t, 4, levenshtein([]rune(""), []rune("hund")))
+}
+
+func TestFindDuplicate(t *testing.T) {
+	index := newDuplicateIndex()
+	index.add(&entity.Card{ID: "card_1", DeckID: "1", Question: "What is the capital of France?"})
+	index.add(&entity.Card{ID: "card_2", DeckID: "1", Question: "hablar"})
+	index.add(&entity.Card{
+		ID:     "card_3",
+		DeckID: "1",
+		Cloze:  &entity.ClozeNote{Text: "{{c1::Paris}} is the capital of {{c2::France}}"},
+	})
+
+	tests := []struct {
+		testName       string
+		card           entity.Card
+		wantCardID     string
+		wantExact      bool
+		wantSimilarity float64
+	}{
+		{
+			"Exact",
+			entity.Card{Question: "what is the capital of france"},
+			"card_1", true, 1,
+		},
+		{
+			"Typo",
+			entity.Card{Question: "What is the capitol of France?"},
+			"card_1", false, 0.97,
+		},
+		{
+			"Different Question",
+			entity.Card{Question: "What is the capital of Spain?"},
+			"", false, 0,
+		},
+		{
+			"Short Question",
+			entity.Card{Question: "hablas"},
+			"", false, 0,
+		},
+		{
+			"Cloze Text",
+			entity.Card{Cloze: &entity.ClozeNote{Text: "{{c1::Paris}} is the capital of France"}},
+			"card_3", false, 0.92,
+		},
+		{
+			"Empty Question",
+			entity.Card{Question: "..."},
+			"", false, 0,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.testName, func(t *testing.T) {
+			duplicate := index.find(&test.card)
+
+			if test.wantCardID == "" {
+				assert.Nil(t, duplicate)
+				return
+			}
+
+			assert.Equal(t, test.wantCardID, duplicate.CardID)
+			assert.Equal(t, test.wantExact, duplicate.Exact)
+			assert.Equal(t, test.wantSimilarity, duplicate.Similarity)
+		})
+	}
+}
+
+func TestFindDuplicateWithoutIndex(t *testing.T) {
+	var index *duplicateIndex
+	index.add(&entity.Card{Question: "hablar"})
+
+	assert.Nil(t, index.find(&entity.Card{Question: "hablar"}))
+}
+
+func TestFindDuplicateInLongText(t *testing.T) {
+	index := newDuplicateIndex()
+	index.add(&entity.Card{ID: "card_1", Question: strings.Repeat("a", maxFuzzyLength+1)})
+
+	assert.Nil(t, index.find(&entity.Card{Question: strings.Repeat("a", maxFuzzyLength) + "b"}))
+}
+
+func TestCreateCardsWithDuplicates(t *testing.T) {
+	tests := []struct {
+		testName    string
+		skip        bool
+		wantSaved   int
+		wantSkipped []bool
+	}{
+		{"Flag Duplicates", false, 3, []bool{false, false, false}},
+		{"Skip Duplicates", true, 1, []bool{true, false, true}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.testName, func(t *testing.T) {
+			cardStoreMock := new(CardStoreMock)
+			cardStoreMock.On("FindByDeckID", "test_deck_id", mock.Anything).Return([]entity.Card{
+				{ID: "card_1", DeckID: "test_deck_id", Question: "hablar", Answer: "to speak"},
+			}, "", nil)
+			cardStoreMock.On("SaveCards", "test_deck_id", "1", mock.Anything).
+				Return([]string{"new_1", "new_2", "new_3"}[:test.wantSaved], nil)
+
+			cardUseCase := NewCardUseCase(
+				cardStoreMock,
+				newOwnedDeckStoreMock(),
+				newUndoStoreMock(),
+				new(NoteTypeStoreMock),
+				newActivityStoreMock(),
+				new(MediaStoreMock),
+			)
+			cards, err := cardUseCase.CreateCards(
+				"test_deck_id",
+				"1",
+				[]entity.CardReq{
+					{Question: "Hablar!", Answer: "to speak"},
+					{Question: "comer", Answer: "to eat"},
+					{Question: "comer", Answer: "eating"},
+				},
+				&entity.DuplicateReq{
+					Duplicates:     entity.DuplicateScopeDeck,
+					SkipDuplicates: test.skip,
+				},
+			)
+
+			assert.Nil(t, err)
+			assert.Len(t, cards, 3)
+			for i := range cards {
+				assert.Equal(t, test.wantSkipped[i], cards[i].Skipped)
+			}
+
+			assert.Equal(t, "card_1", cards[0].Duplicate.CardID)
+			assert.Nil(t, cards[1].Duplicate)
+			assert.Equal(t, "", cards[2].Duplicate.CardID)
+			assert.Equal(t, "comer", cards[2].Duplicate.Question)
+
+			if test.skip {
+				assert.Equal(t, "", cards[0].ID)
+				assert.Equal(t, "new_1", cards[1].ID)
+			}
+
+			saved := cardStoreMock.Calls[1].Arguments.Get(2).([]entity.Card)
+			assert.Len(t, saved, test.wantSaved)
+		})
+	}
+}
+
+func TestCreateSkippedCard(t *testing.T) {
+	deckStoreMock := newOwnedDeckStoreMock()
+	deckStoreMock.On("FindAll", "1", &entity.DeckQuery{WithCards: true}).Return([]entity.Deck{
+		{ID: "2", Cards: []entity.Card{{ID: "card_1", DeckID: "2", Question: "hablar"}}},
+	}, "", nil)
+
+	cardStoreMock := new(CardStoreMock)
+
+	cardUseCase := NewCardUseCase(
+		cardStoreMock,
+		deckStoreMock,
+		newUndoStoreMock(),
+		new(NoteTypeStoreMock),
+		newActivityStoreMock(),
+		new(MediaStoreMock),
+	)
+	card, err := cardUseCase.CreateCard(
+		"test_deck_id",
+		"1",
+		&entity.CardReq{Question: "hablar", Answer: "to speak"},
+		&entity.DuplicateReq{Duplicates: entity.DuplicateScopeAccount, SkipDuplicates: true},
+	)
+
+	assert.Nil(t, err)
+	assert.True(t, card.Skipped)
+	assert.Equal(t, "2", card.Duplicate.DeckID)
+	cardStoreMock.AssertNotCalled(t, "SaveCard", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestImportCardsWithDuplicates(t *testing.T) {
+	deckStoreMock := new(DeckStoreMock)
+	deckStoreMock.On("FindByID", "1", "1").Return(&entity.Deck{ID: "1", UserID: "1"}, nil)
+
+	cardStoreMock := new(CardStoreMock)
+	cardStoreMock.On("FindByDeckID", "1", mock.Anything).Return([]entity.Card{
+		{ID: "card_1", DeckID: "1", Question: "hablar"},
+	}, "", nil)
+
+	transferUseCase := NewTransferUseCase(
+		deckStoreMock,
+		cardStoreMock,
+		newUndoStoreMock(),
+		newActivityStoreMock(),
+		newTestPalette(new(DeckStoreMock)),
+		new(MediaStoreMock),
+		new(LearningServiceMock),
+	)
+
+	res, err := transferUseCase.ImportCards(
+		"1",
+		"1",
+		strings.NewReader("hablar,to speak\ncomer,to eat\n"),
+		&entity.CardImportReq{
+			DuplicateReq: entity.DuplicateReq{
+				Duplicates:     entity.DuplicateScopeDeck,
+				SkipDuplicates: true,
+			},
+			Format: entity.FormatCSV,
+			DryRun: true,
+		},
+	)
+
+	assert.Nil(t, err)
+	assert.Len(t, res.Cards, 2)
+	assert.True(t, res.Cards[0].Skipped)
+	assert.True(t, res.Cards[0].Duplicate.Exact)
+	assert.False(t, res.Cards[1].Skipped)
+	assert.Nil(t, res.Cards[1].Duplicate)
+}
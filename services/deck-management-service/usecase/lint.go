@@ -0,0 +1,317 @@
+package usecase
+
+import (
+	"fmt"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/moshrank/spacey-backend/services/deck-management-service/entity"
+)
+
+const (
+	// maxAnswerWords is the length of an answer that is still easy to
+	// recall as a whole.
+	maxAnswerWords = 40
+	// maxListItems is the number of list items an answer can have before
+	// its facts should be learned on separate cards.
+	maxListItems = 2
+	// minContainedAnswerLength keeps short answers like "no" from being
+	// found in their question by chance.
+	minContainedAnswerLength = 3
+	// maxClozeShare is the share of the text a single cloze card may hide.
+	maxClozeShare = 0.5
+)
+
+// listItem matches the lines of bullet and numbered lists.
+var listItem = regexp.MustCompile(`(?m)^\s*(?:[-*+]|\d+[.)])\s+\S`)
+
+type LintUseCase struct {
+	deckStore     entity.DeckStoreInterface
+	cardStore     entity.CardStoreInterface
+	activityStore entity.ActivityStoreInterface
+}
+
+func NewLintUseCase(
+	deckStore entity.DeckStoreInterface,
+	cardStore entity.CardStoreInterface,
+	activityStore entity.ActivityStoreInterface,
+) entity.LintUseCaseInterface {
+	return &LintUseCase{
+		deckStore:     deckStore,
+		cardStore:     cardStore,
+		activityStore: activityStore,
+	}
+}
+
+// enabledLintRules returns the lint rules that apply to the deck.
+func enabledLintRules(deck *entity.Deck) []string {
+	rules := []string{}
+	for _, rule := range entity.LintRules {
+		if !slices.Contains(deck.DisabledLintRules, rule) {
+			rules = append(rules, rule)
+		}
+	}
+
+	return rules
+}
+
+// hasQuestionAndAnswer reports whether the question and answer of cards of
+// the type are written by the user, instead of generated from a note.
+func hasQuestionAndAnswer(card *entity.Card) bool {
+	return card.Cloze == nil && card.Occlusion == nil && card.Custom == nil
+}
+
+func isBlank(text string) bool {
+	return normalizeText(text) == ""
+}
+
+func lintEmptyField(card *entity.Card) string {
+	switch {
+	case card.Cloze != nil || card.Occlusion != nil:
+		return ""
+	case isBlank(card.Question):
+		return "the question is empty"
+	case card.Custom == nil && isBlank(card.Answer):
+		return "the answer is empty"
+	}
+
+	return ""
+}
+
+func lintAnswerTooLong(card *entity.Card) string {
+	if !hasQuestionAndAnswer(card) {
+		return ""
+	}
+
+	words := len(strings.Fields(card.Answer))
+	if words <= maxAnswerWords {
+		return ""
+	}
+
+	return fmt.Sprintf(
+		"the answer has %d words, answers of up to %d words are easier to recall",
+		words,
+		maxAnswerWords,
+	)
+}
+
+func lintQuestionContainsAnswer(card *entity.Card) string {
+	if !hasQuestionAndAnswer(card) {
+		return ""
+	}
+
+	answer := normalizeText(card.Answer)
+	if utf8.RuneCountInString(answer) < minContainedAnswerLength {
+		return ""
+	}
+
+	// matches whole words only
+	if !strings.Contains(" "+normalizeText(card.Question)+" ", " "+answer+" ") {
+		return ""
+	}
+
+	return "the question contains its answer"
+}
+
+func lintMultipleFacts(card *entity.Card) string {
+	if !hasQuestionAndAnswer(card) {
+		return ""
+	}
+
+	if questions := strings.Count(card.Question, "?"); questions > 1 {
+		return fmt.Sprintf(
+			"the question asks %d questions, split them into separate cards",
+			questions,
+		)
+	}
+
+	if items := len(listItem.FindAllString(card.Answer, -1)); items > maxListItems {
+		return fmt.Sprintf(
+			"the answer lists %d items, split them into separate cards",
+			items,
+		)
+	}
+
+	return ""
+}
+
+// lintUnbalancedCloze checks the cloze text of a note for deletions that
+// are not closed, ordinals that are skipped and cards that hide most of the
+// text.
+func lintUnbalancedCloze(cloze *entity.ClozeNote) string {
+	matches := clozeDeletion.FindAllStringSubmatch(cloze.Text, -1)
+	if strings.Count(cloze.Text, "{{c") != len(matches) {
+		return "a cloze deletion is not closed like {{c1::answer}}"
+	}
+
+	ordinals := clozeOrdinals(cloze.Text)
+	for i, ordinal := range ordinals {
+		if ordinal != i+1 {
+			return fmt.Sprintf("the cloze deletions skip c%d", i+1)
+		}
+	}
+
+	hidden := map[int]int{}
+	for _, match := range matches {
+		ordinal, _ := strconv.Atoi(match[1])
+		hidden[ordinal] += utf8.RuneCountInString(match[2])
+	}
+
+	text := utf8.RuneCountInString(clozeDeletion.ReplaceAllString(cloze.Text, "$2"))
+	for _, ordinal := range ordinals {
+		if float64(hidden[ordinal]) > maxClozeShare*float64(text) {
+			return fmt.Sprintf("c%d hides more than half of the text", ordinal)
+		}
+	}
+
+	return ""
+}
+
+func lintNearDuplicate(duplicate *entity.DuplicateRes) string {
+	if duplicate.Exact {
+		return "the card has the same question as another card"
+	}
+
+	return fmt.Sprintf(
+		"the card is %.0f%% similar to another card",
+		duplicate.Similarity*100,
+	)
+}
+
+// lintCards checks the cards with the rules. Rules for the note of sibling
+// cards warn about the first card of the note only.
+func lintCards(cards []entity.Card, rules []string) []entity.LintWarningRes {
+	warnings := []entity.LintWarningRes{}
+
+	var index *duplicateIndex
+	if slices.Contains(rules, entity.LintNearDuplicate) {
+		index = newDuplicateIndex()
+	}
+
+	cardRules := map[string]func(*entity.Card) string{
+		entity.LintEmptyField:             lintEmptyField,
+		entity.LintAnswerTooLong:          lintAnswerTooLong,
+		entity.LintQuestionContainsAnswer: lintQuestionContainsAnswer,
+		entity.LintMultipleFacts:          lintMultipleFacts,
+	}
+
+	notes := map[string]bool{}
+	for i := range cards {
+		card := &cards[i]
+		firstOfNote := card.NoteID == "" || !notes[card.NoteID]
+		notes[card.NoteID] = true
+
+		for _, rule := range rules {
+			message := ""
+			relatedCardID := ""
+
+			switch {
+			case cardRules[rule] != nil:
+				message = cardRules[rule](card)
+			case rule == entity.LintUnbalancedCloze && card.Cloze != nil && firstOfNote:
+				message = lintUnbalancedCloze(card.Cloze)
+			case rule == entity.LintNearDuplicate && firstOfNote:
+				if duplicate := index.find(card); duplicate != nil {
+					message = lintNearDuplicate(duplicate)
+					relatedCardID = duplicate.CardID
+				}
+				index.add(card)
+			}
+
+			if message != "" {
+				warnings = append(warnings, entity.LintWarningRes{
+					CardID:        card.ID,
+					Rule:          rule,
+					Message:       message,
+					RelatedCardID: relatedCardID,
+				})
+			}
+		}
+	}
+
+	return warnings
+}
+
+// LintDeck checks the cards of the deck with the lint rules that apply to
+// it.
+func (u *LintUseCase) LintDeck(userID, deckID string) (*entity.LintRes, error) {
+	deck, err := authorizeDeck(u.deckStore, userID, deckID, entity.RoleViewer)
+	if err != nil {
+		return nil, err
+	}
+
+	cards, _, err := u.cardStore.FindByDeckID(deckID, &entity.Page{})
+	if err != nil {
+		return nil, err
+	}
+
+	rules := enabledLintRules(deck)
+
+	return &entity.LintRes{
+		Cards:    len(cards),
+		Rules:    rules,
+		Warnings: lintCards(cards, rules),
+	}, nil
+}
+
+func lintSettingsRes(deck *entity.Deck) *entity.LintSettingsRes {
+	disabledRules := deck.DisabledLintRules
+	if disabledRules == nil {
+		disabledRules = []string{}
+	}
+
+	return &entity.LintSettingsRes{
+		Rules:         enabledLintRules(deck),
+		DisabledRules: disabledRules,
+	}
+}
+
+func (u *LintUseCase) GetLintSettings(userID, deckID string) (*entity.LintSettingsRes, error) {
+	deck, err := authorizeDeck(u.deckStore, userID, deckID, entity.RoleViewer)
+	if err != nil {
+		return nil, err
+	}
+
+	return lintSettingsRes(deck), nil
+}
+
+// UpdateLintSettings sets the lint rules that do not apply to the deck.
+func (u *LintUseCase) UpdateLintSettings(
+	userID, deckID string,
+	req *entity.LintSettingsReq,
+) (*entity.LintSettingsRes, error) {
+	deck, err := authorizeDeck(u.deckStore, userID, deckID, entity.RoleEditor)
+	if err != nil {
+		return nil, err
+	}
+
+	disabledRules := []string{}
+	for _, rule := range entity.LintRules {
+		if slices.Contains(req.DisabledRules, rule) {
+			disabledRules = append(disabledRules, rule)
+		}
+	}
+
+	if err := u.deckStore.UpdateLintRules(deckID, disabledRules); err != nil {
+		return nil, err
+	}
+
+	activity := newActivity(userID, deckID, entity.ActivityLintSettings, nil, time.Now())
+	activity.Changes = addChange(
+		nil,
+		"disabledRules",
+		strings.Join(deck.DisabledLintRules, ", "),
+		strings.Join(disabledRules, ", "),
+	)
+	if err := u.activityStore.Save(&activity); err != nil {
+		return nil, err
+	}
+
+	deck.DisabledLintRules = disabledRules
+
+	return lintSettingsRes(deck), nil
+}
@@ -0,0 +1,198 @@
+package usecase
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/moshrank/spacey-backend/services/deck-management-service/entity"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestLintCards(t *testing.T) {
+	tests := []struct {
+		testName  string
+		cards     []entity.Card
+		wantRules []string
+	}{
+		{
+			"Good Card",
+			[]entity.Card{{ID: "1", Question: "Capital of France?", Answer: "Paris"}},
+			[]string{},
+		},
+		{
+			"Empty Answer",
+			[]entity.Card{{ID: "1", Question: "Capital of France?", Answer: " ** "}},
+			[]string{entity.LintEmptyField},
+		},
+		{
+			"Long Answer",
+			[]entity.Card{
+				{ID: "1", Question: "Why?", Answer: strings.Repeat("word ", maxAnswerWords+1)},
+			},
+			[]string{entity.LintAnswerTooLong},
+		},
+		{
+			"Question Contains Answer",
+			[]entity.Card{{ID: "1", Question: "Is Paris the capital of France?", Answer: "Paris"}},
+			[]string{entity.LintQuestionContainsAnswer},
+		},
+		{
+			"Answer Only Part Of A Word",
+			[]entity.Card{{ID: "1", Question: "Which city is Parisian?", Answer: "Paris"}},
+			[]string{},
+		},
+		{
+			"Several Questions",
+			[]entity.Card{{ID: "1", Question: "Capital of France? And Spain?", Answer: "Paris"}},
+			[]string{entity.LintMultipleFacts},
+		},
+		{
+			"Answer Lists Facts",
+			[]entity.Card{{ID: "1", Question: "Name the organs", Answer: "- Heart\n- Lung\n- Liver"}},
+			[]string{entity.LintMultipleFacts},
+		},
+		{
+			"Skipped Cloze Ordinal",
+			[]entity.Card{
+				{
+					ID:     "1",
+					NoteID: "note",
+					Cloze:  &entity.ClozeNote{Text: "{{c1::Paris}} is in {{c3::France}} and Europe"},
+				},
+				{
+					ID:     "2",
+					NoteID: "note",
+					Cloze:  &entity.ClozeNote{Text: "{{c1::Paris}} is in {{c3::France}} and Europe"},
+				},
+			},
+			[]string{entity.LintUnbalancedCloze},
+		},
+		{
+			"Cloze Hides Most Of The Text",
+			[]entity.Card{
+				{
+					ID:    "1",
+					Cloze: &entity.ClozeNote{Text: "{{c1::The capital of France is Paris}} indeed"},
+				},
+			},
+			[]string{entity.LintUnbalancedCloze},
+		},
+		{
+			"Unclosed Cloze",
+			[]entity.Card{{ID: "1", Cloze: &entity.ClozeNote{Text: "{{c1::Paris is in France"}}},
+			[]string{entity.LintUnbalancedCloze},
+		},
+		{
+			"Near Duplicate",
+			[]entity.Card{
+				{ID: "1", Question: "What is the capital of France?", Answer: "Paris"},
+				{ID: "2", Question: "What is the capital of France", Answer: "It is Paris"},
+			},
+			[]string{entity.LintNearDuplicate},
+		},
+		{
+			"Siblings Are No Duplicates",
+			[]entity.Card{
+				{ID: "1", NoteID: "note", Cloze: &entity.ClozeNote{Text: "{{c1::Paris}} is in {{c2::France}}"}},
+				{ID: "2", NoteID: "note", Cloze: &entity.ClozeNote{Text: "{{c1::Paris}} is in {{c2::France}}"}},
+			},
+			[]string{},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.testName, func(t *testing.T) {
+			warnings := lintCards(test.cards, entity.LintRules)
+
+			rules := []string{}
+			for _, warning := range warnings {
+				rules = append(rules, warning.Rule)
+			}
+
+			assert.Equal(t, test.wantRules, rules)
+		})
+	}
+}
+
+func TestLintDeck(t *testing.T) {
+	deckStoreMock := new(DeckStoreMock)
+	deckStoreMock.On("FindByID", "1", "test_deck_id").Return(&entity.Deck{
+		ID:                "test_deck_id",
+		UserID:            "1",
+		DisabledLintRules: []string{entity.LintQuestionContainsAnswer},
+	}, nil)
+
+	cardStoreMock := new(CardStoreMock)
+	cardStoreMock.On("FindByDeckID", "test_deck_id", &entity.Page{}).Return([]entity.Card{
+		{ID: "1", Question: "Is Paris the capital of France?", Answer: "Paris"},
+		{ID: "2", Question: "Is Paris the capital of France?", Answer: ""},
+	}, "", nil)
+
+	lintUseCase := NewLintUseCase(deckStoreMock, cardStoreMock, newActivityStoreMock())
+
+	lintRes, err := lintUseCase.LintDeck("1", "test_deck_id")
+
+	assert.Nil(t, err)
+	assert.Equal(t, 2, lintRes.Cards)
+	assert.NotContains(t, lintRes.Rules, entity.LintQuestionContainsAnswer)
+	assert.Equal(t, []entity.LintWarningRes{
+		{CardID: "2", Rule: entity.LintEmptyField, Message: "the answer is empty"},
+		{
+			CardID:        "2",
+			Rule:          entity.LintNearDuplicate,
+			Message:       "the card has the same question as another card",
+			RelatedCardID: "1",
+		},
+	}, lintRes.Warnings)
+}
+
+func TestUpdateLintSettings(t *testing.T) {
+	deckStoreMock := newOwnedDeckStoreMock()
+	deckStoreMock.On(
+		"UpdateLintRules",
+		"test_deck_id",
+		[]string{entity.LintAnswerTooLong, entity.LintNearDuplicate},
+	).Return(nil)
+
+	activityStoreMock := newActivityStoreMock()
+
+	lintUseCase := NewLintUseCase(deckStoreMock, new(CardStoreMock), activityStoreMock)
+
+	settings, err := lintUseCase.UpdateLintSettings("1", "test_deck_id", &entity.LintSettingsReq{
+		DisabledRules: []string{
+			entity.LintNearDuplicate,
+			entity.LintAnswerTooLong,
+			entity.LintNearDuplicate,
+		},
+	})
+
+	assert.Nil(t, err)
+	assert.Equal(t, &entity.LintSettingsRes{
+		Rules: []string{
+			entity.LintEmptyField,
+			entity.LintQuestionContainsAnswer,
+			entity.LintMultipleFacts,
+			entity.LintUnbalancedCloze,
+		},
+		DisabledRules: []string{entity.LintAnswerTooLong, entity.LintNearDuplicate},
+	}, settings)
+	activityStoreMock.AssertCalled(t, "Save", mock.MatchedBy(func(activity *entity.Activity) bool {
+		return activity.Action == entity.ActivityLintSettings
+	}))
+}
+
+func TestUpdateLintSettingsAsViewer(t *testing.T) {
+	deckStoreMock := new(DeckStoreMock)
+	deckStoreMock.On("FindByID", "2", "test_deck_id").
+		Return(&entity.Deck{ID: "test_deck_id", UserID: "1", Members: []entity.DeckMember{
+			{UserID: "2", Role: entity.RoleViewer, Accepted: true},
+		}}, nil)
+
+	lintUseCase := NewLintUseCase(deckStoreMock, new(CardStoreMock), newActivityStoreMock())
+
+	_, err := lintUseCase.UpdateLintSettings("2", "test_deck_id", &entity.LintSettingsReq{})
+
+	assert.Equal(t, entity.ErrForbidden, err)
+	deckStoreMock.AssertNotCalled(t, "UpdateLintRules", mock.Anything, mock.Anything)
+}
@@ -0,0 +1,241 @@
+package usecase
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/hex"
+	"io"
+	"net/http"
+	"path/filepath"
+	"time"
+
+	"github.com/moshrank/spacey-backend/config"
+	"github.com/moshrank/spacey-backend/services/deck-management-service/entity"
+)
+
+// mediaGracePeriod keeps fresh uploads from being collected before the card
+// referencing them is saved.
+const mediaGracePeriod = 24 * time.Hour
+
+// mediaTypes maps the detected content types of supported media files to the
+// content type they are served with.
+var mediaTypes = map[string]string{
+	"image/png":       "image/png",
+	"image/jpeg":      "image/jpeg",
+	"image/gif":       "image/gif",
+	"image/webp":      "image/webp",
+	"audio/mpeg":      "audio/mpeg",
+	"application/ogg": "audio/ogg",
+	"audio/wave":      "audio/wav",
+}
+
+type MediaUseCase struct {
+	deckStore  entity.DeckStoreInterface
+	mediaStore entity.MediaStoreInterface
+	blobStore  entity.BlobStoreInterface
+	maxSize    int64
+}
+
+func NewMediaUseCase(
+	deckStore entity.DeckStoreInterface,
+	mediaStore entity.MediaStoreInterface,
+	blobStore entity.BlobStoreInterface,
+	cfg config.ConfigInterface,
+) entity.MediaUseCaseInterface {
+	return &MediaUseCase{
+		deckStore:  deckStore,
+		mediaStore: mediaStore,
+		blobStore:  blobStore,
+		maxSize:    int64(cfg.GetMaxMediaSizeMB()) << 20,
+	}
+}
+
+// newMediaKey returns a random key for the blob of a media file.
+func newMediaKey() (string, error) {
+	key := make([]byte, 16)
+	if _, err := rand.Read(key); err != nil {
+		return "", err
+	}
+
+	return hex.EncodeToString(key), nil
+}
+
+// checkMediaRefs makes sure the cards written to the deck only reference
+// media files of the deck or of decks the user can view. Media ids can be
+// guessed, so cards could otherwise be used to read the media files of other
+// users. References the cards had before are kept, e.g. after the cards were
+// copied from a deck the user can no longer view.
+func checkMediaRefs(
+	mediaStore entity.MediaStoreInterface,
+	deckStore entity.DeckStoreInterface,
+	userID, deckID string,
+	cards []entity.Card,
+	previous []entity.Card,
+) error {
+	known := map[string]bool{}
+	for i := range previous {
+		for _, ref := range previous[i].MediaRefs() {
+			known[ref] = true
+		}
+	}
+
+	viewable := map[string]bool{deckID: true}
+	for i := range cards {
+		for _, ref := range cards[i].MediaRefs() {
+			if known[ref] {
+				continue
+			}
+
+			media, err := mediaStore.FindByID(ref)
+			if err != nil {
+				return entity.ErrInvalidMediaRef
+			}
+
+			if _, ok := viewable[media.DeckID]; !ok {
+				_, err := authorizeDeck(deckStore, userID, media.DeckID, entity.RoleViewer)
+				viewable[media.DeckID] = err == nil
+			}
+
+			if !viewable[media.DeckID] {
+				return entity.ErrInvalidMediaRef
+			}
+
+			known[ref] = true
+		}
+	}
+
+	return nil
+}
+
+func mediaRes(media *entity.Media) *entity.MediaRes {
+	return &entity.MediaRes{
+		ID:          media.ID,
+		Name:        media.Name,
+		ContentType: media.ContentType,
+		Size:        media.Size,
+		Ref:         entity.MediaScheme + media.ID,
+		CreatedAt:   media.CreatedAt,
+	}
+}
+
+// Upload stores a media file for the deck. The content type is detected from
+// the content itself, so the name given by the client is only kept for
+// display.
+func (u *MediaUseCase) Upload(
+	userID, deckID, name string,
+	content io.Reader,
+) (*entity.MediaRes, error) {
+	deck, err := authorizeDeck(u.deckStore, userID, deckID, entity.RoleEditor)
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := io.ReadAll(io.LimitReader(content, u.maxSize+1))
+	if err != nil {
+		return nil, err
+	}
+
+	if int64(len(data)) > u.maxSize {
+		return nil, entity.ErrMediaTooLarge
+	}
+
+	contentType, ok := mediaTypes[http.DetectContentType(data)]
+	if !ok {
+		return nil, entity.ErrUnsupportedMediaType
+	}
+
+	key, err := newMediaKey()
+	if err != nil {
+		return nil, err
+	}
+
+	if err := u.blobStore.Put(key, bytes.NewReader(data)); err != nil {
+		return nil, err
+	}
+
+	timestamp := time.Now()
+	media := entity.Media{
+		DeckID:      deck.ID,
+		UserID:      userID,
+		Name:        filepath.Base(name),
+		ContentType: contentType,
+		Size:        int64(len(data)),
+		Key:         key,
+		CreatedAt:   &timestamp,
+	}
+
+	media.ID, err = u.mediaStore.Save(&media)
+	if err != nil {
+		u.blobStore.Delete(key)
+		return nil, err
+	}
+
+	return mediaRes(&media), nil
+}
+
+// GetMedia returns a media file of the deck. Files uploaded to another deck
+// are returned as well if a card of the deck references them, e.g. after the
+// card was copied or moved. Cards can only reference files of other decks
+// the user could view when they were written, see checkMediaRefs.
+func (u *MediaUseCase) GetMedia(userID, deckID, mediaID string) (*entity.MediaFile, error) {
+	if _, err := authorizeDeck(u.deckStore, userID, deckID, entity.RoleViewer); err != nil {
+		return nil, err
+	}
+
+	media, err := u.mediaStore.FindByID(mediaID)
+	if err != nil {
+		return nil, entity.ErrMediaNotFound
+	}
+
+	if media.DeckID != deckID {
+		referenced, err := u.mediaStore.IsReferenced(deckID, mediaID)
+		if err != nil {
+			return nil, err
+		}
+
+		if !referenced {
+			return nil, entity.ErrMediaNotFound
+		}
+	}
+
+	content, err := u.blobStore.Get(media.Key)
+	if err != nil {
+		return nil, entity.ErrMediaNotFound
+	}
+
+	return &entity.MediaFile{Media: *media, Content: content}, nil
+}
+
+// CollectGarbage removes the media files no card references anymore. Cards
+// in the trash still count, so the files are collected once the cards are
+// purged.
+func (u *MediaUseCase) CollectGarbage() (int, error) {
+	candidates, err := u.mediaStore.FindCreatedBefore(time.Now().Add(-mediaGracePeriod))
+	if err != nil {
+		return 0, err
+	}
+
+	collected := 0
+	for _, media := range candidates {
+		references, err := u.mediaStore.CountReferences(media.ID)
+		if err != nil {
+			return collected, err
+		}
+
+		if references > 0 {
+			continue
+		}
+
+		if err := u.blobStore.Delete(media.Key); err != nil {
+			return collected, err
+		}
+
+		if err := u.mediaStore.Delete(media.ID); err != nil {
+			return collected, err
+		}
+
+		collected++
+	}
+
+	return collected, nil
+}
@@ -0,0 +1,392 @@
+package usecase
+
+import (
+	"bytes"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/moshrank/spacey-backend/config"
+	"github.com/moshrank/spacey-backend/services/deck-management-service/entity"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+var mediaConfig = &config.Config{MaxMediaSizeMB: 1}
+
+var pngHeader = []byte("\x89PNG\x0D\x0A\x1A\x0A")
+
+type MediaStoreMock struct {
+	mock.Mock
+}
+
+func (s *MediaStoreMock) Save(media *entity.Media) (string, error) {
+	args := s.Called(media)
+	return args.String(0), args.Error(1)
+}
+
+func (s *MediaStoreMock) FindByID(mediaID string) (*entity.Media, error) {
+	args := s.Called(mediaID)
+	return args.Get(0).(*entity.Media), args.Error(1)
+}
+
+func (s *MediaStoreMock) FindCreatedBefore(createdBefore time.Time) ([]entity.Media, error) {
+	args := s.Called(createdBefore)
+	return args.Get(0).([]entity.Media), args.Error(1)
+}
+
+func (s *MediaStoreMock) CountReferences(mediaID string) (int64, error) {
+	args := s.Called(mediaID)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+func (s *MediaStoreMock) IsReferenced(deckID, mediaID string) (bool, error) {
+	args := s.Called(deckID, mediaID)
+	return args.Bool(0), args.Error(1)
+}
+
+func (s *MediaStoreMock) Delete(mediaID string) error {
+	args := s.Called(mediaID)
+	return args.Error(0)
+}
+
+type BlobStoreMock struct {
+	mock.Mock
+}
+
+func (s *BlobStoreMock) Put(key string, content io.Reader) error {
+	args := s.Called(key, content)
+	return args.Error(0)
+}
+
+func (s *BlobStoreMock) Get(key string) (io.ReadCloser, error) {
+	args := s.Called(key)
+	return args.Get(0).(io.ReadCloser), args.Error(1)
+}
+
+func (s *BlobStoreMock) Delete(key string) error {
+	args := s.Called(key)
+	return args.Error(0)
+}
+
+func TestUploadMedia(t *testing.T) {
+	mediaStoreMock := new(MediaStoreMock)
+	mediaStoreMock.On("Save", mock.MatchedBy(func(media *entity.Media) bool {
+		return media.DeckID == "test_deck_id" && media.Name == "map.png" &&
+			media.ContentType == "image/png" && media.Size == int64(len(pngHeader))
+	})).Return("61f0c1e5d3b7a0c3f8a1b2c4", nil)
+
+	blobStoreMock := new(BlobStoreMock)
+	blobStoreMock.On("Put", mock.Anything, mock.Anything).Return(nil)
+
+	mediaUseCase := NewMediaUseCase(
+		newOwnedDeckStoreMock(),
+		mediaStoreMock,
+		blobStoreMock,
+		mediaConfig,
+	)
+
+	res, err := mediaUseCase.Upload("1", "test_deck_id", "../map.png", bytes.NewReader(pngHeader))
+
+	assert.Nil(t, err)
+	assert.Equal(t, "media:61f0c1e5d3b7a0c3f8a1b2c4", res.Ref)
+	assert.Equal(t, "image/png", res.ContentType)
+	mediaStoreMock.AssertExpectations(t)
+}
+
+func TestUploadMediaInvalid(t *testing.T) {
+	tests := []struct {
+		testName string
+		content  io.Reader
+		wantErr  error
+	}{
+		{
+			"Unsupported Type",
+			strings.NewReader("<html><script>alert(1)</script></html>"),
+			entity.ErrUnsupportedMediaType,
+		},
+		{
+			"Too Large",
+			io.MultiReader(bytes.NewReader(pngHeader), bytes.NewReader(make([]byte, 1<<20))),
+			entity.ErrMediaTooLarge,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.testName, func(t *testing.T) {
+			mediaStoreMock := new(MediaStoreMock)
+			blobStoreMock := new(BlobStoreMock)
+
+			mediaUseCase := NewMediaUseCase(
+				newOwnedDeckStoreMock(),
+				mediaStoreMock,
+				blobStoreMock,
+				mediaConfig,
+			)
+
+			_, err := mediaUseCase.Upload("1", "test_deck_id", "file", test.content)
+
+			assert.Equal(t, test.wantErr, err)
+			blobStoreMock.AssertNotCalled(t, "Put", mock.Anything, mock.Anything)
+		})
+	}
+}
+
+func TestUploadMediaDeletesBlobOnError(t *testing.T) {
+	mediaStoreMock := new(MediaStoreMock)
+	mediaStoreMock.On("Save", mock.Anything).Return("", assert.AnError)
+
+	blobStoreMock := new(BlobStoreMock)
+	blobStoreMock.On("Put", mock.Anything, mock.Anything).Return(nil)
+	blobStoreMock.On("Delete", mock.Anything).Return(nil)
+
+	mediaUseCase := NewMediaUseCase(
+		newOwnedDeckStoreMock(),
+		mediaStoreMock,
+		blobStoreMock,
+		mediaConfig,
+	)
+
+	_, err := mediaUseCase.Upload("1", "test_deck_id", "map.png", bytes.NewReader(pngHeader))
+
+	assert.Equal(t, assert.AnError, err)
+	blobStoreMock.AssertCalled(t, "Delete", mock.Anything)
+}
+
+func TestGetMedia(t *testing.T) {
+	tests := []struct {
+		testName   string
+		mediaID    string
+		referenced bool
+		wantErr    error
+	}{
+		{
+			"Uploaded To Deck",
+			"own_media",
+			false,
+			nil,
+		},
+		{
+			"Referenced By Deck",
+			"other_media",
+			true,
+			nil,
+		},
+		{
+			"Not Referenced By Deck",
+			"other_media",
+			false,
+			entity.ErrMediaNotFound,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.testName, func(t *testing.T) {
+			mediaStoreMock := new(MediaStoreMock)
+			mediaStoreMock.On("FindByID", "own_media").
+				Return(&entity.Media{ID: "own_media", DeckID: "test_deck_id", Key: "key"}, nil)
+			mediaStoreMock.On("FindByID", "other_media").
+				Return(&entity.Media{ID: "other_media", DeckID: "other_deck", Key: "key"}, nil)
+			mediaStoreMock.On("IsReferenced", "test_deck_id", "other_media").
+				Return(test.referenced, nil)
+
+			blobStoreMock := new(BlobStoreMock)
+			blobStoreMock.On("Get", "key").Return(io.NopCloser(bytes.NewReader(pngHeader)), nil)
+
+			mediaUseCase := NewMediaUseCase(
+				newOwnedDeckStoreMock(),
+				mediaStoreMock,
+				blobStoreMock,
+				mediaConfig,
+			)
+
+			file, err := mediaUseCase.GetMedia("1", "test_deck_id", test.mediaID)
+
+			assert.Equal(t, test.wantErr, err)
+			if test.wantErr == nil {
+				assert.Equal(t, test.mediaID, file.Media.ID)
+			}
+		})
+	}
+}
+
+func TestCheckMediaRefs(t *testing.T) {
+	const (
+		ownMedia     = "61f0c1e5d3b7a0c3f8a1b2c1"
+		sharedMedia  = "61f0c1e5d3b7a0c3f8a1b2c2"
+		foreignMedia = "61f0c1e5d3b7a0c3f8a1b2c3"
+		unknownMedia = "61f0c1e5d3b7a0c3f8a1b2c4"
+	)
+
+	tests := []struct {
+		testName string
+		answer   string
+		previous []entity.Card
+		wantErr  error
+	}{
+		{"No Media", "Paris", nil, nil},
+		{"Media Of The Deck", "![map](media:" + ownMedia + ")", nil, nil},
+		{"Media Of A Deck The User Can View", "![map](media:" + sharedMedia + ")", nil, nil},
+		{
+			"Media Of Another User",
+			"![map](media:" + foreignMedia + ")",
+			nil,
+			entity.ErrInvalidMediaRef,
+		},
+		{"Unknown Media", "![map](media:" + unknownMedia + ")", nil, entity.ErrInvalidMediaRef},
+		{
+			"Kept From Before",
+			"![map](media:" + foreignMedia + ")",
+			[]entity.Card{{Answer: "media:" + foreignMedia}},
+			nil,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.testName, func(t *testing.T) {
+			mediaStoreMock := new(MediaStoreMock)
+			mediaStoreMock.On("FindByID", ownMedia).
+				Return(&entity.Media{ID: ownMedia, DeckID: "test_deck_id"}, nil)
+			mediaStoreMock.On("FindByID", sharedMedia).
+				Return(&entity.Media{ID: sharedMedia, DeckID: "shared_deck_id"}, nil)
+			mediaStoreMock.On("FindByID", foreignMedia).
+				Return(&entity.Media{ID: foreignMedia, DeckID: "foreign_deck_id"}, nil)
+			mediaStoreMock.On("FindByID", unknownMedia).
+				Return((*entity.Media)(nil), entity.ErrMediaNotFound)
+
+			deckStoreMock := new(DeckStoreMock)
+			deckStoreMock.On("FindByID", "1", "shared_deck_id").Return(&entity.Deck{
+				ID:     "shared_deck_id",
+				UserID: "2",
+				Members: []entity.DeckMember{
+					{UserID: "1", Role: entity.RoleViewer, Accepted: true},
+				},
+			}, nil)
+			deckStoreMock.On("FindByID", "1", "foreign_deck_id").
+				Return((*entity.Deck)(nil), entity.ErrDeckNotFound)
+
+			err := checkMediaRefs(
+				mediaStoreMock,
+				deckStoreMock,
+				"1",
+				"test_deck_id",
+				[]entity.Card{{Question: "Where is Paris?", Answer: test.answer}},
+				test.previous,
+			)
+
+			assert.Equal(t, test.wantErr, err)
+		})
+	}
+}
+
+func TestCreateCardForeignMedia(t *testing.T) {
+	mediaStoreMock := new(MediaStoreMock)
+	mediaStoreMock.On("FindByID", "61f0c1e5d3b7a0c3f8a1b2c3").
+		Return(&entity.Media{ID: "61f0c1e5d3b7a0c3f8a1b2c3", DeckID: "foreign_deck_id"}, nil)
+
+	deckStoreMock := new(DeckStoreMock)
+	deckStoreMock.On("FindByID", "1", "test_deck_id").
+		Return(&entity.Deck{ID: "test_deck_id", UserID: "1"}, nil)
+	deckStoreMock.On("FindByID", "1", "foreign_deck_id").
+		Return((*entity.Deck)(nil), entity.ErrDeckNotFound)
+
+	cardStoreMock := new(CardStoreMock)
+
+	cardUseCase := NewCardUseCase(
+		cardStoreMock,
+		deckStoreMock,
+		newUndoStoreMock(),
+		new(NoteTypeStoreMock),
+		newActivityStoreMock(),
+		mediaStoreMock,
+	)
+
+	_, err := cardUseCase.CreateCard("test_deck_id", "1", &entity.CardReq{
+		Question: "Where is Paris?",
+		Answer:   "![map](media:61f0c1e5d3b7a0c3f8a1b2c3)",
+	}, nil)
+
+	assert.Equal(t, entity.ErrInvalidMediaRef, err)
+	cardStoreMock.AssertNotCalled(t, "SaveCard", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestCollectGarbage(t *testing.T) {
+	mediaStoreMock := new(MediaStoreMock)
+	mediaStoreMock.On("FindCreatedBefore", mock.Anything).Return([]entity.Media{
+		{ID: "used_media", Key: "used_key"},
+		{ID: "unused_media", Key: "unused_key"},
+	}, nil)
+	mediaStoreMock.On("CountReferences", "used_media").Return(int64(2), nil)
+	mediaStoreMock.On("CountReferences", "unused_media").Return(int64(0), nil)
+	mediaStoreMock.On("Delete", "unused_media").Return(nil)
+
+	blobStoreMock := new(BlobStoreMock)
+	blobStoreMock.On("Delete", "unused_key").Return(nil)
+
+	mediaUseCase := NewMediaUseCase(
+		new(DeckStoreMock),
+		mediaStoreMock,
+		blobStoreMock,
+		mediaConfig,
+	)
+
+	collected, err := mediaUseCase.CollectGarbage()
+
+	assert.Nil(t, err)
+	assert.Equal(t, 1, collected)
+	mediaStoreMock.AssertNotCalled(t, "Delete", "used_media")
+	blobStoreMock.AssertExpectations(t)
+}
+
+func TestCardMediaRefs(t *testing.T) {
+	card := entity.Card{
+		Question: "Where is this? ![map](media:61f0c1e5d3b7a0c3f8a1b2c4)",
+		Answer:   "[audio](media:61f0c1e5d3b7a0c3f8a1b2c5)",
+		Choices:  []string{"media:61f0c1e5d3b7a0c3f8a1b2c4", "media:not-an-id"},
+	}
+
+	assert.Equal(t, []string{
+		"61f0c1e5d3b7a0c3f8a1b2c4",
+		"61f0c1e5d3b7a0c3f8a1b2c5",
+	}, card.MediaRefs())
+}
+
+func TestNoteMediaRefs(t *testing.T) {
+	tests := []struct {
+		testName string
+		card     entity.Card
+	}{
+		{
+			"Cloze",
+			entity.Card{Cloze: &entity.ClozeNote{
+				Text:  "{{c1::![a](media:61f0c1e5d3b7a0c3f8a1b2c4)}}",
+				Extra: "![b](media:61f0c1e5d3b7a0c3f8a1b2c5)",
+			}},
+		},
+		{
+			"Image Occlusion",
+			entity.Card{Occlusion: &entity.OcclusionNote{
+				Image: "61f0c1e5d3b7a0c3f8a1b2c4",
+				Masks: []entity.OcclusionMask{{Label: "![b](media:61f0c1e5d3b7a0c3f8a1b2c5)"}},
+				Extra: "![a](media:61f0c1e5d3b7a0c3f8a1b2c4)",
+			}},
+		},
+		{
+			"Custom Note",
+			entity.Card{Custom: &entity.CustomNote{Fields: map[string]string{
+				"Word":  "![a](media:61f0c1e5d3b7a0c3f8a1b2c4)",
+				"Audio": "[b](media:61f0c1e5d3b7a0c3f8a1b2c5)",
+			}}},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.testName, func(t *testing.T) {
+			assert.ElementsMatch(t, []string{
+				"61f0c1e5d3b7a0c3f8a1b2c4",
+				"61f0c1e5d3b7a0c3f8a1b2c5",
+			}, test.card.MediaRefs())
+		})
+	}
+}
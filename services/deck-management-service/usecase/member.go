@@ -0,0 +1,174 @@
+package usecase
+
+import (
+	"strings"
+	"time"
+
+	mapper "github.com/PeteProgrammer/go-automapper"
+	"github.com/moshrank/spacey-backend/services/deck-management-service/entity"
+	"github.com/moshrank/spacey-backend/services/deck-management-service/external"
+)
+
+func authorizeDeck(
+	deckStore entity.DeckStoreInterface,
+	userID, deckID, role string,
+) (*entity.Deck, error) {
+	deck, err := deckStore.FindByID(userID, deckID)
+	if err != nil {
+		return nil, entity.ErrDeckNotFound
+	}
+
+	if !entity.HasRole(deck.Role(userID), role) {
+		return nil, entity.ErrForbidden
+	}
+
+	return deck, nil
+}
+
+type MemberUseCase struct {
+	deckStore   entity.DeckStoreInterface
+	userService external.UserServiceInterface
+}
+
+func NewMemberUseCase(
+	deckStore entity.DeckStoreInterface,
+	userService external.UserServiceInterface,
+) entity.MemberUseCaseInterface {
+	return &MemberUseCase{
+		deckStore:   deckStore,
+		userService: userService,
+	}
+}
+
+func (u *MemberUseCase) GetMembers(userID, deckID string) ([]entity.MemberRes, error) {
+	deck, err := authorizeDeck(u.deckStore, userID, deckID, entity.RoleViewer)
+	if err != nil {
+		return nil, err
+	}
+
+	var membersRes []entity.MemberRes
+	mapper.MapLoose(deck.Members, &membersRes)
+
+	return membersRes, nil
+}
+
+func (u *MemberUseCase) InviteMember(
+	userID, deckID string,
+	member *entity.MemberReq,
+) (*entity.MemberRes, error) {
+	deck, err := authorizeDeck(u.deckStore, userID, deckID, entity.RoleOwner)
+	if err != nil {
+		return nil, err
+	}
+
+	email := strings.ToLower(member.Email)
+	user, err := u.userService.FindInvitee(email)
+	if err != nil {
+		return nil, err
+	}
+
+	if user.ID == deck.UserID {
+		return nil, entity.ErrMemberExists
+	}
+	for _, existing := range deck.Members {
+		if existing.UserID == user.ID {
+			return nil, entity.ErrMemberExists
+		}
+	}
+
+	timestamp := time.Now()
+	memberDB := entity.DeckMember{
+		UserID:    user.ID,
+		Email:     user.Email,
+		Role:      member.Role,
+		Accepted:  false,
+		InvitedBy: userID,
+		InvitedAt: &timestamp,
+	}
+
+	if err := u.deckStore.AddMember(deckID, &memberDB); err != nil {
+		return nil, err
+	}
+
+	// the email is only sent once the member is stored, and the member is
+	// removed again if it could not be sent
+	if _, err := u.userService.InviteUser(userID, email, deckID, deck.Name); err != nil {
+		if removeErr := u.deckStore.RemoveMember(deckID, user.ID, time.Now()); removeErr != nil {
+			return nil, removeErr
+		}
+		return nil, err
+	}
+
+	var memberRes entity.MemberRes
+	mapper.MapLoose(&memberDB, &memberRes)
+
+	return &memberRes, nil
+}
+
+func (u *MemberUseCase) UpdateMember(
+	userID, deckID, memberID, role string,
+) (*entity.MemberRes, error) {
+	deck, err := authorizeDeck(u.deckStore, userID, deckID, entity.RoleOwner)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := u.deckStore.UpdateMember(deckID, memberID, role, time.Now()); err != nil {
+		return nil, err
+	}
+
+	for _, member := range deck.Members {
+		if member.UserID == memberID {
+			member.Role = role
+
+			var memberRes entity.MemberRes
+			mapper.MapLoose(&member, &memberRes)
+
+			return &memberRes, nil
+		}
+	}
+
+	return nil, entity.ErrMemberNotFound
+}
+
+// RemoveMember removes the member from the deck. Members are always allowed
+// to leave a deck on their own, which is also how invitations are declined,
+// so the store only has to find them among the members.
+func (u *MemberUseCase) RemoveMember(userID, deckID, memberID string) error {
+	if userID != memberID {
+		if _, err := authorizeDeck(u.deckStore, userID, deckID, entity.RoleOwner); err != nil {
+			return err
+		}
+	}
+
+	return u.deckStore.RemoveMember(deckID, memberID, time.Now())
+}
+
+func (u *MemberUseCase) GetInvitations(userID string) ([]entity.InvitationRes, error) {
+	decks, err := u.deckStore.FindInvitations(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	invitationsRes := []entity.InvitationRes{}
+	for _, deck := range decks {
+		for _, member := range deck.Members {
+			if member.UserID != userID {
+				continue
+			}
+
+			invitationsRes = append(invitationsRes, entity.InvitationRes{
+				DeckID:    deck.ID,
+				DeckName:  deck.Name,
+				Role:      member.Role,
+				InvitedAt: member.InvitedAt,
+			})
+		}
+	}
+
+	return invitationsRes, nil
+}
+
+func (u *MemberUseCase) AcceptInvitation(userID, deckID string) error {
+	return u.deckStore.AcceptMember(deckID, userID, time.Now())
+}
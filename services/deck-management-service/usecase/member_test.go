@@ -0,0 +1,220 @@
+package usecase
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/moshrank/spacey-backend/services/deck-management-service/entity"
+	"github.com/moshrank/spacey-backend/services/deck-management-service/external"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type UserServiceMock struct {
+	mock.Mock
+}
+
+func (m *UserServiceMock) FindInvitee(email string) (*external.User, error) {
+	args := m.Called(email)
+	return args.Get(0).(*external.User), args.Error(1)
+}
+
+func (m *UserServiceMock) InviteUser(
+	inviterID, email, deckID, deckName string,
+) (*external.User, error) {
+	args := m.Called(inviterID, email, deckID, deckName)
+	return args.Get(0).(*external.User), args.Error(1)
+}
+
+func TestRoles(t *testing.T) {
+	deck := entity.Deck{
+		UserID: "owner",
+		Members: []entity.DeckMember{
+			{UserID: "editor", Role: entity.RoleEditor, Accepted: true},
+			{UserID: "invited", Role: entity.RoleOwner, Accepted: false},
+		},
+	}
+
+	assert.Equal(t, entity.RoleOwner, deck.Role("owner"))
+	assert.Equal(t, entity.RoleEditor, deck.Role("editor"))
+	assert.Equal(t, "", deck.Role("invited"))
+	assert.Equal(t, "", deck.Role("stranger"))
+
+	assert.True(t, entity.HasRole(entity.RoleOwner, entity.RoleEditor))
+	assert.True(t, entity.HasRole(entity.RoleEditor, entity.RoleEditor))
+	assert.False(t, entity.HasRole(entity.RoleViewer, entity.RoleEditor))
+	assert.False(t, entity.HasRole("", entity.RoleViewer))
+}
+
+func TestInviteMember(t *testing.T) {
+	deckStoreMock := new(DeckStoreMock)
+	deckStoreMock.On("FindByID", "1", "1").
+		Return(&entity.Deck{ID: "1", UserID: "1", Name: "Test Deck"}, nil)
+	deckStoreMock.On("AddMember", "1", mock.Anything).Return(nil)
+
+	userServiceMock := new(UserServiceMock)
+	userServiceMock.On("FindInvitee", "test@spacey.com").
+		Return(&external.User{ID: "2", Email: "test@spacey.com"}, nil)
+	userServiceMock.On("InviteUser", "1", "test@spacey.com", "1", "Test Deck").
+		Return(&external.User{ID: "2", Email: "test@spacey.com"}, nil).
+		Run(func(mock.Arguments) {
+			// the invitation is only sent once the member is stored
+			deckStoreMock.AssertCalled(t, "AddMember", "1", mock.Anything)
+		})
+
+	memberUseCase := NewMemberUseCase(deckStoreMock, userServiceMock)
+
+	member, err := memberUseCase.InviteMember("1", "1", &entity.MemberReq{
+		Email: "Test@spacey.com",
+		Role:  entity.RoleEditor,
+	})
+
+	assert.Nil(t, err)
+	assert.Equal(t, "2", member.UserID)
+	assert.Equal(t, entity.RoleEditor, member.Role)
+	assert.False(t, member.Accepted)
+	userServiceMock.AssertExpectations(t)
+}
+
+func TestInviteMemberNotInvited(t *testing.T) {
+	tests := []struct {
+		testName  string
+		members   []entity.DeckMember
+		addErr    error
+		inviteErr error
+		wantErr   error
+	}{
+		{
+			"Pending Invitation",
+			[]entity.DeckMember{{UserID: "2", Role: entity.RoleViewer}},
+			nil,
+			nil,
+			entity.ErrMemberExists,
+		},
+		{
+			"Invited Meanwhile",
+			nil,
+			entity.ErrMemberExists,
+			nil,
+			entity.ErrMemberExists,
+		},
+		{
+			"Email Not Sent",
+			nil,
+			nil,
+			errors.New("user service responded with status 500"),
+			errors.New("user service responded with status 500"),
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.testName, func(t *testing.T) {
+			deckStoreMock := new(DeckStoreMock)
+			deckStoreMock.On("FindByID", "1", "1").Return(&entity.Deck{
+				ID:      "1",
+				UserID:  "1",
+				Name:    "Test Deck",
+				Members: test.members,
+			}, nil)
+			deckStoreMock.On("AddMember", "1", mock.Anything).Return(test.addErr)
+			deckStoreMock.On("RemoveMember", "1", "2", mock.Anything).Return(nil)
+
+			userServiceMock := new(UserServiceMock)
+			userServiceMock.On("FindInvitee", "test@spacey.com").
+				Return(&external.User{ID: "2", Email: "test@spacey.com"}, nil)
+			userServiceMock.On("InviteUser", "1", "test@spacey.com", "1", "Test Deck").
+				Return(&external.User{}, test.inviteErr)
+
+			memberUseCase := NewMemberUseCase(deckStoreMock, userServiceMock)
+
+			_, err := memberUseCase.InviteMember("1", "1", &entity.MemberReq{
+				Email: "test@spacey.com",
+				Role:  entity.RoleEditor,
+			})
+
+			assert.Equal(t, test.wantErr, err)
+			if test.inviteErr == nil {
+				userServiceMock.AssertNotCalled(
+					t,
+					"InviteUser",
+					mock.Anything,
+					mock.Anything,
+					mock.Anything,
+					mock.Anything,
+				)
+				return
+			}
+			deckStoreMock.AssertCalled(t, "RemoveMember", "1", "2", mock.Anything)
+		})
+	}
+}
+
+func TestInviteMemberAsEditor(t *testing.T) {
+	deckStoreMock := new(DeckStoreMock)
+	deckStoreMock.On("FindByID", "2", "1").Return(&entity.Deck{
+		ID:     "1",
+		UserID: "1",
+		Members: []entity.DeckMember{
+			{UserID: "2", Role: entity.RoleEditor, Accepted: true},
+		},
+	}, nil)
+
+	memberUseCase := NewMemberUseCase(deckStoreMock, new(UserServiceMock))
+
+	_, err := memberUseCase.InviteMember("2", "1", &entity.MemberReq{
+		Email: "test@spacey.com",
+		Role:  entity.RoleEditor,
+	})
+
+	assert.Equal(t, entity.ErrForbidden, err)
+}
+
+func TestRemoveMemberLeave(t *testing.T) {
+	tests := []struct {
+		testName  string
+		removeErr error
+		wantErr   error
+	}{
+		// the store finds accepted members and pending invitations alike
+		{"Member", nil, nil},
+		{"Not A Member", entity.ErrMemberNotFound, entity.ErrMemberNotFound},
+	}
+
+	for _, test := range tests {
+		t.Run(test.testName, func(t *testing.T) {
+			deckStoreMock := new(DeckStoreMock)
+			deckStoreMock.On("RemoveMember", "1", "2", mock.Anything).Return(test.removeErr)
+
+			memberUseCase := NewMemberUseCase(deckStoreMock, new(UserServiceMock))
+
+			err := memberUseCase.RemoveMember("2", "1", "2")
+
+			assert.Equal(t, test.wantErr, err)
+			deckStoreMock.AssertNotCalled(t, "FindByID", mock.Anything, mock.Anything)
+		})
+	}
+}
+
+func TestGetInvitations(t *testing.T) {
+	deckStoreMock := new(DeckStoreMock)
+	deckStoreMock.On("FindInvitations", "2").Return([]entity.Deck{
+		{
+			ID:     "1",
+			Name:   "Test Deck",
+			UserID: "1",
+			Members: []entity.DeckMember{
+				{UserID: "3", Role: entity.RoleViewer, Accepted: true},
+				{UserID: "2", Role: entity.RoleEditor, Accepted: false},
+			},
+		},
+	}, nil)
+
+	memberUseCase := NewMemberUseCase(deckStoreMock, new(UserServiceMock))
+
+	invitations, err := memberUseCase.GetInvitations("2")
+
+	assert.Nil(t, err)
+	assert.Equal(t, []entity.InvitationRes{
+		{DeckID: "1", DeckName: "Test Deck", Role: entity.RoleEditor},
+	}, invitations)
+}
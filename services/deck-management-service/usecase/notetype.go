@@ -0,0 +1,487 @@
+package usecase
+
+import (
+	"errors"
+	"regexp"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+
+	mapper "github.com/PeteProgrammer/go-automapper"
+	"github.com/moshrank/spacey-backend/services/deck-management-service/entity"
+)
+
+// templateTag matches the tags of a card template, i.e. {{Field}},
+// {{#Field}}, {{^Field}} and {{/Field}}.
+var templateTag = regexp.MustCompile(`{{\s*([#^/]?)\s*([^{}]*?)\s*}}`)
+
+// templateNode is a piece of a parsed template. It is plain text if it has
+// no field, and a section shown depending on whether its field is empty if
+// it has a kind.
+type templateNode struct {
+	text     string
+	field    string
+	kind     string
+	children []templateNode
+}
+
+// parseTemplate parses the template into its text, fields and sections.
+func parseTemplate(template string) ([]templateNode, error) {
+	// the last node collects the nodes of the innermost open section
+	stack := []templateNode{{}}
+	pos := 0
+	for _, match := range templateTag.FindAllStringSubmatchIndex(template, -1) {
+		top := &stack[len(stack)-1]
+		if match[0] > pos {
+			top.children = append(top.children, templateNode{text: template[pos:match[0]]})
+		}
+		pos = match[1]
+
+		kind, field := template[match[2]:match[3]], template[match[4]:match[5]]
+		if field == "" {
+			return nil, entity.ErrInvalidTemplate
+		}
+
+		switch kind {
+		case "#", "^":
+			stack = append(stack, templateNode{field: field, kind: kind})
+		case "/":
+			if len(stack) == 1 || top.field != field {
+				return nil, entity.ErrInvalidTemplate
+			}
+
+			section := *top
+			stack = stack[:len(stack)-1]
+			parent := &stack[len(stack)-1]
+			parent.children = append(parent.children, section)
+		default:
+			top.children = append(top.children, templateNode{field: field})
+		}
+	}
+
+	if len(stack) != 1 {
+		return nil, entity.ErrInvalidTemplate
+	}
+
+	if pos < len(template) {
+		stack[0].children = append(stack[0].children, templateNode{text: template[pos:]})
+	}
+
+	return stack[0].children, nil
+}
+
+// renderTemplate renders the parsed template with the fields of a note. A
+// field counts as empty if it holds nothing but white space.
+func renderTemplate(nodes []templateNode, fields map[string]string, frontSide string) string {
+	var rendered strings.Builder
+	for _, node := range nodes {
+		switch {
+		case node.kind != "":
+			filled := strings.TrimSpace(fields[node.field]) != ""
+			if filled == (node.kind == "#") {
+				rendered.WriteString(renderTemplate(node.children, fields, frontSide))
+			}
+		case node.field == entity.FrontSideField:
+			rendered.WriteString(frontSide)
+		case node.field != "":
+			rendered.WriteString(fields[node.field])
+		default:
+			rendered.WriteString(node.text)
+		}
+	}
+
+	return rendered.String()
+}
+
+// checkTemplateFields checks that the template only uses the given fields
+// and reports whether it uses any. The front side may only be shown on the
+// back.
+func checkTemplateFields(nodes []templateNode, fields []string, back bool) (bool, error) {
+	used := false
+	for _, node := range nodes {
+		switch {
+		case node.field == "":
+			continue
+		case node.field == entity.FrontSideField && node.kind == "" && back:
+			continue
+		case !slices.Contains(fields, node.field):
+			return false, entity.ErrInvalidTemplate
+		}
+
+		used = true
+		if _, err := checkTemplateFields(node.children, fields, back); err != nil {
+			return false, err
+		}
+	}
+
+	return used, nil
+}
+
+// checkNoteType checks the fields and templates of the note type and returns
+// its templates ordered by ordinal. Templates without an ordinal get the
+// next free ones.
+func checkNoteType(noteType *entity.NoteTypeReq) ([]entity.CardTemplate, error) {
+	fields := map[string]bool{}
+	for _, field := range noteType.Fields {
+		if field != strings.TrimSpace(field) || strings.ContainsAny(field, "{}") ||
+			strings.ContainsAny(field[:1], "#^/") || field == entity.FrontSideField ||
+			fields[field] {
+			return nil, entity.ErrInvalidNoteType
+		}
+
+		fields[field] = true
+	}
+
+	ordinals := map[int]bool{}
+	next := 1
+	for _, template := range noteType.Templates {
+		front, err := parseTemplate(template.Front)
+		if err != nil {
+			return nil, err
+		}
+
+		back, err := parseTemplate(template.Back)
+		if err != nil {
+			return nil, err
+		}
+
+		used, err := checkTemplateFields(front, noteType.Fields, false)
+		if err != nil || !used {
+			return nil, entity.ErrInvalidTemplate
+		}
+
+		if _, err := checkTemplateFields(back, noteType.Fields, true); err != nil {
+			return nil, err
+		}
+
+		if template.Ordinal == 0 {
+			continue
+		}
+
+		if ordinals[template.Ordinal] {
+			return nil, entity.ErrInvalidNoteType
+		}
+
+		ordinals[template.Ordinal] = true
+		if template.Ordinal >= next {
+			next = template.Ordinal + 1
+		}
+	}
+
+	templates := []entity.CardTemplate{}
+	for _, template := range noteType.Templates {
+		if template.Ordinal == 0 {
+			template.Ordinal = next
+			next++
+		}
+
+		templates = append(templates, template)
+	}
+
+	sort.Slice(templates, func(i, j int) bool {
+		return templates[i].Ordinal < templates[j].Ordinal
+	})
+
+	return templates, nil
+}
+
+// renderCustom renders the cards of a custom note, one per template. A
+// template renders no card if its front looks the same with all fields of
+// the note left empty, the way Anki decides which cards a note has.
+func renderCustom(
+	base entity.Card,
+	noteType *entity.NoteType,
+	fields map[string]string,
+) ([]entity.Card, error) {
+	for field := range fields {
+		if !slices.Contains(noteType.Fields, field) {
+			return nil, entity.ErrInvalidNote
+		}
+	}
+
+	if fields == nil {
+		fields = map[string]string{}
+	}
+	custom := entity.CustomNote{NoteTypeID: noteType.ID, Fields: fields}
+
+	cards := []entity.Card{}
+	for _, template := range noteType.Templates {
+		front, err := parseTemplate(template.Front)
+		if err != nil {
+			return nil, err
+		}
+
+		back, err := parseTemplate(template.Back)
+		if err != nil {
+			return nil, err
+		}
+
+		question := renderTemplate(front, fields, "")
+		if question == renderTemplate(front, nil, "") {
+			continue
+		}
+
+		card := base
+		card.Ordinal = template.Ordinal
+		card.Custom = &custom
+		card.Question = question
+		card.Answer = renderTemplate(back, fields, question)
+
+		cards = append(cards, card)
+	}
+
+	if len(cards) == 0 {
+		return nil, entity.ErrInvalidNote
+	}
+
+	return cards, nil
+}
+
+// loadNoteTypes loads the note types the custom cards of the requests use.
+// Users may use their own note types and the ones of the deck owner, so all
+// editors of a deck can use the note types of its owner. The note type of
+// an edited card may be used in any case, e.g. in a forked deck.
+func loadNoteTypes(
+	noteTypeStore entity.NoteTypeStoreInterface,
+	userID string,
+	deck *entity.Deck,
+	reqs []entity.CardReq,
+	edited *entity.Card,
+) (map[string]*entity.NoteType, error) {
+	noteTypeIDs := []string{}
+	for _, req := range reqs {
+		if req.Type == entity.CardTypeCustom && !slices.Contains(noteTypeIDs, req.NoteTypeID) {
+			noteTypeIDs = append(noteTypeIDs, req.NoteTypeID)
+		}
+	}
+
+	noteTypes := map[string]*entity.NoteType{}
+	if len(noteTypeIDs) == 0 {
+		return noteTypes, nil
+	}
+
+	found, err := noteTypeStore.FindByIDs(noteTypeIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range found {
+		noteType := &found[i]
+		if noteType.UserID == userID || noteType.UserID == deck.UserID ||
+			edited != nil && edited.Custom != nil && edited.Custom.NoteTypeID == noteType.ID {
+			noteTypes[noteType.ID] = noteType
+		}
+	}
+
+	return noteTypes, nil
+}
+
+type NoteTypeUseCase struct {
+	noteTypeStore entity.NoteTypeStoreInterface
+	cardStore     entity.CardStoreInterface
+}
+
+func NewNoteTypeUseCase(
+	noteTypeStore entity.NoteTypeStoreInterface,
+	cardStore entity.CardStoreInterface,
+) entity.NoteTypeUseCaseInterface {
+	return &NoteTypeUseCase{
+		noteTypeStore: noteTypeStore,
+		cardStore:     cardStore,
+	}
+}
+
+func (u *NoteTypeUseCase) GetNoteTypes(userID string) ([]entity.NoteTypeRes, error) {
+	noteTypes, err := u.noteTypeStore.FindAll(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	noteTypesRes := []entity.NoteTypeRes{}
+	for i := range noteTypes {
+		var noteTypeRes entity.NoteTypeRes
+		mapper.MapLoose(&noteTypes[i], &noteTypeRes)
+		noteTypesRes = append(noteTypesRes, noteTypeRes)
+	}
+
+	return noteTypesRes, nil
+}
+
+// findNoteType returns the note type if it belongs to the user.
+func (u *NoteTypeUseCase) findNoteType(userID, noteTypeID string) (*entity.NoteType, error) {
+	found, err := u.noteTypeStore.FindByIDs([]string{noteTypeID})
+	if err != nil {
+		return nil, err
+	}
+
+	if len(found) == 0 || found[0].UserID != userID {
+		return nil, entity.ErrNoteTypeNotFound
+	}
+
+	return &found[0], nil
+}
+
+func (u *NoteTypeUseCase) GetNoteType(userID, noteTypeID string) (*entity.NoteTypeRes, error) {
+	noteType, err := u.findNoteType(userID, noteTypeID)
+	if err != nil {
+		return nil, err
+	}
+
+	var noteTypeRes entity.NoteTypeRes
+	mapper.MapLoose(noteType, &noteTypeRes)
+
+	return &noteTypeRes, nil
+}
+
+func (u *NoteTypeUseCase) CreateNoteType(
+	userID string,
+	noteType *entity.NoteTypeReq,
+) (*entity.NoteTypeRes, error) {
+	templates, err := checkNoteType(noteType)
+	if err != nil {
+		return nil, err
+	}
+
+	timestamp := time.Now()
+	noteTypeDB := entity.NoteType{
+		UserID:    userID,
+		Name:      noteType.Name,
+		Fields:    noteType.Fields,
+		Templates: templates,
+		CreatedAt: &timestamp,
+		UpdatedAt: &timestamp,
+	}
+
+	noteTypeDB.ID, err = u.noteTypeStore.Save(&noteTypeDB)
+	if err != nil {
+		return nil, err
+	}
+
+	var noteTypeRes entity.NoteTypeRes
+	mapper.MapLoose(&noteTypeDB, &noteTypeRes)
+
+	return &noteTypeRes, nil
+}
+
+// UpdateNoteType changes the note type and renders the cards of all its
+// notes again. Fields removed from the note type are dropped from the notes,
+// cards of new templates are created and the cards of removed templates are
+// moved to the trash. Notes the changed templates render no card for are
+// left as they are.
+func (u *NoteTypeUseCase) UpdateNoteType(
+	userID, noteTypeID string,
+	noteType *entity.NoteTypeReq,
+) (*entity.NoteTypeRes, error) {
+	noteTypeDB, err := u.findNoteType(userID, noteTypeID)
+	if err != nil {
+		return nil, err
+	}
+
+	templates, err := checkNoteType(noteType)
+	if err != nil {
+		return nil, err
+	}
+
+	timestamp := time.Now()
+	noteTypeDB.Name = noteType.Name
+	noteTypeDB.Fields = noteType.Fields
+	noteTypeDB.Templates = templates
+	noteTypeDB.UpdatedAt = &timestamp
+
+	if err := u.noteTypeStore.Update(noteTypeDB); err != nil {
+		return nil, err
+	}
+
+	if err := u.renderNotes(noteTypeDB, timestamp); err != nil {
+		return nil, err
+	}
+
+	var noteTypeRes entity.NoteTypeRes
+	mapper.MapLoose(noteTypeDB, &noteTypeRes)
+
+	return &noteTypeRes, nil
+}
+
+// renderNotes renders the cards of all notes of the note type again.
+func (u *NoteTypeUseCase) renderNotes(noteType *entity.NoteType, timestamp time.Time) error {
+	cards, err := u.cardStore.FindByNoteTypeID(noteType.ID)
+	if err != nil {
+		return err
+	}
+
+	notes := map[string][]entity.Card{}
+	noteKeys := []string{}
+	for _, card := range cards {
+		key := card.DeckID + "/" + card.NoteID
+		if _, ok := notes[key]; !ok {
+			noteKeys = append(noteKeys, key)
+		}
+		notes[key] = append(notes[key], card)
+	}
+
+	noteTypes := map[string]*entity.NoteType{noteType.ID: noteType}
+	for _, key := range noteKeys {
+		note := notes[key]
+
+		fields := map[string]string{}
+		for field, value := range note[0].Custom.Fields {
+			if slices.Contains(noteType.Fields, field) {
+				fields[field] = value
+			}
+		}
+
+		rendered, err := expandCard(&entity.CardReq{
+			Tags:       note[0].Tags,
+			Source:     entity.SourceNoteType,
+			Type:       entity.CardTypeCustom,
+			NoteTypeID: noteType.ID,
+			Fields:     fields,
+		}, noteTypes)
+		if errors.Is(err, entity.ErrInvalidNote) {
+			continue
+		}
+		if err != nil {
+			return err
+		}
+
+		siblings := map[int]entity.Card{}
+		for _, sibling := range note {
+			siblings[sibling.Ordinal] = sibling
+		}
+
+		_, err = saveNote(
+			u.cardStore,
+			note[0].UserID,
+			note[0].DeckID,
+			note[0].NoteID,
+			rendered,
+			siblings,
+			timestamp,
+		)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// DeleteNoteType deletes the note type unless cards still use it, since
+// their notes could not be edited anymore.
+func (u *NoteTypeUseCase) DeleteNoteType(userID, noteTypeID string) error {
+	if _, err := u.findNoteType(userID, noteTypeID); err != nil {
+		return err
+	}
+
+	used, err := u.cardStore.HasNoteType(noteTypeID)
+	if err != nil {
+		return err
+	}
+
+	if used {
+		return entity.ErrNoteTypeInUse
+	}
+
+	return u.noteTypeStore.Delete(userID, noteTypeID)
+}
@@ -0,0 +1,375 @@
+package usecase
+
+import (
+	"testing"
+
+	"github.com/moshrank/spacey-backend/services/deck-management-service/entity"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type NoteTypeStoreMock struct {
+	mock.Mock
+}
+
+func (s *NoteTypeStoreMock) Save(noteType *entity.NoteType) (string, error) {
+	args := s.Called(noteType)
+	return args.String(0), args.Error(1)
+}
+
+func (s *NoteTypeStoreMock) FindAll(userID string) ([]entity.NoteType, error) {
+	args := s.Called(userID)
+	return args.Get(0).([]entity.NoteType), args.Error(1)
+}
+
+func (s *NoteTypeStoreMock) FindByIDs(noteTypeIDs []string) ([]entity.NoteType, error) {
+	args := s.Called(noteTypeIDs)
+	return args.Get(0).([]entity.NoteType), args.Error(1)
+}
+
+func (s *NoteTypeStoreMock) Update(noteType *entity.NoteType) error {
+	args := s.Called(noteType)
+	return args.Error(0)
+}
+
+func (s *NoteTypeStoreMock) Delete(userID, noteTypeID string) error {
+	args := s.Called(userID, noteTypeID)
+	return args.Error(0)
+}
+
+func newVocabularyNoteType() entity.NoteType {
+	return entity.NoteType{
+		ID:     "vocabulary",
+		UserID: "1",
+		Name:   "Vocabulary",
+		Fields: []string{"Word", "Reading", "Meaning", "Example"},
+		Templates: []entity.CardTemplate{
+			{
+				Ordinal: 1,
+				Name:    "Recognition",
+				Front:   "{{Word}}",
+				Back:    "{{FrontSide}}<hr>{{Meaning}}{{#Example}}<br>{{Example}}{{/Example}}",
+			},
+			{
+				Ordinal: 2,
+				Name:    "Recall",
+				Front:   "{{Meaning}}",
+				Back:    "{{Word}}{{^Reading}} (no reading){{/Reading}}",
+			},
+			{Ordinal: 3, Name: "Listening", Front: "{{Reading}}", Back: "{{Word}}"},
+		},
+	}
+}
+
+func TestCheckNoteType(t *testing.T) {
+	tests := []struct {
+		testName  string
+		fields    []string
+		templates []entity.CardTemplate
+		wantErr   error
+	}{
+		{
+			"Valid",
+			[]string{"Word", "Meaning"},
+			[]entity.CardTemplate{
+				{Name: "Recall", Front: "{{ Meaning }}", Back: "{{FrontSide}} {{Word}}"},
+				{Ordinal: 1, Name: "Recognition", Front: "{{Word}}", Back: "{{Meaning}}"},
+			},
+			nil,
+		},
+		{
+			"Duplicate Field",
+			[]string{"Word", "Word"},
+			[]entity.CardTemplate{{Name: "Card", Front: "{{Word}}", Back: "{{Word}}"}},
+			entity.ErrInvalidNoteType,
+		},
+		{
+			"Field Named Like A Section",
+			[]string{"#Word"},
+			[]entity.CardTemplate{{Name: "Card", Front: "{{Word}}", Back: "{{Word}}"}},
+			entity.ErrInvalidNoteType,
+		},
+		{
+			"Duplicate Ordinal",
+			[]string{"Word"},
+			[]entity.CardTemplate{
+				{Ordinal: 1, Name: "Card", Front: "{{Word}}", Back: "{{Word}}"},
+				{Ordinal: 1, Name: "Card", Front: "{{Word}}", Back: "{{Word}}"},
+			},
+			entity.ErrInvalidNoteType,
+		},
+		{
+			"Unknown Field",
+			[]string{"Word"},
+			[]entity.CardTemplate{{Name: "Card", Front: "{{Word}}", Back: "{{Meaning}}"}},
+			entity.ErrInvalidTemplate,
+		},
+		{
+			"Unclosed Section",
+			[]string{"Word"},
+			[]entity.CardTemplate{{Name: "Card", Front: "{{#Word}}{{Word}}", Back: "{{Word}}"}},
+			entity.ErrInvalidTemplate,
+		},
+		{
+			"Front Side On Front",
+			[]string{"Word"},
+			[]entity.CardTemplate{{Name: "Card", Front: "{{FrontSide}}", Back: "{{Word}}"}},
+			entity.ErrInvalidTemplate,
+		},
+		{
+			"Front Without Field",
+			[]string{"Word"},
+			[]entity.CardTemplate{{Name: "Card", Front: "Translate", Back: "{{Word}}"}},
+			entity.ErrInvalidTemplate,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.testName, func(t *testing.T) {
+			templates, err := checkNoteType(&entity.NoteTypeReq{
+				Name:      "Vocabulary",
+				Fields:    test.fields,
+				Templates: test.templates,
+			})
+
+			assert.Equal(t, test.wantErr, err)
+			if err == nil {
+				assert.Equal(t, "Recognition", templates[0].Name)
+				assert.Equal(t, 2, templates[1].Ordinal)
+			}
+		})
+	}
+}
+
+func TestExpandCustom(t *testing.T) {
+	noteType := newVocabularyNoteType()
+
+	cards, err := expandCard(&entity.CardReq{
+		Type:       entity.CardTypeCustom,
+		NoteTypeID: "vocabulary",
+		Fields: map[string]string{
+			"Word":    "hablar",
+			"Meaning": "to speak",
+			"Example": "Hablo español.",
+		},
+	}, map[string]*entity.NoteType{"vocabulary": &noteType})
+
+	assert.Nil(t, err)
+	assert.Len(t, cards, 2)
+
+	assert.Equal(t, 1, cards[0].Ordinal)
+	assert.Equal(t, "hablar", cards[0].Question)
+	assert.Equal(t, "hablar<hr>to speak<br>Hablo español.", cards[0].Answer)
+	assert.Equal(t, "vocabulary", cards[0].Custom.NoteTypeID)
+
+	assert.Equal(t, 2, cards[1].Ordinal)
+	assert.Equal(t, "to speak", cards[1].Question)
+	assert.Equal(t, "hablar (no reading)", cards[1].Answer)
+
+	_, err = expandCard(&entity.CardReq{
+		Type:       entity.CardTypeCustom,
+		NoteTypeID: "vocabulary",
+		Fields:     map[string]string{"Word": "hablar", "Plural": "-"},
+	}, map[string]*entity.NoteType{"vocabulary": &noteType})
+
+	assert.Equal(t, entity.ErrInvalidNote, err)
+
+	_, err = expandCard(&entity.CardReq{
+		Type:       entity.CardTypeCustom,
+		NoteTypeID: "vocabulary",
+		Fields:     map[string]string{"Example": "Hablo español."},
+	}, map[string]*entity.NoteType{"vocabulary": &noteType})
+
+	assert.Equal(t, entity.ErrInvalidNote, err)
+
+	_, err = expandCard(&entity.CardReq{
+		Type:       entity.CardTypeCustom,
+		NoteTypeID: "grammar",
+		Fields:     map[string]string{"Word": "hablar"},
+	}, map[string]*entity.NoteType{"vocabulary": &noteType})
+
+	assert.Equal(t, entity.ErrNoteTypeNotFound, err)
+}
+
+func TestCreateCustomCard(t *testing.T) {
+	noteTypeStoreMock := new(NoteTypeStoreMock)
+	noteTypeStoreMock.On("FindByIDs", []string{"vocabulary"}).
+		Return([]entity.NoteType{newVocabularyNoteType()}, nil)
+
+	cardStoreMock := new(CardStoreMock)
+	cardStoreMock.On("SaveCards", "test_deck_id", "1", mock.Anything).
+		Return([]string{"recognition_id", "recall_id"}, nil)
+
+	cardUseCase := NewCardUseCase(
+		cardStoreMock,
+		newOwnedDeckStoreMock(),
+		newUndoStoreMock(),
+		noteTypeStoreMock,
+		newActivityStoreMock(),
+		new(MediaStoreMock),
+	)
+	card, err := cardUseCase.CreateCard("test_deck_id", "1", &entity.CardReq{
+		Type:       entity.CardTypeCustom,
+		NoteTypeID: "vocabulary",
+		Fields:     map[string]string{"Word": "hablar", "Meaning": "to speak"},
+	}, nil)
+
+	assert.Nil(t, err)
+	assert.Equal(t, "recognition_id", card.ID)
+	assert.Equal(t, "hablar", card.Question)
+
+	saved := cardStoreMock.Calls[0].Arguments.Get(2).([]entity.Card)
+	assert.Len(t, saved, 2)
+	assert.NotEmpty(t, saved[0].NoteID)
+	assert.Equal(t, saved[0].NoteID, saved[1].NoteID)
+	assert.Equal(t, "to speak", saved[1].Question)
+}
+
+func TestCreateCustomCardWithForeignNoteType(t *testing.T) {
+	noteType := newVocabularyNoteType()
+	noteType.UserID = "3"
+
+	noteTypeStoreMock := new(NoteTypeStoreMock)
+	noteTypeStoreMock.On("FindByIDs", []string{"vocabulary"}).
+		Return([]entity.NoteType{noteType}, nil)
+
+	cardStoreMock := new(CardStoreMock)
+
+	cardUseCase := NewCardUseCase(
+		cardStoreMock,
+		newOwnedDeckStoreMock(),
+		newUndoStoreMock(),
+		noteTypeStoreMock,
+		newActivityStoreMock(),
+		new(MediaStoreMock),
+	)
+	_, err := cardUseCase.CreateCard("test_deck_id", "1", &entity.CardReq{
+		Type:       entity.CardTypeCustom,
+		NoteTypeID: "vocabulary",
+		Fields:     map[string]string{"Word": "hablar"},
+	}, nil)
+
+	assert.Equal(t, entity.ErrNoteTypeNotFound, err)
+	cardStoreMock.AssertNotCalled(t, "SaveCards", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestUpdateCustomCard(t *testing.T) {
+	custom := &entity.CustomNote{
+		NoteTypeID: "vocabulary",
+		Fields:     map[string]string{"Word": "hablar", "Meaning": "to speak"},
+	}
+
+	noteTypeStoreMock := new(NoteTypeStoreMock)
+	noteTypeStoreMock.On("FindByIDs", []string{"vocabulary"}).
+		Return([]entity.NoteType{newVocabularyNoteType()}, nil)
+
+	cardStoreMock := new(CardStoreMock)
+	cardStoreMock.On("FindByIDs", "test_deck_id", []string{"card_1"}).
+		Return([]entity.Card{
+			{ID: "card_1", Type: "custom", NoteID: "note", Ordinal: 1, Custom: custom, Version: 4},
+		}, nil)
+	cardStoreMock.On("FindByNoteID", "test_deck_id", "note").
+		Return([]entity.Card{
+			{ID: "card_1", Type: "custom", NoteID: "note", Ordinal: 1, Custom: custom, Version: 5},
+			{ID: "card_2", Type: "custom", NoteID: "note", Ordinal: 2, Custom: custom},
+		}, nil)
+	cardStoreMock.On("UpdateCard", "card_1", "1", "test_deck_id", mock.Anything).Return(nil)
+	cardStoreMock.On("SaveCards", "test_deck_id", "1", mock.Anything).
+		Return([]string{"card_3"}, nil)
+	cardStoreMock.On("DeleteCards", "1", "test_deck_id", []string{"card_2"}, mock.Anything).
+		Return(nil)
+
+	cardUseCase := NewCardUseCase(
+		cardStoreMock,
+		newOwnedDeckStoreMock(),
+		newUndoStoreMock(),
+		noteTypeStoreMock,
+		newActivityStoreMock(),
+		new(MediaStoreMock),
+	)
+	card, err := cardUseCase.UpdateCard("card_1", "1", "test_deck_id", &entity.CardReq{
+		Type:       entity.CardTypeCustom,
+		NoteTypeID: "vocabulary",
+		Fields:     map[string]string{"Word": "hablar", "Reading": "a-blar"},
+	}, entity.IfMatch{4})
+
+	assert.Nil(t, err)
+	assert.Equal(t, "card_1", card.ID)
+	assert.Equal(t, 5, card.Version)
+	assert.Equal(t, "a-blar", card.Custom.Fields["Reading"])
+
+	added := cardStoreMock.Calls[3].Arguments.Get(2).([]entity.Card)
+	assert.Len(t, added, 1)
+	assert.Equal(t, 3, added[0].Ordinal)
+	assert.Equal(t, "a-blar", added[0].Question)
+	assert.Equal(t, "note", added[0].NoteID)
+}
+
+func TestUpdateNoteType(t *testing.T) {
+	noteType := newVocabularyNoteType()
+	custom := &entity.CustomNote{
+		NoteTypeID: "vocabulary",
+		Fields:     map[string]string{"Word": "hablar", "Meaning": "to speak"},
+	}
+
+	noteTypeStoreMock := new(NoteTypeStoreMock)
+	noteTypeStoreMock.On("FindByIDs", []string{"vocabulary"}).
+		Return([]entity.NoteType{noteType}, nil)
+	noteTypeStoreMock.On("Update", mock.Anything).Return(nil)
+
+	cardStoreMock := new(CardStoreMock)
+	cardStoreMock.On("FindByNoteTypeID", "vocabulary").Return([]entity.Card{
+		{ID: "card_1", UserID: "2", DeckID: "deck", NoteID: "note", Ordinal: 1, Custom: custom},
+		{ID: "card_2", UserID: "2", DeckID: "deck", NoteID: "note", Ordinal: 2, Custom: custom},
+	}, nil)
+	cardStoreMock.On("UpdateCard", "card_1", "2", "deck", mock.Anything).Return(nil)
+	cardStoreMock.On("DeleteCards", "2", "deck", []string{"card_2"}, mock.Anything).Return(nil)
+
+	noteTypeUseCase := NewNoteTypeUseCase(noteTypeStoreMock, cardStoreMock)
+	res, err := noteTypeUseCase.UpdateNoteType("1", "vocabulary", &entity.NoteTypeReq{
+		Name:   "Vocabulary",
+		Fields: []string{"Word", "Meaning"},
+		Templates: []entity.CardTemplate{
+			{Ordinal: 1, Name: "Recognition", Front: "{{Word}}?", Back: "{{Meaning}}"},
+		},
+	})
+
+	assert.Nil(t, err)
+	assert.Len(t, res.Templates, 1)
+
+	updated := cardStoreMock.Calls[1].Arguments.Get(3).(*entity.Card)
+	assert.Equal(t, "hablar?", updated.Question)
+	assert.Equal(t, "to speak", updated.Answer)
+	assert.Equal(t, entity.SourceNoteType, updated.Source)
+}
+
+func TestUpdateNoteTypeOfOtherUser(t *testing.T) {
+	noteTypeStoreMock := new(NoteTypeStoreMock)
+	noteTypeStoreMock.On("FindByIDs", []string{"vocabulary"}).
+		Return([]entity.NoteType{newVocabularyNoteType()}, nil)
+
+	noteTypeUseCase := NewNoteTypeUseCase(noteTypeStoreMock, new(CardStoreMock))
+	_, err := noteTypeUseCase.UpdateNoteType("2", "vocabulary", &entity.NoteTypeReq{
+		Name:      "Vocabulary",
+		Fields:    []string{"Word"},
+		Templates: []entity.CardTemplate{{Name: "Card", Front: "{{Word}}", Back: "{{Word}}"}},
+	})
+
+	assert.Equal(t, entity.ErrNoteTypeNotFound, err)
+	noteTypeStoreMock.AssertNotCalled(t, "Update", mock.Anything)
+}
+
+func TestDeleteNoteTypeInUse(t *testing.T) {
+	noteTypeStoreMock := new(NoteTypeStoreMock)
+	noteTypeStoreMock.On("FindByIDs", []string{"vocabulary"}).
+		Return([]entity.NoteType{newVocabularyNoteType()}, nil)
+
+	cardStoreMock := new(CardStoreMock)
+	cardStoreMock.On("HasNoteType", "vocabulary").Return(true, nil)
+
+	noteTypeUseCase := NewNoteTypeUseCase(noteTypeStoreMock, cardStoreMock)
+	err := noteTypeUseCase.DeleteNoteType("1", "vocabulary")
+
+	assert.Equal(t, entity.ErrNoteTypeInUse, err)
+	noteTypeStoreMock.AssertNotCalled(t, "Delete", mock.Anything, mock.Anything)
+}
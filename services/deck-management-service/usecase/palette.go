@@ -0,0 +1,91 @@
+package usecase
+
+import (
+	"errors"
+	"slices"
+	"strings"
+	"sync"
+
+	"github.com/moshrank/spacey-backend/services/deck-management-service/entity"
+	"github.com/moshrank/spacey-backend/services/deck-management-service/external"
+)
+
+type Palette struct {
+	configService external.ConfigServiceInterface
+	deckStore     entity.DeckStoreInterface
+	mutex         sync.RWMutex
+	colors        []string
+}
+
+func NewPalette(
+	configService external.ConfigServiceInterface,
+	deckStore entity.DeckStoreInterface,
+) entity.PaletteInterface {
+	return &Palette{
+		configService: configService,
+		deckStore:     deckStore,
+	}
+}
+
+// Refresh loads the colors from the config service and reports whether they
+// changed. The colors loaded before are kept if the config service fails.
+func (p *Palette) Refresh() (bool, error) {
+	colors, err := p.configService.GetColors()
+	if err != nil {
+		return false, err
+	}
+
+	if len(colors) == 0 {
+		return false, errors.New("config service returned no colors")
+	}
+
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	if slices.Equal(p.colors, colors) {
+		return false, nil
+	}
+
+	p.colors = colors
+
+	return true, nil
+}
+
+func (p *Palette) Colors() []string {
+	p.mutex.RLock()
+	defer p.mutex.RUnlock()
+
+	return slices.Clone(p.colors)
+}
+
+// Match returns the color as it is spelled in the palette, ignoring case.
+// Until the palette is loaded, no color can be checked and ErrPaletteNotLoaded
+// is returned.
+func (p *Palette) Match(color string) (string, error) {
+	p.mutex.RLock()
+	defer p.mutex.RUnlock()
+
+	if len(p.colors) == 0 {
+		return "", entity.ErrPaletteNotLoaded
+	}
+
+	for _, paletteColor := range p.colors {
+		if strings.EqualFold(paletteColor, color) {
+			return paletteColor, nil
+		}
+	}
+
+	return "", entity.ErrInvalidColor
+}
+
+// FindInvalidDecks returns the decks outside the trash whose color is not in
+// the palette, for example because it was stored before colors were checked
+// or was removed from the palette since.
+func (p *Palette) FindInvalidDecks() ([]entity.Deck, error) {
+	colors := p.Colors()
+	if len(colors) == 0 {
+		return nil, entity.ErrPaletteNotLoaded
+	}
+
+	return p.deckStore.FindByColorNotIn(colors)
+}
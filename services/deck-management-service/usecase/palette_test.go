@@ -0,0 +1,164 @@
+package usecase
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/moshrank/spacey-backend/services/deck-management-service/entity"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type ConfigServiceMock struct {
+	mock.Mock
+}
+
+func (s *ConfigServiceMock) GetColors() ([]string, error) {
+	args := s.Called()
+	return args.Get(0).([]string), args.Error(1)
+}
+
+var testColors = []string{"#FFEC87", "#BEBDFF"}
+
+func newTestPalette(deckStore entity.DeckStoreInterface) entity.PaletteInterface {
+	configServiceMock := new(ConfigServiceMock)
+	configServiceMock.On("GetColors").Return(testColors, nil)
+
+	palette := NewPalette(configServiceMock, deckStore)
+	palette.Refresh()
+
+	return palette
+}
+
+func TestPaletteRefresh(t *testing.T) {
+	configServiceMock := new(ConfigServiceMock)
+	configServiceMock.On("GetColors").Return(testColors, nil).Twice()
+	configServiceMock.On("GetColors").Return([]string{}, errors.New("unavailable")).Once()
+
+	palette := NewPalette(configServiceMock, new(DeckStoreMock))
+
+	changed, err := palette.Refresh()
+	assert.Nil(t, err)
+	assert.True(t, changed)
+
+	changed, err = palette.Refresh()
+	assert.Nil(t, err)
+	assert.False(t, changed)
+
+	// the colors loaded before stay in use
+	_, err = palette.Refresh()
+	assert.NotNil(t, err)
+	assert.Equal(t, testColors, palette.Colors())
+}
+
+func TestPaletteMatch(t *testing.T) {
+	tests := []struct {
+		testName  string
+		color     string
+		wantColor string
+		wantErr   error
+	}{
+		{"In Palette", "#FFEC87", "#FFEC87", nil},
+		{"Different Case", "#bebdff", "#BEBDFF", nil},
+		{"Not In Palette", "#000000", "", entity.ErrInvalidColor},
+		{"Empty", "", "", entity.ErrInvalidColor},
+	}
+
+	palette := newTestPalette(new(DeckStoreMock))
+
+	for _, test := range tests {
+		t.Run(test.testName, func(t *testing.T) {
+			color, err := palette.Match(test.color)
+
+			assert.Equal(t, test.wantErr, err)
+			assert.Equal(t, test.wantColor, color)
+		})
+	}
+}
+
+func TestPaletteMatchNotLoaded(t *testing.T) {
+	palette := NewPalette(new(ConfigServiceMock), new(DeckStoreMock))
+
+	color, err := palette.Match("#000000")
+
+	assert.Equal(t, entity.ErrPaletteNotLoaded, err)
+	assert.Equal(t, "", color)
+
+	_, err = palette.FindInvalidDecks()
+
+	assert.Equal(t, entity.ErrPaletteNotLoaded, err)
+}
+
+func TestFindInvalidDecks(t *testing.T) {
+	deckStoreMock := new(DeckStoreMock)
+	deckStoreMock.On("FindByColorNotIn", testColors).
+		Return([]entity.Deck{{ID: "1", Color: "red"}}, nil)
+
+	palette := newTestPalette(deckStoreMock)
+
+	decks, err := palette.FindInvalidDecks()
+
+	assert.Nil(t, err)
+	assert.Equal(t, []entity.Deck{{ID: "1", Color: "red"}}, decks)
+}
+
+func TestCreateDeckInvalidColor(t *testing.T) {
+	deckStoreMock := new(DeckStoreMock)
+
+	deckUseCase := NewDeckUseCase(
+		deckStoreMock,
+		new(CardStoreMock),
+		newUndoStoreMock(),
+		new(NoteTypeStoreMock),
+		newActivityStoreMock(),
+		newTestPalette(deckStoreMock),
+	)
+
+	_, err := deckUseCase.CreateDeck("1", &entity.DeckReq{Name: "Deck", Color: "red"})
+
+	assert.Equal(t, entity.ErrInvalidColor, err)
+	deckStoreMock.AssertNotCalled(t, "Save", mock.Anything)
+}
+
+func TestUpdateDeckColor(t *testing.T) {
+	tests := []struct {
+		testName  string
+		color     string
+		wantColor string
+		wantErr   error
+	}{
+		{"Palette Color", "#bebdff", "#BEBDFF", nil},
+		{"Stored Color Outside Palette", "red", "red", nil},
+		{"New Color Outside Palette", "blue", "", entity.ErrInvalidColor},
+	}
+
+	for _, test := range tests {
+		t.Run(test.testName, func(t *testing.T) {
+			deckStoreMock := new(DeckStoreMock)
+			deckStoreMock.On("FindByID", "1", "1").
+				Return(&entity.Deck{ID: "1", UserID: "1", Color: "red"}, nil)
+			deckStoreMock.On("Update", mock.Anything).Return(nil)
+
+			deckUseCase := NewDeckUseCase(
+				deckStoreMock,
+				new(CardStoreMock),
+				newUndoStoreMock(),
+				new(NoteTypeStoreMock),
+				newActivityStoreMock(),
+				newTestPalette(deckStoreMock),
+			)
+
+			deck, err := deckUseCase.UpdateDeck(
+				"1",
+				"1",
+				&entity.DeckReq{Name: "Deck", Color: test.color},
+				nil,
+			)
+
+			assert.Equal(t, test.wantErr, err)
+			if test.wantErr == nil {
+				assert.Equal(t, test.wantColor, deck.Color)
+			}
+		})
+	}
+}
@@ -0,0 +1,252 @@
+package usecase
+
+import (
+	"time"
+
+	mapper "github.com/PeteProgrammer/go-automapper"
+	"github.com/moshrank/spacey-backend/services/deck-management-service/entity"
+)
+
+// undoPeriod is how long the last bulk operation on a deck can be undone.
+// It matches the TTL index of the bulk operation collection.
+const undoPeriod = 10 * time.Minute
+
+// newBulkOperation starts the record of a bulk operation on the deck.
+func newBulkOperation(
+	deck *entity.Deck,
+	operation string,
+	timestamp time.Time,
+) entity.BulkOperation {
+	return entity.BulkOperation{
+		DeckID:       deck.ID,
+		UserID:       deck.UserID,
+		Operation:    operation,
+		CreatedCards: []string{},
+		ChangedCards: []entity.Card{},
+		DeletedCards: []string{},
+		CreatedAt:    &timestamp,
+	}
+}
+
+type RevisionUseCase struct {
+	deckStore     entity.DeckStoreInterface
+	cardStore     entity.CardStoreInterface
+	undoStore     entity.UndoStoreInterface
+	activityStore entity.ActivityStoreInterface
+	mediaStore    entity.MediaStoreInterface
+}
+
+func NewRevisionUseCase(
+	deckStore entity.DeckStoreInterface,
+	cardStore entity.CardStoreInterface,
+	undoStore entity.UndoStoreInterface,
+	activityStore entity.ActivityStoreInterface,
+	mediaStore entity.MediaStoreInterface,
+) entity.RevisionUseCaseInterface {
+	return &RevisionUseCase{
+		deckStore:     deckStore,
+		cardStore:     cardStore,
+		undoStore:     undoStore,
+		activityStore: activityStore,
+		mediaStore:    mediaStore,
+	}
+}
+
+// revertedCards returns the previous versions of cards to write back. They
+// may predate sanitizing or reference media the user can no longer view, so
+// they are sanitized and their media references are checked like any other
+// change. Cards that were moved to the trash since can not be reverted.
+func (u *RevisionUseCase) revertedCards(
+	userID, deckID string,
+	previous []entity.Card,
+	source string,
+	timestamp time.Time,
+) ([]entity.Card, error) {
+	if len(previous) == 0 {
+		return []entity.Card{}, nil
+	}
+
+	current, err := u.cardStore.FindByIDs(deckID, cardIDsOf(previous))
+	if err != nil {
+		return nil, err
+	}
+
+	versions := map[string]int{}
+	for _, card := range current {
+		versions[card.ID] = card.Version
+	}
+
+	cards := make([]entity.Card, len(previous))
+	for i, card := range previous {
+		version, ok := versions[card.ID]
+		if !ok {
+			return nil, entity.ErrCardNotFound
+		}
+
+		card.Source = source
+		card.Version = version
+		card.UpdatedAt = &timestamp
+		sanitizeCard(&card)
+		cards[i] = card
+	}
+
+	err = checkMediaRefs(u.mediaStore, u.deckStore, userID, deckID, cards, current)
+	if err != nil {
+		return nil, err
+	}
+
+	return cards, nil
+}
+
+func (u *RevisionUseCase) GetRevisions(
+	userID, deckID, cardID string,
+) ([]entity.RevisionRes, error) {
+	if _, err := authorizeDeck(u.deckStore, userID, deckID, entity.RoleViewer); err != nil {
+		return nil, err
+	}
+
+	revisions, err := u.cardStore.FindRevisions(deckID, cardID)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(revisions) == 0 {
+		return nil, entity.ErrCardNotFound
+	}
+
+	revisionsRes := []entity.RevisionRes{}
+	mapper.MapLoose(revisions, &revisionsRes)
+
+	return revisionsRes, nil
+}
+
+// RestoreRevision makes the content of a revision the current version of
+// the card, which is stored as a new revision.
+func (u *RevisionUseCase) RestoreRevision(
+	userID, deckID, cardID, revisionID string,
+) (*entity.CardRes, error) {
+	deck, err := authorizeDeck(u.deckStore, userID, deckID, entity.RoleEditor)
+	if err != nil {
+		return nil, err
+	}
+
+	revision, err := u.cardStore.FindRevision(deckID, cardID, revisionID)
+	if err != nil {
+		return nil, entity.ErrRevisionNotFound
+	}
+
+	timestamp := time.Now()
+	cards, err := u.revertedCards(userID, deckID, []entity.Card{{
+		ID:        cardID,
+		Question:  revision.Question,
+		Answer:    revision.Answer,
+		Tags:      revision.Tags,
+		Type:      revision.Type,
+		NoteID:    revision.NoteID,
+		Ordinal:   revision.Ordinal,
+		Cloze:     revision.Cloze,
+		Choices:   revision.Choices,
+		Accepted:  revision.Accepted,
+		Occlusion: revision.Occlusion,
+		Custom:    revision.Custom,
+	}}, entity.SourceRestore, timestamp)
+	if err != nil {
+		return nil, err
+	}
+
+	card := cards[0]
+	if err := u.cardStore.UpdateCard(cardID, deck.UserID, deckID, &card); err != nil {
+		return nil, err
+	}
+	card.Version++
+
+	activity := newActivity(userID, deckID, entity.ActivityCardRevert, []string{cardID}, timestamp)
+	activity.Changes = addChange(nil, "revision", "", revisionID)
+	if err := u.activityStore.Save(&activity); err != nil {
+		return nil, err
+	}
+
+	var cardRes entity.CardRes
+	mapper.MapLoose(&card, &cardRes)
+	cardRes.ID = cardID
+	cardRes.DeckID = deckID
+	renderCard(&cardRes)
+
+	return &cardRes, nil
+}
+
+// Undo reverts the last bulk operation on the deck if it happened within
+// the undo period. Created cards are moved to the trash, changed cards get
+// their previous version back and deleted cards are restored.
+func (u *RevisionUseCase) Undo(userID, deckID string) (*entity.UndoRes, error) {
+	deck, err := authorizeDeck(u.deckStore, userID, deckID, entity.RoleEditor)
+	if err != nil {
+		return nil, err
+	}
+
+	operation, err := u.undoStore.FindByDeckID(deckID)
+	if err != nil || operation.CreatedAt == nil {
+		return nil, entity.ErrNothingToUndo
+	}
+
+	timestamp := time.Now()
+	if timestamp.Sub(*operation.CreatedAt) > undoPeriod {
+		return nil, entity.ErrNothingToUndo
+	}
+
+	// the changed cards are checked before anything is undone
+	changedCards, err := u.revertedCards(
+		userID,
+		deckID,
+		operation.ChangedCards,
+		entity.SourceUndo,
+		timestamp,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(operation.CreatedCards) > 0 {
+		err := u.cardStore.DeleteCards(deck.UserID, deckID, operation.CreatedCards, timestamp)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	for i := range changedCards {
+		card := &changedCards[i]
+		if err := u.cardStore.UpdateCard(card.ID, deck.UserID, deckID, card); err != nil {
+			return nil, err
+		}
+	}
+
+	for _, cardID := range operation.DeletedCards {
+		if err := u.cardStore.RestoreCard(deck.UserID, deckID, cardID, timestamp); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := u.undoStore.Delete(deckID); err != nil {
+		return nil, err
+	}
+
+	// the undone operation is kept as the value before the undo
+	cardIDs := append(cardIDsOf(operation.ChangedCards), operation.CreatedCards...)
+	activity := newActivity(
+		userID,
+		deckID,
+		entity.ActivityUndo,
+		append(cardIDs, operation.DeletedCards...),
+		timestamp,
+	)
+	activity.Changes = addChange(nil, "operation", operation.Operation, "")
+	if err := u.activityStore.Save(&activity); err != nil {
+		return nil, err
+	}
+
+	return &entity.UndoRes{
+		Operation:     operation.Operation,
+		RemovedCards:  len(operation.CreatedCards),
+		RestoredCards: len(operation.ChangedCards) + len(operation.DeletedCards),
+	}, nil
+}
@@ -0,0 +1,313 @@
+package usecase
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/moshrank/spacey-backend/services/deck-management-service/entity"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type UndoStoreMock struct {
+	mock.Mock
+}
+
+func (s *UndoStoreMock) Save(operation *entity.BulkOperation) error {
+	args := s.Called(operation)
+	return args.Error(0)
+}
+
+func (s *UndoStoreMock) FindByDeckID(deckID string) (*entity.BulkOperation, error) {
+	args := s.Called(deckID)
+	return args.Get(0).(*entity.BulkOperation), args.Error(1)
+}
+
+func (s *UndoStoreMock) Delete(deckID string) error {
+	args := s.Called(deckID)
+	return args.Error(0)
+}
+
+func newUndoStoreMock() *UndoStoreMock {
+	undoStoreMock := new(UndoStoreMock)
+	undoStoreMock.On("Save", mock.Anything).Return(nil)
+
+	return undoStoreMock
+}
+
+func TestGetRevisions(t *testing.T) {
+	createdAt := time.Now()
+
+	cardStoreMock := new(CardStoreMock)
+	cardStoreMock.On("FindRevisions", "test_deck_id", "test_card_id").
+		Return([]entity.CardRevision{
+			{
+				ID:        "revision_2",
+				CardID:    "test_card_id",
+				Question:  "Edited",
+				Source:    entity.SourceManual,
+				CreatedAt: &createdAt,
+			},
+			{ID: "revision_1", CardID: "test_card_id", Question: "Generated", Source: "generated"},
+		}, nil)
+	cardStoreMock.On("FindRevisions", "test_deck_id", "unknown_card_id").
+		Return([]entity.CardRevision{}, nil)
+
+	revisionUseCase := NewRevisionUseCase(
+		newOwnedDeckStoreMock(),
+		cardStoreMock,
+		newUndoStoreMock(),
+		newActivityStoreMock(),
+		new(MediaStoreMock),
+	)
+
+	revisions, err := revisionUseCase.GetRevisions("1", "test_deck_id", "test_card_id")
+
+	assert.Nil(t, err)
+	assert.Equal(t, []entity.RevisionRes{
+		{
+			ID:        "revision_2",
+			CardID:    "test_card_id",
+			Question:  "Edited",
+			Source:    entity.SourceManual,
+			CreatedAt: &createdAt,
+		},
+		{ID: "revision_1", CardID: "test_card_id", Question: "Generated", Source: "generated"},
+	}, revisions)
+
+	_, err = revisionUseCase.GetRevisions("1", "test_deck_id", "unknown_card_id")
+
+	assert.Equal(t, entity.ErrCardNotFound, err)
+}
+
+func TestRestoreRevision(t *testing.T) {
+	cardStoreMock := new(CardStoreMock)
+	cardStoreMock.On("FindRevision", "test_deck_id", "test_card_id", "revision_1").
+		Return(&entity.CardRevision{ID: "revision_1", Question: "Old", Answer: "Answer"}, nil)
+	cardStoreMock.On("FindRevision", "test_deck_id", "test_card_id", "unknown").
+		Return(&entity.CardRevision{}, errors.New("no documents"))
+	cardStoreMock.On("FindByIDs", "test_deck_id", []string{"test_card_id"}).
+		Return([]entity.Card{{ID: "test_card_id", Question: "New", Version: 3}}, nil)
+	cardStoreMock.On("UpdateCard", "test_card_id", "1", "test_deck_id", mock.Anything).Return(nil)
+
+	revisionUseCase := NewRevisionUseCase(
+		newOwnedDeckStoreMock(),
+		cardStoreMock,
+		newUndoStoreMock(),
+		newActivityStoreMock(),
+		new(MediaStoreMock),
+	)
+
+	card, err := revisionUseCase.RestoreRevision("1", "test_deck_id", "test_card_id", "revision_1")
+
+	assert.Nil(t, err)
+	assert.Equal(t, &entity.CardRes{
+		ID:           "test_card_id",
+		Question:     "Old",
+		Answer:       "Answer",
+		QuestionHTML: "<p>Old</p>",
+		AnswerHTML:   "<p>Answer</p>",
+		DeckID:       "test_deck_id",
+		Version:      4,
+	}, card)
+
+	restored := cardStoreMock.Calls[2].Arguments.Get(3).(*entity.Card)
+	assert.Equal(t, entity.SourceRestore, restored.Source)
+
+	_, err = revisionUseCase.RestoreRevision("1", "test_deck_id", "test_card_id", "unknown")
+
+	assert.Equal(t, entity.ErrRevisionNotFound, err)
+}
+
+func TestUndo(t *testing.T) {
+	createdAt := time.Now().Add(-time.Minute)
+
+	undoStoreMock := new(UndoStoreMock)
+	undoStoreMock.On("FindByDeckID", "test_deck_id").Return(&entity.BulkOperation{
+		DeckID:       "test_deck_id",
+		UserID:       "1",
+		Operation:    entity.OperationPull,
+		CreatedCards: []string{"new_card"},
+		ChangedCards: []entity.Card{{ID: "synced_card", Question: "Before Pull"}},
+		CreatedAt:    &createdAt,
+	}, nil)
+	undoStoreMock.On("Delete", "test_deck_id").Return(nil)
+
+	cardStoreMock := new(CardStoreMock)
+	cardStoreMock.On("FindByIDs", "test_deck_id", []string{"synced_card"}).
+		Return([]entity.Card{{ID: "synced_card", Question: "After Pull", Version: 2}}, nil)
+	cardStoreMock.On("DeleteCards", "1", "test_deck_id", []string{"new_card"}, mock.Anything).
+		Return(nil)
+	cardStoreMock.On("UpdateCard", "synced_card", "1", "test_deck_id", mock.Anything).Return(nil)
+
+	revisionUseCase := NewRevisionUseCase(
+		newOwnedDeckStoreMock(),
+		cardStoreMock,
+		undoStoreMock,
+		newActivityStoreMock(),
+		new(MediaStoreMock),
+	)
+
+	res, err := revisionUseCase.Undo("1", "test_deck_id")
+
+	assert.Nil(t, err)
+	assert.Equal(t, &entity.UndoRes{
+		Operation:     entity.OperationPull,
+		RemovedCards:  1,
+		RestoredCards: 1,
+	}, res)
+
+	reverted := cardStoreMock.Calls[2].Arguments.Get(3).(*entity.Card)
+	assert.Equal(t, "Before Pull", reverted.Question)
+	assert.Equal(t, entity.SourceUndo, reverted.Source)
+	undoStoreMock.AssertCalled(t, "Delete", "test_deck_id")
+}
+
+func TestRestoreRevisionChecksContent(t *testing.T) {
+	tests := []struct {
+		testName string
+		revision *entity.CardRevision
+		current  []entity.Card
+		wantErr  error
+	}{
+		{
+			"Card In Trash",
+			&entity.CardRevision{ID: "revision_1", Question: "Old"},
+			[]entity.Card{},
+			entity.ErrCardNotFound,
+		},
+		{
+			"Foreign Media",
+			&entity.CardRevision{ID: "revision_1", Question: "![x](media:61f0c1e5d3b7a0c3f8a1b2c4)"},
+			[]entity.Card{{ID: "test_card_id", Version: 1}},
+			entity.ErrInvalidMediaRef,
+		},
+		{
+			"Unsanitized Content",
+			&entity.CardRevision{ID: "revision_1", Question: `<b onclick="alert(1)">Old</b>`},
+			[]entity.Card{{ID: "test_card_id", Version: 1}},
+			nil,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.testName, func(t *testing.T) {
+			cardStoreMock := new(CardStoreMock)
+			cardStoreMock.On("FindRevision", "test_deck_id", "test_card_id", "revision_1").
+				Return(test.revision, nil)
+			cardStoreMock.On("FindByIDs", "test_deck_id", []string{"test_card_id"}).
+				Return(test.current, nil)
+			cardStoreMock.On("UpdateCard", "test_card_id", "1", "test_deck_id", mock.Anything).
+				Return(nil)
+
+			mediaStoreMock := new(MediaStoreMock)
+			mediaStoreMock.On("FindByID", "61f0c1e5d3b7a0c3f8a1b2c4").
+				Return(&entity.Media{ID: "61f0c1e5d3b7a0c3f8a1b2c4", DeckID: "other_deck_id"}, nil)
+
+			deckStoreMock := new(DeckStoreMock)
+			deckStoreMock.On("FindByID", "1", "test_deck_id").
+				Return(&entity.Deck{ID: "test_deck_id", UserID: "1"}, nil)
+			deckStoreMock.On("FindByID", "1", "other_deck_id").
+				Return(&entity.Deck{}, errors.New("not found"))
+
+			revisionUseCase := NewRevisionUseCase(
+				deckStoreMock,
+				cardStoreMock,
+				newUndoStoreMock(),
+				newActivityStoreMock(),
+				mediaStoreMock,
+			)
+
+			card, err := revisionUseCase.RestoreRevision(
+				"1",
+				"test_deck_id",
+				"test_card_id",
+				"revision_1",
+			)
+
+			assert.Equal(t, test.wantErr, err)
+			if test.wantErr != nil {
+				cardStoreMock.AssertNotCalled(
+					t,
+					"UpdateCard",
+					mock.Anything,
+					mock.Anything,
+					mock.Anything,
+					mock.Anything,
+				)
+				return
+			}
+			assert.Equal(t, "<b>Old</b>", card.Question)
+		})
+	}
+}
+
+func TestUndoCardInTrash(t *testing.T) {
+	createdAt := time.Now().Add(-time.Minute)
+
+	undoStoreMock := new(UndoStoreMock)
+	undoStoreMock.On("FindByDeckID", "test_deck_id").Return(&entity.BulkOperation{
+		DeckID:       "test_deck_id",
+		Operation:    entity.OperationReplace,
+		CreatedCards: []string{"new_card"},
+		ChangedCards: []entity.Card{{ID: "deleted_card", Question: "Before"}},
+		CreatedAt:    &createdAt,
+	}, nil)
+
+	cardStoreMock := new(CardStoreMock)
+	cardStoreMock.On("FindByIDs", "test_deck_id", []string{"deleted_card"}).
+		Return([]entity.Card{}, nil)
+
+	revisionUseCase := NewRevisionUseCase(
+		newOwnedDeckStoreMock(),
+		cardStoreMock,
+		undoStoreMock,
+		newActivityStoreMock(),
+		new(MediaStoreMock),
+	)
+
+	_, err := revisionUseCase.Undo("1", "test_deck_id")
+
+	assert.Equal(t, entity.ErrCardNotFound, err)
+	cardStoreMock.AssertNotCalled(
+		t,
+		"DeleteCards",
+		mock.Anything,
+		mock.Anything,
+		mock.Anything,
+		mock.Anything,
+	)
+}
+
+func TestUndoExpired(t *testing.T) {
+	createdAt := time.Now().Add(-undoPeriod - time.Minute)
+
+	undoStoreMock := new(UndoStoreMock)
+	undoStoreMock.On("FindByDeckID", "test_deck_id").Return(&entity.BulkOperation{
+		DeckID:       "test_deck_id",
+		CreatedCards: []string{"new_card"},
+		CreatedAt:    &createdAt,
+	}, nil)
+
+	cardStoreMock := new(CardStoreMock)
+	revisionUseCase := NewRevisionUseCase(
+		newOwnedDeckStoreMock(),
+		cardStoreMock,
+		undoStoreMock,
+		newActivityStoreMock(),
+		new(MediaStoreMock),
+	)
+
+	_, err := revisionUseCase.Undo("1", "test_deck_id")
+
+	assert.Equal(t, entity.ErrNothingToUndo, err)
+	cardStoreMock.AssertNotCalled(
+		t,
+		"DeleteCards",
+		mock.Anything,
+		mock.Anything,
+		mock.Anything,
+		mock.Anything,
+	)
+}
@@ -0,0 +1,264 @@
+package usecase
+
+import (
+	"slices"
+	"sort"
+	"strings"
+	"unicode"
+
+	"github.com/moshrank/spacey-backend/services/deck-management-service/entity"
+)
+
+const (
+	defaultSearchLimit = 20
+	// fragmentRadius is the number of characters kept around the first match
+	// of a highlighted field.
+	fragmentRadius = 60
+)
+
+type SearchUseCase struct {
+	deckStore entity.DeckStoreInterface
+	cardStore entity.CardStoreInterface
+}
+
+func NewSearchUseCase(
+	deckStore entity.DeckStoreInterface,
+	cardStore entity.CardStoreInterface,
+) entity.SearchUseCaseInterface {
+	return &SearchUseCase{
+		deckStore: deckStore,
+		cardStore: cardStore,
+	}
+}
+
+// Search finds decks and cards of the decks the user owns or is a member of.
+// Decks have no tags, so only cards are searched if tags are given.
+func (u *SearchUseCase) Search(userID string, req *entity.SearchReq) (*entity.SearchRes, error) {
+	deckIDs, err := u.searchableDecks(userID, req.DeckID)
+	if err != nil {
+		return nil, err
+	}
+
+	res := entity.SearchRes{Decks: []entity.DeckHitRes{}, Cards: []entity.CardHitRes{}}
+	if len(deckIDs) == 0 {
+		return &res, nil
+	}
+
+	query := entity.SearchQuery{
+		Text:    req.Query,
+		DeckIDs: deckIDs,
+		Tags:    req.Tags,
+		Limit:   req.Limit,
+	}
+	if query.Limit == 0 {
+		query.Limit = defaultSearchLimit
+	}
+
+	terms := searchTerms(req.Query)
+
+	if len(req.Tags) == 0 {
+		decks, err := u.deckStore.Search(&query)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, deck := range decks {
+			res.Decks = append(res.Decks, entity.DeckHitRes{
+				ID:          deck.ID,
+				Name:        deck.Name,
+				Description: deck.Description,
+				Color:       deck.Color,
+				Score:       deck.Score,
+				Highlights: highlights(
+					terms,
+					"name", deck.Name,
+					"description", deck.Description,
+				),
+			})
+		}
+	}
+
+	cards, err := u.cardStore.Search(&query)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, card := range cards {
+		res.Cards = append(res.Cards, entity.CardHitRes{
+			ID:         card.ID,
+			DeckID:     card.DeckID,
+			Question:   card.Question,
+			Answer:     card.Answer,
+			Tags:       card.Tags,
+			Score:      card.Score,
+			Highlights: highlights(terms, "question", card.Question, "answer", card.Answer),
+		})
+	}
+
+	return &res, nil
+}
+
+func (u *SearchUseCase) searchableDecks(userID, deckID string) ([]string, error) {
+	if deckID != "" {
+		if _, err := authorizeDeck(u.deckStore, userID, deckID, entity.RoleViewer); err != nil {
+			return nil, err
+		}
+
+		return []string{deckID}, nil
+	}
+
+	decks, _, err := u.deckStore.FindAll(userID, &entity.DeckQuery{})
+	if err != nil {
+		return nil, err
+	}
+
+	deckIDs := []string{}
+	for _, deck := range decks {
+		deckIDs = append(deckIDs, deck.ID)
+	}
+
+	return deckIDs, nil
+}
+
+func isWordRune(r rune) bool {
+	return unicode.IsLetter(r) || unicode.IsDigit(r)
+}
+
+// searchStem strips common english suffixes. It roughly follows the stemming
+// of the text index, so that searching "learning" highlights "learned".
+func searchStem(term string) string {
+	if len([]rune(term)) <= 4 {
+		return term
+	}
+
+	for _, suffix := range []string{"ing", "ed", "es", "s"} {
+		if strings.HasSuffix(term, suffix) {
+			return strings.TrimSuffix(term, suffix)
+		}
+	}
+
+	return term
+}
+
+// searchTerms splits a text search into the lower case terms to highlight.
+// Quoted phrases are kept as one term and negated words are left out.
+func searchTerms(query string) []string {
+	terms := []string{}
+
+	for i, part := range strings.Split(query, `"`) {
+		if i%2 == 1 {
+			if phrase := strings.ToLower(strings.TrimSpace(part)); phrase != "" {
+				terms = append(terms, phrase)
+			}
+			continue
+		}
+
+		for _, word := range strings.Fields(part) {
+			if strings.HasPrefix(word, "-") {
+				continue
+			}
+
+			isSeparator := func(r rune) bool { return !isWordRune(r) }
+			for _, term := range strings.FieldsFunc(strings.ToLower(word), isSeparator) {
+				terms = append(terms, searchStem(term))
+			}
+		}
+	}
+
+	return terms
+}
+
+// findMatches returns the words of text that start with one of the terms,
+// sorted and without overlaps.
+func findMatches(text []rune, terms []string) []entity.MatchRes {
+	lower := make([]rune, len(text))
+	for i, r := range text {
+		lower[i] = unicode.ToLower(r)
+	}
+
+	matches := []entity.MatchRes{}
+	for _, term := range terms {
+		termRunes := []rune(term)
+		for i := 0; i+len(termRunes) <= len(lower); i++ {
+			atWordStart := i == 0 || !isWordRune(lower[i-1])
+			if !atWordStart || !slices.Equal(lower[i:i+len(termRunes)], termRunes) {
+				continue
+			}
+
+			end := i + len(termRunes)
+			for end < len(lower) && isWordRune(lower[end]) {
+				end++
+			}
+
+			matches = append(matches, entity.MatchRes{Start: i, End: end})
+			i = end - 1
+		}
+	}
+
+	sort.Slice(matches, func(i, j int) bool { return matches[i].Start < matches[j].Start })
+
+	merged := []entity.MatchRes{}
+	for _, match := range matches {
+		last := len(merged) - 1
+		if last >= 0 && match.Start <= merged[last].End {
+			merged[last].End = max(merged[last].End, match.End)
+			continue
+		}
+
+		merged = append(merged, match)
+	}
+
+	return merged
+}
+
+// highlight returns the fragment of text around the first match together
+// with the positions of the matches inside the fragment, or nil if nothing
+// matched.
+func highlight(field, text string, terms []string) *entity.HighlightRes {
+	runes := []rune(text)
+
+	matches := findMatches(runes, terms)
+	if len(matches) == 0 {
+		return nil
+	}
+
+	// cut the fragment at word boundaries
+	start := max(0, matches[0].Start-fragmentRadius)
+	for start > 0 && start < matches[0].Start && isWordRune(runes[start-1]) {
+		start++
+	}
+
+	end := min(len(runes), matches[0].End+fragmentRadius)
+	for end < len(runes) && end > matches[0].End && isWordRune(runes[end]) {
+		end--
+	}
+
+	res := entity.HighlightRes{
+		Field:    field,
+		Fragment: string(runes[start:end]),
+		Matches:  []entity.MatchRes{},
+	}
+
+	for _, match := range matches {
+		if match.Start >= start && match.End <= end {
+			res.Matches = append(res.Matches, entity.MatchRes{
+				Start: match.Start - start,
+				End:   match.End - start,
+			})
+		}
+	}
+
+	return &res
+}
+
+// highlights highlights pairs of field names and texts.
+func highlights(terms []string, fieldsAndTexts ...string) []entity.HighlightRes {
+	res := []entity.HighlightRes{}
+	for i := 0; i+1 < len(fieldsAndTexts); i += 2 {
+		if h := highlight(fieldsAndTexts[i], fieldsAndTexts[i+1], terms); h != nil {
+			res = append(res, *h)
+		}
+	}
+
+	return res
+}
@@ -0,0 +1,121 @@
+package usecase
+
+import (
+	"testing"
+
+	"github.com/moshrank/spacey-backend/services/deck-management-service/entity"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestSearch(t *testing.T) {
+	deckStoreMock := new(DeckStoreMock)
+	deckStoreMock.On("FindAll", "1", &entity.DeckQuery{}).
+		Return([]entity.Deck{{ID: "deck_1"}, {ID: "deck_2"}}, "", nil)
+	deckStoreMock.On("Search", mock.Anything).Return([]entity.DeckMatch{
+		{Deck: entity.Deck{ID: "deck_1", Name: "Learning Spanish"}, Score: 1.5},
+	}, nil)
+
+	cardStoreMock := new(CardStoreMock)
+	cardStoreMock.On("Search", mock.Anything).Return([]entity.CardMatch{
+		{
+			Card:  entity.Card{ID: "card_1", DeckID: "deck_2", Question: "What did you learn?"},
+			Score: 0.75,
+		},
+	}, nil)
+
+	searchUseCase := NewSearchUseCase(deckStoreMock, cardStoreMock)
+
+	res, err := searchUseCase.Search("1", &entity.SearchReq{Query: "learning"})
+
+	assert.Nil(t, err)
+	deckStoreMock.AssertCalled(t, "Search", &entity.SearchQuery{
+		Text:    "learning",
+		DeckIDs: []string{"deck_1", "deck_2"},
+		Limit:   defaultSearchLimit,
+	})
+
+	assert.Equal(t, []entity.DeckHitRes{{
+		ID:    "deck_1",
+		Name:  "Learning Spanish",
+		Score: 1.5,
+		Highlights: []entity.HighlightRes{{
+			Field:    "name",
+			Fragment: "Learning Spanish",
+			Matches:  []entity.MatchRes{{Start: 0, End: 8}},
+		}},
+	}}, res.Decks)
+
+	assert.Equal(t, 1, len(res.Cards))
+	assert.Equal(t, []entity.HighlightRes{{
+		Field:    "question",
+		Fragment: "What did you learn?",
+		Matches:  []entity.MatchRes{{Start: 13, End: 18}},
+	}}, res.Cards[0].Highlights)
+}
+
+func TestSearchWithTags(t *testing.T) {
+	deckStoreMock := newOwnedDeckStoreMock()
+
+	cardStoreMock := new(CardStoreMock)
+	cardStoreMock.On("Search", mock.Anything).Return([]entity.CardMatch{}, nil)
+
+	searchUseCase := NewSearchUseCase(deckStoreMock, cardStoreMock)
+
+	res, err := searchUseCase.Search("1", &entity.SearchReq{
+		Query:  "verb",
+		DeckID: "test_deck_id",
+		Tags:   []string{"grammar"},
+		Limit:  5,
+	})
+
+	assert.Nil(t, err)
+	assert.Equal(t, 0, len(res.Decks))
+	deckStoreMock.AssertNotCalled(t, "Search", mock.Anything)
+	cardStoreMock.AssertCalled(t, "Search", &entity.SearchQuery{
+		Text:    "verb",
+		DeckIDs: []string{"test_deck_id"},
+		Tags:    []string{"grammar"},
+		Limit:   5,
+	})
+}
+
+func TestSearchForeignDeck(t *testing.T) {
+	deckStoreMock := new(DeckStoreMock)
+	deckStoreMock.On("FindByID", "2", "test_deck_id").Return(&entity.Deck{
+		ID:     "test_deck_id",
+		UserID: "1",
+	}, nil)
+
+	searchUseCase := NewSearchUseCase(deckStoreMock, new(CardStoreMock))
+
+	_, err := searchUseCase.Search("2", &entity.SearchReq{Query: "verb", DeckID: "test_deck_id"})
+
+	assert.Equal(t, entity.ErrForbidden, err)
+}
+
+func TestSearchTerms(t *testing.T) {
+	assert.Equal(
+		t,
+		[]string{"learn", "the past tense", "spanish"},
+		searchTerms(`Learning "the past tense" -french spanish`),
+	)
+}
+
+func TestHighlight(t *testing.T) {
+	text := "Some filler words that come before the actual match in this rather long answer. " +
+		"Irregular verbs in the preterite change their stem, for example tener becomes tuv."
+
+	res := highlight("answer", text, []string{"verb", "stem"})
+
+	assert.Equal(t, "answer", res.Field)
+	assert.Equal(t, "the actual match in this rather long answer. Irregular verbs in the "+
+		"preterite change their stem, for example tener", res.Fragment)
+
+	fragment := []rune(res.Fragment)
+	assert.Equal(t, 2, len(res.Matches))
+	assert.Equal(t, "verbs", string(fragment[res.Matches[0].Start:res.Matches[0].End]))
+	assert.Equal(t, "stem", string(fragment[res.Matches[1].Start:res.Matches[1].End]))
+
+	assert.Nil(t, highlight("answer", text, []string{"french"}))
+}
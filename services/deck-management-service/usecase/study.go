@@ -0,0 +1,79 @@
+package usecase
+
+import (
+	mapper "github.com/PeteProgrammer/go-automapper"
+	"github.com/moshrank/spacey-backend/services/deck-management-service/entity"
+	"github.com/moshrank/spacey-backend/services/deck-management-service/external"
+)
+
+type StudyUseCase struct {
+	deckStore       entity.DeckStoreInterface
+	cardStore       entity.CardStoreInterface
+	learningService external.LearningServiceInterface
+}
+
+func NewStudyUseCase(
+	deckStore entity.DeckStoreInterface,
+	cardStore entity.CardStoreInterface,
+	learningService external.LearningServiceInterface,
+) entity.StudyUseCaseInterface {
+	return &StudyUseCase{
+		deckStore:       deckStore,
+		cardStore:       cardStore,
+		learningService: learningService,
+	}
+}
+
+// GetQueue returns the cards of the deck and of all sub-decks the user can
+// access that are due for learning, the ones most likely forgotten first.
+func (u *StudyUseCase) GetQueue(userID, deckID string) ([]entity.StudyCardRes, error) {
+	if _, err := authorizeDeck(u.deckStore, userID, deckID, entity.RoleViewer); err != nil {
+		return nil, err
+	}
+
+	decks, _, err := u.deckStore.FindAll(userID, &entity.DeckQuery{})
+	if err != nil {
+		return nil, err
+	}
+
+	cards := map[string]entity.Card{}
+	cardIDs := []string{}
+	for _, id := range append([]string{deckID}, subDeckIDs(decks, deckID)...) {
+		deckCards, _, err := u.cardStore.FindByDeckID(id, &entity.Page{})
+		if err != nil {
+			return nil, err
+		}
+
+		for _, card := range deckCards {
+			cards[card.ID] = card
+			cardIDs = append(cardIDs, card.ID)
+		}
+	}
+
+	queue := []entity.StudyCardRes{}
+	if len(cardIDs) == 0 {
+		return queue, nil
+	}
+
+	learningCards, err := u.learningService.GetLearningQueue(userID, cardIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, learningCard := range learningCards {
+		card, ok := cards[learningCard.CardID]
+		if !ok {
+			continue
+		}
+
+		var cardRes entity.CardRes
+		mapper.MapLoose(&card, &cardRes)
+		renderCard(&cardRes)
+		queue = append(queue, entity.StudyCardRes{
+			CardRes:           cardRes,
+			RecallProbability: learningCard.RecallProbability,
+		})
+	}
+
+	return queue, nil
+}
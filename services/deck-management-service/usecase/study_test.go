@@ -0,0 +1,64 @@
+package usecase
+
+import (
+	"testing"
+
+	"github.com/moshrank/spacey-backend/services/deck-management-service/entity"
+	"github.com/moshrank/spacey-backend/services/deck-management-service/external"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestGetQueue(t *testing.T) {
+	deckStoreMock := new(DeckStoreMock)
+	deckStoreMock.On("FindByID", "1", "medicine").
+		Return(&entity.Deck{ID: "medicine", UserID: "1"}, nil)
+	deckStoreMock.On("FindAll", "1", &entity.DeckQuery{}).Return([]entity.Deck{
+		{ID: "medicine", UserID: "1"},
+		{ID: "cardio", UserID: "1", ParentID: "medicine"},
+		{ID: "other", UserID: "1"},
+	}, "", nil)
+
+	cardStoreMock := new(CardStoreMock)
+	cardStoreMock.On("FindByDeckID", "medicine", &entity.Page{}).
+		Return([]entity.Card{{ID: "card_1", DeckID: "medicine", Question: "Q1"}}, "", nil)
+	cardStoreMock.On("FindByDeckID", "cardio", &entity.Page{}).
+		Return([]entity.Card{{ID: "card_2", DeckID: "cardio", Question: "Q2"}}, "", nil)
+
+	learningServiceMock := new(LearningServiceMock)
+	learningServiceMock.On("GetLearningQueue", "1", []string{"card_1", "card_2"}).
+		Return([]external.LearningCard{
+			{CardID: "card_2", RecallProbability: 0.1},
+			{CardID: "card_1", RecallProbability: 0.3},
+		}, nil)
+
+	studyUseCase := NewStudyUseCase(deckStoreMock, cardStoreMock, learningServiceMock)
+
+	queue, err := studyUseCase.GetQueue("1", "medicine")
+
+	assert.Nil(t, err)
+	assert.Len(t, queue, 2)
+	assert.Equal(t, "card_2", queue[0].ID)
+	assert.Equal(t, "cardio", queue[0].DeckID)
+	assert.Equal(t, 0.1, queue[0].RecallProbability)
+	assert.Equal(t, "Q1", queue[1].Question)
+	cardStoreMock.AssertNotCalled(t, "FindByDeckID", "other", mock.Anything)
+}
+
+func TestGetQueueWithoutCards(t *testing.T) {
+	deckStoreMock := newOwnedDeckStoreMock()
+	deckStoreMock.On("FindAll", "1", &entity.DeckQuery{}).Return([]entity.Deck{}, "", nil)
+
+	cardStoreMock := new(CardStoreMock)
+	cardStoreMock.On("FindByDeckID", "test_deck_id", &entity.Page{}).
+		Return([]entity.Card{}, "", nil)
+
+	learningServiceMock := new(LearningServiceMock)
+	studyUseCase := NewStudyUseCase(deckStoreMock, cardStoreMock, learningServiceMock)
+
+	queue, err := studyUseCase.GetQueue("1", "test_deck_id")
+
+	assert.Nil(t, err)
+	assert.Equal(t, []entity.StudyCardRes{}, queue)
+	learningServiceMock.AssertNotCalled(t, "GetLearningQueue", mock.Anything, mock.Anything)
+}
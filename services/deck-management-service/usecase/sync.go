@@ -0,0 +1,431 @@
+package usecase
+
+import (
+	"encoding/base64"
+	"errors"
+	"time"
+
+	mapper "github.com/PeteProgrammer/go-automapper"
+	"github.com/moshrank/spacey-backend/config"
+	"github.com/moshrank/spacey-backend/services/deck-management-service/entity"
+	"github.com/moshrank/spacey-backend/services/deck-management-service/external"
+)
+
+// syncOverlap is subtracted from the time of a sync to get its cursor. A
+// change is stamped before it is written, so changes written while a sync
+// runs are repeated by the next sync instead of getting lost.
+const syncOverlap = 5 * time.Second
+
+type SyncUseCase struct {
+	deckStore       entity.DeckStoreInterface
+	cardStore       entity.CardStoreInterface
+	learningService external.LearningServiceInterface
+	deckUseCase     entity.DeckUseCaseInterface
+	cardUseCase     entity.CardUseCaseInterface
+	retention       time.Duration
+}
+
+func NewSyncUseCase(
+	deckStore entity.DeckStoreInterface,
+	cardStore entity.CardStoreInterface,
+	undoStore entity.UndoStoreInterface,
+	noteTypeStore entity.NoteTypeStoreInterface,
+	activityStore entity.ActivityStoreInterface,
+	palette entity.PaletteInterface,
+	mediaStore entity.MediaStoreInterface,
+	learningService external.LearningServiceInterface,
+	cfg config.ConfigInterface,
+) entity.SyncUseCaseInterface {
+	return &SyncUseCase{
+		deckStore:       deckStore,
+		cardStore:       cardStore,
+		learningService: learningService,
+		deckUseCase: NewDeckUseCase(
+			deckStore,
+			cardStore,
+			undoStore,
+			noteTypeStore,
+			activityStore,
+			palette,
+		),
+		cardUseCase: NewCardUseCase(
+			cardStore,
+			deckStore,
+			undoStore,
+			noteTypeStore,
+			activityStore,
+			mediaStore,
+		),
+		retention: time.Duration(cfg.GetTrashRetentionDays()) * 24 * time.Hour,
+	}
+}
+
+func encodeSyncCursor(since time.Time) string {
+	return base64.RawURLEncoding.EncodeToString([]byte(since.UTC().Format(time.RFC3339Nano)))
+}
+
+// decodeSyncCursor returns the time of the cursor, or the zero time if the
+// cursor is empty.
+func decodeSyncCursor(cursor string) (time.Time, error) {
+	if cursor == "" {
+		return time.Time{}, nil
+	}
+
+	raw, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return time.Time{}, entity.ErrInvalidCursor
+	}
+
+	since, err := time.Parse(time.RFC3339Nano, string(raw))
+	if err != nil {
+		return time.Time{}, entity.ErrInvalidCursor
+	}
+
+	return since, nil
+}
+
+// acceptedSince reports whether the user became a member of the deck after
+// the given time, in which case none of its cards are on the clients yet.
+func acceptedSince(deck *entity.Deck, userID string, since time.Time) bool {
+	for _, member := range deck.Members {
+		if member.UserID == userID && member.AcceptedAt != nil {
+			return member.AcceptedAt.After(since)
+		}
+	}
+
+	return false
+}
+
+// GetChanges returns what changed since the cursor of the request. Changes
+// older than the trash retention can not be synced, since the decks and
+// cards deleted back then are gone, so the client has to start over.
+func (u *SyncUseCase) GetChanges(userID string, req *entity.SyncReq) (*entity.SyncRes, error) {
+	since, err := decodeSyncCursor(req.Since)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	res := &entity.SyncRes{
+		Cursor:  encodeSyncCursor(now.Add(-syncOverlap)),
+		Decks:   []entity.DeckRes{},
+		Cards:   []entity.CardRes{},
+		States:  []entity.CardStateRes{},
+		Deleted: entity.SyncDeletedRes{Decks: []string{}, Cards: []string{}},
+	}
+
+	if !since.IsZero() && since.Before(now.Add(-u.retention)) {
+		since = time.Time{}
+		res.Reset = true
+	}
+
+	changedDecks, err := u.deckStore.FindChanged(userID, since)
+	if err != nil {
+		return nil, err
+	}
+
+	newDeckIDs := []string{}
+	for _, deck := range changedDecks {
+		role := deck.Role(userID)
+		if deck.DeletedAt != nil || role == "" {
+			res.Deleted.Decks = append(res.Deleted.Decks, deck.ID)
+			continue
+		}
+
+		var deckRes entity.DeckRes
+		mapper.MapLoose(&deck, &deckRes)
+		deckRes.Role = role
+		res.Decks = append(res.Decks, deckRes)
+
+		if !since.IsZero() && acceptedSince(&deck, userID, since) {
+			newDeckIDs = append(newDeckIDs, deck.ID)
+		}
+	}
+
+	decks, _, err := u.deckStore.FindAll(userID, &entity.DeckQuery{})
+	if err != nil {
+		return nil, err
+	}
+
+	deckIDs := []string{}
+	for _, deck := range decks {
+		deckIDs = append(deckIDs, deck.ID)
+	}
+
+	cards, err := u.cardStore.FindChanged(deckIDs, since)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(newDeckIDs) > 0 {
+		newCards, err := u.cardStore.FindChanged(newDeckIDs, time.Time{})
+		if err != nil {
+			return nil, err
+		}
+
+		cards = append(cards, newCards...)
+	}
+
+	synced := map[string]bool{}
+	for _, card := range cards {
+		if synced[card.ID] {
+			continue
+		}
+		synced[card.ID] = true
+
+		if card.DeletedAt != nil {
+			res.Deleted.Cards = append(res.Deleted.Cards, card.ID)
+			continue
+		}
+
+		var cardRes entity.CardRes
+		mapper.MapLoose(&card, &cardRes)
+		renderCard(&cardRes)
+		res.Cards = append(res.Cards, cardRes)
+	}
+
+	states, err := u.learningService.GetChangedCardStates(userID, since)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, state := range states {
+		var stateRes entity.CardStateRes
+		mapper.MapLoose(&state, &stateRes)
+		res.States = append(res.States, stateRes)
+	}
+
+	return res, nil
+}
+
+// syncStatus returns the status of a change that failed with err, or an
+// error if the push can not go on. Changes to decks and cards deleted in the
+// meantime conflict with the deletion.
+func syncStatus(err error) (string, error) {
+	switch {
+	case errors.Is(err, entity.ErrVersionConflict),
+		errors.Is(err, entity.ErrDeckNotFound),
+		errors.Is(err, entity.ErrCardNotFound):
+		return entity.SyncConflict, nil
+	case errors.Is(err, entity.ErrForbidden),
+		errors.Is(err, entity.ErrInvalidParent),
+		errors.Is(err, entity.ErrInvalidColor),
+		errors.Is(err, entity.ErrInvalidCloze),
+		errors.Is(err, entity.ErrInvalidTags),
+		errors.Is(err, entity.ErrInvalidChoices),
+		errors.Is(err, entity.ErrInvalidTypedAnswer),
+		errors.Is(err, entity.ErrInvalidOcclusion),
+		errors.Is(err, entity.ErrNoteTypeNotFound),
+		errors.Is(err, entity.ErrInvalidNote),
+		errors.Is(err, entity.ErrInvalidMediaRef):
+		return entity.SyncRejected, nil
+	}
+
+	return "", err
+}
+
+// resolveID returns the id of the deck or card created for the client id,
+// or the id itself if it is not a client id of the push.
+func resolveID(clientIDs map[string]string, id string) string {
+	if serverID, ok := clientIDs[id]; ok {
+		return serverID
+	}
+
+	return id
+}
+
+func (u *SyncUseCase) currentDeck(userID, deckID string) *entity.DeckRes {
+	deck, err := u.deckStore.FindByID(userID, deckID)
+	if err != nil {
+		return nil
+	}
+
+	var deckRes entity.DeckRes
+	mapper.MapLoose(deck, &deckRes)
+	deckRes.Role = deck.Role(userID)
+
+	return &deckRes
+}
+
+func (u *SyncUseCase) currentCard(deckID, cardID string) *entity.CardRes {
+	cards, err := u.cardStore.FindByIDs(deckID, []string{cardID})
+	if err != nil || len(cards) == 0 {
+		return nil
+	}
+
+	var cardRes entity.CardRes
+	mapper.MapLoose(&cards[0], &cardRes)
+	renderCard(&cardRes)
+
+	return &cardRes
+}
+
+func (u *SyncUseCase) pushDeck(
+	userID string,
+	change *entity.SyncDeckChange,
+	deckIDs map[string]string,
+) (*entity.SyncDeckResultRes, error) {
+	result := &entity.SyncDeckResultRes{ID: change.ID, ClientID: change.ClientID}
+	ifMatch := entity.IfMatch{change.Version}
+
+	var err error
+	switch {
+	case change.ID == "":
+		deck := *change.Deck
+		deck.ParentID = resolveID(deckIDs, deck.ParentID)
+		result.Deck, err = u.deckUseCase.CreateDeck(userID, &deck)
+		if err == nil {
+			result.ID = result.Deck.ID
+			deckIDs[change.ClientID] = result.ID
+		}
+	case change.Deleted:
+		err = u.deckUseCase.DeleteDeck(userID, change.ID, ifMatch)
+		// the deck is gone either way
+		if errors.Is(err, entity.ErrDeckNotFound) {
+			err = nil
+		}
+	default:
+		result.Deck, err = u.deckUseCase.UpdateDeck(userID, change.ID, change.Deck, ifMatch)
+	}
+
+	if err == nil {
+		result.Status = entity.SyncApplied
+		return result, nil
+	}
+
+	status, statusErr := syncStatus(err)
+	if statusErr != nil {
+		return nil, statusErr
+	}
+
+	// decks created offline can not conflict with anything
+	if change.ID == "" && status == entity.SyncConflict {
+		status = entity.SyncRejected
+	}
+
+	result.Status = status
+	if status == entity.SyncConflict {
+		result.Deck = u.currentDeck(userID, change.ID)
+	} else {
+		result.Error = err.Error()
+	}
+
+	return result, nil
+}
+
+func (u *SyncUseCase) pushCard(
+	userID string,
+	change *entity.SyncCardChange,
+	deckIDs, cardIDs map[string]string,
+) (*entity.SyncCardResultRes, error) {
+	result := &entity.SyncCardResultRes{ID: change.ID, ClientID: change.ClientID}
+	deckID := resolveID(deckIDs, change.DeckID)
+	ifMatch := entity.IfMatch{change.Version}
+
+	var err error
+	switch {
+	case change.ID == "":
+		result.Card, err = u.cardUseCase.CreateCard(deckID, userID, change.Card, nil)
+		if err == nil {
+			result.ID = result.Card.ID
+			cardIDs[change.ClientID] = result.ID
+		}
+	case change.Deleted:
+		err = u.cardUseCase.DeleteCard(userID, deckID, change.ID, ifMatch)
+		if errors.Is(err, entity.ErrCardNotFound) {
+			err = nil
+		}
+	default:
+		result.Card, err = u.cardUseCase.UpdateCard(change.ID, userID, deckID, change.Card, ifMatch)
+	}
+
+	if err == nil {
+		result.Status = entity.SyncApplied
+		return result, nil
+	}
+
+	status, statusErr := syncStatus(err)
+	if statusErr != nil {
+		return nil, statusErr
+	}
+
+	// cards can not be created in a deck that is gone
+	if change.ID == "" && status == entity.SyncConflict {
+		status = entity.SyncRejected
+	}
+
+	result.Status = status
+	if status == entity.SyncConflict {
+		result.Card = u.currentCard(deckID, change.ID)
+	} else {
+		result.Error = err.Error()
+	}
+
+	return result, nil
+}
+
+// PushChanges applies the changes a client made offline one by one and
+// reports for each change whether it was applied, conflicts with a change
+// made elsewhere or was rejected. The reviews of cards that could not be
+// created are left out.
+func (u *SyncUseCase) PushChanges(
+	userID string,
+	req *entity.SyncPushReq,
+) (*entity.SyncPushRes, error) {
+	res := &entity.SyncPushRes{
+		Decks: []entity.SyncDeckResultRes{},
+		Cards: []entity.SyncCardResultRes{},
+	}
+
+	deckIDs := map[string]string{}
+	for i := range req.Decks {
+		result, err := u.pushDeck(userID, &req.Decks[i], deckIDs)
+		if err != nil {
+			return nil, err
+		}
+
+		res.Decks = append(res.Decks, *result)
+	}
+
+	cardIDs := map[string]string{}
+	failedCards := map[string]bool{}
+	for i := range req.Cards {
+		change := &req.Cards[i]
+		result, err := u.pushCard(userID, change, deckIDs, cardIDs)
+		if err != nil {
+			return nil, err
+		}
+
+		if change.ID == "" && result.Status != entity.SyncApplied {
+			failedCards[change.ClientID] = true
+		}
+
+		res.Cards = append(res.Cards, *result)
+	}
+
+	reviews := []external.CardReview{}
+	for _, review := range req.Reviews {
+		if failedCards[review.CardID] {
+			continue
+		}
+
+		reviews = append(reviews, external.CardReview{
+			DeckID:     resolveID(deckIDs, review.DeckID),
+			CardID:     resolveID(cardIDs, review.CardID),
+			ReviewedAt: review.ReviewedAt,
+			DurationMs: review.DurationMs,
+			Correct:    review.Correct,
+		})
+	}
+
+	if len(reviews) > 0 {
+		imported, err := u.learningService.ImportReviews(userID, reviews)
+		if err != nil {
+			return nil, err
+		}
+
+		res.Reviews = imported
+	}
+
+	return res, nil
+}
@@ -0,0 +1,246 @@
+package usecase
+
+import (
+	"testing"
+	"time"
+
+	"github.com/moshrank/spacey-backend/services/deck-management-service/entity"
+	"github.com/moshrank/spacey-backend/services/deck-management-service/external"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestSyncCursor(t *testing.T) {
+	since := time.Date(2022, 3, 1, 12, 0, 0, 500, time.UTC)
+
+	decoded, err := decodeSyncCursor(encodeSyncCursor(since))
+	assert.Nil(t, err)
+	assert.True(t, since.Equal(decoded))
+
+	decoded, err = decodeSyncCursor("")
+	assert.Nil(t, err)
+	assert.True(t, decoded.IsZero())
+
+	_, err = decodeSyncCursor("yesterday")
+	assert.Equal(t, entity.ErrInvalidCursor, err)
+}
+
+func TestGetChanges(t *testing.T) {
+	since := time.Now().Add(-time.Hour).UTC()
+	before := since.Add(-time.Hour)
+	after := since.Add(time.Minute)
+
+	deckStoreMock := new(DeckStoreMock)
+	deckStoreMock.On("FindChanged", "1", mock.MatchedBy(since.Equal)).Return([]entity.Deck{
+		{ID: "own_deck", UserID: "1", Name: "Spanish"},
+		{ID: "deleted_deck", UserID: "1", DeletedAt: &after},
+		{ID: "left_deck", UserID: "2"},
+		{
+			ID:     "shared_deck",
+			UserID: "2",
+			Members: []entity.DeckMember{{
+				UserID:     "1",
+				Role:       entity.RoleViewer,
+				Accepted:   true,
+				AcceptedAt: &after,
+			}},
+		},
+	}, nil)
+	deckStoreMock.On("FindAll", "1", &entity.DeckQuery{}).Return([]entity.Deck{
+		{ID: "own_deck", UserID: "1"},
+		{ID: "old_deck", UserID: "1", UpdatedAt: &before},
+		{ID: "shared_deck", UserID: "2"},
+	}, "", nil)
+
+	cardStoreMock := new(CardStoreMock)
+	cardStoreMock.On(
+		"FindChanged",
+		[]string{"own_deck", "old_deck", "shared_deck"},
+		mock.MatchedBy(since.Equal),
+	).Return([]entity.Card{
+		{ID: "card_1", DeckID: "own_deck", Tags: []string{"verbs"}},
+		{ID: "card_2", DeckID: "old_deck", DeletedAt: &after},
+		{ID: "card_3", DeckID: "shared_deck"},
+	}, nil)
+	cardStoreMock.On("FindChanged", []string{"shared_deck"}, time.Time{}).Return([]entity.Card{
+		{ID: "card_3", DeckID: "shared_deck"},
+		{ID: "card_4", DeckID: "shared_deck"},
+	}, nil)
+
+	learningServiceMock := new(LearningServiceMock)
+	learningServiceMock.On("GetChangedCardStates", "1", mock.MatchedBy(since.Equal)).
+		Return([]external.CardState{{CardID: "card_1", MemoryHalfLife: 2, NumberPracticed: 3}}, nil)
+
+	syncUseCase := NewSyncUseCase(
+		deckStoreMock,
+		cardStoreMock,
+		newUndoStoreMock(),
+		new(NoteTypeStoreMock),
+		newActivityStoreMock(),
+		newTestPalette(new(DeckStoreMock)),
+		new(MediaStoreMock),
+		learningServiceMock,
+		trashConfig,
+	)
+
+	res, err := syncUseCase.GetChanges("1", &entity.SyncReq{Since: encodeSyncCursor(since)})
+
+	assert.Nil(t, err)
+	assert.False(t, res.Reset)
+	assert.NotEmpty(t, res.Cursor)
+
+	assert.Len(t, res.Decks, 2)
+	assert.Equal(t, "own_deck", res.Decks[0].ID)
+	assert.Equal(t, entity.RoleOwner, res.Decks[0].Role)
+	assert.Equal(t, entity.RoleViewer, res.Decks[1].Role)
+	assert.Equal(t, []string{"deleted_deck", "left_deck"}, res.Deleted.Decks)
+
+	cardIDs := []string{}
+	for _, card := range res.Cards {
+		cardIDs = append(cardIDs, card.ID)
+	}
+	assert.Equal(t, []string{"card_1", "card_3", "card_4"}, cardIDs)
+	assert.Equal(t, []string{"verbs"}, res.Cards[0].Tags)
+	assert.Equal(t, []string{"card_2"}, res.Deleted.Cards)
+
+	assert.Equal(t, []entity.CardStateRes{
+		{CardID: "card_1", MemoryHalfLife: 2, NumberPracticed: 3},
+	}, res.States)
+}
+
+func TestGetChangesAfterRetention(t *testing.T) {
+	since := time.Now().AddDate(0, 0, -31)
+
+	deckStoreMock := new(DeckStoreMock)
+	deckStoreMock.On("FindChanged", "1", time.Time{}).Return([]entity.Deck{
+		{ID: "own_deck", UserID: "1"},
+	}, nil)
+	deckStoreMock.On("FindAll", "1", &entity.DeckQuery{}).Return([]entity.Deck{
+		{ID: "own_deck", UserID: "1"},
+	}, "", nil)
+
+	cardStoreMock := new(CardStoreMock)
+	cardStoreMock.On("FindChanged", []string{"own_deck"}, time.Time{}).
+		Return([]entity.Card{{ID: "card_1", DeckID: "own_deck"}}, nil)
+
+	learningServiceMock := new(LearningServiceMock)
+	learningServiceMock.On("GetChangedCardStates", "1", time.Time{}).
+		Return([]external.CardState{}, nil)
+
+	syncUseCase := NewSyncUseCase(
+		deckStoreMock,
+		cardStoreMock,
+		newUndoStoreMock(),
+		new(NoteTypeStoreMock),
+		newActivityStoreMock(),
+		newTestPalette(new(DeckStoreMock)),
+		new(MediaStoreMock),
+		learningServiceMock,
+		trashConfig,
+	)
+
+	res, err := syncUseCase.GetChanges("1", &entity.SyncReq{Since: encodeSyncCursor(since)})
+
+	assert.Nil(t, err)
+	assert.True(t, res.Reset)
+	assert.Len(t, res.Decks, 1)
+	assert.Len(t, res.Cards, 1)
+	assert.Empty(t, res.Deleted.Decks)
+}
+
+func TestGetChangesInvalidCursor(t *testing.T) {
+	syncUseCase := NewSyncUseCase(
+		new(DeckStoreMock),
+		new(CardStoreMock),
+		newUndoStoreMock(),
+		new(NoteTypeStoreMock),
+		newActivityStoreMock(),
+		newTestPalette(new(DeckStoreMock)),
+		new(MediaStoreMock),
+		new(LearningServiceMock),
+		trashConfig,
+	)
+
+	_, err := syncUseCase.GetChanges("1", &entity.SyncReq{Since: "yesterday"})
+
+	assert.Equal(t, entity.ErrInvalidCursor, err)
+}
+
+func TestPushChanges(t *testing.T) {
+	deckStoreMock := new(DeckStoreMock)
+	deckStoreMock.On("Save", mock.Anything).Return("new_deck", nil)
+	deckStoreMock.On("FindByID", "1", "1").
+		Return(&entity.Deck{ID: "1", UserID: "1", Name: "Spanish", Version: 3}, nil)
+	deckStoreMock.On("FindByID", "1", "new_deck").
+		Return(&entity.Deck{ID: "new_deck", UserID: "1", Version: 1}, nil)
+	deckStoreMock.On("FindByID", "1", "gone").
+		Return((*entity.Deck)(nil), entity.ErrDeckNotFound)
+
+	cardStoreMock := new(CardStoreMock)
+	cardStoreMock.On("SaveCard", "new_deck", "1", mock.Anything).Return("new_card", nil)
+	cardStoreMock.On("FindByIDs", "1", []string{"card_2"}).Return([]entity.Card{}, nil)
+
+	learningServiceMock := new(LearningServiceMock)
+	learningServiceMock.On("ImportReviews", "1", mock.Anything).Return(1, nil)
+
+	syncUseCase := NewSyncUseCase(
+		deckStoreMock,
+		cardStoreMock,
+		newUndoStoreMock(),
+		new(NoteTypeStoreMock),
+		newActivityStoreMock(),
+		newTestPalette(new(DeckStoreMock)),
+		new(MediaStoreMock),
+		learningServiceMock,
+		trashConfig,
+	)
+
+	reviewedAt := time.Date(2022, 3, 1, 12, 0, 0, 0, time.UTC)
+	res, err := syncUseCase.PushChanges("1", &entity.SyncPushReq{
+		Decks: []entity.SyncDeckChange{
+			{ClientID: "client_deck", Deck: &entity.DeckReq{Name: "Verbs", Color: "#FFEC87"}},
+			{ID: "1", Version: 2, Deck: &entity.DeckReq{Name: "Spanish Verbs", Color: "#FFEC87"}},
+		},
+		Cards: []entity.SyncCardChange{
+			{
+				ClientID: "client_card",
+				DeckID:   "client_deck",
+				Card:     &entity.CardReq{Question: "hablar", Answer: "to speak"},
+			},
+			{ID: "card_2", DeckID: "1", Version: 1, Deleted: true},
+			{
+				ClientID: "lost_card",
+				DeckID:   "gone",
+				Card:     &entity.CardReq{Question: "comer", Answer: "to eat"},
+			},
+		},
+		Reviews: []entity.SyncReviewReq{
+			{DeckID: "client_deck", CardID: "client_card", ReviewedAt: &reviewedAt, Correct: true},
+			{DeckID: "gone", CardID: "lost_card", ReviewedAt: &reviewedAt},
+		},
+	})
+
+	assert.Nil(t, err)
+
+	assert.Equal(t, entity.SyncApplied, res.Decks[0].Status)
+	assert.Equal(t, "new_deck", res.Decks[0].ID)
+	assert.Equal(t, "client_deck", res.Decks[0].ClientID)
+	assert.Equal(t, entity.SyncConflict, res.Decks[1].Status)
+	assert.Equal(t, "Spanish", res.Decks[1].Deck.Name)
+	assert.Equal(t, 3, res.Decks[1].Deck.Version)
+	deckStoreMock.AssertNotCalled(t, "Update", mock.Anything)
+
+	assert.Equal(t, entity.SyncApplied, res.Cards[0].Status)
+	assert.Equal(t, "new_card", res.Cards[0].ID)
+	assert.Equal(t, entity.SyncApplied, res.Cards[1].Status)
+	assert.Equal(t, entity.SyncRejected, res.Cards[2].Status)
+	assert.Equal(t, entity.ErrDeckNotFound.Error(), res.Cards[2].Error)
+
+	assert.Equal(t, 1, res.Reviews)
+	learningServiceMock.AssertCalled(t, "ImportReviews", "1", []external.CardReview{{
+		DeckID:     "new_deck",
+		CardID:     "new_card",
+		ReviewedAt: &reviewedAt,
+		Correct:    true,
+	}})
+}
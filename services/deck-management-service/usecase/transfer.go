@@ -0,0 +1,731 @@
+package usecase
+
+import (
+	"bytes"
+	"io"
+	"math"
+	"regexp"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+
+	mapper "github.com/PeteProgrammer/go-automapper"
+	"github.com/moshrank/spacey-backend/services/deck-management-service/entity"
+	"github.com/moshrank/spacey-backend/services/deck-management-service/external"
+	"github.com/moshrank/spacey-backend/services/deck-management-service/format"
+)
+
+const (
+	ankiDeckDescription = "Imported from Anki"
+	ankiReviewManual    = 4
+	ankiEaseAgain       = 1
+	maxDeckNameLength   = 30
+)
+
+var fileNamePattern = regexp.MustCompile(`[^\p{L}\p{N} _-]+`)
+
+type TransferUseCase struct {
+	deckStore       entity.DeckStoreInterface
+	cardStore       entity.CardStoreInterface
+	undoStore       entity.UndoStoreInterface
+	activityStore   entity.ActivityStoreInterface
+	palette         entity.PaletteInterface
+	mediaStore      entity.MediaStoreInterface
+	learningService external.LearningServiceInterface
+}
+
+func NewTransferUseCase(
+	deckStore entity.DeckStoreInterface,
+	cardStore entity.CardStoreInterface,
+	undoStore entity.UndoStoreInterface,
+	activityStore entity.ActivityStoreInterface,
+	palette entity.PaletteInterface,
+	mediaStore entity.MediaStoreInterface,
+	learningService external.LearningServiceInterface,
+) entity.TransferUseCaseInterface {
+	return &TransferUseCase{
+		deckStore:       deckStore,
+		cardStore:       cardStore,
+		undoStore:       undoStore,
+		activityStore:   activityStore,
+		palette:         palette,
+		mediaStore:      mediaStore,
+		learningService: learningService,
+	}
+}
+
+type importedCard struct {
+	deckID string
+	cardID string
+}
+
+func (u *TransferUseCase) ImportAnki(
+	userID string,
+	file io.ReaderAt,
+	size int64,
+	history bool,
+	duplicates *entity.DuplicateReq,
+) (*entity.AnkiImportRes, error) {
+	pkg, err := format.ReadAnkiPackage(file, size)
+	if err != nil {
+		return nil, err
+	}
+
+	res := entity.AnkiImportRes{
+		Decks:       []entity.DeckRes{},
+		Unsupported: []entity.UnsupportedNoteRes{},
+		Skipped:     []entity.CardRes{},
+	}
+
+	// the decks are new, so an account wide index is shared by all of them
+	// while a deck index only compares the cards of the same deck
+	var accountIndex *duplicateIndex
+	if duplicates != nil && duplicates.Duplicates == entity.DuplicateScopeAccount {
+		accountIndex, err = loadDuplicateIndex(u.deckStore, u.cardStore, userID, "", duplicates)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	timestamp := time.Now()
+	cardsByDeck := map[int64][]entity.Card{}
+	ankiCardIDs := map[int64][]int64{}
+	reported := map[int64]bool{}
+	noteIDs := map[int64]string{}
+
+	for _, ankiCard := range pkg.Cards {
+		card, reason, model := u.convertAnkiCard(pkg, &ankiCard)
+		if reason != "" {
+			if !reported[ankiCard.NoteID] {
+				reported[ankiCard.NoteID] = true
+				res.Unsupported = append(res.Unsupported, entity.UnsupportedNoteRes{
+					NoteID: ankiCard.NoteID,
+					Model:  model,
+					Reason: reason,
+				})
+			}
+			continue
+		}
+
+		// the card is imported without its media, which is reported as well
+		note := pkg.Notes[ankiCard.NoteID]
+		if files := format.AnkiMediaFiles(note.Fields); len(files) > 0 &&
+			!reported[ankiCard.NoteID] {
+			reported[ankiCard.NoteID] = true
+			res.Unsupported = append(res.Unsupported, entity.UnsupportedNoteRes{
+				NoteID: ankiCard.NoteID,
+				Model:  model,
+				Reason: "images and audio are not imported: " + strings.Join(files, ", "),
+			})
+		}
+
+		// the cards of an anki note are siblings of the same note
+		if hasSiblings(card.Type) {
+			if _, ok := noteIDs[ankiCard.NoteID]; !ok {
+				noteIDs[ankiCard.NoteID] = newNoteID()
+			}
+			card.NoteID = noteIDs[ankiCard.NoteID]
+		}
+
+		card.UserID = userID
+		card.CreatedAt = &timestamp
+		card.UpdatedAt = &timestamp
+		cardsByDeck[ankiCard.DeckID] = append(cardsByDeck[ankiCard.DeckID], *card)
+		ankiCardIDs[ankiCard.DeckID] = append(ankiCardIDs[ankiCard.DeckID], ankiCard.ID)
+	}
+
+	// the decks are created below, so the cards can only reference media
+	// files of the decks the user can view already
+	ankiDeckIDs := []int64{}
+	paths := map[int64][]string{}
+	for ankiDeckID, cards := range cardsByDeck {
+		if err := checkMediaRefs(u.mediaStore, u.deckStore, userID, "", cards, nil); err != nil {
+			return nil, err
+		}
+
+		ankiDeckIDs = append(ankiDeckIDs, ankiDeckID)
+		paths[ankiDeckID] = ankiDeckPath(pkg.Decks[ankiDeckID].Name)
+	}
+
+	// parents come before their sub-decks, so sub-decks are created below them
+	sort.Slice(ankiDeckIDs, func(i, j int) bool {
+		order := slices.Compare(paths[ankiDeckIDs[i]], paths[ankiDeckIDs[j]])
+		if order != 0 {
+			return order < 0
+		}
+
+		return ankiDeckIDs[i] < ankiDeckIDs[j]
+	})
+
+	colors := u.palette.Colors()
+	if len(colors) == 0 {
+		return nil, entity.ErrPaletteNotLoaded
+	}
+
+	imported := map[int64]importedCard{}
+	deckIDs := map[string]string{}
+	for _, ankiDeckID := range ankiDeckIDs {
+		deckDB, err := u.saveAnkiDeck(userID, paths[ankiDeckID], deckIDs, colors, &res, timestamp)
+		if err != nil {
+			return nil, err
+		}
+		deckID := deckDB.ID
+
+		index := accountIndex
+		if duplicates != nil && duplicates.Duplicates == entity.DuplicateScopeDeck {
+			index = newDuplicateIndex()
+		}
+
+		cards := []entity.Card{}
+		cardDuplicates := []*entity.DuplicateRes{}
+		ankiIDs := []int64{}
+		for j, card := range cardsByDeck[ankiDeckID] {
+			card.DeckID = deckID
+			duplicate := index.find(&card)
+			index.add(&card)
+
+			if skipDuplicate(duplicates, duplicate) {
+				var cardRes entity.CardRes
+				mapper.MapLoose(&card, &cardRes)
+				renderCard(&cardRes)
+				cardRes.Duplicate = duplicate
+				cardRes.Skipped = true
+				res.Skipped = append(res.Skipped, cardRes)
+				continue
+			}
+
+			cards = append(cards, card)
+			cardDuplicates = append(cardDuplicates, duplicate)
+			ankiIDs = append(ankiIDs, ankiCardIDs[ankiDeckID][j])
+		}
+
+		if len(cards) > 0 {
+			cardIDs, err := u.cardStore.SaveCards(deckID, userID, cards)
+			if err != nil {
+				return nil, err
+			}
+
+			for j := range cards {
+				cards[j].ID = cardIDs[j]
+				imported[ankiIDs[j]] = importedCard{deckID: deckID, cardID: cardIDs[j]}
+			}
+		}
+		deckDB.Cards = cards
+
+		activity := newActivity(
+			userID,
+			deckID,
+			entity.ActivityCardsImport,
+			cardIDsOf(cards),
+			timestamp,
+		)
+		if err := u.activityStore.Save(&activity); err != nil {
+			return nil, err
+		}
+
+		var deckRes entity.DeckRes
+		mapper.MapLoose(deckDB, &deckRes)
+		deckRes.Role = entity.RoleOwner
+		renderCards(deckRes.Cards)
+		for j := range deckRes.Cards {
+			deckRes.Cards[j].Duplicate = cardDuplicates[j]
+		}
+		res.Decks = append(res.Decks, deckRes)
+	}
+
+	if history {
+		reviews := ankiReviews(pkg, imported)
+		if len(reviews) > 0 {
+			res.ImportedReviews, err = u.learningService.ImportReviews(userID, reviews)
+			if err != nil {
+				// the cards are already imported, so only the history is lost
+				res.HistoryError = err.Error()
+			}
+		}
+	}
+
+	return &res, nil
+}
+
+// saveAnkiDeck creates the deck for the path of an anki deck, e.g. "Spanish"
+// below "Languages" for "Languages::Spanish". Parents that are neither
+// among the ids of the decks created so far nor have cards of their own are
+// created empty and added to the result.
+func (u *TransferUseCase) saveAnkiDeck(
+	userID string,
+	path []string,
+	deckIDs map[string]string,
+	colors []string,
+	res *entity.AnkiImportRes,
+	timestamp time.Time,
+) (*entity.Deck, error) {
+	parentID := ""
+	for depth := 1; depth < len(path); depth++ {
+		key := strings.Join(path[:depth], "::")
+		if id, ok := deckIDs[key]; ok {
+			parentID = id
+			continue
+		}
+
+		color := colors[len(deckIDs)%len(colors)]
+		parent, err := u.newAnkiDeck(userID, path[depth-1], parentID, color, timestamp)
+		if err != nil {
+			return nil, err
+		}
+
+		activity := newActivity(userID, parent.ID, entity.ActivityDeckCreate, nil, timestamp)
+		if err := u.activityStore.Save(&activity); err != nil {
+			return nil, err
+		}
+
+		var deckRes entity.DeckRes
+		mapper.MapLoose(parent, &deckRes)
+		deckRes.Role = entity.RoleOwner
+		res.Decks = append(res.Decks, deckRes)
+
+		deckIDs[key] = parent.ID
+		parentID = parent.ID
+	}
+
+	color := colors[len(deckIDs)%len(colors)]
+	deck, err := u.newAnkiDeck(userID, path[len(path)-1], parentID, color, timestamp)
+	if err != nil {
+		return nil, err
+	}
+
+	deckIDs[strings.Join(path, "::")] = deck.ID
+
+	return deck, nil
+}
+
+func (u *TransferUseCase) newAnkiDeck(
+	userID, name, parentID, color string,
+	timestamp time.Time,
+) (*entity.Deck, error) {
+	deck := entity.Deck{
+		Name:        name,
+		Description: ankiDeckDescription,
+		Color:       color,
+		UserID:      userID,
+		ParentID:    parentID,
+		Members:     []entity.DeckMember{},
+		Cards:       []entity.Card{},
+		CreatedAt:   &timestamp,
+		UpdatedAt:   &timestamp,
+	}
+
+	id, err := u.deckStore.Save(&deck)
+	if err != nil {
+		return nil, err
+	}
+	deck.ID = id
+
+	return &deck, nil
+}
+
+// convertAnkiCard converts an anki card into a card, which is checked like
+// the cards users create. Cloze cards keep the text of their note, with the
+// other fields the answer shows as extra information. If the card can not
+// be imported the reason is returned instead.
+func (u *TransferUseCase) convertAnkiCard(
+	pkg *format.AnkiPackage,
+	ankiCard *format.AnkiCard,
+) (*entity.Card, string, string) {
+	note, ok := pkg.Notes[ankiCard.NoteID]
+	if !ok {
+		return nil, "note is missing", ""
+	}
+
+	model, ok := pkg.Models[note.ModelID]
+	if !ok {
+		return nil, "note type is missing", ""
+	}
+
+	fields := model.FieldMap(&note)
+	req := entity.CardReq{Tags: note.Tags, Source: entity.SourceImport}
+
+	if model.Type == format.AnkiModelCloze {
+		// cloze note types have a single template rendering a card per deletion
+		if len(model.Templates) == 0 {
+			return nil, "card template is missing", model.Name
+		}
+
+		template := model.Templates[0]
+		field, ok := format.AnkiClozeField(template.QFmt)
+		if !ok {
+			return nil, "cloze field is missing", model.Name
+		}
+
+		req.Type = entity.CardTypeCloze
+		req.Question = format.AnkiHTMLToText(fields[field])
+		fields[field] = ""
+		req.Answer = format.AnkiHTMLToText(format.AnkiAnswer(template.AFmt, fields))
+	} else {
+		template, ok := model.Template(ankiCard.Ord)
+		if !ok {
+			return nil, "card template is missing", model.Name
+		}
+
+		question := format.RenderAnkiTemplate(template.QFmt, fields)
+		fields["FrontSide"] = question
+
+		req.Question = format.AnkiHTMLToText(question)
+		req.Answer = format.AnkiHTMLToText(format.AnkiAnswer(template.AFmt, fields))
+	}
+
+	cards, err := expandCard(&req, nil)
+	if err != nil {
+		return nil, err.Error(), model.Name
+	}
+
+	card := &cards[0]
+	if req.Type == entity.CardTypeCloze {
+		// anki numbers the cards of cloze notes from 0, deletions from 1
+		card = nil
+		for i := range cards {
+			if cards[i].Ordinal == ankiCard.Ord+1 {
+				card = &cards[i]
+			}
+		}
+
+		if card == nil {
+			return nil, "cloze deletion of the card is missing", model.Name
+		}
+	}
+
+	if card.Question == "" || card.Answer == "" {
+		return nil, "card has no text on one of its sides", model.Name
+	}
+
+	return card, "", model.Name
+}
+
+// ankiTags replaces whitespace in tags, since anki separates tags by spaces.
+func ankiTags(tags []string) []string {
+	converted := []string{}
+	for _, tag := range tags {
+		converted = append(converted, strings.Join(strings.Fields(tag), "_"))
+	}
+
+	return converted
+}
+
+// ankiDeckPath splits the name of an anki deck like "Languages::Spanish"
+// into the names of its parents and itself.
+func ankiDeckPath(name string) []string {
+	path := []string{}
+	for _, part := range strings.Split(name, "::") {
+		if strings.TrimSpace(part) != "" {
+			path = append(path, ankiDeckName(part))
+		}
+	}
+
+	if len(path) == 0 {
+		path = append(path, ankiDeckName(""))
+	}
+
+	return path
+}
+
+func ankiDeckName(name string) string {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		name = "Anki"
+	}
+
+	runes := []rune(name)
+	if len(runes) > maxDeckNameLength {
+		name = strings.TrimSpace(string(runes[:maxDeckNameLength]))
+	}
+
+	return name
+}
+
+func ankiReviews(
+	pkg *format.AnkiPackage,
+	imported map[int64]importedCard,
+) []external.CardReview {
+	reviews := []external.CardReview{}
+
+	for _, review := range pkg.Reviews {
+		card, ok := imported[review.CardID]
+		if !ok || review.Ease == 0 || review.Type == ankiReviewManual {
+			continue
+		}
+
+		reviewedAt := time.UnixMilli(review.ID)
+		reviews = append(reviews, external.CardReview{
+			DeckID:     card.deckID,
+			CardID:     card.cardID,
+			ReviewedAt: &reviewedAt,
+			DurationMs: review.Time,
+			Correct:    review.Ease > ankiEaseAgain,
+		})
+	}
+
+	return reviews
+}
+
+func exportFileName(deckName, extension string) string {
+	name := strings.TrimSpace(fileNamePattern.ReplaceAllString(deckName, ""))
+	if name == "" {
+		name = "deck"
+	}
+
+	return name + "." + extension
+}
+
+func (u *TransferUseCase) ExportAnki(
+	userID, deckID string,
+	scheduling bool,
+) (*entity.ExportFile, error) {
+	deck, err := authorizeDeck(u.deckStore, userID, deckID, entity.RoleViewer)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := loadCards(u.cardStore, deck); err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	created := now.Truncate(24 * time.Hour)
+	ankiDeckID := now.UnixMilli()
+
+	pkg := format.AnkiPackage{
+		Created: created,
+		Models:  map[int64]format.AnkiModel{format.AnkiBasicModelID: format.AnkiBasicModel},
+		Decks: map[int64]format.AnkiDeck{
+			ankiDeckID: {Name: deck.Name, Description: deck.Description},
+		},
+		Notes: map[int64]format.AnkiNote{},
+		Cards: []format.AnkiCard{},
+	}
+
+	states := map[string]external.CardState{}
+	if scheduling && len(deck.Cards) > 0 {
+		cardIDs := []string{}
+		for _, card := range deck.Cards {
+			cardIDs = append(cardIDs, card.ID)
+		}
+
+		cardStates, err := u.learningService.GetCardStates(userID, cardIDs)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, state := range cardStates {
+			states[state.CardID] = state
+		}
+	}
+
+	for i, card := range deck.Cards {
+		// anki uses millisecond timestamps as ids
+		id := ankiDeckID + int64(i) + 1
+
+		pkg.Notes[id] = format.AnkiNote{
+			ID:      id,
+			GUID:    card.ID,
+			ModelID: format.AnkiBasicModelID,
+			Tags:    ankiTags(card.Tags),
+			Fields: []string{
+				format.RenderMarkdown(card.Question, nil),
+				format.RenderMarkdown(card.Answer, nil),
+			},
+		}
+
+		ankiCard := format.AnkiCard{
+			ID:     id,
+			NoteID: id,
+			DeckID: ankiDeckID,
+			Type:   format.AnkiCardNew,
+			Queue:  format.AnkiCardNew,
+			Due:    int64(i) + 1,
+		}
+
+		if state, ok := states[card.ID]; ok && state.LastReviewedAt != nil {
+			ankiSchedule(&ankiCard, &state, created)
+		}
+
+		pkg.Cards = append(pkg.Cards, ankiCard)
+	}
+
+	var buf bytes.Buffer
+	if err := format.WriteAnkiPackage(&buf, &pkg); err != nil {
+		return nil, err
+	}
+
+	return &entity.ExportFile{
+		Name:        exportFileName(deck.Name, "apkg"),
+		ContentType: "application/apkg",
+		Content:     buf.Bytes(),
+	}, nil
+}
+
+// ankiSchedule turns the memory half life of a card into an anki review
+// interval. The card is due once the half life has passed since the last
+// review, counted in days since the creation of the collection.
+func ankiSchedule(card *format.AnkiCard, state *external.CardState, created time.Time) {
+	interval := int(math.Max(1, math.Round(state.MemoryHalfLife)))
+	lastReview := int64(math.Floor(state.LastReviewedAt.Sub(created).Hours() / 24))
+
+	card.Type = format.AnkiCardReview
+	card.Queue = format.AnkiCardReview
+	card.Interval = interval
+	card.Due = lastReview + int64(interval)
+	card.Factor = format.AnkiDefaultFactor
+	card.Reps = state.NumberPracticed
+	card.Lapses = state.NumberIncorrect
+}
+
+func (u *TransferUseCase) ImportCards(
+	userID, deckID string,
+	file io.Reader,
+	req *entity.CardImportReq,
+) (*entity.CardImportRes, error) {
+	deck, err := authorizeDeck(u.deckStore, userID, deckID, entity.RoleEditor)
+	if err != nil {
+		return nil, err
+	}
+
+	var textCards []format.TextCard
+	var parseErrors []format.ParseError
+
+	mapping := format.ColumnMapping{Question: req.Question, Answer: req.Answer}
+	switch req.Format {
+	case entity.FormatCSV:
+		textCards, parseErrors, err = format.ReadDelimited(file, ',', req.Header, mapping)
+	case entity.FormatTSV:
+		textCards, parseErrors, err = format.ReadDelimited(file, '\t', req.Header, mapping)
+	case entity.FormatMarkdown:
+		textCards, parseErrors, err = format.ReadMarkdown(file)
+	default:
+		return nil, entity.ErrUnknownFormat
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	res := entity.CardImportRes{
+		DryRun: req.DryRun,
+		Cards:  []entity.CardRes{},
+		Errors: []entity.ImportErrorRes{},
+	}
+	mapper.MapLoose(parseErrors, &res.Errors)
+
+	index, err := loadDuplicateIndex(
+		u.deckStore,
+		u.cardStore,
+		userID,
+		deckID,
+		&req.DuplicateReq,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	timestamp := time.Now()
+	cards := []entity.Card{}
+	for _, textCard := range textCards {
+		card := entity.Card{
+			Question:  textCard.Question,
+			Answer:    textCard.Answer,
+			UserID:    deck.UserID,
+			DeckID:    deckID,
+			Source:    entity.SourceImport,
+			CreatedAt: &timestamp,
+			UpdatedAt: &timestamp,
+		}
+
+		sanitizeCard(&card)
+
+		var cardRes entity.CardRes
+		mapper.MapLoose(&card, &cardRes)
+		renderCard(&cardRes)
+		cardRes.Duplicate = index.find(&card)
+		index.add(&card)
+
+		if skipDuplicate(&req.DuplicateReq, cardRes.Duplicate) {
+			cardRes.Skipped = true
+		} else {
+			cards = append(cards, card)
+		}
+		res.Cards = append(res.Cards, cardRes)
+	}
+
+	if err := checkMediaRefs(u.mediaStore, u.deckStore, userID, deckID, cards, nil); err != nil {
+		return nil, err
+	}
+
+	if !req.DryRun && len(cards) > 0 {
+		cardIDs, err := u.cardStore.SaveCards(deckID, deck.UserID, cards)
+		if err != nil {
+			return nil, err
+		}
+
+		created := 0
+		for i := range res.Cards {
+			if !res.Cards[i].Skipped {
+				res.Cards[i].ID = cardIDs[created]
+				created++
+			}
+		}
+
+		operation := newBulkOperation(deck, entity.OperationImport, timestamp)
+		operation.CreatedCards = cardIDs
+		if err := u.undoStore.Save(&operation); err != nil {
+			return nil, err
+		}
+
+		activity := newActivity(userID, deckID, entity.ActivityCardsImport, cardIDs, timestamp)
+		if err := u.activityStore.Save(&activity); err != nil {
+			return nil, err
+		}
+	}
+
+	return &res, nil
+}
+
+func (u *TransferUseCase) ExportCards(
+	userID, deckID, fileFormat string,
+) (*entity.ExportFile, error) {
+	deck, err := authorizeDeck(u.deckStore, userID, deckID, entity.RoleViewer)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := loadCards(u.cardStore, deck); err != nil {
+		return nil, err
+	}
+
+	textCards := []format.TextCard{}
+	mapper.MapLoose(deck.Cards, &textCards)
+
+	var buf bytes.Buffer
+	file := entity.ExportFile{}
+
+	switch fileFormat {
+	case entity.FormatCSV:
+		file.Name = exportFileName(deck.Name, "csv")
+		file.ContentType = "text/csv"
+		err = format.WriteDelimited(&buf, ',', textCards)
+	case entity.FormatTSV:
+		file.Name = exportFileName(deck.Name, "tsv")
+		file.ContentType = "text/tab-separated-values"
+		err = format.WriteDelimited(&buf, '\t', textCards)
+	case entity.FormatMarkdown:
+		file.Name = exportFileName(deck.Name, "md")
+		file.ContentType = "text/markdown"
+		err = format.WriteMarkdown(&buf, textCards)
+	default:
+		return nil, entity.ErrUnknownFormat
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	file.Content = buf.Bytes()
+
+	return &file, nil
+}
@@ -0,0 +1,522 @@
+package usecase
+
+import (
+	"archive/zip"
+	"bytes"
+	"database/sql"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/moshrank/spacey-backend/services/deck-management-service/entity"
+	"github.com/moshrank/spacey-backend/services/deck-management-service/external"
+	"github.com/moshrank/spacey-backend/services/deck-management-service/format"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type LearningServiceMock struct {
+	mock.Mock
+}
+
+func (s *LearningServiceMock) ImportReviews(
+	userID string,
+	reviews []external.CardReview,
+) (int, error) {
+	args := s.Called(userID, reviews)
+	return args.Int(0), args.Error(1)
+}
+
+func (s *LearningServiceMock) GetCardStates(
+	userID string,
+	cardIDs []string,
+) ([]external.CardState, error) {
+	args := s.Called(userID, cardIDs)
+	return args.Get(0).([]external.CardState), args.Error(1)
+}
+
+func (s *LearningServiceMock) GetChangedCardStates(
+	userID string,
+	since time.Time,
+) ([]external.CardState, error) {
+	args := s.Called(userID, since)
+	return args.Get(0).([]external.CardState), args.Error(1)
+}
+
+func (s *LearningServiceMock) MoveCards(cardIDs []string, deckID string) error {
+	args := s.Called(cardIDs, deckID)
+	return args.Error(0)
+}
+
+func (s *LearningServiceMock) GetLearningQueue(
+	userID string,
+	cardIDs []string,
+) ([]external.LearningCard, error) {
+	args := s.Called(userID, cardIDs)
+	return args.Get(0).([]external.LearningCard), args.Error(1)
+}
+
+const testAnkiModels = `{
+	"1": {
+		"name": "Basic",
+		"type": 0,
+		"flds": [{"name": "Front", "ord": 0}, {"name": "Back", "ord": 1}],
+		"tmpls": [{
+			"name": "Card 1",
+			"ord": 0,
+			"qfmt": "{{Front}}",
+			"afmt": "{{FrontSide}}<hr id=answer>{{Back}}{{#Extra}}<br>{{Extra}}{{/Extra}}"
+		}]
+	},
+	"2": {
+		"name": "Cloze",
+		"type": 1,
+		"flds": [{"name": "Text", "ord": 0}],
+		"tmpls": [{"name": "Cloze", "ord": 0, "qfmt": "{{cloze:Text}}", "afmt": "{{cloze:Text}}"}]
+	}
+}`
+
+func newTestAnkiPackage(t *testing.T) *bytes.Reader {
+	path := filepath.Join(t.TempDir(), "collection.anki2")
+
+	db, err := sql.Open("sqlite", path)
+	assert.Nil(t, err)
+
+	statements := []string{
+		"CREATE TABLE col (crt INTEGER, models TEXT, decks TEXT)",
+		`CREATE TABLE notes (id INTEGER, guid TEXT, mid INTEGER, tags TEXT, flds TEXT)`,
+		`CREATE TABLE cards (id INTEGER, nid INTEGER, did INTEGER, ord INTEGER, type INTEGER,
+			queue INTEGER, due INTEGER, ivl INTEGER, factor INTEGER, reps INTEGER,
+			lapses INTEGER)`,
+		`CREATE TABLE revlog (id INTEGER, cid INTEGER, ease INTEGER, ivl INTEGER,
+			lastIvl INTEGER, factor INTEGER, time INTEGER, type INTEGER)`,
+	}
+	for _, statement := range statements {
+		_, err = db.Exec(statement)
+		assert.Nil(t, err)
+	}
+
+	_, err = db.Exec(
+		"INSERT INTO col VALUES (0, ?, ?)",
+		testAnkiModels,
+		`{"1": {"name": "Default"}, "10": {"name": "Languages::Spanish"}}`,
+	)
+	assert.Nil(t, err)
+
+	_, err = db.Exec(
+		`INSERT INTO notes VALUES
+		(100, 'a', 1, ' verbs verbs ', 'hablar' || char(31) || 'to <b>speak</b>'),
+		(101, 'b', 2, '', '{{c1::Madrid}} is the capital'),
+		(102, 'c', 1, 'a_tag_longer_than_thirty_characters', 'comer' || char(31) || 'to eat')`,
+	)
+	assert.Nil(t, err)
+
+	_, err = db.Exec(
+		`INSERT INTO cards VALUES
+		(1000, 100, 10, 0, 2, 2, 0, 1, 2500, 2, 0),
+		(1001, 101, 10, 0, 0, 0, 0, 0, 0, 0, 0),
+		(1002, 102, 1, 0, 0, 0, 0, 0, 0, 0, 0)`,
+	)
+	assert.Nil(t, err)
+
+	_, err = db.Exec(
+		`INSERT INTO revlog VALUES
+		(1600000000000, 1000, 3, 1, 0, 2500, 4000, 0),
+		(1600086400000, 1000, 1, 1, 1, 2500, 4000, 1),
+		(1600172800000, 1000, 0, 0, 0, 0, 0, 4)`,
+	)
+	assert.Nil(t, err)
+	assert.Nil(t, db.Close())
+
+	collection, err := os.ReadFile(path)
+	assert.Nil(t, err)
+
+	var buf bytes.Buffer
+	archive := zip.NewWriter(&buf)
+	for name, content := range map[string][]byte{
+		"collection.anki2": collection,
+		"media":            []byte("{}"),
+	} {
+		file, err := archive.Create(name)
+		assert.Nil(t, err)
+		_, err = file.Write(content)
+		assert.Nil(t, err)
+	}
+	assert.Nil(t, archive.Close())
+
+	return bytes.NewReader(buf.Bytes())
+}
+
+func TestImportAnki(t *testing.T) {
+	pkg := newTestAnkiPackage(t)
+
+	deckStoreMock := new(DeckStoreMock)
+	deckStoreMock.On("Save", mock.MatchedBy(func(deck *entity.Deck) bool {
+		return deck.Name == "Languages"
+	})).Return("1", nil)
+	deckStoreMock.On("Save", mock.MatchedBy(func(deck *entity.Deck) bool {
+		return deck.Name == "Spanish" && deck.ParentID == "1"
+	})).Return("2", nil)
+
+	cardStoreMock := new(CardStoreMock)
+	cardStoreMock.On("SaveCards", "2", "1", mock.Anything).
+		Return([]string{"card_1", "card_2"}, nil)
+
+	learningServiceMock := new(LearningServiceMock)
+	learningServiceMock.On("ImportReviews", "1", mock.Anything).Return(2, nil)
+
+	transferUseCase := NewTransferUseCase(
+		deckStoreMock,
+		cardStoreMock,
+		newUndoStoreMock(),
+		newActivityStoreMock(),
+		newTestPalette(new(DeckStoreMock)),
+		new(MediaStoreMock),
+		learningServiceMock,
+	)
+
+	res, err := transferUseCase.ImportAnki("1", pkg, pkg.Size(), true, nil)
+
+	assert.Nil(t, err)
+	assert.Equal(t, 2, len(res.Decks))
+	assert.Equal(t, "Languages", res.Decks[0].Name)
+	assert.Equal(t, 0, len(res.Decks[0].Cards))
+	assert.Equal(t, "Spanish", res.Decks[1].Name)
+	assert.Equal(t, "1", res.Decks[1].ParentID)
+	assert.Equal(t, "hablar", res.Decks[1].Cards[0].Question)
+	assert.Equal(t, "to speak", res.Decks[1].Cards[0].Answer)
+	assert.Equal(t, []string{"verbs"}, res.Decks[1].Cards[0].Tags)
+	assert.Equal(t, 2, res.ImportedReviews)
+
+	cloze := res.Decks[1].Cards[1]
+	assert.Equal(t, entity.CardTypeCloze, cloze.Type)
+	assert.Equal(t, 1, cloze.Ordinal)
+	assert.Equal(t, "[...] is the capital", cloze.Question)
+	assert.Equal(t, "Madrid is the capital", cloze.Answer)
+	assert.Equal(t, "{{c1::Madrid}} is the capital", cloze.Cloze.Text)
+	assert.NotEmpty(t, cloze.NoteID)
+
+	assert.Equal(t, []entity.UnsupportedNoteRes{
+		{NoteID: 102, Model: "Basic", Reason: entity.ErrInvalidTags.Error()},
+	}, res.Unsupported)
+
+	reviews := learningServiceMock.Calls[0].Arguments.Get(1).([]external.CardReview)
+	assert.Equal(t, 2, len(reviews))
+	assert.Equal(t, "card_1", reviews[0].CardID)
+	assert.True(t, reviews[0].Correct)
+	assert.False(t, reviews[1].Correct)
+}
+
+func TestImportAnkiWithoutHistory(t *testing.T) {
+	pkg := newTestAnkiPackage(t)
+
+	deckStoreMock := new(DeckStoreMock)
+	deckStoreMock.On("Save", mock.Anything).Return("1", nil)
+
+	cardStoreMock := new(CardStoreMock)
+	cardStoreMock.On("SaveCards", "1", "1", mock.Anything).
+		Return([]string{"card_1", "card_2"}, nil)
+
+	learningServiceMock := new(LearningServiceMock)
+
+	transferUseCase := NewTransferUseCase(
+		deckStoreMock,
+		cardStoreMock,
+		newUndoStoreMock(),
+		newActivityStoreMock(),
+		newTestPalette(new(DeckStoreMock)),
+		new(MediaStoreMock),
+		learningServiceMock,
+	)
+
+	res, err := transferUseCase.ImportAnki("1", pkg, pkg.Size(), false, nil)
+
+	assert.Nil(t, err)
+	assert.Equal(t, 0, res.ImportedReviews)
+	learningServiceMock.AssertNotCalled(t, "ImportReviews", mock.Anything, mock.Anything)
+}
+
+func TestImportAnkiSkipsDuplicates(t *testing.T) {
+	pkg := newTestAnkiPackage(t)
+
+	deckStoreMock := new(DeckStoreMock)
+	deckStoreMock.On("FindAll", "1", &entity.DeckQuery{WithCards: true}).Return([]entity.Deck{
+		{ID: "2", Cards: []entity.Card{{ID: "card_2", DeckID: "2", Question: "Hablar"}}},
+	}, "", nil)
+	deckStoreMock.On("Save", mock.Anything).Return("1", nil)
+
+	cardStoreMock := new(CardStoreMock)
+	cardStoreMock.On("SaveCards", "1", "1", mock.Anything).Return([]string{"card_1"}, nil)
+	learningServiceMock := new(LearningServiceMock)
+
+	transferUseCase := NewTransferUseCase(
+		deckStoreMock,
+		cardStoreMock,
+		newUndoStoreMock(),
+		newActivityStoreMock(),
+		newTestPalette(new(DeckStoreMock)),
+		new(MediaStoreMock),
+		learningServiceMock,
+	)
+
+	res, err := transferUseCase.ImportAnki(
+		"1",
+		pkg,
+		pkg.Size(),
+		true,
+		&entity.DuplicateReq{Duplicates: entity.DuplicateScopeAccount, SkipDuplicates: true},
+	)
+
+	assert.Nil(t, err)
+	assert.Equal(t, 1, len(res.Decks[1].Cards))
+	assert.Equal(t, entity.CardTypeCloze, res.Decks[1].Cards[0].Type)
+	assert.Equal(t, 1, len(res.Skipped))
+	assert.Equal(t, "card_2", res.Skipped[0].Duplicate.CardID)
+	learningServiceMock.AssertNotCalled(t, "ImportReviews", mock.Anything, mock.Anything)
+}
+
+func TestImportAnkiReportsMedia(t *testing.T) {
+	var buf bytes.Buffer
+	err := format.WriteAnkiPackage(&buf, &format.AnkiPackage{
+		Models: map[int64]format.AnkiModel{format.AnkiBasicModelID: format.AnkiBasicModel},
+		Decks:  map[int64]format.AnkiDeck{10: {Name: "Spanish"}},
+		Notes: map[int64]format.AnkiNote{
+			100: {
+				ID:      100,
+				ModelID: format.AnkiBasicModelID,
+				Fields:  []string{`hola <img src="wave.jpg">`, "hello [sound:hello.mp3]"},
+			},
+		},
+		Cards: []format.AnkiCard{{ID: 1000, NoteID: 100, DeckID: 10}},
+	})
+	assert.Nil(t, err)
+	pkg := bytes.NewReader(buf.Bytes())
+
+	deckStoreMock := new(DeckStoreMock)
+	deckStoreMock.On("Save", mock.Anything).Return("1", nil)
+
+	cardStoreMock := new(CardStoreMock)
+	cardStoreMock.On("SaveCards", "1", "1", mock.Anything).Return([]string{"card_1"}, nil)
+
+	transferUseCase := NewTransferUseCase(
+		deckStoreMock,
+		cardStoreMock,
+		newUndoStoreMock(),
+		newActivityStoreMock(),
+		newTestPalette(new(DeckStoreMock)),
+		new(MediaStoreMock),
+		new(LearningServiceMock),
+	)
+
+	res, err := transferUseCase.ImportAnki("1", pkg, pkg.Size(), false, nil)
+
+	assert.Nil(t, err)
+	assert.Equal(t, "hola", res.Decks[0].Cards[0].Question)
+	assert.Equal(t, []entity.UnsupportedNoteRes{
+		{
+			NoteID: 100,
+			Model:  format.AnkiBasicModel.Name,
+			Reason: "images and audio are not imported: wave.jpg, hello.mp3",
+		},
+	}, res.Unsupported)
+}
+
+func TestImportAnkiInvalidPackage(t *testing.T) {
+	pkg := bytes.NewReader([]byte("not a zip file"))
+
+	transferUseCase := NewTransferUseCase(
+		new(DeckStoreMock),
+		new(CardStoreMock),
+		newUndoStoreMock(),
+		newActivityStoreMock(),
+		newTestPalette(new(DeckStoreMock)),
+		new(MediaStoreMock),
+		new(LearningServiceMock),
+	)
+
+	_, err := transferUseCase.ImportAnki("1", pkg, pkg.Size(), false, nil)
+
+	assert.NotNil(t, err)
+}
+
+func TestExportAnki(t *testing.T) {
+	reviewedAt := time.Now()
+
+	deckStoreMock := new(DeckStoreMock)
+	deckStoreMock.On("FindByID", "1", "1").Return(&entity.Deck{
+		ID:     "1",
+		Name:   "Spanish: Verbs",
+		UserID: "1",
+	}, nil)
+
+	cardStoreMock := new(CardStoreMock)
+	cardStoreMock.On("FindByDeckID", "1", mock.Anything).Return([]entity.Card{
+		{ID: "card_1", Question: "hablar", Answer: "to speak"},
+		{ID: "card_2", Question: "comer", Answer: "to eat"},
+	}, "", nil)
+
+	learningServiceMock := new(LearningServiceMock)
+	learningServiceMock.On("GetCardStates", "1", []string{"card_1", "card_2"}).
+		Return([]external.CardState{
+			{CardID: "card_1", MemoryHalfLife: 4, NumberPracticed: 3, LastReviewedAt: &reviewedAt},
+		}, nil)
+
+	transferUseCase := NewTransferUseCase(
+		deckStoreMock,
+		cardStoreMock,
+		newUndoStoreMock(),
+		newActivityStoreMock(),
+		newTestPalette(new(DeckStoreMock)),
+		new(MediaStoreMock),
+		learningServiceMock,
+	)
+
+	file, err := transferUseCase.ExportAnki("1", "1", true)
+
+	assert.Nil(t, err)
+	assert.Equal(t, "Spanish Verbs.apkg", file.Name)
+
+	pkg, err := format.ReadAnkiPackage(bytes.NewReader(file.Content), int64(len(file.Content)))
+	assert.Nil(t, err)
+	assert.Equal(t, 2, len(pkg.Cards))
+
+	reviewed := pkg.Cards[0]
+	assert.Equal(t, format.AnkiCardReview, reviewed.Type)
+	assert.Equal(t, 4, reviewed.Interval)
+	assert.Equal(t, int64(4), reviewed.Due)
+	assert.Equal(t, "card_1", pkg.Notes[reviewed.NoteID].GUID)
+
+	assert.Equal(t, format.AnkiCardNew, pkg.Cards[1].Type)
+}
+
+func TestImportCards(t *testing.T) {
+	file := "Q: hablar\nA: to speak\n\nQ: comer\n"
+
+	tests := []struct {
+		testName     string
+		dryRun       bool
+		wantCardID   string
+		wantSaveCall bool
+	}{
+		{"Import", false, "card_1", true},
+		{"Dry Run", true, "", false},
+	}
+
+	for _, test := range tests {
+		t.Run(test.testName, func(t *testing.T) {
+			deckStoreMock := new(DeckStoreMock)
+			deckStoreMock.On("FindByID", "1", "1").Return(&entity.Deck{ID: "1", UserID: "1"}, nil)
+
+			cardStoreMock := new(CardStoreMock)
+			cardStoreMock.On("SaveCards", "1", "1", mock.Anything).Return([]string{"card_1"}, nil)
+
+			transferUseCase := NewTransferUseCase(
+				deckStoreMock,
+				cardStoreMock,
+				newUndoStoreMock(),
+				newActivityStoreMock(),
+				newTestPalette(new(DeckStoreMock)),
+				new(MediaStoreMock),
+				new(LearningServiceMock),
+			)
+
+			res, err := transferUseCase.ImportCards(
+				"1",
+				"1",
+				strings.NewReader(file),
+				&entity.CardImportReq{Format: entity.FormatMarkdown, DryRun: test.dryRun},
+			)
+
+			assert.Nil(t, err)
+			assert.Equal(t, test.dryRun, res.DryRun)
+			assert.Equal(t, []entity.CardRes{
+				{
+					ID:           test.wantCardID,
+					Question:     "hablar",
+					Answer:       "to speak",
+					QuestionHTML: "<p>hablar</p>",
+					AnswerHTML:   "<p>to speak</p>",
+					DeckID:       "1",
+				},
+			}, res.Cards)
+			assert.Equal(
+				t,
+				[]entity.ImportErrorRes{{Line: 4, Message: "answer is empty"}},
+				res.Errors,
+			)
+
+			if test.wantSaveCall {
+				cardStoreMock.AssertNumberOfCalls(t, "SaveCards", 1)
+			} else {
+				cardStoreMock.AssertNotCalled(
+					t,
+					"SaveCards",
+					mock.Anything,
+					mock.Anything,
+					mock.Anything,
+				)
+			}
+		})
+	}
+}
+
+func TestImportCardsAsViewer(t *testing.T) {
+	deckStoreMock := new(DeckStoreMock)
+	deckStoreMock.On("FindByID", "2", "1").Return(&entity.Deck{
+		ID:      "1",
+		UserID:  "1",
+		Members: []entity.DeckMember{{UserID: "2", Role: entity.RoleViewer, Accepted: true}},
+	}, nil)
+
+	transferUseCase := NewTransferUseCase(
+		deckStoreMock,
+		new(CardStoreMock),
+		newUndoStoreMock(),
+		newActivityStoreMock(),
+		newTestPalette(new(DeckStoreMock)),
+		new(MediaStoreMock),
+		new(LearningServiceMock),
+	)
+
+	_, err := transferUseCase.ImportCards(
+		"2",
+		"1",
+		strings.NewReader("hablar,to speak"),
+		&entity.CardImportReq{Format: entity.FormatCSV},
+	)
+
+	assert.Equal(t, entity.ErrForbidden, err)
+}
+
+func TestExportCards(t *testing.T) {
+	deckStoreMock := new(DeckStoreMock)
+	deckStoreMock.On("FindByID", "1", "1").Return(&entity.Deck{
+		ID:     "1",
+		Name:   "Spanish",
+		UserID: "1",
+	}, nil)
+
+	cardStoreMock := new(CardStoreMock)
+	cardStoreMock.On("FindByDeckID", "1", mock.Anything).Return([]entity.Card{
+		{ID: "card_1", Question: "hablar", Answer: "to speak"},
+		{ID: "card_2", Question: "comer", Answer: "to eat, to have lunch"},
+	}, "", nil)
+
+	transferUseCase := NewTransferUseCase(
+		deckStoreMock,
+		cardStoreMock,
+		newUndoStoreMock(),
+		newActivityStoreMock(),
+		newTestPalette(new(DeckStoreMock)),
+		new(MediaStoreMock),
+		new(LearningServiceMock),
+	)
+
+	file, err := transferUseCase.ExportCards("1", "1", entity.FormatCSV)
+
+	assert.Nil(t, err)
+	assert.Equal(t, "Spanish.csv", file.Name)
+	assert.Equal(t, "hablar,to speak\ncomer,\"to eat, to have lunch\"\n", string(file.Content))
+}
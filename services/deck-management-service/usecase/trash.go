@@ -0,0 +1,177 @@
+package usecase
+
+import (
+	"time"
+
+	"github.com/moshrank/spacey-backend/config"
+	"github.com/moshrank/spacey-backend/services/deck-management-service/entity"
+)
+
+type TrashUseCase struct {
+	deckStore     entity.DeckStoreInterface
+	cardStore     entity.CardStoreInterface
+	activityStore entity.ActivityStoreInterface
+	retention     time.Duration
+}
+
+func NewTrashUseCase(
+	deckStore entity.DeckStoreInterface,
+	cardStore entity.CardStoreInterface,
+	activityStore entity.ActivityStoreInterface,
+	cfg config.ConfigInterface,
+) entity.TrashUseCaseInterface {
+	return &TrashUseCase{
+		deckStore:     deckStore,
+		cardStore:     cardStore,
+		activityStore: activityStore,
+		retention:     time.Duration(cfg.GetTrashRetentionDays()) * 24 * time.Hour,
+	}
+}
+
+func (u *TrashUseCase) purgeAt(deletedAt *time.Time) *time.Time {
+	if deletedAt == nil {
+		return nil
+	}
+
+	purgeAt := deletedAt.Add(u.retention)
+
+	return &purgeAt
+}
+
+// GetTrash lists the deleted decks the user owns and the deleted cards of
+// the decks the user can edit.
+func (u *TrashUseCase) GetTrash(userID string) (*entity.TrashRes, error) {
+	res := entity.TrashRes{Decks: []entity.TrashDeckRes{}, Cards: []entity.TrashCardRes{}}
+
+	deletedDecks, err := u.deckStore.FindDeleted(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, deck := range deletedDecks {
+		res.Decks = append(res.Decks, entity.TrashDeckRes{
+			ID:          deck.ID,
+			Name:        deck.Name,
+			Description: deck.Description,
+			Color:       deck.Color,
+			DeletedAt:   deck.DeletedAt,
+			PurgeAt:     u.purgeAt(deck.DeletedAt),
+		})
+	}
+
+	decks, _, err := u.deckStore.FindAll(userID, &entity.DeckQuery{})
+	if err != nil {
+		return nil, err
+	}
+
+	deckIDs := []string{}
+	for _, deck := range decks {
+		if entity.HasRole(deck.Role(userID), entity.RoleEditor) {
+			deckIDs = append(deckIDs, deck.ID)
+		}
+	}
+
+	if len(deckIDs) == 0 {
+		return &res, nil
+	}
+
+	cards, err := u.cardStore.FindDeleted(deckIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, card := range cards {
+		res.Cards = append(res.Cards, entity.TrashCardRes{
+			ID:        card.ID,
+			DeckID:    card.DeckID,
+			Question:  card.Question,
+			Answer:    card.Answer,
+			DeletedAt: card.DeletedAt,
+			PurgeAt:   u.purgeAt(card.DeletedAt),
+		})
+	}
+
+	return &res, nil
+}
+
+// RestoreDeck takes a deck out of the trash together with the sub-decks
+// that were deleted with it. Only owners can delete decks, so deleted decks
+// are looked up by their owner. A deck whose parent is not there anymore is
+// restored at the top level.
+func (u *TrashUseCase) RestoreDeck(userID, deckID string) error {
+	deletedDecks, err := u.deckStore.FindDeleted(userID)
+	if err != nil {
+		return err
+	}
+
+	var deck *entity.Deck
+	for i := range deletedDecks {
+		if deletedDecks[i].ID == deckID {
+			deck = &deletedDecks[i]
+		}
+	}
+
+	if deck == nil {
+		return entity.ErrDeckNotFound
+	}
+
+	deletedWith := []entity.Deck{}
+	for _, deleted := range deletedDecks {
+		if deleted.DeletedAt.Equal(*deck.DeletedAt) {
+			deletedWith = append(deletedWith, deleted)
+		}
+	}
+
+	timestamp := time.Now()
+	for _, id := range append([]string{deckID}, subDeckIDs(deletedWith, deckID)...) {
+		if err := u.deckStore.Restore(userID, id, timestamp); err != nil {
+			return err
+		}
+	}
+
+	activity := newActivity(userID, deckID, entity.ActivityDeckRestore, nil, timestamp)
+	if err := u.activityStore.Save(&activity); err != nil {
+		return err
+	}
+
+	if deck.ParentID == "" {
+		return nil
+	}
+
+	if _, err := u.deckStore.FindByID(userID, deck.ParentID); err != nil {
+		return u.deckStore.UpdateParent(userID, deckID, "", timestamp)
+	}
+
+	return nil
+}
+
+func (u *TrashUseCase) RestoreCard(userID, deckID, cardID string) error {
+	deck, err := authorizeDeck(u.deckStore, userID, deckID, entity.RoleEditor)
+	if err != nil {
+		return err
+	}
+
+	timestamp := time.Now()
+	if err := u.cardStore.RestoreCard(deck.UserID, deckID, cardID, timestamp); err != nil {
+		return err
+	}
+
+	activity := newActivity(userID, deckID, entity.ActivityCardRestore, []string{cardID}, timestamp)
+
+	return u.activityStore.Save(&activity)
+}
+
+// Purge permanently removes the decks and cards that have been in the trash
+// for longer than the retention period and returns how many were removed.
+func (u *TrashUseCase) Purge() (int64, error) {
+	deletedBefore := time.Now().Add(-u.retention)
+
+	decks, err := u.deckStore.Purge(deletedBefore)
+	if err != nil {
+		return 0, err
+	}
+
+	cards, err := u.cardStore.Purge(deletedBefore)
+
+	return decks + cards, err
+}
@@ -0,0 +1,157 @@
+package usecase
+
+import (
+	"testing"
+	"time"
+
+	"github.com/moshrank/spacey-backend/config"
+	"github.com/moshrank/spacey-backend/services/deck-management-service/entity"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+var trashConfig = &config.Config{TrashRetentionDays: 30}
+
+func TestGetTrash(t *testing.T) {
+	deletedAt := time.Date(2022, 3, 1, 12, 0, 0, 0, time.UTC)
+	purgeAt := deletedAt.AddDate(0, 0, 30)
+
+	deckStoreMock := new(DeckStoreMock)
+	deckStoreMock.On("FindDeleted", "1").Return([]entity.Deck{
+		{ID: "deleted_deck", Name: "Old Deck", UserID: "1", DeletedAt: &deletedAt},
+	}, nil)
+	deckStoreMock.On("FindAll", "1", &entity.DeckQuery{}).Return([]entity.Deck{
+		{ID: "own_deck", UserID: "1"},
+		{
+			ID:      "shared_deck",
+			UserID:  "2",
+			Members: []entity.DeckMember{{UserID: "1", Role: entity.RoleViewer, Accepted: true}},
+		},
+	}, "", nil)
+
+	cardStoreMock := new(CardStoreMock)
+	cardStoreMock.On("FindDeleted", []string{"own_deck"}).Return([]entity.Card{
+		{ID: "deleted_card", DeckID: "own_deck", Question: "Q", DeletedAt: &deletedAt},
+	}, nil)
+
+	trashUseCase := NewTrashUseCase(
+		deckStoreMock,
+		cardStoreMock,
+		newActivityStoreMock(),
+		trashConfig,
+	)
+
+	trash, err := trashUseCase.GetTrash("1")
+
+	assert.Nil(t, err)
+	assert.Equal(t, []entity.TrashDeckRes{
+		{ID: "deleted_deck", Name: "Old Deck", DeletedAt: &deletedAt, PurgeAt: &purgeAt},
+	}, trash.Decks)
+	assert.Equal(t, []entity.TrashCardRes{
+		{
+			ID:        "deleted_card",
+			DeckID:    "own_deck",
+			Question:  "Q",
+			DeletedAt: &deletedAt,
+			PurgeAt:   &purgeAt,
+		},
+	}, trash.Cards)
+}
+
+func TestRestoreDeckWithSubDecks(t *testing.T) {
+	deletedAt := time.Date(2022, 3, 1, 12, 0, 0, 0, time.UTC)
+	earlier := deletedAt.Add(-time.Hour)
+
+	deckStoreMock := new(DeckStoreMock)
+	deckStoreMock.On("FindDeleted", "1").Return([]entity.Deck{
+		{ID: "cardio", UserID: "1", ParentID: "medicine", DeletedAt: &deletedAt},
+		{ID: "drugs", UserID: "1", ParentID: "cardio", DeletedAt: &deletedAt},
+		{ID: "old", UserID: "1", ParentID: "cardio", DeletedAt: &earlier},
+	}, nil)
+	deckStoreMock.On("Restore", "1", mock.Anything, mock.Anything).Return(nil)
+	deckStoreMock.On("FindByID", "1", "medicine").Return((*entity.Deck)(nil), assert.AnError)
+	deckStoreMock.On("UpdateParent", "1", "cardio", "", mock.Anything).Return(nil)
+
+	trashUseCase := NewTrashUseCase(
+		deckStoreMock,
+		new(CardStoreMock),
+		newActivityStoreMock(),
+		trashConfig,
+	)
+
+	err := trashUseCase.RestoreDeck("1", "cardio")
+
+	assert.Nil(t, err)
+	deckStoreMock.AssertCalled(t, "Restore", "1", "cardio", mock.Anything)
+	deckStoreMock.AssertCalled(t, "Restore", "1", "drugs", mock.Anything)
+	deckStoreMock.AssertNotCalled(t, "Restore", "1", "old", mock.Anything)
+	deckStoreMock.AssertCalled(t, "UpdateParent", "1", "cardio", "", mock.Anything)
+}
+
+func TestRestoreCard(t *testing.T) {
+	cardStoreMock := new(CardStoreMock)
+	cardStoreMock.On("RestoreCard", "1", "test_deck_id", "test_card_id", mock.Anything).Return(nil)
+
+	trashUseCase := NewTrashUseCase(
+		newOwnedDeckStoreMock(),
+		cardStoreMock,
+		newActivityStoreMock(),
+		trashConfig,
+	)
+
+	err := trashUseCase.RestoreCard("1", "test_deck_id", "test_card_id")
+
+	assert.Nil(t, err)
+}
+
+func TestRestoreCardAsViewer(t *testing.T) {
+	deckStoreMock := new(DeckStoreMock)
+	deckStoreMock.On("FindByID", "2", "test_deck_id").Return(&entity.Deck{
+		ID:      "test_deck_id",
+		UserID:  "1",
+		Members: []entity.DeckMember{{UserID: "2", Role: entity.RoleViewer, Accepted: true}},
+	}, nil)
+
+	cardStoreMock := new(CardStoreMock)
+	trashUseCase := NewTrashUseCase(
+		deckStoreMock,
+		cardStoreMock,
+		newActivityStoreMock(),
+		trashConfig,
+	)
+
+	err := trashUseCase.RestoreCard("2", "test_deck_id", "test_card_id")
+
+	assert.Equal(t, entity.ErrForbidden, err)
+	cardStoreMock.AssertNotCalled(
+		t,
+		"RestoreCard",
+		mock.Anything,
+		mock.Anything,
+		mock.Anything,
+		mock.Anything,
+	)
+}
+
+func TestPurge(t *testing.T) {
+	deckStoreMock := new(DeckStoreMock)
+	deckStoreMock.On("Purge", mock.Anything).Return(int64(1), nil)
+
+	cardStoreMock := new(CardStoreMock)
+	cardStoreMock.On("Purge", mock.Anything).Return(int64(3), nil)
+
+	trashUseCase := NewTrashUseCase(
+		deckStoreMock,
+		cardStoreMock,
+		newActivityStoreMock(),
+		trashConfig,
+	)
+
+	purged, err := trashUseCase.Purge()
+
+	assert.Nil(t, err)
+	assert.Equal(t, int64(4), purged)
+
+	deletedBefore := deckStoreMock.Calls[0].Arguments.Get(0).(time.Time)
+	assert.WithinDuration(t, time.Now().AddDate(0, 0, -30), deletedBefore, time.Minute)
+}
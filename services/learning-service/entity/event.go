@@ -18,6 +18,9 @@ type CardEvent struct {
 	CreatedAt                  *time.Time `bson:"createdAt"`
 	StartedAt                  *time.Time `bson:"startedAt"`
 	FinishedAt                 *time.Time `bson:"finishedAt"`
+	// RecordedAt is when the event was stored, which differs from the time
+	// of the review for imported events. Older events only have CreatedAt.
+	RecordedAt *time.Time `bson:"recordedAt,omitempty"`
 }
 
 type DeckCardEvents struct {
@@ -29,12 +32,19 @@ type CardEventStoreInterface interface {
 	GetLatestEvents(userID string, cardIDs []string) ([]CardEvent, error)
 	GetLatestEvent(userID, cardID string) (*CardEvent, error)
 	CreateCardEvent(event *CardEvent) (string, error)
+	CreateCardEvents(events []CardEvent) error
 	GetCardEventsByDeckIDs(userID string, deckIDs []string) ([]DeckCardEvents, error)
+	MoveCardEvents(cardIDs []string, deckID string) (int64, error)
+	GetChangedCardIDs(userID string, since time.Time) ([]string, error)
 }
 
 type CardEventUsecaseInterface interface {
 	GetLearningCards(userID string, cardIDs []string) ([]CardEventRes, error)
 	CreateCardEvent(userID string, event *CardEventReq) error
+	ImportCardEvents(userID string, events []CardEventImportReq) (int, error)
+	GetCardStates(userID string, cardIDs []string) ([]CardStateRes, error)
+	GetChangedCardStates(userID string, since time.Time) ([]CardStateRes, error)
+	MoveCardEvents(move *CardMoveReq) (int, error)
 	CalculateDeckRecallProbabilities(
 		userID string,
 		deckData []ProbabilitiesReq,
@@ -50,12 +60,48 @@ type CardEventReq struct {
 	Correct           bool       `json:"correct"`
 }
 
+// CardEventImportReq is a review done outside of spacey, e.g. in anki.
+type CardEventImportReq struct {
+	DeckID     string     `json:"deckID"     binding:"required"`
+	CardID     string     `json:"cardID"     binding:"required"`
+	ReviewedAt *time.Time `json:"reviewedAt" binding:"required"`
+	DurationMs int        `json:"durationMs"`
+	Correct    bool       `json:"correct"`
+}
+
 type CardEventRes struct {
 	CardID            string  `json:"cardID"`
 	LearningSessionID string  `json:"learningSessionID"`
 	RecallProbability float64 `json:"recallProbability"`
 }
 
+// CardMoveReq tells that cards were moved to another deck, so that their
+// learning history follows them.
+type CardMoveReq struct {
+	CardIDs []string `json:"cardIDs" binding:"required,min=1"`
+	DeckID  string   `json:"deckID"  binding:"required"`
+}
+
+// LearningQueueReq asks for the learning queue of many cards at once, e.g.
+// of a deck with all of its sub-decks.
+type LearningQueueReq struct {
+	CardIDs []string `json:"cardIDs" binding:"required"`
+}
+
+type CardStatesReq struct {
+	CardIDs []string `json:"cardIDs" binding:"required"`
+}
+
+// CardStateRes is the learning state of a card after its latest review.
+type CardStateRes struct {
+	CardID          string     `json:"cardID"`
+	MemoryHalfLife  float64    `json:"memoryHalfLife"`
+	NumberPracticed int        `json:"numberPracticed"`
+	NumberCorrect   int        `json:"numberCorrect"`
+	NumberIncorrect int        `json:"numberIncorrect"`
+	LastReviewedAt  *time.Time `json:"lastReviewedAt"`
+}
+
 type ProbabilitiesReq struct {
 	DeckID       string `json:"deckID"       binding:"required"`
 	TotalNoCards int    `json:"totalNoCards"`
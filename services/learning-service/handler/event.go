@@ -1,6 +1,8 @@
 package handler
 
 import (
+	"time"
+
 	"github.com/gin-gonic/gin"
 	"github.com/moshrank/spacey-backend/pkg/httpconst"
 	"github.com/moshrank/spacey-backend/pkg/logger"
@@ -17,6 +19,11 @@ type EventHandler struct {
 type EventHandlerInterface interface {
 	GetLearningCards(c *gin.Context)
 	CreateCardEvent(c *gin.Context)
+	ImportCardEvents(c *gin.Context)
+	GetCardStates(c *gin.Context)
+	GetChangedCardStates(c *gin.Context)
+	GetLearningQueue(c *gin.Context)
+	MoveCardEvents(c *gin.Context)
 	GetDeckRecallProbabilities(c *gin.Context)
 }
 
@@ -76,6 +83,121 @@ func (h *EventHandler) CreateCardEvent(c *gin.Context) {
 	httpconst.WriteCreated(c, nil)
 }
 
+func (h *EventHandler) ImportCardEvents(c *gin.Context) {
+	userID := c.Query("userID")
+	if userID == "" {
+		httpconst.WriteBadRequest(c, "userID is required")
+		return
+	}
+
+	var events []entity.CardEventImportReq
+	if err := h.validator.ValidateJSON(c, &events); err != nil {
+		return
+	}
+
+	imported, err := h.usecase.ImportCardEvents(userID, events)
+	if err != nil {
+		httpconst.WriteBadRequest(c, err.Error())
+		return
+	}
+
+	httpconst.WriteCreated(c, gin.H{"imported": imported})
+}
+
+func (h *EventHandler) GetCardStates(c *gin.Context) {
+	userID := c.Query("userID")
+	if userID == "" {
+		httpconst.WriteBadRequest(c, "userID is required")
+		return
+	}
+
+	var req entity.CardStatesReq
+	if err := h.validator.ValidateJSON(c, &req); err != nil {
+		return
+	}
+
+	states, err := h.usecase.GetCardStates(userID, req.CardIDs)
+	if err != nil {
+		httpconst.WriteBadRequest(c, err.Error())
+		return
+	}
+
+	httpconst.WriteSuccess(c, states)
+}
+
+// GetChangedCardStates returns the states of the cards reviewed after the
+// time in the since parameter, or of all reviewed cards if it is missing.
+func (h *EventHandler) GetChangedCardStates(c *gin.Context) {
+	userID := c.Query("userID")
+	if userID == "" {
+		httpconst.WriteBadRequest(c, "userID is required")
+		return
+	}
+
+	var since time.Time
+	if value := c.Query("since"); value != "" {
+		var err error
+		if since, err = time.Parse(time.RFC3339Nano, value); err != nil {
+			httpconst.WriteBadRequest(c, "since must be a RFC 3339 time")
+			return
+		}
+	}
+
+	states, err := h.usecase.GetChangedCardStates(userID, since)
+	if err != nil {
+		httpconst.WriteBadRequest(c, err.Error())
+		return
+	}
+
+	httpconst.WriteSuccess(c, states)
+}
+
+// GetLearningQueue returns the same cards as GetLearningCards, but takes the
+// card ids in the body, so the queue can span more cards than fit into a URL.
+func (h *EventHandler) GetLearningQueue(c *gin.Context) {
+	userID := c.Query("userID")
+	if userID == "" {
+		httpconst.WriteBadRequest(c, "userID is required")
+		return
+	}
+
+	var req entity.LearningQueueReq
+	if err := h.validator.ValidateJSON(c, &req); err != nil {
+		return
+	}
+
+	if len(req.CardIDs) == 0 {
+		httpconst.WriteSuccess(c, []entity.CardEventRes{})
+		return
+	}
+
+	cards, err := h.usecase.GetLearningCards(userID, req.CardIDs)
+	if err != nil {
+		httpconst.WriteBadRequest(c, err.Error())
+		return
+	}
+
+	httpconst.WriteSuccess(c, cards)
+}
+
+// MoveCardEvents is called by the deck management service after cards were
+// moved to another deck. The history of every user learning the cards is
+// moved, so no user id is needed.
+func (h *EventHandler) MoveCardEvents(c *gin.Context) {
+	var move entity.CardMoveReq
+	if err := h.validator.ValidateJSON(c, &move); err != nil {
+		return
+	}
+
+	moved, err := h.usecase.MoveCardEvents(&move)
+	if err != nil {
+		httpconst.WriteBadRequest(c, err.Error())
+		return
+	}
+
+	httpconst.WriteSuccess(c, gin.H{"moved": moved})
+}
+
 func (h *EventHandler) GetDeckRecallProbabilities(c *gin.Context) {
 	userID := c.Query("userID")
 	if userID == "" {
@@ -3,6 +3,7 @@ package handler
 import (
 	"net/http/httptest"
 	"testing"
+	"time"
 
 	"github.com/moshrank/spacey-backend/pkg/testingutil"
 	"github.com/moshrank/spacey-backend/pkg/validator"
@@ -28,6 +29,35 @@ func (u *EventUsecaseMock) CreateCardEvent(userID string, event *entity.CardEven
 	return args.Error(0)
 }
 
+func (u *EventUsecaseMock) ImportCardEvents(
+	userID string,
+	events []entity.CardEventImportReq,
+) (int, error) {
+	args := u.Called(userID, events)
+	return args.Int(0), args.Error(1)
+}
+
+func (u *EventUsecaseMock) GetCardStates(
+	userID string,
+	cardIDs []string,
+) ([]entity.CardStateRes, error) {
+	args := u.Called(userID, cardIDs)
+	return args.Get(0).([]entity.CardStateRes), args.Error(1)
+}
+
+func (u *EventUsecaseMock) GetChangedCardStates(
+	userID string,
+	since time.Time,
+) ([]entity.CardStateRes, error) {
+	args := u.Called(userID, since)
+	return args.Get(0).([]entity.CardStateRes), args.Error(1)
+}
+
+func (u *EventUsecaseMock) MoveCardEvents(move *entity.CardMoveReq) (int, error) {
+	args := u.Called(move)
+	return args.Int(0), args.Error(1)
+}
+
 func (u *EventUsecaseMock) CalculateDeckRecallProbabilities(
 	userID string,
 	deckData []entity.ProbabilitiesReq,
@@ -91,3 +121,169 @@ func TestCreateCardEvent(t *testing.T) {
 	handler.CreateCardEvent(c.Context)
 	assert.Equal(t, expStatusCode, w.Code)
 }
+
+func TestImportCardEvents(t *testing.T) {
+	expStatusCode := 201
+	body := `[{
+		"deckID": "1",
+		"cardID": "1",
+		"reviewedAt": "2020-01-01T00:00:00Z",
+		"durationMs": 3000,
+		"correct": true
+		}]`
+
+	u := &EventUsecaseMock{}
+
+	handler := NewEventHandler(log.New(), u, validator.NewValidator())
+	u.On("ImportCardEvents", "1", mock.Anything).Return(1, nil)
+
+	w := httptest.NewRecorder()
+	c := testingutil.NewTestingContext(w, "POST", "/events/import", body).
+		AddQueryParameter("userID", "1")
+
+	handler.ImportCardEvents(c.Context)
+	assert.Equal(t, expStatusCode, w.Code)
+}
+
+func TestGetCardStates(t *testing.T) {
+	expStatusCode := 200
+	body := `{"cardIDs": ["1", "2"]}`
+
+	u := &EventUsecaseMock{}
+
+	handler := NewEventHandler(log.New(), u, validator.NewValidator())
+	u.On("GetCardStates", "1", []string{"1", "2"}).Return([]entity.CardStateRes{}, nil)
+
+	w := httptest.NewRecorder()
+	c := testingutil.NewTestingContext(w, "POST", "/events/states", body).
+		AddQueryParameter("userID", "1")
+
+	handler.GetCardStates(c.Context)
+	assert.Equal(t, expStatusCode, w.Code)
+}
+
+func TestGetChangedCardStates(t *testing.T) {
+	since := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		testName      string
+		since         string
+		expStatusCode int
+	}{
+		{"Since", "2020-01-01T00:00:00Z", 200},
+		{"All", "", 200},
+		{"Invalid Since", "yesterday", 400},
+	}
+
+	for _, test := range tests {
+		t.Run(test.testName, func(t *testing.T) {
+			u := &EventUsecaseMock{}
+			u.On("GetChangedCardStates", "1", since).Return([]entity.CardStateRes{}, nil)
+			u.On("GetChangedCardStates", "1", time.Time{}).Return([]entity.CardStateRes{}, nil)
+
+			handler := NewEventHandler(log.New(), u, validator.NewValidator())
+
+			w := httptest.NewRecorder()
+			c := testingutil.NewTestingContext(w, "GET", "/events/changes", "").
+				AddQueryParameter("userID", "1")
+			if test.since != "" {
+				c.AddQueryParameter("since", test.since)
+			}
+
+			handler.GetChangedCardStates(c.Context)
+			assert.Equal(t, test.expStatusCode, w.Code)
+		})
+	}
+}
+
+func TestGetLearningQueue(t *testing.T) {
+	tests := []struct {
+		testName       string
+		body           string
+		userID         string
+		wantStatusCode int
+	}{
+		{
+			"Valid Queue",
+			`{"cardIDs": ["1", "2"]}`,
+			"1",
+			200,
+		},
+		{
+			"No Cards",
+			`{"cardIDs": []}`,
+			"1",
+			200,
+		},
+		{
+			"Missing Card IDs",
+			`{}`,
+			"1",
+			400,
+		},
+		{
+			"Missing User ID",
+			`{"cardIDs": ["1", "2"]}`,
+			"",
+			400,
+		},
+	}
+
+	u := &EventUsecaseMock{}
+
+	handler := NewEventHandler(log.New(), u, validator.NewValidator())
+	u.On("GetLearningCards", "1", []string{"1", "2"}).Return([]entity.CardEventRes{
+		{CardID: "2", RecallProbability: 0.2},
+		{CardID: "1", RecallProbability: 0.4},
+	}, nil)
+
+	for _, test := range tests {
+		t.Run(test.testName, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			c := testingutil.NewTestingContext(w, "POST", "/events/queue", test.body).
+				AddQueryParameter("userID", test.userID)
+
+			handler.GetLearningQueue(c.Context)
+			assert.Equal(t, test.wantStatusCode, w.Code)
+		})
+	}
+}
+
+func TestMoveCardEvents(t *testing.T) {
+	tests := []struct {
+		testName       string
+		body           string
+		wantStatusCode int
+	}{
+		{
+			"Valid Move",
+			`{"cardIDs": ["1", "2"], "deckID": "target_deck_id"}`,
+			200,
+		},
+		{
+			"No Cards",
+			`{"cardIDs": [], "deckID": "target_deck_id"}`,
+			400,
+		},
+		{
+			"Missing Deck ID",
+			`{"cardIDs": ["1"]}`,
+			400,
+		},
+	}
+
+	u := &EventUsecaseMock{}
+
+	handler := NewEventHandler(log.New(), u, validator.NewValidator())
+	u.On("MoveCardEvents", mock.Anything).Return(2, nil)
+
+	for _, test := range tests {
+		t.Run(test.testName, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			c := testingutil.NewTestingContext(w, "POST", "/events/move", test.body)
+
+			handler.MoveCardEvents(c.Context)
+			assert.Equal(t, test.wantStatusCode, w.Code)
+		})
+	}
+}
@@ -34,6 +34,11 @@ func runServer(
 		router.PUT("session", sessionHandler.FinishLearningSession)
 		router.POST("event", eventHandler.CreateCardEvent)
 		router.GET("events", eventHandler.GetLearningCards)
+		router.POST("events/import", eventHandler.ImportCardEvents)
+		router.POST("events/states", eventHandler.GetCardStates)
+		router.GET("events/changes", eventHandler.GetChangedCardStates)
+		router.POST("events/queue", eventHandler.GetLearningQueue)
+		router.POST("events/move", eventHandler.MoveCardEvents)
 		router.POST("probabilities", eventHandler.GetDeckRecallProbabilities)
 
 		router.Run(":" + cfg.GetPort())
@@ -3,6 +3,7 @@ package store
 import (
 	"context"
 	"fmt"
+	"time"
 
 	"github.com/moshrank/spacey-backend/pkg/db"
 	"github.com/moshrank/spacey-backend/pkg/logger"
@@ -125,6 +126,72 @@ func (s *EventStore) CreateCardEvent(event *entity.CardEvent) (string, error) {
 	return id, nil
 }
 
+func (s *EventStore) CreateCardEvents(events []entity.CardEvent) error {
+	if len(events) == 0 {
+		return nil
+	}
+
+	docs := make([]interface{}, len(events))
+	for i := range events {
+		docs[i] = events[i]
+	}
+
+	_, err := s.db.CreateDocuments(cardEventCollection, docs)
+	if err != nil {
+		err = errors.Wrap(err, "could not create card events")
+		s.logger.Error(err)
+		return err
+	}
+
+	return nil
+}
+
+// MoveCardEvents assigns the events of the given cards of all users to the
+// deck.
+func (s *EventStore) MoveCardEvents(cardIDs []string, deckID string) (int64, error) {
+	res, err := s.db.UpdateDocuments(
+		cardEventCollection,
+		bson.M{"cardID": bson.M{"$in": cardIDs}},
+		bson.M{"$set": bson.M{"deckID": deckID}},
+	)
+	if err != nil {
+		err = errors.Wrap(err, "could not move card events")
+		s.logger.Error(err)
+		return 0, err
+	}
+
+	return res.ModifiedCount, nil
+}
+
+// GetChangedCardIDs returns the cards of the user with events recorded after
+// the given time, or all cards the user has events for if the time is zero.
+func (s *EventStore) GetChangedCardIDs(userID string, since time.Time) ([]string, error) {
+	filter := bson.M{"userID": userID}
+	if !since.IsZero() {
+		filter["$or"] = []bson.M{
+			{"recordedAt": bson.M{"$gt": since}},
+			{"recordedAt": nil, "createdAt": bson.M{"$gt": since}},
+		}
+	}
+
+	col := s.db.GetDB().Collection(cardEventCollection)
+	values, err := col.Distinct(context.TODO(), "cardID", filter)
+	if err != nil {
+		err = errors.Wrap(err, "could not query changed cards")
+		s.logger.Error(err)
+		return nil, err
+	}
+
+	cardIDs := []string{}
+	for _, value := range values {
+		if cardID, ok := value.(string); ok {
+			cardIDs = append(cardIDs, cardID)
+		}
+	}
+
+	return cardIDs, nil
+}
+
 func (s *EventStore) GetCardEventsByDeckIDs(
 	userID string,
 	deckIDs []string,
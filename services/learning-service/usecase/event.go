@@ -189,6 +189,7 @@ func (u *EventUsecase) createEmptyCardEvent(
 		CreatedAt:                  &now,
 		StartedAt:                  startedAt,
 		FinishedAt:                 finishedAt,
+		RecordedAt:                 &now,
 	}
 
 	return &cardEvent
@@ -245,6 +246,7 @@ func (u *EventUsecase) CreateCardEvent(
 			CreatedAt:                  &now,
 			StartedAt:                  cardEvent.StartedAt,
 			FinishedAt:                 cardEvent.FinishedAt,
+			RecordedAt:                 &now,
 		}
 	}
 
@@ -253,6 +255,145 @@ func (u *EventUsecase) CreateCardEvent(
 	return err
 }
 
+const importedLearningSessionID = "import"
+
+// ImportCardEvents replays reviews done outside of spacey, e.g. in anki or
+// offline, in chronological order on top of the latest event of every card,
+// so the latest event carries the resulting half life. A review older than
+// the latest event of its card is recorded at the time of that event, since
+// it would otherwise not count as the latest one.
+func (u *EventUsecase) ImportCardEvents(
+	userID string,
+	reviews []entity.CardEventImportReq,
+) (int, error) {
+	if len(reviews) == 0 {
+		return 0, nil
+	}
+
+	sort.SliceStable(reviews, func(i, j int) bool {
+		return reviews[i].ReviewedAt.Before(*reviews[j].ReviewedAt)
+	})
+
+	cardIDs := []string{}
+	for _, review := range reviews {
+		cardIDs = append(cardIDs, review.CardID)
+	}
+
+	stored, err := u.store.GetLatestEvents(userID, cardIDs)
+	if err != nil {
+		return 0, err
+	}
+
+	latestEvents := map[string]entity.CardEvent{}
+	for _, event := range stored {
+		latestEvents[event.CardID] = event
+	}
+
+	now := time.Now()
+	events := []entity.CardEvent{}
+
+	for _, review := range reviews {
+		finishedAt := *review.ReviewedAt
+		startedAt := finishedAt.Add(-time.Duration(review.DurationMs) * time.Millisecond)
+
+		createdAt := finishedAt
+		if latest, ok := latestEvents[review.CardID]; ok && latest.CreatedAt != nil &&
+			latest.CreatedAt.After(createdAt) {
+			createdAt = *latest.CreatedAt
+		}
+
+		event := entity.CardEvent{
+			CardID:            review.CardID,
+			UserID:            userID,
+			DeckID:            review.DeckID,
+			LearningSessionID: importedLearningSessionID,
+			CreatedAt:         &createdAt,
+			StartedAt:         &startedAt,
+			FinishedAt:        &finishedAt,
+			RecordedAt:        &now,
+		}
+
+		if latest, ok := latestEvents[review.CardID]; ok {
+			event.MemoryHalfLife = u.calculateHalfLife(latest.MemoryHalfLife, review.Correct)
+			event.NumberPracticed = latest.NumberPracticed
+			event.NumberCorrect = latest.NumberCorrect
+			event.NumberIncorrect = latest.NumberIncorrect
+		} else {
+			event.MemoryHalfLife = u.calculateHalfLife(0., review.Correct)
+		}
+
+		event.NumberPracticed++
+		if review.Correct {
+			event.NumberCorrect++
+		} else {
+			event.NumberIncorrect++
+		}
+		event.NumberPracticedLastSession = event.NumberPracticed
+		event.NumberCorrectLastSession = event.NumberCorrect
+		event.NumberIncorrectLastSession = event.NumberIncorrect
+
+		events = append(events, event)
+		latestEvents[review.CardID] = event
+	}
+
+	if err := u.store.CreateCardEvents(events); err != nil {
+		return 0, err
+	}
+
+	return len(events), nil
+}
+
+func (u *EventUsecase) MoveCardEvents(move *entity.CardMoveReq) (int, error) {
+	moved, err := u.store.MoveCardEvents(move.CardIDs, move.DeckID)
+	if err != nil {
+		return 0, err
+	}
+
+	return int(moved), nil
+}
+
+func (u *EventUsecase) GetCardStates(
+	userID string,
+	cardIDs []string,
+) ([]entity.CardStateRes, error) {
+	events, err := u.store.GetLatestEvents(userID, cardIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	states := []entity.CardStateRes{}
+	for _, event := range events {
+		states = append(states, entity.CardStateRes{
+			CardID:          event.CardID,
+			MemoryHalfLife:  event.MemoryHalfLife,
+			NumberPracticed: event.NumberPracticed,
+			NumberCorrect:   event.NumberCorrect,
+			NumberIncorrect: event.NumberIncorrect,
+			LastReviewedAt:  event.CreatedAt,
+		})
+	}
+
+	return states, nil
+}
+
+// GetChangedCardStates returns the states of the cards reviewed after the
+// given time, or of all cards the user reviewed if the time is zero.
+func (u *EventUsecase) GetChangedCardStates(
+	userID string,
+	since time.Time,
+) ([]entity.CardStateRes, error) {
+	cardIDs, err := u.store.GetChangedCardIDs(userID, since)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(cardIDs) == 0 {
+		return []entity.CardStateRes{}, nil
+	}
+
+	return u.GetCardStates(userID, cardIDs)
+}
+
 func (u *EventUsecase) CalculateDeckRecallProbabilities(
 	userID string,
 	deckData []entity.ProbabilitiesReq,
@@ -2,6 +2,7 @@ package usecase
 
 import (
 	"testing"
+	"time"
 
 	log "github.com/sirupsen/logrus"
 
@@ -35,6 +36,11 @@ func (s *EventStoreMock) CreateCardEvent(event *entity.CardEvent) (string, error
 	return args.String(0), args.Error(1)
 }
 
+func (s *EventStoreMock) CreateCardEvents(events []entity.CardEvent) error {
+	args := s.Called(events)
+	return args.Error(0)
+}
+
 func (s *EventStoreMock) GetCardEventsByDeckIDs(
 	userID string,
 	deckIDs []string,
@@ -43,6 +49,16 @@ func (s *EventStoreMock) GetCardEventsByDeckIDs(
 	return args.Get(0).([]entity.DeckCardEvents), args.Error(1)
 }
 
+func (s *EventStoreMock) MoveCardEvents(cardIDs []string, deckID string) (int64, error) {
+	args := s.Called(cardIDs, deckID)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+func (s *EventStoreMock) GetChangedCardIDs(userID string, since time.Time) ([]string, error) {
+	args := s.Called(userID, since)
+	return args.Get(0).([]string), args.Error(1)
+}
+
 func TestCalculateRecallProbability(t *testing.T) {
 	tests := []struct {
 		testName string
@@ -83,3 +99,117 @@ func TestCalculateRecallProbability(t *testing.T) {
 		})
 	}
 }
+
+func TestImportCardEvents(t *testing.T) {
+	first := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	second := first.Add(24 * time.Hour)
+	third := second.Add(48 * time.Hour)
+
+	eventStoreMock := new(EventStoreMock)
+	eventStoreMock.On("GetLatestEvents", "1", mock.Anything).Return([]entity.CardEvent{}, nil)
+	eventStoreMock.On("CreateCardEvents", mock.Anything).Return(nil)
+
+	usecase := NewEventUsecase(log.New(), eventStoreMock)
+
+	imported, err := usecase.ImportCardEvents("1", []entity.CardEventImportReq{
+		{DeckID: "1", CardID: "1", ReviewedAt: &third, Correct: true},
+		{DeckID: "1", CardID: "1", ReviewedAt: &first, Correct: true},
+		{DeckID: "1", CardID: "2", ReviewedAt: &first, Correct: false},
+		{DeckID: "1", CardID: "1", ReviewedAt: &second, Correct: true},
+	})
+
+	assert.Nil(t, err)
+	assert.Equal(t, 4, imported)
+
+	events := eventStoreMock.Calls[1].Arguments.Get(0).([]entity.CardEvent)
+	last := events[len(events)-1]
+	assert.Equal(t, "1", last.CardID)
+	assert.Equal(t, third, *last.CreatedAt)
+	assert.Equal(t, 4., last.MemoryHalfLife)
+	assert.Equal(t, 3, last.NumberCorrect)
+	assert.Equal(t, 0., events[1].MemoryHalfLife)
+}
+
+func TestImportCardEventsAfterLatestEvent(t *testing.T) {
+	latest := time.Date(2020, 1, 2, 0, 0, 0, 0, time.UTC)
+	offline := latest.Add(-time.Hour)
+
+	eventStoreMock := new(EventStoreMock)
+	eventStoreMock.On("GetLatestEvents", "1", []string{"1"}).Return([]entity.CardEvent{
+		{CardID: "1", MemoryHalfLife: 2, NumberPracticed: 3, NumberCorrect: 2, CreatedAt: &latest},
+	}, nil)
+	eventStoreMock.On("CreateCardEvents", mock.Anything).Return(nil)
+
+	usecase := NewEventUsecase(log.New(), eventStoreMock)
+
+	imported, err := usecase.ImportCardEvents("1", []entity.CardEventImportReq{
+		{DeckID: "1", CardID: "1", ReviewedAt: &offline, Correct: true},
+	})
+
+	assert.Nil(t, err)
+	assert.Equal(t, 1, imported)
+
+	event := eventStoreMock.Calls[1].Arguments.Get(0).([]entity.CardEvent)[0]
+	assert.Equal(t, 4., event.MemoryHalfLife)
+	assert.Equal(t, 4, event.NumberPracticed)
+	assert.Equal(t, 3, event.NumberCorrect)
+	assert.Equal(t, latest, *event.CreatedAt)
+	assert.Equal(t, offline, *event.FinishedAt)
+	assert.NotNil(t, event.RecordedAt)
+}
+
+func TestGetChangedCardStates(t *testing.T) {
+	since := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	eventStoreMock := new(EventStoreMock)
+	eventStoreMock.On("GetChangedCardIDs", "1", since).Return([]string{"1"}, nil)
+	eventStoreMock.On("GetLatestEvents", "1", []string{"1"}).Return([]entity.CardEvent{
+		{CardID: "1", MemoryHalfLife: 1, NumberPracticed: 1},
+	}, nil)
+	eventStoreMock.On("GetChangedCardIDs", "2", since).Return([]string{}, nil)
+
+	usecase := NewEventUsecase(log.New(), eventStoreMock)
+
+	states, err := usecase.GetChangedCardStates("1", since)
+	assert.Nil(t, err)
+	assert.Len(t, states, 1)
+
+	states, err = usecase.GetChangedCardStates("2", since)
+	assert.Nil(t, err)
+	assert.Empty(t, states)
+	eventStoreMock.AssertNumberOfCalls(t, "GetLatestEvents", 1)
+}
+
+func TestGetCardStates(t *testing.T) {
+	reviewedAt := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	eventStoreMock := new(EventStoreMock)
+	eventStoreMock.On("GetLatestEvents", "1", []string{"1", "2"}).Return([]entity.CardEvent{
+		{CardID: "1", MemoryHalfLife: 2, NumberPracticed: 2, CreatedAt: &reviewedAt},
+	}, nil)
+
+	usecase := NewEventUsecase(log.New(), eventStoreMock)
+
+	states, err := usecase.GetCardStates("1", []string{"1", "2"})
+
+	assert.Nil(t, err)
+	assert.Equal(t, []entity.CardStateRes{
+		{CardID: "1", MemoryHalfLife: 2, NumberPracticed: 2, LastReviewedAt: &reviewedAt},
+	}, states)
+}
+
+func TestMoveCardEvents(t *testing.T) {
+	eventStoreMock := new(EventStoreMock)
+	eventStoreMock.On("MoveCardEvents", []string{"1", "2"}, "target_deck_id").
+		Return(int64(5), nil)
+
+	usecase := NewEventUsecase(log.New(), eventStoreMock)
+
+	moved, err := usecase.MoveCardEvents(&entity.CardMoveReq{
+		CardIDs: []string{"1", "2"},
+		DeckID:  "target_deck_id",
+	})
+
+	assert.Nil(t, err)
+	assert.Equal(t, 5, moved)
+}
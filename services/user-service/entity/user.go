@@ -32,6 +32,16 @@ type UserReq struct {
 	Password string `json:"password" binding:"required,min=6"`
 }
 
+type InviteeReq struct {
+	Email string `json:"email" binding:"required,email"`
+}
+
+type InvitationReq struct {
+	Email    string `json:"email"    binding:"required,email"`
+	DeckID   string `json:"deckID"   binding:"required"`
+	DeckName string `json:"deckName" binding:"required"`
+}
+
 type UserStoreInterface interface {
 	SaveUser(user *User) (string, error)
 	GetUserByEmail(email string) (*User, error)
@@ -46,6 +56,11 @@ type UserUsecaseInterface interface {
 	VerifyEmail(userID, token string) (string, error)
 	CreateToken(id string, isBeta, emailVerified bool) (string, error)
 	SendVerificationEmail(id string) error
+	FindInvitee(email string) (*UserResponseModel, error)
+	InviteUser(inviterID string, invitation *InvitationReq) (*UserResponseModel, error)
+	RevokeToken(userID, tokenID string) error
+	RevokeAllTokens(userID string) error
 }
 
 var ErrEmailAlreadyExists = errors.New("email already exists")
+var ErrUserNotFound = errors.New("user not found")
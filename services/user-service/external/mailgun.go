@@ -12,6 +12,7 @@ import (
 
 type EmailSenderInterface interface {
 	SendEmail(recipient, validationLink string) error
+	SendInvitationEmail(recipient, inviterName, deckName, invitationLink string) error
 }
 
 type EmailSender struct {
@@ -59,3 +60,32 @@ func (e *EmailSender) SendEmail(recipient, validationLink string) error {
 
 	return nil
 }
+
+func (e *EmailSender) SendInvitationEmail(
+	recipient, inviterName, deckName, invitationLink string,
+) error {
+	domain := e.cfg.GetMailDomain()
+
+	sender := fmt.Sprintf("Spacey <noreply@%s>", domain)
+	subject := fmt.Sprintf("%s invited you to the deck %s", inviterName, deckName)
+	body := ""
+
+	message := e.mg.NewMessage(sender, subject, body, recipient)
+	message.SetTemplate("deck_invitation")
+	for key, value := range map[string]string{
+		"inviter_name":    inviterName,
+		"deck_name":       deckName,
+		"invitation_link": invitationLink,
+	} {
+		if err := message.AddTemplateVariable(key, value); err != nil {
+			return err
+		}
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second*10)
+	defer cancel()
+
+	_, _, err := e.mg.Send(ctx, message)
+
+	return err
+}
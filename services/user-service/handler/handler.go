@@ -1,6 +1,8 @@
 package handler
 
 import (
+	"errors"
+
 	"github.com/moshrank/spacey-backend/config"
 	"github.com/moshrank/spacey-backend/pkg/httpconst"
 	"github.com/moshrank/spacey-backend/pkg/logger"
@@ -23,9 +25,12 @@ type HandlerInterface interface {
 	CreateUser(c *gin.Context)
 	Login(c *gin.Context)
 	Logout(c *gin.Context)
+	LogoutAll(c *gin.Context)
 	GetUser(c *gin.Context)
 	Validate(c *gin.Context)
 	SendValidationEmail(c *gin.Context)
+	FindInvitee(c *gin.Context)
+	InviteUser(c *gin.Context)
 }
 
 func NewHandler(
@@ -113,9 +118,46 @@ func (h *Handler) Login(c *gin.Context) {
 
 }
 
-func (h *Handler) Logout(c *gin.Context) {
+func (h *Handler) clearAuthCookies(c *gin.Context) {
 	c.SetCookie("Authorization", "", -1, "/", h.config.GetDomain(), false, true)
 	c.SetCookie("LoggedIn", "false", -1, "/", h.config.GetDomain(), false, false)
+}
+
+// Logout revokes the token of the request, so it can not be used again even
+// if it was copied before the cookie was cleared. Tokens without an id can
+// only be revoked with LogoutAll.
+func (h *Handler) Logout(c *gin.Context) {
+	userID := c.Request.URL.Query().Get("userID")
+	tokenID := c.Request.URL.Query().Get("tokenID")
+
+	if userID != "" && tokenID != "" {
+		if err := h.userUsecase.RevokeToken(userID, tokenID); err != nil {
+			h.logger.Error(err)
+			httpconst.WriteInternalServerError(c, "Could not revoke the token.")
+			return
+		}
+	}
+
+	h.clearAuthCookies(c)
+	httpconst.WriteSuccess(c, nil)
+}
+
+// LogoutAll revokes all tokens of the user, logging out every session.
+func (h *Handler) LogoutAll(c *gin.Context) {
+	userID := c.Request.URL.Query().Get("userID")
+
+	if userID == "" {
+		httpconst.WriteBadRequest(c, "userID is required.")
+		return
+	}
+
+	if err := h.userUsecase.RevokeAllTokens(userID); err != nil {
+		h.logger.Error(err)
+		httpconst.WriteInternalServerError(c, "Could not revoke the tokens.")
+		return
+	}
+
+	h.clearAuthCookies(c)
 	httpconst.WriteSuccess(c, nil)
 }
 
@@ -183,3 +225,48 @@ func (h *Handler) SendValidationEmail(c *gin.Context) {
 
 	httpconst.WriteSuccess(c, nil)
 }
+
+func (h *Handler) FindInvitee(c *gin.Context) {
+	var invitee entity.InviteeReq
+	if err := h.validator.ValidateJSON(c, &invitee); err != nil {
+		return
+	}
+
+	userRes, err := h.userUsecase.FindInvitee(invitee.Email)
+	if errors.Is(err, entity.ErrUserNotFound) {
+		httpconst.WriteNotFound(c, "Could not find a user with this email.")
+		return
+	}
+	if err != nil {
+		httpconst.WriteBadRequest(c, err.Error())
+		return
+	}
+
+	httpconst.WriteSuccess(c, userRes)
+}
+
+func (h *Handler) InviteUser(c *gin.Context) {
+	userID := c.Request.URL.Query().Get("userID")
+
+	if userID == "" {
+		httpconst.WriteBadRequest(c, "userID is required.")
+		return
+	}
+
+	var invitation entity.InvitationReq
+	if err := h.validator.ValidateJSON(c, &invitation); err != nil {
+		return
+	}
+
+	userRes, err := h.userUsecase.InviteUser(userID, &invitation)
+	if errors.Is(err, entity.ErrUserNotFound) {
+		httpconst.WriteNotFound(c, "Could not find a user with this email.")
+		return
+	}
+	if err != nil {
+		httpconst.WriteBadRequest(c, err.Error())
+		return
+	}
+
+	httpconst.WriteSuccess(c, userRes)
+}
@@ -3,6 +3,7 @@ package handler
 import (
 	"bytes"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"net/http"
 	"net/http/httptest"
@@ -53,6 +54,29 @@ func (u *UserUsecaseMock) CreateToken(userID string, isBeta, emailVerified bool)
 	return args.Get(0).(string), args.Error(1)
 }
 
+func (u *UserUsecaseMock) FindInvitee(email string) (*entity.UserResponseModel, error) {
+	args := u.Called(email)
+	return args.Get(0).(*entity.UserResponseModel), args.Error(1)
+}
+
+func (u *UserUsecaseMock) InviteUser(
+	inviterID string,
+	invitation *entity.InvitationReq,
+) (*entity.UserResponseModel, error) {
+	args := u.Called(inviterID, invitation)
+	return args.Get(0).(*entity.UserResponseModel), args.Error(1)
+}
+
+func (u *UserUsecaseMock) RevokeToken(userID, tokenID string) error {
+	args := u.Called(userID, tokenID)
+	return args.Error(0)
+}
+
+func (u *UserUsecaseMock) RevokeAllTokens(userID string) error {
+	args := u.Called(userID)
+	return args.Error(0)
+}
+
 func getJSONErr(statusCode int) string {
 	return fmt.Sprintf("{\"error\": \"%s\", \"message\": \"\"}", httpconst.ErrorMapping[statusCode])
 }
@@ -314,3 +338,176 @@ func TestGetUser(t *testing.T) {
 	assert.JSONEq(t, wantBody, w.Body.String())
 
 }
+
+func TestInviteUser(t *testing.T) {
+	tests := []struct {
+		testName       string
+		body           string
+		userID         string
+		wantStatusCode int
+	}{
+		{
+			"Valid Invitation",
+			`{"email": "moritz.e50@gmail.com", "deckID": "1", "deckName": "Test Deck"}`,
+			"1",
+			200,
+		},
+		{
+			"Unknown User",
+			`{"email": "unknown@gmail.com", "deckID": "1", "deckName": "Test Deck"}`,
+			"1",
+			404,
+		},
+		{
+			"Invalid Email",
+			`{"email": "moritz", "deckID": "1", "deckName": "Test Deck"}`,
+			"1",
+			400,
+		},
+		{
+			"Missing User ID",
+			`{"email": "moritz.e50@gmail.com", "deckID": "1", "deckName": "Test Deck"}`,
+			"",
+			400,
+		},
+	}
+
+	usecaseMock := &UserUsecaseMock{}
+	usecaseMock.On("InviteUser", "1", &entity.InvitationReq{
+		Email:    "moritz.e50@gmail.com",
+		DeckID:   "1",
+		DeckName: "Test Deck",
+	}).Return(&entity.UserResponseModel{ID: "2", Email: "moritz.e50@gmail.com"}, nil)
+	usecaseMock.On("InviteUser", "1", mock.Anything).
+		Return(&entity.UserResponseModel{}, entity.ErrUserNotFound)
+
+	conf, _ := config.NewConfig()
+	handler := NewHandler(log.New(), usecaseMock, validator.NewValidator(), conf, nil)
+
+	for _, test := range tests {
+		t.Run(test.testName, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(w)
+			c.Request, _ = http.NewRequest(
+				"POST",
+				"/invitation",
+				bytes.NewBuffer([]byte(test.body)),
+			)
+			q := c.Request.URL.Query()
+			q.Add("userID", test.userID)
+			c.Request.URL.RawQuery = q.Encode()
+
+			handler.InviteUser(c)
+
+			assert.Equal(t, test.wantStatusCode, c.Writer.Status())
+		})
+	}
+}
+
+func TestFindInvitee(t *testing.T) {
+	tests := []struct {
+		testName       string
+		body           string
+		wantStatusCode int
+	}{
+		{
+			"Known User",
+			`{"email": "moritz.e50@gmail.com"}`,
+			200,
+		},
+		{
+			"Unknown User",
+			`{"email": "unknown@gmail.com"}`,
+			404,
+		},
+		{
+			"Invalid Email",
+			`{"email": "moritz"}`,
+			400,
+		},
+	}
+
+	usecaseMock := &UserUsecaseMock{}
+	usecaseMock.On("FindInvitee", "moritz.e50@gmail.com").
+		Return(&entity.UserResponseModel{ID: "2", Email: "moritz.e50@gmail.com"}, nil)
+	usecaseMock.On("FindInvitee", mock.Anything).
+		Return(&entity.UserResponseModel{}, entity.ErrUserNotFound)
+
+	conf, _ := config.NewConfig()
+	handler := NewHandler(log.New(), usecaseMock, validator.NewValidator(), conf, nil)
+
+	for _, test := range tests {
+		t.Run(test.testName, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(w)
+			c.Request, _ = http.NewRequest(
+				"POST",
+				"/invitation/invitee",
+				bytes.NewBuffer([]byte(test.body)),
+			)
+
+			handler.FindInvitee(c)
+
+			assert.Equal(t, test.wantStatusCode, c.Writer.Status())
+		})
+	}
+}
+
+func TestLogout(t *testing.T) {
+	usecaseMock := &UserUsecaseMock{}
+	usecaseMock.On("RevokeToken", "1", "token").Return(nil)
+	conf, _ := config.NewConfig()
+
+	handler := Handler{
+		logger:      log.New(),
+		userUsecase: usecaseMock,
+		validator:   validator.NewValidator(),
+		config:      conf,
+	}
+
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request, _ = http.NewRequest("GET", "/logout?userID=1&tokenID=token", nil)
+
+	handler.Logout(c)
+
+	assert.Equal(t, 200, c.Writer.Status())
+	assert.Contains(t, w.Header().Values("Set-Cookie")[0], "Authorization=;")
+	usecaseMock.AssertCalled(t, "RevokeToken", "1", "token")
+}
+
+func TestLogoutAll(t *testing.T) {
+	tests := []struct {
+		testName       string
+		userID         string
+		revokeErr      error
+		wantStatusCode int
+	}{
+		{"Success", "1", nil, 200},
+		{"Missing UserID", "", nil, 400},
+		{"Store Error", "1", errors.New("db down"), 500},
+	}
+
+	for _, test := range tests {
+		t.Run(test.testName, func(t *testing.T) {
+			usecaseMock := &UserUsecaseMock{}
+			usecaseMock.On("RevokeAllTokens", test.userID).Return(test.revokeErr)
+			conf, _ := config.NewConfig()
+
+			handler := Handler{
+				logger:      log.New(),
+				userUsecase: usecaseMock,
+				validator:   validator.NewValidator(),
+				config:      conf,
+			}
+
+			w := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(w)
+			c.Request, _ = http.NewRequest("POST", "/logout/all?userID="+test.userID, nil)
+
+			handler.LogoutAll(c)
+
+			assert.Equal(t, test.wantStatusCode, c.Writer.Status())
+		})
+	}
+}
@@ -14,26 +14,29 @@ import (
 )
 
 type UserUsecase struct {
-	logger      logger.LoggerInterface
-	jwt         auth.JWTInterface
-	userStore   entity.UserStoreInterface
-	emailSender external.EmailSenderInterface
-	cfg         config.ConfigInterface
+	logger          logger.LoggerInterface
+	jwt             auth.JWTInterface
+	userStore       entity.UserStoreInterface
+	revocationStore auth.RevocationStoreInterface
+	emailSender     external.EmailSenderInterface
+	cfg             config.ConfigInterface
 }
 
 func NewUserUseCase(
 	loggerObj logger.LoggerInterface,
 	jwtObj auth.JWTInterface,
 	userStore entity.UserStoreInterface,
+	revocationStore auth.RevocationStoreInterface,
 	emailSender external.EmailSenderInterface,
 	cfg config.ConfigInterface,
 ) entity.UserUsecaseInterface {
 	return &UserUsecase{
-		logger:      loggerObj,
-		jwt:         jwtObj,
-		userStore:   userStore,
-		emailSender: emailSender,
-		cfg:         cfg,
+		logger:          loggerObj,
+		jwt:             jwtObj,
+		userStore:       userStore,
+		revocationStore: revocationStore,
+		emailSender:     emailSender,
+		cfg:             cfg,
 	}
 }
 
@@ -176,3 +179,78 @@ func (u *UserUsecase) SendVerificationEmail(id string) error {
 
 	return u.emailSender.SendEmail(dbUser.Email, link)
 }
+
+// FindInvitee returns the user with the email, for a deck to add them as a
+// member before they are invited.
+func (u *UserUsecase) FindInvitee(email string) (*entity.UserResponseModel, error) {
+	dbUser, err := u.userStore.GetUserByEmail(strings.ToLower(email))
+	if err != nil {
+		return nil, entity.ErrUserNotFound
+	}
+
+	var respUser entity.UserResponseModel
+	mapper.MapLoose(dbUser, &respUser)
+
+	return &respUser, nil
+}
+
+func (u *UserUsecase) InviteUser(
+	inviterID string,
+	invitation *entity.InvitationReq,
+) (*entity.UserResponseModel, error) {
+	inviter, err := u.userStore.GetUserByID(inviterID)
+	if err != nil {
+		return nil, err
+	}
+
+	dbUser, err := u.userStore.GetUserByEmail(strings.ToLower(invitation.Email))
+	if err != nil {
+		return nil, entity.ErrUserNotFound
+	}
+
+	if u.cfg.GetEnv() == "production" {
+		link := fmt.Sprintf(
+			"https://%s/decks/%s/invitation",
+			u.cfg.GetDomain(),
+			invitation.DeckID,
+		)
+
+		err = u.emailSender.SendInvitationEmail(
+			dbUser.Email,
+			inviter.Name,
+			invitation.DeckName,
+			link,
+		)
+		if err != nil {
+			u.logger.Error(err)
+		}
+	}
+
+	var respUser entity.UserResponseModel
+	mapper.MapLoose(dbUser, &respUser)
+
+	return &respUser, nil
+}
+
+// revoke saves a revocation that is kept until the tokens it covers have
+// expired.
+func (u *UserUsecase) revoke(userID, tokenID string) error {
+	now := time.Now()
+
+	return u.revocationStore.Save(&auth.Revocation{
+		TokenID:   tokenID,
+		UserID:    userID,
+		RevokedAt: now,
+		ExpiresAt: now.Add(time.Duration(u.cfg.GetMaxAgeAuth()) * time.Second),
+	})
+}
+
+// RevokeToken invalidates the token with the id.
+func (u *UserUsecase) RevokeToken(userID, tokenID string) error {
+	return u.revoke(userID, tokenID)
+}
+
+// RevokeAllTokens invalidates all tokens of the user issued until now.
+func (u *UserUsecase) RevokeAllTokens(userID string) error {
+	return u.revoke(userID, "")
+}
@@ -36,6 +36,20 @@ func (m *UserStoreMock) VerifyEmail(id string) error {
 	return args.Error(0)
 }
 
+type RevocationStoreMock struct {
+	mock.Mock
+}
+
+func (m *RevocationStoreMock) Save(revocation *auth.Revocation) error {
+	args := m.Called(revocation)
+	return args.Error(0)
+}
+
+func (m *RevocationStoreMock) FindAll() ([]auth.Revocation, error) {
+	args := m.Called()
+	return args.Get(0).([]auth.Revocation), args.Error(1)
+}
+
 func TestCreateUser(t *testing.T) {
 	tests := []struct {
 		TestName  string
@@ -66,7 +80,7 @@ func TestCreateUser(t *testing.T) {
 		storeMock.On("SaveUser", mock.Anything).Return("1", nil)
 
 		cfg, _ := config.NewConfig()
-		usecase := NewUserUseCase(log.New(), auth.NewJWT(cfg), storeMock, nil, cfg)
+		usecase := NewUserUseCase(log.New(), auth.NewJWT(cfg), storeMock, nil, nil, cfg)
 
 		res, err := usecase.CreateUser(test.inpUser)
 
@@ -75,3 +89,56 @@ func TestCreateUser(t *testing.T) {
 
 	}
 }
+
+func TestRevokeTokens(t *testing.T) {
+	tests := []struct {
+		testName    string
+		revoke      func(usecase entity.UserUsecaseInterface) error
+		wantTokenID string
+	}{
+		{
+			"Single Token",
+			func(usecase entity.UserUsecaseInterface) error {
+				return usecase.RevokeToken("1", "token")
+			},
+			"token",
+		},
+		{
+			"All Tokens",
+			func(usecase entity.UserUsecaseInterface) error {
+				return usecase.RevokeAllTokens("1")
+			},
+			"",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.testName, func(t *testing.T) {
+			revocationStoreMock := &RevocationStoreMock{}
+			revocationStoreMock.On("Save", mock.Anything).Return(nil)
+
+			cfg, _ := config.NewConfig()
+			usecase := NewUserUseCase(
+				log.New(),
+				auth.NewJWT(cfg),
+				&UserStoreMock{},
+				revocationStoreMock,
+				nil,
+				cfg,
+			)
+
+			err := test.revoke(usecase)
+
+			assert.Nil(t, err)
+			revocationStoreMock.AssertCalled(
+				t,
+				"Save",
+				mock.MatchedBy(func(revocation *auth.Revocation) bool {
+					return revocation.UserID == "1" &&
+						revocation.TokenID == test.wantTokenID &&
+						revocation.ExpiresAt.After(revocation.RevokedAt)
+				}),
+			)
+		})
+	}
+}
@@ -49,8 +49,11 @@ func runServer(
 		router.POST("/user", handler.CreateUser)
 		router.POST("/login", handler.Login)
 		router.GET("/logout", handler.Logout)
+		router.POST("/logout/all", handler.LogoutAll)
 		router.GET("/validate", handler.SendValidationEmail)
 		router.POST("/validate", handler.Validate)
+		router.POST("/invitation", handler.InviteUser)
+		router.POST("/invitation/invitee", handler.FindInvitee)
 
 		log.Info("Starting server on port: " + cfg.GetPort())
 		router.Run(":" + cfg.GetPort())
@@ -68,6 +71,7 @@ func main() {
 		fx.Provide(auth.NewJWT),
 		fx.Provide(external.NewEmailSender),
 		fx.Provide(store.NewStore),
+		fx.Provide(auth.NewRevocationStore),
 		fx.Provide(usecase.NewUserUseCase),
 		fx.Provide(handler.NewHandler),
 		fx.Invoke(runServer),